@@ -38,6 +38,8 @@ func main() {
 	// Initialize controllers
 	nodeMonitor := controller.NewNodeMonitor(repo, 2*time.Minute)
 	serviceController := controller.NewServiceController(repo)
+	deploymentController := controller.NewDeploymentController(repo)
+	replicaSetController := controller.NewReplicaSetController(repo)
 	
 	// Initialize load balancer
 	lb := loadbalancer.NewLoadBalancer(repo)
@@ -45,6 +47,8 @@ func main() {
 	// Start controllers
 	nodeMonitor.Start()
 	serviceController.Start()
+	deploymentController.Start()
+	replicaSetController.Start()
 	
 	// Start load balancer
 	if err := lb.Start(*lbPort); err != nil {
@@ -60,6 +64,8 @@ func main() {
 		log.Println("Shutting down services...")
 		nodeMonitor.Stop()
 		serviceController.Stop()
+		deploymentController.Stop()
+		replicaSetController.Stop()
 		lb.Stop()
 		os.Exit(0)
 	}()
@@ -5,46 +5,96 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"mini-k8s-orchestration/internal/scheduler"
 	"mini-k8s-orchestration/internal/storage"
 )
 
-func main() {
-	var (
-		dataDir = flag.String("data-dir", "./data", "Directory to store data")
-		useResourceScheduler = flag.Bool("resource-scheduler", true, "Use resource-aware scheduler")
-	)
-	flag.Parse()
+// options are the flags shared by the scheduler and the simulate subcommand
+type options struct {
+	dataDir              *string
+	configFile           *string
+	profileNames         *string
+	useResourceScheduler *bool
+}
+
+// addFlags registers the shared flags on a flag set
+func addFlags(fs *flag.FlagSet) *options {
+	return &options{
+		dataDir:              fs.String("data-dir", "./data", "Directory to store data"),
+		configFile:           fs.String("config", "", "Scheduler config file choosing the plugins of each profile"),
+		profileNames:         fs.String("profiles", "", "Comma separated profiles of the config file to run (default: all of them)"),
+		useResourceScheduler: fs.Bool("resource-scheduler", true, "Use resource-aware scheduler when no config file is given"),
+	}
+}
+
+// profiles chooses the scheduling profiles from the flags
+func (o *options) profiles() []scheduler.Profile {
+	if *o.configFile == "" {
+		if *o.useResourceScheduler {
+			log.Println("Using resource-aware scheduler")
+			return []scheduler.Profile{scheduler.DefaultProfile()}
+		}
+		log.Println("Using basic scheduler")
+		return []scheduler.Profile{scheduler.BasicProfile()}
+	}
+
+	config, err := scheduler.LoadConfig(*o.configFile)
+	if err != nil {
+		log.Fatalf("Failed to load scheduler config: %v", err)
+	}
+	profiles := config.Profiles
+	if *o.profileNames != "" {
+		profiles = nil
+		for _, name := range strings.Split(*o.profileNames, ",") {
+			profile, ok := config.Profile(strings.TrimSpace(name))
+			if !ok {
+				log.Fatalf("Profile %s not found in %s", name, *o.configFile)
+			}
+			profiles = append(profiles, profile)
+		}
+	}
+	for _, profile := range profiles {
+		log.Printf("Using profile %s from %s", profile.SchedulerName, *o.configFile)
+	}
+	return profiles
+}
+
+// newScheduler opens the database and creates a scheduler running the
+// profiles chosen by the flags
+func (o *options) newScheduler() (*scheduler.Scheduler, *storage.Database) {
+	profiles := o.profiles()
 
 	// Initialize database
-	db, err := storage.NewDatabase(*dataDir)
+	db, err := storage.NewDatabase(*o.dataDir)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer db.Close()
 
 	// Initialize repository
 	repo := storage.NewSQLRepository(db)
 
-	// Create base scheduler
-	baseScheduler := scheduler.NewScheduler(repo)
-
 	// Create scheduler instance
-	var sched interface {
-		Start()
-		Stop()
+	sched, err := scheduler.NewSchedulerWithProfiles(repo, profiles, scheduler.NewInTreeRegistry())
+	if err != nil {
+		log.Fatalf("Failed to create scheduler: %v", err)
 	}
+	return sched, db
+}
 
-	if *useResourceScheduler {
-		log.Println("Using resource-aware scheduler")
-		sched = scheduler.NewResourceScheduler(baseScheduler)
-	} else {
-		log.Println("Using basic scheduler")
-		sched = baseScheduler
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		os.Exit(simulate(os.Args[2:]))
 	}
 
+	opts := addFlags(flag.CommandLine)
+	flag.Parse()
+
+	sched, db := opts.newScheduler()
+	defer db.Close()
+
 	// Start scheduler
 	sched.Start()
 
@@ -55,4 +105,4 @@ func main() {
 
 	log.Println("Shutting down scheduler...")
 	sched.Stop()
-}
\ No newline at end of file
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"mini-k8s-orchestration/internal/scheduler"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// simulate runs the simulate subcommand: it reports where a set of pods, or
+// the replicas of a deployment, would be scheduled without writing anything.
+// It returns the exit code: 0 if every pod fits, 2 if some don't.
+func simulate(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s simulate (--pods=pods.json | --deployment=deployment.json [--replicas=N]) [flags]\n\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Reports where the pods would be scheduled against the current nodes and bound pods, without binding them.")
+		fs.PrintDefaults()
+	}
+	opts := addFlags(fs)
+	podsFile := fs.String("pods", "", "JSON file with a pod or a list of pods to simulate")
+	deploymentFile := fs.String("deployment", "", "JSON file with a deployment whose pods to simulate")
+	replicas := fs.Int("replicas", -1, "Number of deployment replicas to simulate (default: the deployment's spec.replicas)")
+	output := fs.String("output", "table", "Output format: table or json")
+	fs.Parse(args)
+
+	if (*podsFile == "") == (*deploymentFile == "") {
+		fs.Usage()
+		log.Fatal("Exactly one of --pods and --deployment is required")
+	}
+	if *output != "table" && *output != "json" {
+		log.Fatalf("Unknown output format %s", *output)
+	}
+
+	var pods []*types.Pod
+	if *podsFile != "" {
+		var err error
+		if pods, err = readPods(*podsFile); err != nil {
+			log.Fatalf("Failed to read pods: %v", err)
+		}
+	} else {
+		deployment, err := readDeployment(*deploymentFile)
+		if err != nil {
+			log.Fatalf("Failed to read deployment: %v", err)
+		}
+		count := deployment.Spec.Replicas
+		if *replicas >= 0 {
+			count = int32(*replicas)
+		}
+		pods = scheduler.DeploymentPods(deployment, count)
+	}
+
+	sched, db := opts.newScheduler()
+	defer db.Close()
+
+	result, err := sched.Simulate(pods)
+	if err != nil {
+		log.Fatalf("Simulation failed: %v", err)
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			log.Fatalf("Failed to write result: %v", err)
+		}
+	} else {
+		printSimulation(os.Stdout, result)
+	}
+
+	if len(result.Unschedulable) > 0 {
+		return 2
+	}
+	return 0
+}
+
+// readPods reads a pod or a list of pods from a JSON file
+func readPods(path string) ([]*types.Pod, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var pod types.Pod
+		if err := json.Unmarshal(data, &pod); err != nil {
+			return nil, err
+		}
+		return []*types.Pod{&pod}, nil
+	}
+
+	var pods []*types.Pod
+	if err := json.Unmarshal(data, &pods); err != nil {
+		return nil, err
+	}
+	return pods, nil
+}
+
+// readDeployment reads a deployment from a JSON file
+func readDeployment(path string) (*types.Deployment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var deployment types.Deployment
+	if err := json.Unmarshal(data, &deployment); err != nil {
+		return nil, err
+	}
+	if deployment.Metadata.Name == "" {
+		return nil, fmt.Errorf("deployment has no name")
+	}
+	return &deployment, nil
+}
+
+// printSimulation writes the simulation result as tables
+func printSimulation(out io.Writer, result *scheduler.SimulationResult) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "%d pod(s) fit, %d pod(s) don't\n\n", len(result.Placements), len(result.Unschedulable))
+
+	if len(result.Placements) > 0 {
+		fmt.Fprintln(w, "POD\tNODE")
+		for _, placement := range result.Placements {
+			fmt.Fprintf(w, "%s/%s\t%s\n", placement.Namespace, placement.Name, placement.Node)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(result.Unschedulable) > 0 {
+		fmt.Fprintln(w, "UNSCHEDULABLE POD\tREASON")
+		for _, failure := range result.Unschedulable {
+			fmt.Fprintf(w, "%s/%s\t%s\n", failure.Namespace, failure.Name, failure.Reason)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "NODE\tPODS\tNEW PODS\tCPU\tMEMORY")
+	for _, node := range result.Nodes {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%dm/%dm (%.0f%%)\t%dMi/%dMi (%.0f%%)\n",
+			node.Name, node.Pods, node.SimulatedPods,
+			node.RequestedCPU, node.AllocatableCPU, node.CPUPercent,
+			node.RequestedMemory/(1024*1024), node.AllocatableMemory/(1024*1024), node.MemoryPercent)
+	}
+}
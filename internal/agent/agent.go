@@ -2,6 +2,8 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -78,6 +80,7 @@ func (a *NodeAgent) Stop() {
 	close(a.stopCh)
 	a.heartbeatTicker.Stop()
 	a.wg.Wait()
+	a.podManager.Stop()
 }
 
 // registerNode registers the node with the API server
@@ -209,15 +212,35 @@ func (a *NodeAgent) runPodSync() {
 	defer a.wg.Done()
 	log.Printf("Starting pod sync for node %s", a.nodeName)
 
-	syncTicker := time.NewTicker(10 * time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Changes to this node's pods trigger a sync; the ticker is only a resync
+	changes := a.watchPods(ctx)
+	syncTicker := time.NewTicker(30 * time.Second)
 	defer syncTicker.Stop()
 
+	// Exited containers are restarted as their back-off allows
+	restartTicker := time.NewTicker(time.Second)
+	defer restartTicker.Stop()
+
 	for {
 		select {
+		case <-changes:
+			if err := a.syncPods(); err != nil {
+				log.Printf("Failed to sync pods: %v", err)
+			}
 		case <-syncTicker.C:
 			if err := a.syncPods(); err != nil {
 				log.Printf("Failed to sync pods: %v", err)
 			}
+		case <-restartTicker.C:
+			a.podManager.RestartExitedContainers()
+		case <-a.podManager.StatusChanges():
+			// A probe result or restart changed a status; report it
+			if err := a.syncPods(); err != nil {
+				log.Printf("Failed to sync pods: %v", err)
+			}
 		case <-a.stopCh:
 			log.Printf("Stopping pod sync for node %s", a.nodeName)
 			return
@@ -225,6 +248,50 @@ func (a *NodeAgent) runPodSync() {
 	}
 }
 
+// watchPods watches the API server for changes to pods assigned to this node
+// and signals on the returned channel, until ctx is done. Signals are
+// coalesced. The watch resumes from the last seen resourceVersion when the
+// stream breaks and starts over when that version has expired.
+func (a *NodeAgent) watchPods(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		resourceVersion := ""
+		for ctx.Err() == nil {
+			events, err := a.apiClient().WatchPods(ctx, a.nodeName, resourceVersion)
+			if errors.Is(err, ErrResourceVersionExpired) {
+				resourceVersion = ""
+				continue
+			}
+			if err == nil {
+				a.forwardPodEvents(events, &resourceVersion, changes)
+			} else {
+				log.Printf("Failed to watch pods: %v", err)
+			}
+
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return changes
+}
+
+// forwardPodEvents signals a change for every event about this node's pods
+// and records the resourceVersion of each event it reads
+func (a *NodeAgent) forwardPodEvents(events <-chan types.WatchEvent, resourceVersion *string, changes chan<- struct{}) {
+	for event := range events {
+		*resourceVersion = event.ResourceVersion
+
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+}
+
 // syncPods synchronizes the pods running on the node with the API server
 func (a *NodeAgent) syncPods() error {
 	// Get assigned pods from API server
@@ -234,7 +301,47 @@ func (a *NodeAgent) syncPods() error {
 	}
 
 	// Sync pods with pod manager
-	return a.podManager.SyncPods(assignedPods)
+	if err := a.podManager.SyncPods(assignedPods); err != nil {
+		return err
+	}
+
+	// Remove the pods whose deletion is done, and report what is actually
+	// running back to the API server
+	var pods []*types.Pod
+	for _, pod := range assignedPods {
+		if types.IsPodTerminating(pod) && a.podManager.PodTerminated(pod) {
+			if err := a.apiClient().DeletePod(pod); err != nil {
+				log.Printf("Failed to delete terminated pod %s: %v", pod.Metadata.Name, err)
+			}
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	a.pushPodStatuses(pods)
+	return nil
+}
+
+// pushPodStatuses reports the observed status of each pod to the API server.
+// Pods whose status hasn't changed are skipped, so a sync triggered by our
+// own update doesn't write again.
+func (a *NodeAgent) pushPodStatuses(pods []*types.Pod) {
+	for _, pod := range pods {
+		status, err := a.podManager.GetPodStatus(pod)
+		if err != nil {
+			log.Printf("Failed to get status of pod %s: %v", pod.Metadata.Name, err)
+			continue
+		}
+
+		current, _ := json.Marshal(pod.Status)
+		observed, _ := json.Marshal(status)
+		if string(current) == string(observed) {
+			continue
+		}
+
+		if err := a.apiClient().UpdatePodStatus(pod, status); err != nil {
+			log.Printf("Failed to update status of pod %s: %v", pod.Metadata.Name, err)
+		}
+	}
 }
 
 // apiClient returns a client for the API server
@@ -1,10 +1,14 @@
 package agent
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
 	"mini-k8s-orchestration/pkg/types"
@@ -16,12 +20,19 @@ type APIClient interface {
 	UpdateNodeStatus(nodeName string, status *types.NodeStatus) error
 	GetAssignedPods(nodeName string) ([]*types.Pod, error)
 	UpdatePodStatus(pod *types.Pod, status *types.PodStatus) error
+	DeletePod(pod *types.Pod) error
+	WatchPods(ctx context.Context, nodeName, resourceVersion string) (<-chan types.WatchEvent, error)
 }
 
+// ErrResourceVersionExpired is returned when a watch can no longer resume from
+// the requested resourceVersion and must be restarted without one
+var ErrResourceVersionExpired = errors.New("resource version expired")
+
 // HTTPAPIClient implements APIClient using HTTP
 type HTTPAPIClient struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL     string
+	httpClient  *http.Client
+	watchClient *http.Client
 }
 
 // NewAPIClient creates a new API client
@@ -31,6 +42,8 @@ func NewAPIClient(baseURL string) APIClient {
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		// Watches are long-lived, so they are only bounded by their context
+		watchClient: &http.Client{},
 	}
 }
 
@@ -79,6 +92,85 @@ func (c *HTTPAPIClient) UpdatePodStatus(pod *types.Pod, status *types.PodStatus)
 	return c.putJSON(url, status)
 }
 
+// DeletePod removes a terminated pod from the API server. A pod that is
+// already gone counts as deleted.
+func (c *HTTPAPIClient) DeletePod(pod *types.Pod) error {
+	url := fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s?gracePeriodSeconds=0",
+		c.baseURL, pod.Metadata.Namespace, pod.Metadata.Name)
+	
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create DELETE request: %w", err)
+	}
+	
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send DELETE request: %w", err)
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	
+	return nil
+}
+
+// WatchPods streams changes to the pods assigned to a node after
+// resourceVersion. An empty resourceVersion starts with the current pods as
+// ADDED events. The channel is closed when ctx is done or the stream ends.
+func (c *HTTPAPIClient) WatchPods(ctx context.Context, nodeName, resourceVersion string) (<-chan types.WatchEvent, error) {
+	query := url.Values{"watch": {"true"}}
+	if resourceVersion != "" {
+		query.Set("resourceVersion", resourceVersion)
+	}
+	watchURL := fmt.Sprintf("%s/api/v1/nodes/%s/pods?%s", c.baseURL, nodeName, query.Encode())
+	
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, watchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watch request: %w", err)
+	}
+	
+	resp, err := c.watchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch pods: %w", err)
+	}
+	
+	if resp.StatusCode == http.StatusGone {
+		resp.Body.Close()
+		return nil, ErrResourceVersionExpired
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to watch pods: status code %d", resp.StatusCode)
+	}
+	
+	events := make(chan types.WatchEvent)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+		for scanner.Scan() {
+			var event types.WatchEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				return
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	
+	return events, nil
+}
+
 // postJSON sends a POST request with JSON body
 func (c *HTTPAPIClient) postJSON(url string, data interface{}) error {
 	jsonData, err := json.Marshal(data)
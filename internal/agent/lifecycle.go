@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+const (
+	// lifecycleHookTimeout bounds how long a postStart hook may run
+	lifecycleHookTimeout = 30 * time.Second
+	// minStopTimeout is how long a container always has to exit after
+	// SIGTERM, even once a slow preStop hook used up its grace period
+	minStopTimeout = 2 * time.Second
+)
+
+// runLifecycleHook runs a postStart or preStop hook, within timeout: a
+// command in the container, or an HTTP GET against it that must return a
+// status from 200 to 399
+func (pm *PodManager) runLifecycleHook(handler *types.LifecycleHandler, containerID string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	switch {
+	case handler.Exec != nil:
+		return pm.containerRuntime.ExecInContainer(ctx, containerID, handler.Exec.Command)
+	case handler.HTTPGet != nil:
+		status, err := pm.containerRuntime.GetContainerStatus(ctx, containerID)
+		if err != nil {
+			return err
+		}
+		if status == nil || status.IPAddress == "" {
+			return fmt.Errorf("container has no IP address")
+		}
+		return probeHTTP(ctx, handler.HTTPGet, status.IPAddress)
+	default:
+		return fmt.Errorf("lifecycle hook has no action")
+	}
+}
+
+// containerStart is a container that was just started, whose postStart hook
+// still has to run
+type containerStart struct {
+	pod         *types.Pod
+	container   types.Container
+	containerID string
+}
+
+// runPostStartHooks runs the postStart hooks of containers that just
+// started. A container whose hook fails is killed, and restarted by its
+// restart policy rather than failing the pod. Callers must not hold pm.mu.
+func (pm *PodManager) runPostStartHooks(starts []containerStart) {
+	for _, start := range starts {
+		lifecycle := start.container.Lifecycle
+		if lifecycle == nil || lifecycle.PostStart == nil {
+			continue
+		}
+		if err := pm.runLifecycleHook(lifecycle.PostStart, start.containerID, lifecycleHookTimeout); err != nil {
+			pm.killContainer(start.pod, start.container, start.containerID, fmt.Sprintf("postStart hook failed: %v", err))
+		}
+	}
+}
+
+// stopContainer runs the preStop hook of a container, then sends it SIGTERM
+// and kills it if it hasn't exited by the end of its grace period. A failed
+// hook is logged and doesn't keep the container from stopping.
+func (pm *PodManager) stopContainer(container types.Container, containerID string, gracePeriod time.Duration) error {
+	deadline := time.Now().Add(gracePeriod)
+	if container.Lifecycle != nil && container.Lifecycle.PreStop != nil && gracePeriod > 0 {
+		if err := pm.runLifecycleHook(container.Lifecycle.PreStop, containerID, gracePeriod); err != nil {
+			log.Printf("PreStop hook of container %s failed: %v", container.Name, err)
+		}
+	}
+
+	timeout := time.Until(deadline)
+	if gracePeriod > 0 && timeout < minStopTimeout {
+		timeout = minStopTimeout
+	}
+	if timeout < 0 {
+		timeout = 0
+	}
+	return pm.containerRuntime.StopContainer(context.Background(), containerID, int(timeout.Round(time.Second)/time.Second))
+}
+
+// killContainer stops a container the agent gave up on, for the given
+// reason, and restarts it like any container that failed. Callers must not
+// hold pm.mu: the preStop hook and the stop may take the whole grace period.
+func (pm *PodManager) killContainer(pod *types.Pod, container types.Container, containerID, reason string) {
+	key := containerKey(pod, container.Name)
+
+	// The pod may have been deleted or recreated since, or be shutting down
+	pm.mu.Lock()
+	if !pm.podActive(pod) || pm.containerIDs[key] != containerID || pm.killing[key] {
+		pm.mu.Unlock()
+		return
+	}
+	pm.killing[key] = true
+	pm.mu.Unlock()
+
+	log.Printf("Killing container %s of pod %s: %s", container.Name, pod.Metadata.Name, reason)
+	err := pm.stopContainer(container, containerID, types.PodTerminationGracePeriod(pod))
+	if err != nil {
+		log.Printf("Failed to stop container %s: %v", containerID, err)
+	}
+
+	pm.mu.Lock()
+	delete(pm.killing, key)
+	restarted := err == nil && pm.podActive(pod) && pm.exitKilledContainer(pod, container, containerID, reason)
+	pm.mu.Unlock()
+
+	if restarted {
+		pm.runPostStartHooks([]containerStart{{pod: pod, container: container, containerID: containerID}})
+	}
+}
+
+// exitKilledContainer records the exit of a container the agent killed and
+// restarts it as the pod's restart policy says. It reports whether the
+// container was restarted. Callers must hold pm.mu.
+func (pm *PodManager) exitKilledContainer(pod *types.Pod, container types.Container, containerID, reason string) bool {
+	pm.resetProbeResults(containerKey(pod, container.Name))
+	pm.notifyStatusChange()
+
+	status, err := pm.containerRuntime.GetContainerStatus(context.Background(), containerID)
+	if err != nil || status == nil {
+		log.Printf("Failed to get status for container %s: %v", containerID, err)
+		return false
+	}
+	return pm.handleExit(pod, container, containerID, status, reason)
+}
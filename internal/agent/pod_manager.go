@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"sync"
 	"time"
 
@@ -14,136 +15,300 @@ import (
 // PodManager manages the lifecycle of pods on a node
 type PodManager struct {
 	containerRuntime runtime.ContainerRuntime
-	pods            map[string]*types.Pod // podUID -> Pod
-	containerIDs    map[string]string     // containerName -> containerID
-	mu              sync.RWMutex
+	pods             map[string]*types.Pod                     // podUID -> Pod
+	containerIDs     map[string]string                         // containerName -> containerID
+	probeWorkers     map[string][]*probeWorker                 // podUID -> probe workers
+	restartCounts    map[string]int32                          // containerKey -> restarts by the agent
+	backoffs         map[string]*containerBackoff              // containerKey -> restart back-off
+	lastTerminations map[string]types.ContainerStateTerminated // containerKey -> exit before the last restart
+	terminating      map[string]bool                           // podUID -> containers shutting down
+	terminated       map[string]bool                           // podUID -> containers gone, pod not yet removed
+	killing          map[string]bool                           // containerKey -> being stopped to restart
+	mu               sync.RWMutex
+
+	// probeResults holds the passed readiness and startup probes by
+	// container key
+	probeResults  map[probeType]map[string]bool
+	probeMu       sync.Mutex
+	statusChanges chan struct{}
 }
 
 // NewPodManager creates a new pod manager
 func NewPodManager(containerRuntime runtime.ContainerRuntime) *PodManager {
 	return &PodManager{
 		containerRuntime: containerRuntime,
-		pods:            make(map[string]*types.Pod),
-		containerIDs:    make(map[string]string),
+		pods:             make(map[string]*types.Pod),
+		containerIDs:     make(map[string]string),
+		probeWorkers:     make(map[string][]*probeWorker),
+		restartCounts:    make(map[string]int32),
+		backoffs:         make(map[string]*containerBackoff),
+		lastTerminations: make(map[string]types.ContainerStateTerminated),
+		terminating:      make(map[string]bool),
+		terminated:       make(map[string]bool),
+		killing:          make(map[string]bool),
+		probeResults: map[probeType]map[string]bool{
+			readinessProbe: make(map[string]bool),
+			startupProbe:   make(map[string]bool),
+		},
+		statusChanges: make(chan struct{}, 1),
 	}
 }
 
-// SyncPods synchronizes the desired pod state with the actual state
-func (pm *PodManager) SyncPods(desiredPods []*types.Pod) error {
+// StatusChanges signals when a probe changes the status of a pod, so it can
+// be reported without waiting for the next sync. Signals are coalesced.
+func (pm *PodManager) StatusChanges() <-chan struct{} {
+	return pm.statusChanges
+}
+
+// Stop stops probing the containers of all pods
+func (pm *PodManager) Stop() {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
+	for _, pod := range pm.pods {
+		pm.stopProbeWorkers(pod)
+	}
+}
+
+// podRemoval is a pod whose containers are shut down before it is removed
+type podRemoval struct {
+	pod         *types.Pod
+	gracePeriod time.Duration
+}
+
+// SyncPods synchronizes the desired pod state with the actual state.
+// Containers are stopped and postStart hooks run without holding pm.mu, so
+// that they don't hold up the status, probes and restarts of other pods.
+func (pm *PodManager) SyncPods(desiredPods []*types.Pod) error {
+	pm.mu.Lock()
+
 	// Create a map of desired pods for quick lookup
 	desiredPodsMap := make(map[string]*types.Pod)
 	for _, pod := range desiredPods {
 		desiredPodsMap[pod.Metadata.UID] = pod
 	}
 
-	// Find pods to delete (pods that are no longer in the desired state)
+	// Find pods to delete (pods that are no longer in the desired state).
+	// Pods that are terminating are removed once their containers are gone.
 	var podsToDelete []string
 	for uid := range pm.pods {
-		if _, exists := desiredPodsMap[uid]; !exists {
+		if _, exists := desiredPodsMap[uid]; !exists && !pm.terminating[uid] {
 			podsToDelete = append(podsToDelete, uid)
 		}
 	}
+	for uid := range pm.terminated {
+		if _, exists := desiredPodsMap[uid]; !exists {
+			delete(pm.terminated, uid)
+		}
+	}
 
-	// Delete pods that are no longer desired
+	// A pod that is gone without having been deleted gracefully was force
+	// deleted, so nothing waits for its containers to stop
+	var removals []podRemoval
 	for _, uid := range podsToDelete {
-		existingPod := pm.pods[uid]
-		if err := pm.deletePod(existingPod); err != nil {
-			log.Printf("Failed to delete pod %s: %v", existingPod.Metadata.Name, err)
-		}
-		delete(pm.pods, uid)
+		pm.terminating[uid] = true
+		removals = append(removals, podRemoval{pod: pm.pods[uid]})
 	}
 
 	// Create or update pods
+	var created, replacements []*types.Pod
+	var starts []containerStart
 	for _, pod := range desiredPods {
 		existingPod, exists := pm.pods[pod.Metadata.UID]
+		if types.IsPodTerminating(pod) {
+			// Shut down the containers of a pod being deleted, without
+			// holding up the other pods
+			switch {
+			case pm.terminating[pod.Metadata.UID] || pm.terminated[pod.Metadata.UID]:
+			case exists:
+				pm.terminating[pod.Metadata.UID] = true
+				go pm.terminatePod(existingPod, types.PodTerminationGracePeriod(pod))
+			default:
+				// Nothing of the pod runs here
+				pm.terminated[pod.Metadata.UID] = true
+			}
+			continue
+		}
 		if !exists {
 			// New pod, create it
-			if err := pm.createPod(pod); err != nil {
+			podStarts, err := pm.createPod(pod)
+			if err != nil {
 				log.Printf("Failed to create pod %s: %v", pod.Metadata.Name, err)
 				continue
 			}
 			pm.pods[pod.Metadata.UID] = pod
-		} else {
-			// Existing pod, check if it needs to be updated
-			if podNeedsUpdate(existingPod, pod) {
-				// Delete and recreate the pod
-				if err := pm.deletePod(existingPod); err != nil {
-					log.Printf("Failed to delete pod %s for update: %v", existingPod.Metadata.Name, err)
-					continue
-				}
-				if err := pm.createPod(pod); err != nil {
-					log.Printf("Failed to recreate pod %s: %v", pod.Metadata.Name, err)
-					continue
-				}
-				pm.pods[pod.Metadata.UID] = pod
-			}
+			created = append(created, pod)
+			starts = append(starts, podStarts...)
+		} else if podNeedsUpdate(existingPod, pod) {
+			// Delete and recreate the pod
+			pm.terminating[pod.Metadata.UID] = true
+			removals = append(removals, podRemoval{pod: existingPod, gracePeriod: types.PodTerminationGracePeriod(pod)})
+			replacements = append(replacements, pod)
+		}
+	}
+	pm.mu.Unlock()
+
+	// The removed pods shut down side by side
+	var wg sync.WaitGroup
+	for _, removal := range removals {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Printf("Deleting pod %s with a grace period of %s", removal.pod.Metadata.Name, removal.gracePeriod)
+			pm.stopPod(removal.pod, removal.gracePeriod)
+		}()
+	}
+	wg.Wait()
+
+	pm.mu.Lock()
+	for _, removal := range removals {
+		pm.removeContainers(removal.pod)
+		delete(pm.pods, removal.pod.Metadata.UID)
+		delete(pm.terminating, removal.pod.Metadata.UID)
+	}
+	for _, pod := range replacements {
+		podStarts, err := pm.createPod(pod)
+		if err != nil {
+			log.Printf("Failed to recreate pod %s: %v", pod.Metadata.Name, err)
+			continue
+		}
+		pm.pods[pod.Metadata.UID] = pod
+		created = append(created, pod)
+		starts = append(starts, podStarts...)
+	}
+	pm.mu.Unlock()
+
+	// Probing starts once the postStart hooks are done
+	pm.runPostStartHooks(starts)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	for _, pod := range created {
+		if pm.podActive(pod) {
+			pm.startProbeWorkers(pod)
 		}
 	}
 
 	return nil
 }
 
-// createPod creates a new pod
-func (pm *PodManager) createPod(pod *types.Pod) error {
+// createPod creates and starts the containers of a new pod. It returns the
+// started containers, whose postStart hooks the caller runs once it has
+// released pm.mu. Callers must hold pm.mu.
+func (pm *PodManager) createPod(pod *types.Pod) ([]containerStart, error) {
 	log.Printf("Creating pod %s", pod.Metadata.Name)
 
 	// Convert pod to container specs
 	containerSpecs, err := runtime.PodToContainerSpecs(pod)
 	if err != nil {
-		return fmt.Errorf("failed to convert pod to container specs: %w", err)
+		return nil, fmt.Errorf("failed to convert pod to container specs: %w", err)
 	}
 
 	ctx := context.Background()
 
 	// Create and start containers
-	for _, spec := range containerSpecs {
+	var starts []containerStart
+	for i, spec := range containerSpecs {
 		// Pull image
 		if err := pm.containerRuntime.PullImage(ctx, spec.Image); err != nil {
-			return fmt.Errorf("failed to pull image %s: %w", spec.Image, err)
+			return nil, fmt.Errorf("failed to pull image %s: %w", spec.Image, err)
 		}
 
 		// Create container
 		containerID, err := pm.containerRuntime.CreateContainer(ctx, spec)
 		if err != nil {
-			return fmt.Errorf("failed to create container %s: %w", spec.Name, err)
+			return nil, fmt.Errorf("failed to create container %s: %w", spec.Name, err)
 		}
 
 		// Store container ID
-		containerKey := fmt.Sprintf("%s-%s", pod.Metadata.UID, spec.Name)
-		pm.containerIDs[containerKey] = containerID
+		pm.containerIDs[containerKey(pod, spec.Name)] = containerID
 
 		// Start container
 		if err := pm.containerRuntime.StartContainer(ctx, containerID); err != nil {
-			return fmt.Errorf("failed to start container %s: %w", spec.Name, err)
+			return nil, fmt.Errorf("failed to start container %s: %w", spec.Name, err)
 		}
 
 		log.Printf("Started container %s for pod %s with ID %s", spec.Name, pod.Metadata.Name, containerID)
+		starts = append(starts, containerStart{pod: pod, container: pod.Spec.Containers[i], containerID: containerID})
 	}
 
-	return nil
+	return starts, nil
 }
 
-// deletePod deletes a pod and its containers
-func (pm *PodManager) deletePod(pod *types.Pod) error {
-	log.Printf("Deleting pod %s", pod.Metadata.Name)
+// terminatePod shuts down the containers of a pod being deleted: each runs
+// its preStop hook and gets SIGTERM, and is killed if it hasn't exited by the
+// end of the grace period. The containers are then removed and the pod is
+// reported as terminated.
+func (pm *PodManager) terminatePod(pod *types.Pod, gracePeriod time.Duration) {
+	log.Printf("Terminating pod %s with a grace period of %s", pod.Metadata.Name, gracePeriod)
+	pm.stopPod(pod, gracePeriod)
 
-	ctx := context.Background()
+	pm.mu.Lock()
+	pm.removeContainers(pod)
+	delete(pm.pods, pod.Metadata.UID)
+	delete(pm.terminating, pod.Metadata.UID)
+	pm.terminated[pod.Metadata.UID] = true
+	pm.mu.Unlock()
+
+	log.Printf("Terminated pod %s", pod.Metadata.Name)
+	pm.notifyStatusChange()
+}
 
-	// Stop and remove all containers in the pod
+// stopPod stops probing a pod and stops its containers within the grace
+// period. The pod must be marked as terminating, so that its containers
+// aren't restarted meanwhile. Callers must not hold pm.mu.
+func (pm *PodManager) stopPod(pod *types.Pod, gracePeriod time.Duration) {
+	pm.mu.Lock()
+	pm.stopProbeWorkers(pod)
+	containerIDs := make(map[string]string)
 	for _, container := range pod.Spec.Containers {
-		containerKey := fmt.Sprintf("%s-%s", pod.Metadata.UID, container.Name)
-		containerID, exists := pm.containerIDs[containerKey]
+		if containerID, exists := pm.containerIDs[containerKey(pod, container.Name)]; exists {
+			containerIDs[container.Name] = containerID
+		}
+	}
+	pm.mu.Unlock()
+
+	// The containers share the grace period, so they stop side by side
+	var wg sync.WaitGroup
+	for _, container := range pod.Spec.Containers {
+		containerID, exists := containerIDs[container.Name]
 		if !exists {
-			log.Printf("Container %s not found for pod %s", container.Name, pod.Metadata.Name)
 			continue
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pm.stopContainer(container, containerID, gracePeriod); err != nil {
+				log.Printf("Failed to stop container %s: %v", containerID, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// PodTerminated reports whether the containers of a pod being deleted are
+// gone, so the pod can be removed
+func (pm *PodManager) PodTerminated(pod *types.Pod) bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.terminated[pod.Metadata.UID]
+}
 
-		// Stop container with a timeout
-		if err := pm.containerRuntime.StopContainer(ctx, containerID, 30); err != nil {
-			log.Printf("Failed to stop container %s: %v", containerID, err)
+// removeContainers removes the stopped containers of a pod and forgets
+// their state
+func (pm *PodManager) removeContainers(pod *types.Pod) {
+	ctx := context.Background()
+	for _, container := range pod.Spec.Containers {
+		containerKey := containerKey(pod, container.Name)
+		pm.resetProbeResults(containerKey)
+		delete(pm.restartCounts, containerKey)
+		delete(pm.backoffs, containerKey)
+		delete(pm.lastTerminations, containerKey)
+
+		containerID, exists := pm.containerIDs[containerKey]
+		if !exists {
+			log.Printf("Container %s not found for pod %s", container.Name, pod.Metadata.Name)
+			continue
 		}
 
 		// Remove container
@@ -154,84 +319,116 @@ func (pm *PodManager) deletePod(pod *types.Pod) error {
 		// Remove container ID from map
 		delete(pm.containerIDs, containerKey)
 	}
-
-	return nil
 }
 
-// GetPodStatus gets the status of a pod
+// GetPodStatus gets the status of a pod from the state of its containers.
+// Conditions and the start time already recorded on the pod are kept, so the
+// status only changes when the containers do.
 func (pm *PodManager) GetPodStatus(pod *types.Pod) (*types.PodStatus, error) {
 	pm.mu.RLock()
 	defer pm.mu.RUnlock()
 
 	ctx := context.Background()
 	status := &types.PodStatus{
-		Phase:      "Running",
-		Conditions: []types.PodCondition{},
-		StartTime:  &time.Time{},
+		Conditions: append([]types.PodCondition(nil), pod.Status.Conditions...),
+		StartTime:  pod.Status.StartTime,
 	}
 
 	// Check all containers in the pod
 	containerStatuses := make([]types.ContainerStatus, 0, len(pod.Spec.Containers))
 	allRunning := true
+	allReady := true
 	allSucceeded := true
+	anyFailed := false
+	anyRestarting := false
+	allTerminated := true
 
 	for _, container := range pod.Spec.Containers {
-		containerKey := fmt.Sprintf("%s-%s", pod.Metadata.UID, container.Name)
+		containerKey := containerKey(pod, container.Name)
 		containerID, exists := pm.containerIDs[containerKey]
 		if !exists {
 			allRunning = false
 			allSucceeded = false
+			allTerminated = false
 			containerStatuses = append(containerStatuses, types.ContainerStatus{
-				Name:   container.Name,
-				Ready:  false,
-				State:  types.ContainerState{Waiting: &types.ContainerStateWaiting{Reason: "ContainerCreating"}},
-				Image:  container.Image,
+				Name:  container.Name,
+				Ready: false,
+				State: types.ContainerState{Waiting: &types.ContainerStateWaiting{Reason: "ContainerCreating"}},
+				Image: container.Image,
 			})
 			continue
 		}
 
 		// Get container status
 		containerStatus, err := pm.containerRuntime.GetContainerStatus(ctx, containerID)
-		if err != nil {
+		if err != nil || containerStatus == nil {
 			log.Printf("Failed to get status for container %s: %v", containerID, err)
 			allRunning = false
 			allSucceeded = false
+			allTerminated = false
 			containerStatuses = append(containerStatuses, types.ContainerStatus{
-				Name:   container.Name,
-				Ready:  false,
-				State:  types.ContainerState{Waiting: &types.ContainerStateWaiting{Reason: "ContainerStatusUnknown"}},
-				Image:  container.Image,
+				Name:  container.Name,
+				Ready: false,
+				State: types.ContainerState{Waiting: &types.ContainerStateWaiting{Reason: "ContainerStatusUnknown"}},
+				Image: container.Image,
 			})
 			continue
 		}
 
+		// The pod is reachable at the address of its containers
+		if status.PodIP == "" {
+			status.PodIP = containerStatus.IPAddress
+		}
+
+		// The pod started when its first container did
+		if containerStatus.Started > 0 {
+			started := time.Unix(containerStatus.Started, 0)
+			if status.StartTime == nil || started.Before(*status.StartTime) {
+				status.StartTime = &started
+			}
+		}
+
 		// Convert container status
 		var state types.ContainerState
 		ready := false
 
 		switch containerStatus.State {
 		case "running":
+			allTerminated = false
 			state = types.ContainerState{
 				Running: &types.ContainerStateRunning{
 					StartedAt: time.Unix(containerStatus.Started, 0),
 				},
 			}
-			ready = true
+			// A container with a startup or readiness probe is ready once
+			// they pass
+			ready = (container.StartupProbe == nil || pm.probePassed(startupProbe, containerKey)) &&
+				(container.ReadinessProbe == nil || pm.probePassed(readinessProbe, containerKey))
 		case "exited":
 			allRunning = false
-			if containerStatus.ExitCode != 0 {
+			if backoff := pm.backoffs[containerKey]; backoff != nil && !backoff.restartAt.IsZero() {
+				// The container is waiting out its back-off to restart
 				allSucceeded = false
+				allTerminated = false
+				anyRestarting = true
+				state = types.ContainerState{
+					Waiting: &types.ContainerStateWaiting{
+						Reason:  "CrashLoopBackOff",
+						Message: fmt.Sprintf("back-off %s restarting failed container %s of pod %s", backoff.delay, container.Name, pod.Metadata.Name),
+					},
+				}
+				break
 			}
-			state = types.ContainerState{
-				Terminated: &types.ContainerStateTerminated{
-					ExitCode:   containerStatus.ExitCode,
-					FinishedAt: time.Unix(containerStatus.Finished, 0),
-					Reason:     "Completed",
-				},
+			if containerStatus.ExitCode != 0 {
+				allSucceeded = false
+				anyFailed = true
 			}
+			terminated := terminatedState(containerStatus, "")
+			state = types.ContainerState{Terminated: &terminated}
 		default:
 			allRunning = false
 			allSucceeded = false
+			allTerminated = false
 			state = types.ContainerState{
 				Waiting: &types.ContainerStateWaiting{
 					Reason: containerStatus.State,
@@ -239,31 +436,172 @@ func (pm *PodManager) GetPodStatus(pod *types.Pod) (*types.PodStatus, error) {
 			}
 		}
 
+		if !ready {
+			allReady = false
+		}
+		var lastState types.ContainerState
+		if terminated, exists := pm.lastTerminations[containerKey]; exists {
+			lastState.Terminated = &terminated
+		}
 		containerStatuses = append(containerStatuses, types.ContainerStatus{
-			Name:         container.Name,
-			State:        state,
-			Ready:        ready,
-			RestartCount: containerStatus.RestartCount,
-			Image:        containerStatus.Image,
-			ImageID:      containerStatus.ImageID,
-			ContainerID:  containerStatus.ID,
+			Name:                 container.Name,
+			State:                state,
+			LastTerminationState: lastState,
+			Ready:                ready,
+			RestartCount:         containerStatus.RestartCount + pm.restartCounts[containerKey],
+			Image:                containerStatus.Image,
+			ImageID:              containerStatus.ImageID,
+			ContainerID:          containerStatus.ID,
 		})
 	}
 
 	// Set pod phase based on container statuses
-	if allRunning {
+	if types.IsPodTerminating(pod) {
+		status.Phase = types.PodPhaseTerminating
+	} else if allRunning {
 		status.Phase = "Running"
 	} else if allSucceeded {
 		status.Phase = "Succeeded"
+	} else if allTerminated && anyFailed {
+		status.Phase = "Failed"
+	} else if anyRestarting {
+		// Containers waiting to restart keep the pod running
+		status.Phase = "Running"
 	} else {
 		status.Phase = "Pending"
 	}
 
 	status.ContainerStatuses = containerStatuses
 
+	// The pod can serve traffic once all of its containers are running and
+	// pass their readiness probes, and stops as soon as it is being deleted
+	ready := "False"
+	if allRunning && allReady && len(containerStatuses) > 0 && !types.IsPodTerminating(pod) {
+		ready = "True"
+	}
+	setPodCondition(status, "ContainersReady", ready)
+	setPodCondition(status, "Ready", ready)
+
 	return status, nil
 }
 
+// startProbeWorkers starts probing the containers of a pod that have
+// startup, liveness or readiness probes
+func (pm *PodManager) startProbeWorkers(pod *types.Pod) {
+	for _, container := range pod.Spec.Containers {
+		probes := []struct {
+			probeType probeType
+			probe     *types.Probe
+		}{
+			{startupProbe, container.StartupProbe},
+			{livenessProbe, container.LivenessProbe},
+			{readinessProbe, container.ReadinessProbe},
+		}
+		for _, p := range probes {
+			if p.probe == nil {
+				continue
+			}
+			worker := newProbeWorker(pm, pod, container, p.probeType, p.probe)
+			pm.probeWorkers[pod.Metadata.UID] = append(pm.probeWorkers[pod.Metadata.UID], worker)
+			go worker.run()
+		}
+	}
+}
+
+// stopProbeWorkers stops probing the containers of a pod
+func (pm *PodManager) stopProbeWorkers(pod *types.Pod) {
+	for _, worker := range pm.probeWorkers[pod.Metadata.UID] {
+		worker.stop()
+	}
+	delete(pm.probeWorkers, pod.Metadata.UID)
+}
+
+// probePassed returns the last result of a container's readiness or startup
+// probe
+func (pm *PodManager) probePassed(probeType probeType, containerKey string) bool {
+	pm.probeMu.Lock()
+	defer pm.probeMu.Unlock()
+	return pm.probeResults[probeType][containerKey]
+}
+
+// setProbeResult records a readiness or startup probe result, signalling a
+// status change when it differs from the last one
+func (pm *PodManager) setProbeResult(probeType probeType, containerKey string, passed bool) {
+	pm.probeMu.Lock()
+	results := pm.probeResults[probeType]
+	changed := results[containerKey] != passed
+	if passed {
+		results[containerKey] = true
+	} else {
+		delete(results, containerKey)
+	}
+	pm.probeMu.Unlock()
+
+	if changed {
+		pm.notifyStatusChange()
+	}
+}
+
+// resetProbeResults forgets a container's probe results, as when it restarts
+func (pm *PodManager) resetProbeResults(containerKey string) {
+	pm.setProbeResult(startupProbe, containerKey, false)
+	pm.setProbeResult(readinessProbe, containerKey, false)
+}
+
+// notifyStatusChange signals a status change without blocking
+func (pm *PodManager) notifyStatusChange() {
+	select {
+	case pm.statusChanges <- struct{}{}:
+	default:
+	}
+}
+
+// restartContainer kills a container that failed its liveness or startup
+// probe. It is restarted like any container that failed, unless the pod's
+// restart policy is Never.
+func (pm *PodManager) restartContainer(pod *types.Pod, container types.Container, probeType probeType) {
+	pm.mu.RLock()
+	containerID, exists := pm.containerIDs[containerKey(pod, container.Name)]
+	pm.mu.RUnlock()
+	if !exists {
+		return
+	}
+	pm.killContainer(pod, container, containerID, fmt.Sprintf("%s probe failed", probeType))
+}
+
+// podActive reports whether a pod is the current version of one the
+// manager runs, and isn't shutting down. Callers must hold pm.mu.
+func (pm *PodManager) podActive(pod *types.Pod) bool {
+	return pm.pods[pod.Metadata.UID] == pod && !pm.terminating[pod.Metadata.UID]
+}
+
+// containerKey identifies a container of a pod on this node
+func containerKey(pod *types.Pod, containerName string) string {
+	return fmt.Sprintf("%s-%s", pod.Metadata.UID, containerName)
+}
+
+// setPodCondition sets the status of a pod condition, keeping its transition
+// time when the status doesn't change
+func setPodCondition(status *types.PodStatus, conditionType, value string) {
+	for i := range status.Conditions {
+		condition := &status.Conditions[i]
+		if condition.Type != conditionType {
+			continue
+		}
+		if condition.Status != value {
+			condition.Status = value
+			condition.LastTransitionTime = time.Now()
+		}
+		return
+	}
+
+	status.Conditions = append(status.Conditions, types.PodCondition{
+		Type:               conditionType,
+		Status:             value,
+		LastTransitionTime: time.Now(),
+	})
+}
+
 // podNeedsUpdate checks if a pod needs to be updated
 func podNeedsUpdate(oldPod, newPod *types.Pod) bool {
 	// Check if the number of containers has changed
@@ -277,6 +615,12 @@ func podNeedsUpdate(oldPod, newPod *types.Pod) bool {
 		if newContainer.Image != oldContainer.Image {
 			return true
 		}
+		// Check what the container runs
+		if !slices.Equal(newContainer.Command, oldContainer.Command) ||
+			!slices.Equal(newContainer.Args, oldContainer.Args) ||
+			newContainer.WorkingDir != oldContainer.WorkingDir {
+			return true
+		}
 		// Check environment variables
 		if len(newContainer.Env) != len(oldContainer.Env) {
 			return true
@@ -290,4 +634,4 @@ func podNeedsUpdate(oldPod, newPod *types.Pod) bool {
 	}
 
 	return false
-}
\ No newline at end of file
+}
@@ -2,8 +2,16 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"mini-k8s-orchestration/internal/runtime"
 	"mini-k8s-orchestration/pkg/types"
@@ -11,14 +19,20 @@ import (
 
 // MockContainerRuntime is a mock implementation of runtime.ContainerRuntime
 type MockContainerRuntime struct {
-	containers map[string]*runtime.ContainerStatus
-	images     map[string]bool
+	containers   map[string]*runtime.ContainerStatus
+	images       map[string]bool
+	execErrors   map[string]error // command -> result of running it
+	execs        []string         // commands run, with the state of their container
+	stopTimeouts map[string]int   // containerID -> timeout of the last stop
+	onExec       func()           // called as each command runs, if set
 }
 
 func NewMockContainerRuntime() *MockContainerRuntime {
 	return &MockContainerRuntime{
-		containers: make(map[string]*runtime.ContainerStatus),
-		images:     make(map[string]bool),
+		containers:   make(map[string]*runtime.ContainerStatus),
+		images:       make(map[string]bool),
+		execErrors:   make(map[string]error),
+		stopTimeouts: make(map[string]int),
 	}
 }
 
@@ -41,6 +55,7 @@ func (m *MockContainerRuntime) StartContainer(ctx context.Context, containerID s
 }
 
 func (m *MockContainerRuntime) StopContainer(ctx context.Context, containerID string, timeout int) error {
+	m.stopTimeouts[containerID] = timeout
 	if container, exists := m.containers[containerID]; exists {
 		container.State = "exited"
 	}
@@ -93,7 +108,12 @@ func (m *MockContainerRuntime) GetContainerLogs(ctx context.Context, containerID
 }
 
 func (m *MockContainerRuntime) ExecInContainer(ctx context.Context, containerID string, cmd []string) error {
-	return nil
+	command := strings.Join(cmd, " ")
+	m.execs = append(m.execs, command+" ("+m.containers[containerID].State+")")
+	if m.onExec != nil {
+		m.onExec()
+	}
+	return m.execErrors[command]
 }
 
 func (m *MockContainerRuntime) Ping(ctx context.Context) error {
@@ -161,4 +181,591 @@ func TestPodManager(t *testing.T) {
 	if len(containers) != 0 {
 		t.Fatalf("Expected 0 containers, got %d", len(containers))
 	}
-}
\ No newline at end of file
+}
+
+func TestGetPodStatus(t *testing.T) {
+	mockRuntime := NewMockContainerRuntime()
+	podManager := NewPodManager(mockRuntime)
+	
+	pod := &types.Pod{
+		Metadata: types.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			UID:       "pod-123",
+		},
+		Spec: types.PodSpec{
+			Containers: []types.Container{
+				{Name: "nginx", Image: "nginx:latest"},
+			},
+		},
+		Status: types.PodStatus{
+			Phase: "Scheduled",
+			Conditions: []types.PodCondition{
+				{Type: "PodScheduled", Status: "True"},
+			},
+		},
+	}
+	
+	if err := podManager.SyncPods([]*types.Pod{pod}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	
+	container := mockRuntime.containers["container-nginx"]
+	container.IPAddress = "172.17.0.2"
+	container.RestartCount = 3
+	container.Started = 1700000000
+	
+	status, err := podManager.GetPodStatus(pod)
+	if err != nil {
+		t.Fatalf("Failed to get pod status: %v", err)
+	}
+	
+	if status.Phase != "Running" {
+		t.Errorf("Expected phase Running, got %s", status.Phase)
+	}
+	if status.PodIP != "172.17.0.2" {
+		t.Errorf("Expected pod IP 172.17.0.2, got %s", status.PodIP)
+	}
+	if status.StartTime == nil || status.StartTime.Unix() != 1700000000 {
+		t.Errorf("Expected start time from the container, got %v", status.StartTime)
+	}
+	if len(status.ContainerStatuses) != 1 || status.ContainerStatuses[0].RestartCount != 3 {
+		t.Fatalf("Expected container status with 3 restarts, got %+v", status.ContainerStatuses)
+	}
+	
+	conditions := make(map[string]string)
+	for _, condition := range status.Conditions {
+		conditions[condition.Type] = condition.Status
+	}
+	for _, conditionType := range []string{"PodScheduled", "ContainersReady", "Ready"} {
+		if conditions[conditionType] != "True" {
+			t.Errorf("Expected condition %s to be True, got %q", conditionType, conditions[conditionType])
+		}
+	}
+	
+	// A container that exited with an error fails the pod
+	container.State = "exited"
+	container.ExitCode = 1
+	
+	status, err = podManager.GetPodStatus(pod)
+	if err != nil {
+		t.Fatalf("Failed to get pod status: %v", err)
+	}
+	if status.Phase != "Failed" {
+		t.Errorf("Expected phase Failed, got %s", status.Phase)
+	}
+	if status.ContainerStatuses[0].State.Terminated == nil || status.ContainerStatuses[0].State.Terminated.ExitCode != 1 {
+		t.Errorf("Expected terminated container with exit code 1, got %+v", status.ContainerStatuses[0].State)
+	}
+}
+
+// probedPod is a pod with one container and the given probes. The probes
+// run once an hour, so tests drive them by hand.
+func probedPod(liveness, readiness *types.Probe) *types.Pod {
+	for _, probe := range []*types.Probe{liveness, readiness} {
+		if probe != nil {
+			probe.PeriodSeconds = 3600
+		}
+	}
+	return &types.Pod{
+		Metadata: types.ObjectMeta{Name: "web", Namespace: "default", UID: "pod-web"},
+		Spec: types.PodSpec{
+			Containers: []types.Container{{
+				Name:           "app",
+				Image:          "app:latest",
+				LivenessProbe:  liveness,
+				ReadinessProbe: readiness,
+			}},
+		},
+	}
+}
+
+// podReady returns whether the pod and its container are reported ready
+func podReady(t *testing.T, podManager *PodManager, pod *types.Pod) (bool, bool) {
+	status, err := podManager.GetPodStatus(pod)
+	if err != nil {
+		t.Fatalf("Failed to get pod status: %v", err)
+	}
+	podReady := false
+	for _, condition := range status.Conditions {
+		if condition.Type == "Ready" {
+			podReady = condition.Status == "True"
+		}
+	}
+	return podReady, status.ContainerStatuses[0].Ready
+}
+
+func TestReadinessProbe(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" || !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+
+	mockRuntime := NewMockContainerRuntime()
+	podManager := NewPodManager(mockRuntime)
+	pod := probedPod(nil, &types.Probe{
+		HTTPGet:          &types.HTTPGetAction{Path: "/healthz", Port: int32(port)},
+		FailureThreshold: 2,
+		SuccessThreshold: 2,
+	})
+	if err := podManager.SyncPods([]*types.Pod{pod}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	defer podManager.SyncPods(nil)
+	mockRuntime.containers["container-app"].IPAddress = "127.0.0.1"
+
+	// Running isn't enough until the probe passes
+	if podIsReady, containerIsReady := podReady(t, podManager, pod); podIsReady || containerIsReady {
+		t.Errorf("Expected pod and container not to be ready before probing")
+	}
+
+	// The probe passes after two successes
+	worker := podManager.probeWorkers[pod.Metadata.UID][0]
+	worker.doProbe()
+	if podIsReady, _ := podReady(t, podManager, pod); podIsReady {
+		t.Errorf("Expected pod not to be ready below the success threshold")
+	}
+	worker.doProbe()
+	if podIsReady, containerIsReady := podReady(t, podManager, pod); !podIsReady || !containerIsReady {
+		t.Errorf("Expected pod and container to be ready after a passing probe")
+	}
+	select {
+	case <-podManager.StatusChanges():
+	default:
+		t.Error("Expected the readiness change to be signalled")
+	}
+
+	// One failure is tolerated, the second reaches the threshold
+	healthy = false
+	worker.doProbe()
+	if podIsReady, _ := podReady(t, podManager, pod); !podIsReady {
+		t.Error("Expected pod to stay ready below the failure threshold")
+	}
+	worker.doProbe()
+	if podIsReady, containerIsReady := podReady(t, podManager, pod); podIsReady || containerIsReady {
+		t.Error("Expected pod and container not to be ready after failing the probe")
+	}
+}
+
+func TestLivenessProbe(t *testing.T) {
+	tests := []struct {
+		restartPolicy string
+		state         string
+		restarts      int32
+	}{
+		{"Always", "running", 1},
+		{"OnFailure", "running", 1},
+		{"Never", "exited", 0},
+	}
+	for _, tt := range tests {
+		mockRuntime := NewMockContainerRuntime()
+		podManager := NewPodManager(mockRuntime)
+		pod := probedPod(&types.Probe{
+			Exec:             &types.ExecAction{Command: []string{"cat", "/tmp/healthy"}},
+			FailureThreshold: 1,
+		}, nil)
+		pod.Spec.RestartPolicy = tt.restartPolicy
+		if err := podManager.SyncPods([]*types.Pod{pod}); err != nil {
+			t.Fatalf("Failed to sync pods: %v", err)
+		}
+
+		// A passing probe leaves the container alone
+		worker := podManager.probeWorkers[pod.Metadata.UID][0]
+		worker.doProbe()
+		status, _ := podManager.GetPodStatus(pod)
+		if status.ContainerStatuses[0].RestartCount != 0 {
+			t.Errorf("%s: expected no restarts while the probe passes", tt.restartPolicy)
+		}
+
+		mockRuntime.execErrors["cat /tmp/healthy"] = errors.New("command exited with code 1")
+		worker.doProbe()
+		status, _ = podManager.GetPodStatus(pod)
+		if state := mockRuntime.containers["container-app"].State; state != tt.state {
+			t.Errorf("%s: expected container %s, got %s", tt.restartPolicy, tt.state, state)
+		}
+		if restarts := status.ContainerStatuses[0].RestartCount; restarts != tt.restarts {
+			t.Errorf("%s: expected %d restarts, got %d", tt.restartPolicy, tt.restarts, restarts)
+		}
+		podManager.SyncPods(nil)
+	}
+}
+
+func TestStartupProbe(t *testing.T) {
+	mockRuntime := NewMockContainerRuntime()
+	podManager := NewPodManager(mockRuntime)
+	pod := probedPod(&types.Probe{
+		Exec:             &types.ExecAction{Command: []string{"cat", "/tmp/healthy"}},
+		FailureThreshold: 1,
+	}, nil)
+	pod.Spec.Containers[0].StartupProbe = &types.Probe{
+		Exec:             &types.ExecAction{Command: []string{"cat", "/tmp/started"}},
+		PeriodSeconds:    3600,
+		FailureThreshold: 2,
+	}
+	if err := podManager.SyncPods([]*types.Pod{pod}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	defer podManager.SyncPods(nil)
+
+	workers := make(map[probeType]*probeWorker)
+	for _, worker := range podManager.probeWorkers[pod.Metadata.UID] {
+		workers[worker.probeType] = worker
+	}
+	restarts := func() int32 {
+		status, _ := podManager.GetPodStatus(pod)
+		return status.ContainerStatuses[0].RestartCount
+	}
+
+	// The liveness probe waits for the container to start
+	mockRuntime.execErrors["cat /tmp/started"] = errors.New("command exited with code 1")
+	mockRuntime.execErrors["cat /tmp/healthy"] = errors.New("command exited with code 1")
+	workers[livenessProbe].doProbe()
+	if restarts() != 0 {
+		t.Error("Expected the liveness probe to be held off until the startup probe passes")
+	}
+	if podIsReady, _ := podReady(t, podManager, pod); podIsReady {
+		t.Error("Expected pod not to be ready before it started")
+	}
+
+	// Failing the startup probe restarts the container
+	workers[startupProbe].doProbe()
+	workers[startupProbe].doProbe()
+	if restarts() != 1 {
+		t.Errorf("Expected the failed startup probe to restart the container, got %d restarts", restarts())
+	}
+
+	// Once started, the container is ready and the liveness probe takes over
+	delete(mockRuntime.execErrors, "cat /tmp/started")
+	workers[startupProbe].doProbe()
+	if podIsReady, _ := podReady(t, podManager, pod); !podIsReady {
+		t.Error("Expected pod to be ready once started")
+	}
+	// The second restart waits out the back-off
+	workers[livenessProbe].doProbe()
+	if restarts() != 1 {
+		t.Errorf("Expected the container to wait before restarting again, got %d restarts", restarts())
+	}
+	podManager.backoffs["pod-web-app"].restartAt = time.Now()
+	podManager.RestartExitedContainers()
+	if restarts() != 2 {
+		t.Errorf("Expected the failed liveness probe to restart the container, got %d restarts", restarts())
+	}
+	if podIsReady, _ := podReady(t, podManager, pod); podIsReady {
+		t.Error("Expected the restarted container to have to start again")
+	}
+}
+
+func TestRestartPolicy(t *testing.T) {
+	tests := []struct {
+		restartPolicy string
+		exitCode      int32
+		restarted     bool
+	}{
+		{"Always", 0, true},
+		{"Always", 1, true},
+		{"OnFailure", 0, false},
+		{"OnFailure", 1, true},
+		{"Never", 1, false},
+	}
+	for _, tt := range tests {
+		mockRuntime := NewMockContainerRuntime()
+		podManager := NewPodManager(mockRuntime)
+		pod := probedPod(nil, nil)
+		pod.Spec.RestartPolicy = tt.restartPolicy
+		if err := podManager.SyncPods([]*types.Pod{pod}); err != nil {
+			t.Fatalf("Failed to sync pods: %v", err)
+		}
+
+		container := mockRuntime.containers["container-app"]
+		container.State = "exited"
+		container.ExitCode = tt.exitCode
+		podManager.RestartExitedContainers()
+
+		if restarted := container.State == "running"; restarted != tt.restarted {
+			t.Errorf("%s with exit code %d: expected restarted=%v, got %v", tt.restartPolicy, tt.exitCode, tt.restarted, restarted)
+		}
+		status, _ := podManager.GetPodStatus(pod)
+		if tt.restarted && status.ContainerStatuses[0].RestartCount != 1 {
+			t.Errorf("%s with exit code %d: expected 1 restart, got %d", tt.restartPolicy, tt.exitCode, status.ContainerStatuses[0].RestartCount)
+		}
+		podManager.SyncPods(nil)
+	}
+}
+
+func TestCrashLoopBackOff(t *testing.T) {
+	mockRuntime := NewMockContainerRuntime()
+	podManager := NewPodManager(mockRuntime)
+	pod := probedPod(nil, nil)
+	if err := podManager.SyncPods([]*types.Pod{pod}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	defer podManager.SyncPods(nil)
+	container := mockRuntime.containers["container-app"]
+	backoff := func() *containerBackoff { return podManager.backoffs["pod-web-app"] }
+
+	// The first crash restarts the container at once, the next ones back off
+	// 10s doubling up to 5m
+	for i, expected := range []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second} {
+		container.State = "exited"
+		container.ExitCode = int32(i + 1)
+		podManager.RestartExitedContainers()
+		if i > 0 {
+			if container.State != "exited" {
+				t.Fatalf("Crash %d: expected the container to wait before restarting", i+1)
+			}
+			backoff().restartAt = time.Now()
+			podManager.RestartExitedContainers()
+		}
+		if container.State != "running" {
+			t.Fatalf("Crash %d: expected the container to be restarted", i+1)
+		}
+		if backoff().delay != expected {
+			t.Errorf("Crash %d: expected a back-off of %s, got %s", i+1, expected, backoff().delay)
+		}
+	}
+	if delay := nextRestartBackoff(4 * time.Minute); delay != 5*time.Minute {
+		t.Errorf("Expected the back-off to be capped at 5m, got %s", delay)
+	}
+
+	// While backing off the container waits in CrashLoopBackOff, and the last
+	// termination is kept
+	container.State = "exited"
+	container.ExitCode = 137
+	podManager.RestartExitedContainers()
+	status, err := podManager.GetPodStatus(pod)
+	if err != nil {
+		t.Fatalf("Failed to get pod status: %v", err)
+	}
+	containerStatus := status.ContainerStatuses[0]
+	if containerStatus.State.Waiting == nil || containerStatus.State.Waiting.Reason != "CrashLoopBackOff" {
+		t.Errorf("Expected the container to wait in CrashLoopBackOff, got %+v", containerStatus.State)
+	}
+	if last := containerStatus.LastTerminationState.Terminated; last == nil || last.ExitCode != 137 || last.Reason != "Error" {
+		t.Errorf("Expected the last termination with exit code 137, got %+v", containerStatus.LastTerminationState)
+	}
+	if containerStatus.RestartCount != 3 {
+		t.Errorf("Expected 3 restarts, got %d", containerStatus.RestartCount)
+	}
+	if status.Phase != "Running" {
+		t.Errorf("Expected the pod to stay Running while its container backs off, got %s", status.Phase)
+	}
+
+	// A container that ran long enough starts its back-off over
+	backoff().restartAt = time.Now()
+	podManager.RestartExitedContainers()
+	container.State = "exited"
+	container.Started = time.Now().Add(-time.Hour).Unix()
+	container.Finished = time.Now().Unix()
+	podManager.RestartExitedContainers()
+	if container.State != "running" {
+		t.Error("Expected a container that ran for an hour to be restarted at once")
+	}
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	mockRuntime := NewMockContainerRuntime()
+	podManager := NewPodManager(mockRuntime)
+	pod := probedPod(nil, nil)
+	pod.Spec.Containers[0].Lifecycle = &types.Lifecycle{
+		PostStart: &types.LifecycleHandler{Exec: &types.ExecAction{Command: []string{"warm-cache"}}},
+		PreStop:   &types.LifecycleHandler{Exec: &types.ExecAction{Command: []string{"drain"}}},
+	}
+	if err := podManager.SyncPods([]*types.Pod{pod}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+
+	// The preStop hook runs while the container is still running
+	deleting := *pod
+	deletedAt := time.Now()
+	deleting.Metadata.DeletionTimestamp = &deletedAt
+	if err := podManager.SyncPods([]*types.Pod{&deleting}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !podManager.PodTerminated(&deleting) {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the pod to terminate")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := podManager.SyncPods(nil); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	expected := "warm-cache (running),drain (running)"
+	if execs := strings.Join(mockRuntime.execs, ","); execs != expected {
+		t.Errorf("Expected hooks %s, got %s", expected, execs)
+	}
+
+	// A failing postStart hook kills the container, which is restarted at
+	// once and then backs off
+	mockRuntime.execErrors["warm-cache"] = errors.New("command exited with code 1")
+	if err := podManager.SyncPods([]*types.Pod{pod}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	defer podManager.SyncPods(nil)
+	status, err := podManager.GetPodStatus(pod)
+	if err != nil {
+		t.Fatalf("Failed to get pod status: %v", err)
+	}
+	containerStatus := status.ContainerStatuses[0]
+	if containerStatus.RestartCount != 1 || containerStatus.State.Waiting == nil || containerStatus.State.Waiting.Reason != "CrashLoopBackOff" {
+		t.Errorf("Expected the container to back off after 1 restart, got %+v", containerStatus)
+	}
+	if last := containerStatus.LastTerminationState.Terminated; last == nil || !strings.Contains(last.Message, "postStart hook failed") {
+		t.Errorf("Expected the hook failure as the last termination, got %+v", containerStatus.LastTerminationState)
+	}
+}
+
+func TestTCPProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	port := int32(listener.Addr().(*net.TCPAddr).Port)
+
+	podManager := NewPodManager(NewMockContainerRuntime())
+	probe := &types.Probe{TCPSocket: &types.TCPSocketAction{Port: port}}
+	if err := podManager.runProbe(probe, "container-app", "127.0.0.1"); err != nil {
+		t.Errorf("Expected the probe to connect, got %v", err)
+	}
+
+	listener.Close()
+	if err := podManager.runProbe(probe, "container-app", "127.0.0.1"); err == nil {
+		t.Error("Expected the probe to fail once the port is closed")
+	}
+}
+
+func TestGracefulTermination(t *testing.T) {
+	mockRuntime := NewMockContainerRuntime()
+	podManager := NewPodManager(mockRuntime)
+	pod := probedPod(nil, nil)
+	gracePeriod := int64(5)
+	pod.Spec.TerminationGracePeriodSeconds = &gracePeriod
+	pod.Spec.Containers[0].Lifecycle = &types.Lifecycle{
+		PreStop: &types.LifecycleHandler{Exec: &types.ExecAction{Command: []string{"drain"}}},
+	}
+	if err := podManager.SyncPods([]*types.Pod{pod}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+
+	// Deleting the pod shuts its containers down in the background
+	deleting := *pod
+	deletedAt := time.Now()
+	deleting.Metadata.DeletionTimestamp = &deletedAt
+	if err := podManager.SyncPods([]*types.Pod{&deleting}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !podManager.PodTerminated(&deleting) {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the pod to terminate")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The preStop hook ran before SIGTERM, which got the rest of the grace
+	// period
+	if execs := strings.Join(mockRuntime.execs, ","); execs != "drain (running)" {
+		t.Errorf("Expected the preStop hook to run, got %s", execs)
+	}
+	if timeout := mockRuntime.stopTimeouts["container-app"]; timeout != 5 {
+		t.Errorf("Expected a stop timeout of 5s, got %d", timeout)
+	}
+	if len(mockRuntime.containers) != 0 {
+		t.Errorf("Expected the containers to be removed, got %v", mockRuntime.containers)
+	}
+
+	status, err := podManager.GetPodStatus(&deleting)
+	if err != nil {
+		t.Fatalf("Failed to get pod status: %v", err)
+	}
+	if status.Phase != types.PodPhaseTerminating {
+		t.Errorf("Expected phase %s, got %s", types.PodPhaseTerminating, status.Phase)
+	}
+	if ready, _ := podReady(t, podManager, &deleting); ready {
+		t.Error("Expected a terminating pod not to be ready")
+	}
+
+	// The pod isn't recreated while the API server still has it
+	if err := podManager.SyncPods([]*types.Pod{&deleting}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	if len(mockRuntime.containers) != 0 {
+		t.Errorf("Expected no containers to be created, got %v", mockRuntime.containers)
+	}
+
+	// and is forgotten once it is gone
+	if err := podManager.SyncPods(nil); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	if podManager.PodTerminated(&deleting) {
+		t.Error("Expected the removed pod to be forgotten")
+	}
+}
+
+func TestForceDeletedPod(t *testing.T) {
+	mockRuntime := NewMockContainerRuntime()
+	podManager := NewPodManager(mockRuntime)
+	pod := probedPod(nil, nil)
+	pod.Spec.Containers[0].Lifecycle = &types.Lifecycle{
+		PreStop: &types.LifecycleHandler{Exec: &types.ExecAction{Command: []string{"drain"}}},
+	}
+	if err := podManager.SyncPods([]*types.Pod{pod}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+
+	// A pod that is gone without a graceful deletion was force deleted, so
+	// its containers get no grace period
+	if err := podManager.SyncPods(nil); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	if len(mockRuntime.execs) != 0 {
+		t.Errorf("Expected no preStop hook to run, got %v", mockRuntime.execs)
+	}
+	if timeout, stopped := mockRuntime.stopTimeouts["container-app"]; !stopped || timeout != 0 {
+		t.Errorf("Expected the container to be stopped at once, got a timeout of %d", timeout)
+	}
+	if len(mockRuntime.containers) != 0 {
+		t.Errorf("Expected the containers to be removed, got %v", mockRuntime.containers)
+	}
+}
+
+func TestLifecycleHooksRunUnlocked(t *testing.T) {
+	mockRuntime := NewMockContainerRuntime()
+	podManager := NewPodManager(mockRuntime)
+	pod := probedPod(nil, nil)
+	pod.Spec.Containers[0].Lifecycle = &types.Lifecycle{
+		PostStart: &types.LifecycleHandler{Exec: &types.ExecAction{Command: []string{"warm-cache"}}},
+		PreStop:   &types.LifecycleHandler{Exec: &types.ExecAction{Command: []string{"drain"}}},
+	}
+	mockRuntime.execErrors["warm-cache"] = errors.New("command exited with code 1")
+
+	// Slow hooks must not keep the other pods from being managed
+	var locked []string
+	mockRuntime.onExec = func() {
+		if !podManager.mu.TryLock() {
+			locked = append(locked, mockRuntime.execs[len(mockRuntime.execs)-1])
+			return
+		}
+		podManager.mu.Unlock()
+	}
+
+	// The failing postStart hook kills the container, running its preStop
+	// hook, and the restarted container runs its postStart hook again
+	if err := podManager.SyncPods([]*types.Pod{pod}); err != nil {
+		t.Fatalf("Failed to sync pods: %v", err)
+	}
+	defer podManager.SyncPods(nil)
+	expected := "warm-cache (running),drain (running),warm-cache (running),drain (running)"
+	if execs := strings.Join(mockRuntime.execs, ","); execs != expected {
+		t.Errorf("Expected hooks %s, got %s", expected, execs)
+	}
+	if len(locked) != 0 {
+		t.Errorf("Expected hooks to run without holding the lock, got %v", locked)
+	}
+}
@@ -0,0 +1,261 @@
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// Defaults for probe fields left unset, as in Kubernetes
+const (
+	defaultProbePeriodSeconds    = 10
+	defaultProbeTimeoutSeconds   = 1
+	defaultProbeFailureThreshold = 3
+	defaultProbeSuccessThreshold = 1
+)
+
+// probeType is the kind of check a probe worker runs
+type probeType string
+
+const (
+	// livenessProbe failures restart the container
+	livenessProbe probeType = "liveness"
+	// readinessProbe results decide whether the container is ready
+	readinessProbe probeType = "readiness"
+	// startupProbe holds off the other probes until it passes; its failures
+	// restart the container
+	startupProbe probeType = "startup"
+)
+
+// probeWorker periodically runs one probe of one container. It stops when
+// the pod is deleted or recreated.
+type probeWorker struct {
+	podManager *PodManager
+	pod        *types.Pod
+	container  types.Container
+	probeType  probeType
+	probe      *types.Probe
+	stopCh     chan struct{}
+
+	// failures and successes count the consecutive failed and passed
+	// probes; only the worker's goroutine touches them
+	failures  int32
+	successes int32
+}
+
+// newProbeWorker creates a worker for a probe of a container
+func newProbeWorker(pm *PodManager, pod *types.Pod, container types.Container, probeType probeType, probe *types.Probe) *probeWorker {
+	return &probeWorker{
+		podManager: pm,
+		pod:        pod,
+		container:  container,
+		probeType:  probeType,
+		probe:      probe,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// run probes the container every period until the worker is stopped
+func (w *probeWorker) run() {
+	ticker := time.NewTicker(probePeriod(w.probe))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.doProbe()
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+// stop stops the worker without waiting for a probe in flight
+func (w *probeWorker) stop() {
+	close(w.stopCh)
+}
+
+// doProbe runs the probe once and acts on the result. Probes are skipped
+// while the container isn't running and during its initial delay, and
+// liveness and readiness probes until the startup probe has passed. A probe
+// passes after SuccessThreshold consecutive successes. It fails after
+// FailureThreshold consecutive failures: a readiness probe then marks the
+// container not ready, and a liveness or startup probe restarts it.
+func (w *probeWorker) doProbe() {
+	pm := w.podManager
+	key := containerKey(w.pod, w.container.Name)
+
+	pm.mu.RLock()
+	containerID, exists := pm.containerIDs[key]
+	pm.mu.RUnlock()
+	if !exists {
+		return
+	}
+
+	status, err := pm.containerRuntime.GetContainerStatus(context.Background(), containerID)
+	if err != nil || status == nil || status.State != "running" {
+		w.reset()
+		if w.probeType == readinessProbe {
+			pm.setProbeResult(readinessProbe, key, false)
+		}
+		return
+	}
+
+	switch w.probeType {
+	case startupProbe:
+		// Once started, the container stays started until it restarts
+		if pm.probePassed(startupProbe, key) {
+			w.reset()
+			return
+		}
+	default:
+		if w.container.StartupProbe != nil && !pm.probePassed(startupProbe, key) {
+			w.reset()
+			return
+		}
+	}
+
+	initialDelay := time.Duration(w.probe.InitialDelaySeconds) * time.Second
+	if time.Since(time.Unix(status.Started, 0)) < initialDelay {
+		return
+	}
+
+	err = pm.runProbe(w.probe, containerID, status.IPAddress)
+	if err == nil {
+		w.failures = 0
+		w.successes++
+		if w.successes < probeSuccessThreshold(w.probe) {
+			return
+		}
+		if w.probeType != livenessProbe {
+			pm.setProbeResult(w.probeType, key, true)
+		}
+		return
+	}
+
+	w.successes = 0
+	w.failures++
+	log.Printf("Container %s of pod %s failed %s probe (%d/%d): %v",
+		w.container.Name, w.pod.Metadata.Name, w.probeType,
+		w.failures, probeFailureThreshold(w.probe), err)
+	if w.failures < probeFailureThreshold(w.probe) {
+		return
+	}
+
+	w.failures = 0
+	switch w.probeType {
+	case readinessProbe:
+		pm.setProbeResult(readinessProbe, key, false)
+	case livenessProbe, startupProbe:
+		pm.restartContainer(w.pod, w.container, w.probeType)
+	}
+}
+
+// reset forgets the consecutive results, so counting starts over
+func (w *probeWorker) reset() {
+	w.failures = 0
+	w.successes = 0
+}
+
+// runProbe runs the probe's action against a container, within the probe's
+// timeout. HTTP and TCP probes connect to the container's address.
+func (pm *PodManager) runProbe(probe *types.Probe, containerID, containerIP string) error {
+	timeout := time.Duration(probe.TimeoutSeconds) * time.Second
+	if probe.TimeoutSeconds <= 0 {
+		timeout = defaultProbeTimeoutSeconds * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	switch {
+	case probe.HTTPGet != nil:
+		if containerIP == "" {
+			return fmt.Errorf("container has no IP address")
+		}
+		return probeHTTP(ctx, probe.HTTPGet, containerIP)
+	case probe.TCPSocket != nil:
+		if containerIP == "" {
+			return fmt.Errorf("container has no IP address")
+		}
+		return probeTCP(ctx, probe.TCPSocket, containerIP)
+	case probe.Exec != nil:
+		return pm.containerRuntime.ExecInContainer(ctx, containerID, probe.Exec.Command)
+	default:
+		return fmt.Errorf("probe has no action")
+	}
+}
+
+// probeHTTP succeeds if a GET of the path returns a status from 200 to 399
+func probeHTTP(ctx context.Context, action *types.HTTPGetAction, host string) error {
+	scheme := strings.ToLower(action.Scheme)
+	if scheme == "" {
+		scheme = "http"
+	}
+	path := action.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(action.Port))), path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	// Like the kubelet, HTTPS probes don't verify the certificate
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("HTTP probe returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// probeTCP succeeds if a connection to the port can be opened
+func probeTCP(ctx context.Context, action *types.TCPSocketAction, host string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(int(action.Port))))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// probePeriod returns how often a probe runs
+func probePeriod(probe *types.Probe) time.Duration {
+	if probe.PeriodSeconds <= 0 {
+		return defaultProbePeriodSeconds * time.Second
+	}
+	return time.Duration(probe.PeriodSeconds) * time.Second
+}
+
+// probeSuccessThreshold returns how many consecutive successes pass a probe
+func probeSuccessThreshold(probe *types.Probe) int32 {
+	if probe.SuccessThreshold <= 0 {
+		return defaultProbeSuccessThreshold
+	}
+	return probe.SuccessThreshold
+}
+
+// probeFailureThreshold returns how many consecutive failures fail a probe
+func probeFailureThreshold(probe *types.Probe) int32 {
+	if probe.FailureThreshold <= 0 {
+		return defaultProbeFailureThreshold
+	}
+	return probe.FailureThreshold
+}
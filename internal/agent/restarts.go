@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"mini-k8s-orchestration/internal/runtime"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// Restart back-off, as in Kubernetes: a container that keeps exiting is
+// restarted at once the first time, then after 10s, doubling up to 5m. The
+// back-off starts over once a container ran for 10 minutes.
+const (
+	initialRestartBackoff = 10 * time.Second
+	maxRestartBackoff     = 5 * time.Minute
+	restartBackoffReset   = 10 * time.Minute
+)
+
+// containerBackoff tracks the restarts of a container that keeps exiting
+type containerBackoff struct {
+	// delay is how long the container waits before its next restart
+	delay time.Duration
+	// restartAt is when the exited container is due to restart; zero while
+	// it isn't waiting for one
+	restartAt time.Time
+}
+
+// RestartExitedContainers restarts the containers that exited, as their pod's
+// restart policy says, once their back-off has passed
+func (pm *PodManager) RestartExitedContainers() {
+	pm.mu.Lock()
+	ctx := context.Background()
+	var starts []containerStart
+	for _, pod := range pm.pods {
+		// Containers of a pod being deleted stay down
+		if pm.terminating[pod.Metadata.UID] {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			key := containerKey(pod, container.Name)
+			containerID, exists := pm.containerIDs[key]
+			// A container being killed is restarted once it has stopped
+			if !exists || pm.killing[key] {
+				continue
+			}
+			status, err := pm.containerRuntime.GetContainerStatus(ctx, containerID)
+			if err != nil || status == nil || status.State != "exited" {
+				continue
+			}
+			if pm.handleExit(pod, container, containerID, status, "") {
+				starts = append(starts, containerStart{pod: pod, container: container, containerID: containerID})
+			}
+		}
+	}
+	pm.mu.Unlock()
+
+	pm.runPostStartHooks(starts)
+}
+
+// handleExit records how an exited container terminated and restarts it if
+// the pod's restart policy says so and its back-off has passed. A non-empty
+// killReason marks a container the agent killed, which counts as failed
+// whatever its exit code. It reports whether the container was restarted, in
+// which case the caller runs its postStart hook once it has released pm.mu.
+// Callers must hold pm.mu.
+func (pm *PodManager) handleExit(pod *types.Pod, container types.Container, containerID string, status *runtime.ContainerStatus, killReason string) bool {
+	failed := status.ExitCode != 0 || killReason != ""
+	if !shouldRestart(pod.Spec.RestartPolicy, failed) {
+		return false
+	}
+
+	key := containerKey(pod, container.Name)
+	now := time.Now()
+	backoff, exists := pm.backoffs[key]
+	if !exists {
+		backoff = &containerBackoff{}
+		pm.backoffs[key] = backoff
+	}
+	if backoff.restartAt.IsZero() {
+		// The container has just exited
+		if status.Started > 0 && time.Duration(status.Finished-status.Started)*time.Second >= restartBackoffReset {
+			backoff.delay = 0
+		}
+		backoff.restartAt = now.Add(backoff.delay)
+		pm.lastTerminations[key] = terminatedState(status, killReason)
+		pm.resetProbeResults(key)
+		pm.notifyStatusChange()
+	}
+	if now.Before(backoff.restartAt) {
+		return false
+	}
+
+	if err := pm.containerRuntime.StartContainer(context.Background(), containerID); err != nil {
+		log.Printf("Failed to restart container %s of pod %s: %v", container.Name, pod.Metadata.Name, err)
+		return false
+	}
+	backoff.restartAt = time.Time{}
+	backoff.delay = nextRestartBackoff(backoff.delay)
+	pm.restartCounts[key]++
+	pm.notifyStatusChange()
+	log.Printf("Restarted container %s of pod %s (restart %d)", container.Name, pod.Metadata.Name, pm.restartCounts[key])
+	return true
+}
+
+// shouldRestart reports whether a restart policy restarts a container that
+// exited, successfully or not. An empty policy means Always.
+func shouldRestart(restartPolicy string, failed bool) bool {
+	switch restartPolicy {
+	case "Never":
+		return false
+	case "OnFailure":
+		return failed
+	default:
+		return true
+	}
+}
+
+// nextRestartBackoff returns the back-off after a restart that waited delay
+func nextRestartBackoff(delay time.Duration) time.Duration {
+	if delay == 0 {
+		return initialRestartBackoff
+	}
+	if delay*2 > maxRestartBackoff {
+		return maxRestartBackoff
+	}
+	return delay * 2
+}
+
+// terminatedState describes how a container exited. A non-empty killReason
+// is why the agent killed it.
+func terminatedState(status *runtime.ContainerStatus, killReason string) types.ContainerStateTerminated {
+	reason := "Completed"
+	if status.ExitCode != 0 || killReason != "" {
+		reason = "Error"
+	}
+	terminated := types.ContainerStateTerminated{
+		ExitCode:   status.ExitCode,
+		Reason:     reason,
+		Message:    killReason,
+		FinishedAt: time.Unix(status.Finished, 0),
+	}
+	if status.Started > 0 {
+		terminated.StartedAt = time.Unix(status.Started, 0)
+	}
+	return terminated
+}
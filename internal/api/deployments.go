@@ -2,8 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -55,6 +58,14 @@ func (s *Server) createDeploymentInNamespace(c *gin.Context, namespace string) {
 		return
 	}
 	
+	// Default the selector to the template labels
+	if deployment.Spec.Selector.Empty() && len(deployment.Spec.Template.Metadata.Labels) > 0 {
+		deployment.Spec.Selector.MatchLabels = make(map[string]string)
+		for key, value := range deployment.Spec.Template.Metadata.Labels {
+			deployment.Spec.Selector.MatchLabels[key] = value
+		}
+	}
+	
 	// Validate the deployment
 	if err := types.ValidateDeployment(&deployment); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
@@ -74,7 +85,8 @@ func (s *Server) createDeploymentInNamespace(c *gin.Context, namespace string) {
 	deployment.Metadata.CreatedAt = now
 	deployment.Metadata.UpdatedAt = now
 	
-	// Initialize status
+	// Initialize status; the deployment controller fills in the rest
+	// once it has created the pods
 	deployment.Status = types.DeploymentStatus{
 		ObservedGeneration:  1,
 		Replicas:            0,
@@ -82,19 +94,19 @@ func (s *Server) createDeploymentInNamespace(c *gin.Context, namespace string) {
 		ReadyReplicas:       0,
 		AvailableReplicas:   0,
 		UnavailableReplicas: deployment.Spec.Replicas,
-		Conditions: []types.DeploymentCondition{
-			{
-				Type:               "Progressing",
-				Status:             "True",
-				LastUpdateTime:     now,
-				LastTransitionTime: now,
-				Reason:             "NewReplicaSetCreated",
-				Message:            "Created new replica set",
-			},
-		},
 	}
 	
 	// Convert to storage resource
+	metadataJSON, err := json.Marshal(deployment.Metadata)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "SERIALIZATION_ERROR",
+			Message: "Failed to serialize deployment metadata",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	
 	specJSON, err := json.Marshal(deployment.Spec)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -120,6 +132,7 @@ func (s *Server) createDeploymentInNamespace(c *gin.Context, namespace string) {
 		Kind:      "Deployment",
 		Namespace: deployment.Metadata.Namespace,
 		Name:      deployment.Metadata.Name,
+		Metadata:  string(metadataJSON),
 		Spec:      string(specJSON),
 		Status:    string(statusJSON),
 		CreatedAt: now,
@@ -137,6 +150,11 @@ func (s *Server) createDeploymentInNamespace(c *gin.Context, namespace string) {
 		return
 	}
 	
+	// Return the version the resource was stored with
+	if created, err := s.repository.GetResource("Deployment", resource.Namespace, resource.Name); err == nil {
+		deployment.Metadata.ResourceVersion = storage.FormatResourceVersion(created.ResourceVersion)
+	}
+	
 	c.JSON(http.StatusCreated, deployment)
 }
 
@@ -264,7 +282,29 @@ func (s *Server) updateDeploymentInNamespace(c *gin.Context, namespace string) {
 	// Update timestamp
 	deployment.Metadata.UpdatedAt = time.Now()
 	
+	// Only apply the update to the version the client read, if it sent one
+	resourceVersion, err := storage.ParseResourceVersion(deployment.Metadata.ResourceVersion)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "Invalid resourceVersion",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+	
 	// Convert to storage resource
+	metadataJSON, err := json.Marshal(deployment.Metadata)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "SERIALIZATION_ERROR",
+			Message: "Failed to serialize deployment metadata",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	
 	specJSON, err := json.Marshal(deployment.Spec)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -289,12 +329,24 @@ func (s *Server) updateDeploymentInNamespace(c *gin.Context, namespace string) {
 		Kind:      "Deployment",
 		Namespace: namespace,
 		Name:      name,
+		Metadata:  string(metadataJSON),
 		Spec:      string(specJSON),
 		Status:    string(statusJSON),
 	}
 	
+	resource.ResourceVersion = resourceVersion
+	
 	// Update in database
 	if err := s.repository.UpdateResource(resource); err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "CONFLICT",
+				Message: "Deployment has been modified, get the latest version and try again",
+				Code:    http.StatusConflict,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
 		c.JSON(http.StatusNotFound, ErrorResponse{
 			Error:   "RESOURCE_NOT_FOUND",
 			Message: "Deployment not found",
@@ -304,6 +356,11 @@ func (s *Server) updateDeploymentInNamespace(c *gin.Context, namespace string) {
 		return
 	}
 	
+	// Return the version the update was stored with
+	if updated, err := s.repository.GetResource("Deployment", namespace, name); err == nil {
+		deployment.Metadata.ResourceVersion = storage.FormatResourceVersion(updated.ResourceVersion)
+	}
+	
 	c.JSON(http.StatusOK, deployment)
 }
 
@@ -365,21 +422,40 @@ func (s *Server) listNamespacedDeployments(c *gin.Context) {
 
 // listDeploymentsInNamespace lists deployments in the specified namespace
 func (s *Server) listDeploymentsInNamespace(c *gin.Context, namespace string) {
+	// Only return the objects matching the request's selectors
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+	
+	// Only return the requested page
+	options, ok := listOptions(c)
+	if !ok {
+		return
+	}
+	
+	// Stream changes instead of listing when watching
+	if isWatchRequest(c) {
+		s.watchResources(c, "Deployment", namespace, filter, func(resource storage.Resource) (interface{}, error) {
+			return s.resourceToDeployment(resource)
+		})
+		return
+	}
+	
+	// Read the version before listing so a watch started from it misses nothing
+	metadata := s.listMetadata()
+	
 	// Get from database
-	resources, err := s.repository.ListResources("Deployment", namespace)
+	page, err := s.repository.ListResourcesPage("Deployment", namespace, options)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "DATABASE_ERROR",
-			Message: "Failed to list deployments",
-			Code:    http.StatusInternalServerError,
-			Details: map[string]string{"error": err.Error()},
-		})
+		listError(c, err, "Failed to list deployments")
 		return
 	}
+	setContinue(metadata, page.Continue)
 	
 	// Convert to deployments
 	var deployments []types.Deployment
-	for _, resource := range resources {
+	for _, resource := range page.Resources {
 		deployment, err := s.resourceToDeployment(resource)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -390,18 +466,36 @@ func (s *Server) listDeploymentsInNamespace(c *gin.Context, namespace string) {
 			})
 			return
 		}
+		if !matchesFilter(filter, deployment) {
+			continue
+		}
 		deployments = append(deployments, *deployment)
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
 		"apiVersion": "apps/v1",
 		"kind":       "DeploymentList",
+		"metadata":   metadata,
 		"items":      deployments,
 	})
 }
 
 // resourceToDeployment converts a storage resource to a Deployment
 func (s *Server) resourceToDeployment(resource storage.Resource) (*types.Deployment, error) {
+	var metadata types.ObjectMeta
+	if resource.Metadata != "" {
+		if err := json.Unmarshal([]byte(resource.Metadata), &metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal deployment metadata: %w", err)
+		}
+	}
+	
+	// Identity always comes from the stored row
+	metadata.Name = resource.Name
+	metadata.Namespace = resource.Namespace
+	metadata.UID = resource.ID
+	metadata.CreatedAt = resource.CreatedAt
+	metadata.UpdatedAt = resource.UpdatedAt
+	
 	var spec types.DeploymentSpec
 	if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal deployment spec: %w", err)
@@ -417,16 +511,240 @@ func (s *Server) resourceToDeployment(resource storage.Resource) (*types.Deploym
 	deployment := &types.Deployment{
 		APIVersion: "apps/v1",
 		Kind:       "Deployment",
-		Metadata: types.ObjectMeta{
-			Name:      resource.Name,
-			Namespace: resource.Namespace,
-			UID:       resource.ID,
-			CreatedAt: resource.CreatedAt,
-			UpdatedAt: resource.UpdatedAt,
-		},
-		Spec:   spec,
-		Status: status,
+		Metadata:   metadata,
+		Spec:       spec,
+		Status:     status,
 	}
 	
 	return deployment, nil
-}
\ No newline at end of file
+}
+// getDeploymentHistory handles GET /api/v1/deployments/{name}/history
+func (s *Server) getDeploymentHistory(c *gin.Context) {
+	s.getDeploymentHistoryFromNamespace(c, "default")
+}
+
+// getNamespacedDeploymentHistory handles GET /api/v1/namespaces/{namespace}/deployments/{name}/history
+func (s *Server) getNamespacedDeploymentHistory(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.getDeploymentHistoryFromNamespace(c, namespace)
+}
+
+// getDeploymentHistoryFromNamespace lists the rollout revisions of a deployment, oldest first
+func (s *Server) getDeploymentHistoryFromNamespace(c *gin.Context, namespace string) {
+	name := c.Param("name")
+	
+	resource, err := s.repository.GetResource("Deployment", namespace, name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: "Deployment not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	
+	revisions, err := s.deploymentRevisions(resource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "DATABASE_ERROR",
+			Message: "Failed to list deployment revisions",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"apiVersion": "apps/v1",
+		"kind":       "DeploymentHistory",
+		"items":      revisions,
+	})
+}
+
+// rollbackDeployment handles POST /api/v1/deployments/{name}/rollback
+func (s *Server) rollbackDeployment(c *gin.Context) {
+	s.rollbackDeploymentInNamespace(c, "default")
+}
+
+// rollbackNamespacedDeployment handles POST /api/v1/namespaces/{namespace}/deployments/{name}/rollback
+func (s *Server) rollbackNamespacedDeployment(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.rollbackDeploymentInNamespace(c, namespace)
+}
+
+// rollbackDeploymentInNamespace restores the pod template of an earlier revision.
+// Without a revision, or with revision 0, the deployment rolls back to the previous one.
+func (s *Server) rollbackDeploymentInNamespace(c *gin.Context, namespace string) {
+	name := c.Param("name")
+	
+	var target int64
+	if value := c.Query("revision"); value != "" {
+		revision, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || revision < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "INVALID_PARAMETER",
+				Message: "Revision must be a non-negative integer",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		target = revision
+	}
+	
+	resource, err := s.repository.GetResource("Deployment", namespace, name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: "Deployment not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	
+	deployment, err := s.resourceToDeployment(resource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "DESERIALIZATION_ERROR",
+			Message: "Failed to deserialize deployment",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	
+	revisions, err := s.deploymentRevisions(resource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "DATABASE_ERROR",
+			Message: "Failed to list deployment revisions",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	
+	// Revisions are sorted, so the previous one is second to last
+	var found *types.DeploymentRevision
+	if target == 0 {
+		if len(revisions) >= 2 {
+			found = &revisions[len(revisions)-2]
+		}
+	} else {
+		for i := range revisions {
+			if revisions[i].Revision == target {
+				found = &revisions[i]
+			}
+		}
+	}
+	if found == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "REVISION_NOT_FOUND",
+			Message: "Deployment revision not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"revision": strconv.FormatInt(target, 10)},
+		})
+		return
+	}
+	
+	// The deployment controller rolls out the restored template like any other change
+	deployment.Spec.Template = found.Template
+	deployment.Metadata.UpdatedAt = time.Now()
+	
+	specJSON, err := json.Marshal(deployment.Spec)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "SERIALIZATION_ERROR",
+			Message: "Failed to serialize deployment spec",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	
+	resource.Spec = string(specJSON)
+	if err := s.repository.UpdateResource(resource); err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "CONFLICT",
+				Message: "Deployment has been modified, try the rollback again",
+				Code:    http.StatusConflict,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: "Deployment not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	
+	// Return the version the rollback was stored with
+	if updated, err := s.repository.GetResource("Deployment", resource.Namespace, resource.Name); err == nil {
+		deployment.Metadata.ResourceVersion = storage.FormatResourceVersion(updated.ResourceVersion)
+	}
+	
+	c.JSON(http.StatusOK, deployment)
+}
+
+// deploymentRevisions returns the revisions recorded on the replica sets a deployment owns, oldest first
+func (s *Server) deploymentRevisions(deploymentResource storage.Resource) ([]types.DeploymentRevision, error) {
+	resources, err := s.repository.ListResources("ReplicaSet", deploymentResource.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	
+	revisions := []types.DeploymentRevision{}
+	for _, resource := range resources {
+		replicaSet, err := s.resourceToReplicaSet(resource)
+		if err != nil {
+			return nil, err
+		}
+		if !isControlledByUID(&replicaSet.Metadata, deploymentResource.ID) {
+			continue
+		}
+		
+		revision, _ := strconv.ParseInt(replicaSet.Metadata.Annotations[types.RevisionAnnotation], 10, 64)
+		
+		// Report the template as it appears on the deployment
+		template := replicaSet.Spec.Template
+		template.Metadata.Labels = make(map[string]string)
+		for key, value := range replicaSet.Spec.Template.Metadata.Labels {
+			if key != types.PodTemplateHashLabel {
+				template.Metadata.Labels[key] = value
+			}
+		}
+		
+		revisions = append(revisions, types.DeploymentRevision{
+			Revision:   revision,
+			ReplicaSet: replicaSet.Metadata.Name,
+			Replicas:   replicaSet.Spec.Replicas,
+			Template:   template,
+			CreatedAt:  replicaSet.Metadata.CreatedAt,
+		})
+	}
+	
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// isControlledByUID checks if an object's controlling owner has the given UID
+func isControlledByUID(meta *types.ObjectMeta, uid string) bool {
+	for _, ref := range meta.OwnerReferences {
+		if ref.Controller {
+			return ref.UID == uid
+		}
+	}
+	return false
+}
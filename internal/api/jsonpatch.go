@@ -0,0 +1,371 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Patch content types accepted by the PATCH endpoints
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// applyPatch applies a patch of the given content type to a JSON document and
+// returns the patched document
+func applyPatch(contentType string, document, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	var patched interface{}
+	var err error
+	switch contentType {
+	case mergePatchType:
+		patched, err = applyMergePatch(target, patch)
+	case jsonPatchType:
+		patched, err = applyJSONPatch(target, patch)
+	default:
+		return nil, fmt.Errorf("unsupported patch type %q", contentType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(patched)
+}
+
+// applyMergePatch applies a JSON merge patch (RFC 7386): objects in the patch
+// are merged into the target recursively, null removes a field, and any other
+// value replaces the target's value
+func applyMergePatch(target interface{}, patch []byte) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(patch, &value); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	return mergePatch(target, value), nil
+}
+
+// mergePatch merges a decoded merge patch into a decoded target
+func mergePatch(target, patch interface{}) interface{} {
+	fields, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = make(map[string]interface{})
+	}
+	for key, value := range fields {
+		if value == nil {
+			delete(object, key)
+			continue
+		}
+		object[key] = mergePatch(object[key], value)
+	}
+	return object
+}
+
+// jsonPatchOperation is a single operation of a JSON patch
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch applies a JSON patch (RFC 6902). The operations are applied
+// in order and the patch fails as a whole if any of them fails.
+func applyJSONPatch(target interface{}, patch []byte) (interface{}, error) {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("invalid JSON patch: %w", err)
+	}
+
+	document := target
+	for i, operation := range operations {
+		var err error
+		document, err = applyJSONPatchOperation(document, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i, operation.Op, err)
+		}
+	}
+	return document, nil
+}
+
+// applyJSONPatchOperation applies one JSON patch operation to a document
+func applyJSONPatchOperation(document interface{}, operation jsonPatchOperation) (interface{}, error) {
+	if operation.Path == nil {
+		return nil, fmt.Errorf("missing path")
+	}
+	path, err := parsePointer(*operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		if len(operation.Value) == 0 {
+			return nil, fmt.Errorf("missing value")
+		}
+		var value interface{}
+		if err := json.Unmarshal(operation.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+
+		switch operation.Op {
+		case "add":
+			return addValue(document, path, value)
+		case "replace":
+			return replaceValue(document, path, value)
+		default:
+			current, err := getValue(document, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("value at %q does not match", *operation.Path)
+			}
+			return document, nil
+		}
+
+	case "remove":
+		document, _, err := removeValue(document, path)
+		return document, err
+
+	case "move", "copy":
+		if operation.From == nil {
+			return nil, fmt.Errorf("missing from")
+		}
+		from, err := parsePointer(*operation.From)
+		if err != nil {
+			return nil, err
+		}
+
+		if operation.Op == "copy" {
+			value, err := getValue(document, from)
+			if err != nil {
+				return nil, err
+			}
+			return addValue(document, path, deepCopy(value))
+		}
+
+		if isPointerPrefix(from, path) {
+			if len(from) == len(path) {
+				return document, nil
+			}
+			return nil, fmt.Errorf("cannot move %q into itself", *operation.From)
+		}
+		document, value, err := removeValue(document, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(document, path, value)
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q", operation.Op)
+	}
+}
+
+// parsePointer splits a JSON pointer (RFC 6901) into its unescaped reference
+// tokens. The empty pointer refers to the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// isPointerPrefix reports whether the location prefix is, or contains, path
+func isPointerPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex parses an array index token. Indexes must be decimal without
+// leading zeros and at most max.
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || strconv.Itoa(index) != token {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index > max {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
+
+// getValue returns the value at a location
+func getValue(document interface{}, path []string) (interface{}, error) {
+	value := document
+	for _, token := range path {
+		switch container := value.(type) {
+		case map[string]interface{}:
+			child, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", "/"+strings.Join(path, "/"))
+			}
+			value = child
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			value = container[index]
+		default:
+			return nil, fmt.Errorf("path %q not found", "/"+strings.Join(path, "/"))
+		}
+	}
+	return value, nil
+}
+
+// updateParent calls update with the container holding the location at path
+// and the location's last token, and stores the container update returns in
+// place of the original. The result is the updated document.
+func updateParent(document interface{}, path []string, update func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return update(document, path[0])
+	}
+
+	child, err := getValue(document, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = updateParent(child, path[1:], update)
+	if err != nil {
+		return nil, err
+	}
+
+	switch container := document.(type) {
+	case map[string]interface{}:
+		container[path[0]] = child
+	case []interface{}:
+		index, _ := arrayIndex(path[0], len(container)-1)
+		container[index] = child
+	}
+	return document, nil
+}
+
+// addValue adds a value at a location: object members are set, array elements
+// are inserted before the index, and "-" appends to an array
+func addValue(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(document, path, func(parent interface{}, token string) (interface{}, error) {
+		switch container := parent.(type) {
+		case map[string]interface{}:
+			container[token] = value
+			return container, nil
+		case []interface{}:
+			if token == "-" {
+				return append(container, value), nil
+			}
+			index, err := arrayIndex(token, len(container))
+			if err != nil {
+				return nil, err
+			}
+			updated := make([]interface{}, 0, len(container)+1)
+			updated = append(updated, container[:index]...)
+			updated = append(updated, value)
+			return append(updated, container[index:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot add %q to a scalar", token)
+		}
+	})
+}
+
+// removeValue removes the value at a location and returns it
+func removeValue(document interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	var removed interface{}
+	document, err := updateParent(document, path, func(parent interface{}, token string) (interface{}, error) {
+		switch container := parent.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", "/"+strings.Join(path, "/"))
+			}
+			removed = value
+			delete(container, token)
+			return container, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = container[index]
+			updated := make([]interface{}, 0, len(container)-1)
+			updated = append(updated, container[:index]...)
+			return append(updated, container[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("path %q not found", "/"+strings.Join(path, "/"))
+		}
+	})
+	return document, removed, err
+}
+
+// replaceValue replaces the value at a location, which must exist
+func replaceValue(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if _, err := getValue(document, path); err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(document, path, func(parent interface{}, token string) (interface{}, error) {
+		switch container := parent.(type) {
+		case map[string]interface{}:
+			container[token] = value
+		case []interface{}:
+			index, _ := arrayIndex(token, len(container)-1)
+			container[index] = value
+		}
+		return parent, nil
+	})
+}
+
+// deepCopy copies a decoded JSON value, so copies don't share maps or slices
+func deepCopy(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for key, child := range value {
+			copied[key] = deepCopy(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, child := range value {
+			copied[i] = deepCopy(child)
+		}
+		return copied
+	default:
+		return value
+	}
+}
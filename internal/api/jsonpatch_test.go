@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		expected string
+	}{
+		{"set field", `{"a": 1}`, `{"b": 2}`, `{"a": 1, "b": 2}`},
+		{"remove field", `{"a": 1, "b": 2}`, `{"a": null}`, `{"b": 2}`},
+		{"nested merge", `{"a": {"b": 1, "c": 2}}`, `{"a": {"c": 3}}`, `{"a": {"b": 1, "c": 3}}`},
+		{"arrays are replaced", `{"a": [1, 2]}`, `{"a": [3]}`, `{"a": [3]}`},
+		{"object replaces scalar", `{"a": 1}`, `{"a": {"b": null, "c": 1}}`, `{"a": {"c": 1}}`},
+		{"non-object patch replaces document", `{"a": 1}`, `[1]`, `[1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := applyPatch(mergePatchType, []byte(tt.document), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Failed to apply patch: %v", err)
+			}
+			assertJSONEqual(t, tt.expected, string(result))
+		})
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		patch    string
+		expected string
+		wantErr  bool
+	}{
+		{"add member", `{"a": 1}`, `[{"op": "add", "path": "/b", "value": 2}]`, `{"a": 1, "b": 2}`, false},
+		{"add null", `{"a": 1}`, `[{"op": "add", "path": "/b", "value": null}]`, `{"a": 1, "b": null}`, false},
+		{"insert into array", `{"a": [1, 3]}`, `[{"op": "add", "path": "/a/1", "value": 2}]`, `{"a": [1, 2, 3]}`, false},
+		{"append to array", `{"a": [1]}`, `[{"op": "add", "path": "/a/-", "value": 2}]`, `{"a": [1, 2]}`, false},
+		{"remove array element", `{"a": [1, 2, 3]}`, `[{"op": "remove", "path": "/a/0"}]`, `{"a": [2, 3]}`, false},
+		{"replace", `{"a": {"b": 1}}`, `[{"op": "replace", "path": "/a/b", "value": "x"}]`, `{"a": {"b": "x"}}`, false},
+		{"escaped pointer", `{"a/b": 1, "c~d": 2}`, `[{"op": "remove", "path": "/a~1b"}, {"op": "remove", "path": "/c~0d"}]`, `{}`, false},
+		{"move", `{"a": {"b": 1}, "c": {}}`, `[{"op": "move", "from": "/a/b", "path": "/c/d"}]`, `{"a": {}, "c": {"d": 1}}`, false},
+		{"copy", `{"a": {"b": [1]}}`, `[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "add", "path": "/c/b/-", "value": 2}]`, `{"a": {"b": [1]}, "c": {"b": [1, 2]}}`, false},
+		{"passing test", `{"a": [1, {"b": "c"}]}`, `[{"op": "test", "path": "/a", "value": [1, {"b": "c"}]}]`, `{"a": [1, {"b": "c"}]}`, false},
+		{"failing test", `{"a": 1}`, `[{"op": "test", "path": "/a", "value": 2}]`, "", true},
+		{"replace missing member", `{"a": 1}`, `[{"op": "replace", "path": "/b", "value": 2}]`, "", true},
+		{"add past end of array", `{"a": [1]}`, `[{"op": "add", "path": "/a/2", "value": 2}]`, "", true},
+		{"leading zero index", `{"a": [1, 2]}`, `[{"op": "remove", "path": "/a/01"}]`, "", true},
+		{"move into own child", `{"a": {"b": {}}}`, `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`, "", true},
+		{"missing value", `{"a": 1}`, `[{"op": "add", "path": "/b"}]`, "", true},
+		{"unknown operation", `{"a": 1}`, `[{"op": "merge", "path": "/a", "value": 2}]`, "", true},
+		{"not an array", `{"a": 1}`, `{"op": "remove", "path": "/a"}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := applyPatch(jsonPatchType, []byte(tt.document), []byte(tt.patch))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to apply patch: %v", err)
+			}
+			assertJSONEqual(t, tt.expected, string(result))
+		})
+	}
+}
+
+func assertJSONEqual(t *testing.T, expected, actual string) {
+	t.Helper()
+
+	var expectedValue, actualValue interface{}
+	if err := json.Unmarshal([]byte(expected), &expectedValue); err != nil {
+		t.Fatalf("Invalid expected JSON %s: %v", expected, err)
+	}
+	if err := json.Unmarshal([]byte(actual), &actualValue); err != nil {
+		t.Fatalf("Invalid JSON %s: %v", actual, err)
+	}
+	if !reflect.DeepEqual(expectedValue, actualValue) {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"mini-k8s-orchestration/internal/storage"
 	"mini-k8s-orchestration/pkg/types"
 )
 
@@ -141,11 +143,31 @@ func (s *Server) updateNode(c *gin.Context) {
 		return
 	}
 	
+	// Only apply the update to the version the client read, if it sent one
+	if _, err := storage.ParseResourceVersion(node.Metadata.ResourceVersion); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "Invalid resourceVersion",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+	
 	// Update timestamp
 	node.Metadata.UpdatedAt = time.Now()
 	
 	// Update in database using node-specific method
 	if err := s.repository.UpdateNode(&node); err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "CONFLICT",
+				Message: "Node has been modified, get the latest version and try again",
+				Code:    http.StatusConflict,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
 		c.JSON(http.StatusNotFound, ErrorResponse{
 			Error:   "RESOURCE_NOT_FOUND",
 			Message: "Node not found",
@@ -155,6 +177,11 @@ func (s *Server) updateNode(c *gin.Context) {
 		return
 	}
 	
+	// Return the version the update was stored with
+	if updated, err := s.repository.GetNode(name); err == nil {
+		node.Metadata.ResourceVersion = updated.Metadata.ResourceVersion
+	}
+	
 	c.JSON(http.StatusOK, node)
 }
 
@@ -188,25 +215,164 @@ func (s *Server) deleteNode(c *gin.Context) {
 
 // listNodes handles GET /api/v1/nodes
 func (s *Server) listNodes(c *gin.Context) {
+	// Only return the nodes matching the request's selectors
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+	
+	// Only return the requested page
+	options, ok := listOptions(c)
+	if !ok {
+		return
+	}
+	
+	// Stream changes instead of listing when watching
+	if isWatchRequest(c) {
+		s.watchResources(c, "Node", "", filter, func(resource storage.Resource) (interface{}, error) {
+			return s.resourceToNode(resource)
+		})
+		return
+	}
+	
+	// Read the version before listing so a watch started from it misses nothing
+	metadata := s.listMetadata()
+	
 	// Get from database using node-specific method
-	nodes, err := s.repository.ListNodes()
+	page, err := s.repository.ListNodesPage(options)
+	if err != nil {
+		listError(c, err, "Failed to list nodes")
+		return
+	}
+	setContinue(metadata, page.Continue)
+	
+	matching := []*types.Node{}
+	for _, node := range page.Nodes {
+		if matchesFilter(filter, node) {
+			matching = append(matching, node)
+		}
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"apiVersion": "v1",
+		"kind":       "NodeList",
+		"metadata":   metadata,
+		"items":      matching,
+	})
+}
+
+// listNodePods handles GET /api/v1/nodes/{name}/pods. It lists the pods
+// bound to the node, or assigned to it by the scheduler and not yet bound,
+// across all namespaces.
+func (s *Server) listNodePods(c *gin.Context) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "MISSING_PARAMETER",
+			Message: "Node name is required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	
+	node, err := s.repository.GetNode(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: "Node not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	
+	// Only return the pods matching the request's selectors
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+	
+	// Only return the requested page
+	options, ok := listOptions(c)
+	if !ok {
+		return
+	}
+	
+	// Stream changes to the node's pods instead of listing when watching.
+	// Assignments are looked up as events arrive since they change over time.
+	if isWatchRequest(c) {
+		nodeFilter := func(object interface{}) bool {
+			pod, ok := object.(*types.Pod)
+			return ok && matchesFilter(filter, pod) && podOnNode(pod, node, func(podID string) bool {
+				assignment, err := s.repository.GetPodAssignment(podID)
+				return err == nil && assignment.NodeID == node.Metadata.UID
+			})
+		}
+		s.watchResources(c, "Pod", "", nodeFilter, func(resource storage.Resource) (interface{}, error) {
+			return s.resourceToPod(resource)
+		})
+		return
+	}
+	
+	// Read the version before listing so a watch started from it misses nothing
+	metadata := s.listMetadata()
+	
+	assignments, err := s.repository.ListPodAssignmentsByNode(node.Metadata.UID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "DATABASE_ERROR",
-			Message: "Failed to list nodes",
+			Message: "Failed to list pod assignments",
 			Code:    http.StatusInternalServerError,
 			Details: map[string]string{"error": err.Error()},
 		})
 		return
 	}
+	assigned := make(map[string]bool)
+	for _, assignment := range assignments {
+		assigned[assignment.PodID] = true
+	}
+	
+	page, err := s.repository.ListResourcesPage("Pod", "", options)
+	if err != nil {
+		listError(c, err, "Failed to list pods")
+		return
+	}
+	setContinue(metadata, page.Continue)
+	
+	pods := []types.Pod{}
+	for _, resource := range page.Resources {
+		pod, err := s.resourceToPod(resource)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "DESERIALIZATION_ERROR",
+				Message: "Failed to deserialize pod",
+				Code:    http.StatusInternalServerError,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
+		if matchesFilter(filter, pod) && podOnNode(pod, node, func(podID string) bool { return assigned[podID] }) {
+			pods = append(pods, *pod)
+		}
+	}
 	
 	c.JSON(http.StatusOK, gin.H{
 		"apiVersion": "v1",
-		"kind":       "NodeList",
-		"items":      nodes,
+		"kind":       "PodList",
+		"metadata":   metadata,
+		"items":      pods,
 	})
 }
 
+// podOnNode reports whether a pod belongs to a node. A pod's spec.nodeName is
+// authoritative once it is bound; until then its scheduler assignment is used.
+func podOnNode(pod *types.Pod, node *types.Node, assigned func(podID string) bool) bool {
+	if pod.Spec.NodeName != "" {
+		return pod.Spec.NodeName == node.Metadata.Name
+	}
+	return assigned(pod.Metadata.UID)
+}
+
 // updateNodeHeartbeat handles POST /api/v1/nodes/{name}/heartbeat
 func (s *Server) updateNodeHeartbeat(c *gin.Context) {
 	name := c.Param("name")
@@ -254,37 +420,22 @@ func (s *Server) updateNodeHeartbeat(c *gin.Context) {
 			return
 		}
 	} else {
-		// Update node status with provided data
-		node.Status = statusUpdate
-		
-		// Update Ready condition with current timestamp
-		now := time.Now()
-		readyConditionFound := false
-		
-		for i, condition := range node.Status.Conditions {
-			if condition.Type == "Ready" {
-				node.Status.Conditions[i].LastHeartbeatTime = now
-				node.Status.Conditions[i].Status = "True"
-				node.Status.Conditions[i].Reason = "NodeReady"
-				node.Status.Conditions[i].Message = "Node is ready"
-				readyConditionFound = true
-				break
+		// Re-read the node on conflict so concurrent label or spec changes
+		// aren't overwritten by the status update
+		err := storage.RetryOnConflict(func() error {
+			node, err := s.repository.GetNode(name)
+			if err != nil {
+				return err
 			}
-		}
-		
-		if !readyConditionFound {
-			node.Status.Conditions = append(node.Status.Conditions, types.NodeCondition{
-				Type:               "Ready",
-				Status:             "True",
-				LastHeartbeatTime:  now,
-				LastTransitionTime: now,
-				Reason:             "NodeReady",
-				Message:            "Node is ready",
-			})
-		}
+			
+			// Update node status with provided data
+			node.Status = statusUpdate
+			setNodeReady(&node.Status, time.Now())
+			
+			return s.repository.UpdateNode(node)
+		})
 		
-		// Update node in database
-		if err := s.repository.UpdateNode(node); err != nil {
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
 				Error:   "DATABASE_ERROR",
 				Message: "Failed to update node status",
@@ -299,4 +450,27 @@ func (s *Server) updateNodeHeartbeat(c *gin.Context) {
 		"message":   "Node heartbeat updated successfully",
 		"timestamp": time.Now().UTC(),
 	})
-}
\ No newline at end of file
+}
+
+// setNodeReady marks the Ready condition of a node status as true and records
+// a heartbeat at now
+func setNodeReady(status *types.NodeStatus, now time.Time) {
+	for i, condition := range status.Conditions {
+		if condition.Type == "Ready" {
+			status.Conditions[i].LastHeartbeatTime = now
+			status.Conditions[i].Status = "True"
+			status.Conditions[i].Reason = "NodeReady"
+			status.Conditions[i].Message = "Node is ready"
+			return
+		}
+	}
+	
+	status.Conditions = append(status.Conditions, types.NodeCondition{
+		Type:               "Ready",
+		Status:             "True",
+		LastHeartbeatTime:  now,
+		LastTransitionTime: now,
+		Reason:             "NodeReady",
+		Message:            "Node is ready",
+	})
+}
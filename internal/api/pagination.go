@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"mini-k8s-orchestration/internal/storage"
+)
+
+// listOptions parses the limit and continue query parameters of a list
+// request. When either is invalid an error response is written and false is
+// returned.
+//
+// A page may hold fewer than limit objects when selectors filter some of the
+// objects it covers; clients should keep following the continue token of
+// each page until it is empty.
+func listOptions(c *gin.Context) (storage.ListOptions, bool) {
+	options := storage.ListOptions{Continue: c.Query("continue")}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "INVALID_PARAMETER",
+				Message: "limit must be a non-negative integer",
+				Code:    http.StatusBadRequest,
+			})
+			return storage.ListOptions{}, false
+		}
+		options.Limit = limit
+	}
+
+	return options, true
+}
+
+// setContinue adds the continue token of the next page to list metadata
+func setContinue(metadata gin.H, token string) {
+	if token != "" {
+		metadata["continue"] = token
+	}
+}
+
+// listError writes the response of a list request whose page could not be read
+func listError(c *gin.Context, err error, message string) {
+	if errors.Is(err, storage.ErrInvalidContinue) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_PARAMETER",
+			Message: "Invalid continue token, list again without one",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"continue": c.Query("continue")},
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:   "DATABASE_ERROR",
+		Message: message,
+		Code:    http.StatusInternalServerError,
+		Details: map[string]string{"error": err.Error()},
+	})
+}
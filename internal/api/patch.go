@@ -0,0 +1,413 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// patchFailure is an error that ends a patch with a specific response
+type patchFailure struct {
+	response ErrorResponse
+}
+
+func (f *patchFailure) Error() string {
+	return f.response.Message
+}
+
+// patchedObject is an API object decoded from a patched document, with the
+// parts the patch handler needs to check and store it
+type patchedObject struct {
+	object   interface{}
+	metadata *types.ObjectMeta
+	spec     interface{}
+	status   interface{}
+	validate func() error
+}
+
+// readPatch reads the patch in the request body. Only JSON merge patches and
+// JSON patches are accepted; otherwise an error response is written and false
+// is returned.
+func readPatch(c *gin.Context) (string, []byte, bool) {
+	contentType := c.ContentType()
+	if contentType != mergePatchType && contentType != jsonPatchType {
+		c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{
+			Error:   "UNSUPPORTED_MEDIA_TYPE",
+			Message: fmt.Sprintf("Patch content type must be %s or %s", mergePatchType, jsonPatchType),
+			Code:    http.StatusUnsupportedMediaType,
+			Details: map[string]string{"contentType": contentType},
+		})
+		return "", nil, false
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "Failed to read patch",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return "", nil, false
+	}
+
+	return contentType, patch, true
+}
+
+// patchObject applies a patch to the JSON form of current, decodes the result
+// with decode and checks that the patch left the object's identity alone and
+// was made against the stored version
+func patchObject(kind, contentType string, patch []byte, current interface{}, meta types.ObjectMeta, version int64, decode func([]byte) (*patchedObject, error)) (*patchedObject, error) {
+	document, err := json.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize %s: %w", kind, err)
+	}
+
+	document, err = applyPatch(contentType, document, patch)
+	if err != nil {
+		return nil, &patchFailure{ErrorResponse{
+			Error:   "INVALID_PATCH",
+			Message: fmt.Sprintf("Failed to apply patch to %s", kind),
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"error": err.Error()},
+		}}
+	}
+
+	patched, err := decode(document)
+	if err != nil {
+		return nil, &patchFailure{ErrorResponse{
+			Error:   "INVALID_PATCH",
+			Message: fmt.Sprintf("Patched %s is not valid", kind),
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"error": err.Error()},
+		}}
+	}
+
+	if patched.metadata.Name != meta.Name {
+		return nil, &patchFailure{ErrorResponse{
+			Error:   "NAME_MISMATCH",
+			Message: fmt.Sprintf("%s name cannot be changed", kind),
+			Code:    http.StatusBadRequest,
+		}}
+	}
+	if patched.metadata.Namespace != meta.Namespace {
+		return nil, &patchFailure{ErrorResponse{
+			Error:   "NAMESPACE_MISMATCH",
+			Message: fmt.Sprintf("%s namespace cannot be changed", kind),
+			Code:    http.StatusBadRequest,
+		}}
+	}
+
+	// A patch that sets resourceVersion only applies to that version
+	if patched.metadata.ResourceVersion != storage.FormatResourceVersion(version) {
+		return nil, &patchFailure{ErrorResponse{
+			Error:   "CONFLICT",
+			Message: fmt.Sprintf("%s has been modified, get the latest version and try again", kind),
+			Code:    http.StatusConflict,
+			Details: map[string]string{"resourceVersion": storage.FormatResourceVersion(version)},
+		}}
+	}
+
+	if err := patched.validate(); err != nil {
+		return nil, &patchFailure{ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: fmt.Sprintf("%s validation failed", kind),
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		}}
+	}
+
+	// Fields owned by the server can't be patched
+	patched.metadata.UID = meta.UID
+	patched.metadata.CreatedAt = meta.CreatedAt
+	patched.metadata.UpdatedAt = time.Now()
+
+	return patched, nil
+}
+
+// patchResource applies the patch in the request body to a stored resource.
+// The patch is applied to the version that was read and stored only if that
+// version is still current; otherwise it is re-applied to the new version.
+func (s *Server) patchResource(c *gin.Context, kind, namespace string, convert func(storage.Resource) (interface{}, error), decode func([]byte) (*patchedObject, error)) {
+	name := c.Param("name")
+
+	contentType, patch, ok := readPatch(c)
+	if !ok {
+		return
+	}
+
+	err := storage.RetryOnConflict(func() error {
+		resource, err := s.repository.GetResource(kind, namespace, name)
+		if err != nil {
+			return err
+		}
+
+		current, err := convert(resource)
+		if err != nil {
+			return err
+		}
+
+		// Identity always comes from the stored row
+		meta := types.ObjectMeta{
+			Name:      resource.Name,
+			Namespace: resource.Namespace,
+			UID:       resource.ID,
+			CreatedAt: resource.CreatedAt,
+		}
+
+		patched, err := patchObject(kind, contentType, patch, current, meta, resource.ResourceVersion, decode)
+		if err != nil {
+			return err
+		}
+
+		metadataJSON, err := json.Marshal(patched.metadata)
+		if err != nil {
+			return fmt.Errorf("failed to serialize %s metadata: %w", kind, err)
+		}
+		specJSON, err := json.Marshal(patched.spec)
+		if err != nil {
+			return fmt.Errorf("failed to serialize %s spec: %w", kind, err)
+		}
+		statusJSON, err := json.Marshal(patched.status)
+		if err != nil {
+			return fmt.Errorf("failed to serialize %s status: %w", kind, err)
+		}
+
+		resource.Metadata = string(metadataJSON)
+		resource.Spec = string(specJSON)
+		resource.Status = string(statusJSON)
+		return s.repository.UpdateResource(resource)
+	})
+	if err != nil {
+		patchError(c, kind, err)
+		return
+	}
+
+	// Return the object as it was stored
+	resource, err := s.repository.GetResource(kind, namespace, name)
+	if err != nil {
+		patchError(c, kind, err)
+		return
+	}
+	object, err := convert(resource)
+	if err != nil {
+		patchError(c, kind, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, object)
+}
+
+// patchError writes the response of a patch that failed
+func patchError(c *gin.Context, kind string, err error) {
+	var failure *patchFailure
+	switch {
+	case errors.As(err, &failure):
+		c.JSON(failure.response.Code, failure.response)
+	case errors.Is(err, storage.ErrConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "CONFLICT",
+			Message: fmt.Sprintf("%s is being modified too often, try again", kind),
+			Code:    http.StatusConflict,
+			Details: map[string]string{"error": err.Error()},
+		})
+	default:
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: fmt.Sprintf("%s not found", kind),
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+	}
+}
+
+// patchPod handles PATCH /api/v1/pods/{name}
+func (s *Server) patchPod(c *gin.Context) {
+	s.patchPodInNamespace(c, "default")
+}
+
+// patchNamespacedPod handles PATCH /api/v1/namespaces/{namespace}/pods/{name}
+func (s *Server) patchNamespacedPod(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.patchPodInNamespace(c, namespace)
+}
+
+func (s *Server) patchPodInNamespace(c *gin.Context, namespace string) {
+	convert := func(resource storage.Resource) (interface{}, error) {
+		return s.resourceToPod(resource)
+	}
+	s.patchResource(c, "Pod", namespace, convert, func(data []byte) (*patchedObject, error) {
+		var pod types.Pod
+		if err := json.Unmarshal(data, &pod); err != nil {
+			return nil, err
+		}
+		return &patchedObject{
+			object:   &pod,
+			metadata: &pod.Metadata,
+			spec:     &pod.Spec,
+			status:   &pod.Status,
+			validate: func() error { return types.ValidatePod(&pod) },
+		}, nil
+	})
+}
+
+// patchService handles PATCH /api/v1/services/{name}
+func (s *Server) patchService(c *gin.Context) {
+	s.patchServiceInNamespace(c, "default")
+}
+
+// patchNamespacedService handles PATCH /api/v1/namespaces/{namespace}/services/{name}
+func (s *Server) patchNamespacedService(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.patchServiceInNamespace(c, namespace)
+}
+
+func (s *Server) patchServiceInNamespace(c *gin.Context, namespace string) {
+	convert := func(resource storage.Resource) (interface{}, error) {
+		return s.resourceToService(resource)
+	}
+	s.patchResource(c, "Service", namespace, convert, func(data []byte) (*patchedObject, error) {
+		var service types.Service
+		if err := json.Unmarshal(data, &service); err != nil {
+			return nil, err
+		}
+		return &patchedObject{
+			object:   &service,
+			metadata: &service.Metadata,
+			spec:     &service.Spec,
+			status:   &service.Status,
+			validate: func() error { return types.ValidateService(&service) },
+		}, nil
+	})
+}
+
+// patchDeployment handles PATCH /api/v1/deployments/{name}
+func (s *Server) patchDeployment(c *gin.Context) {
+	s.patchDeploymentInNamespace(c, "default")
+}
+
+// patchNamespacedDeployment handles PATCH /api/v1/namespaces/{namespace}/deployments/{name}
+func (s *Server) patchNamespacedDeployment(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.patchDeploymentInNamespace(c, namespace)
+}
+
+func (s *Server) patchDeploymentInNamespace(c *gin.Context, namespace string) {
+	convert := func(resource storage.Resource) (interface{}, error) {
+		return s.resourceToDeployment(resource)
+	}
+	s.patchResource(c, "Deployment", namespace, convert, func(data []byte) (*patchedObject, error) {
+		var deployment types.Deployment
+		if err := json.Unmarshal(data, &deployment); err != nil {
+			return nil, err
+		}
+		return &patchedObject{
+			object:   &deployment,
+			metadata: &deployment.Metadata,
+			spec:     &deployment.Spec,
+			status:   &deployment.Status,
+			validate: func() error { return types.ValidateDeployment(&deployment) },
+		}, nil
+	})
+}
+
+// patchReplicaSet handles PATCH /api/v1/replicasets/{name}
+func (s *Server) patchReplicaSet(c *gin.Context) {
+	s.patchReplicaSetInNamespace(c, "default")
+}
+
+// patchNamespacedReplicaSet handles PATCH /api/v1/namespaces/{namespace}/replicasets/{name}
+func (s *Server) patchNamespacedReplicaSet(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.patchReplicaSetInNamespace(c, namespace)
+}
+
+func (s *Server) patchReplicaSetInNamespace(c *gin.Context, namespace string) {
+	convert := func(resource storage.Resource) (interface{}, error) {
+		return s.resourceToReplicaSet(resource)
+	}
+	s.patchResource(c, "ReplicaSet", namespace, convert, func(data []byte) (*patchedObject, error) {
+		var replicaSet types.ReplicaSet
+		if err := json.Unmarshal(data, &replicaSet); err != nil {
+			return nil, err
+		}
+		return &patchedObject{
+			object:   &replicaSet,
+			metadata: &replicaSet.Metadata,
+			spec:     &replicaSet.Spec,
+			status:   &replicaSet.Status,
+			validate: func() error { return types.ValidateReplicaSet(&replicaSet) },
+		}, nil
+	})
+}
+
+// patchNode handles PATCH /api/v1/nodes/{name}
+func (s *Server) patchNode(c *gin.Context) {
+	name := c.Param("name")
+
+	contentType, patch, ok := readPatch(c)
+	if !ok {
+		return
+	}
+
+	var node *types.Node
+	err := storage.RetryOnConflict(func() error {
+		current, err := s.repository.GetNode(name)
+		if err != nil {
+			return err
+		}
+		version, err := storage.ParseResourceVersion(current.Metadata.ResourceVersion)
+		if err != nil {
+			return err
+		}
+
+		patched, err := patchObject("Node", contentType, patch, current, current.Metadata, version, func(data []byte) (*patchedObject, error) {
+			var node types.Node
+			if err := json.Unmarshal(data, &node); err != nil {
+				return nil, err
+			}
+			return &patchedObject{
+				object:   &node,
+				metadata: &node.Metadata,
+				spec:     &node.Spec,
+				status:   &node.Status,
+				validate: func() error { return types.ValidateNode(&node) },
+			}, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		node = patched.object.(*types.Node)
+		return s.repository.UpdateNode(node)
+	})
+	if err != nil {
+		patchError(c, "Node", err)
+		return
+	}
+
+	// Return the version the patch was stored with
+	if updated, err := s.repository.GetNode(name); err == nil {
+		node = updated
+	}
+
+	c.JSON(http.StatusOK, node)
+}
@@ -2,8 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -74,6 +76,11 @@ func (s *Server) createPodInNamespace(c *gin.Context, namespace string) {
 		return
 	}
 	
+	// Resolve the pod's priority from its priority class
+	if !s.resolvePodPriority(c, &pod) {
+		return
+	}
+	
 	// Set metadata
 	now := time.Now()
 	pod.APIVersion = "v1"
@@ -150,6 +157,11 @@ func (s *Server) createPodInNamespace(c *gin.Context, namespace string) {
 		return
 	}
 	
+	// Return the version the resource was stored with
+	if created, err := s.repository.GetResource("Pod", resource.Namespace, resource.Name); err == nil {
+		pod.Metadata.ResourceVersion = storage.FormatResourceVersion(created.ResourceVersion)
+	}
+	
 	c.JSON(http.StatusCreated, pod)
 }
 
@@ -277,13 +289,14 @@ func (s *Server) updatePodInNamespace(c *gin.Context, namespace string) {
 	// Update timestamp
 	pod.Metadata.UpdatedAt = time.Now()
 	
-	// Convert to storage resource
-	metadataJSON, err := json.Marshal(pod.Metadata)
+	// Only apply the update to the version the client read, if it sent one
+	resourceVersion, err := storage.ParseResourceVersion(pod.Metadata.ResourceVersion)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "SERIALIZATION_ERROR",
-			Message: "Failed to serialize pod metadata",
-			Code:    http.StatusInternalServerError,
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "Invalid resourceVersion",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
 		})
 		return
 	}
@@ -308,17 +321,140 @@ func (s *Server) updatePodInNamespace(c *gin.Context, namespace string) {
 		return
 	}
 	
-	resource := storage.Resource{
-		Kind:      "Pod",
-		Namespace: namespace,
-		Name:      name,
-		Metadata:  string(metadataJSON),
-		Spec:      string(specJSON),
-		Status:    string(statusJSON),
+	// Only a deletion can mark a pod as terminating, and an update can't undo
+	// it. The deletion state is read from the version the update replaces, so
+	// an update without a resourceVersion can't race a deletion.
+	err = storage.RetryOnConflict(func() error {
+		existing, err := s.repository.GetResource("Pod", namespace, name)
+		if err != nil {
+			return err
+		}
+		pod.Metadata.DeletionTimestamp = nil
+		pod.Metadata.DeletionGracePeriodSeconds = nil
+		if existingPod, err := s.resourceToPod(existing); err == nil {
+			pod.Metadata.DeletionTimestamp = existingPod.Metadata.DeletionTimestamp
+			pod.Metadata.DeletionGracePeriodSeconds = existingPod.Metadata.DeletionGracePeriodSeconds
+		}
+		
+		metadataJSON, err := json.Marshal(pod.Metadata)
+		if err != nil {
+			return err
+		}
+		
+		resource := storage.Resource{
+			Kind:            "Pod",
+			Namespace:       namespace,
+			Name:            name,
+			Metadata:        string(metadataJSON),
+			Spec:            string(specJSON),
+			Status:          string(statusJSON),
+			ResourceVersion: existing.ResourceVersion,
+		}
+		if resourceVersion != 0 {
+			resource.ResourceVersion = resourceVersion
+		}
+		return s.repository.UpdateResource(resource)
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "CONFLICT",
+				Message: "Pod has been modified, get the latest version and try again",
+				Code:    http.StatusConflict,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: "Pod not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	
+	// Return the version the update was stored with
+	if updated, err := s.repository.GetResource("Pod", namespace, name); err == nil {
+		pod.Metadata.ResourceVersion = storage.FormatResourceVersion(updated.ResourceVersion)
+	}
+	
+	c.JSON(http.StatusOK, pod)
+}
+
+// updatePodStatus handles PUT /api/v1/pods/{name}/status
+func (s *Server) updatePodStatus(c *gin.Context) {
+	s.updatePodStatusInNamespace(c, "default")
+}
+
+// updateNamespacedPodStatus handles PUT /api/v1/namespaces/{namespace}/pods/{name}/status
+func (s *Server) updateNamespacedPodStatus(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.updatePodStatusInNamespace(c, namespace)
+}
+
+// updatePodStatusInNamespace records the status a node agent observed for a
+// pod in the specified namespace. Only the fields the agent owns are merged
+// into the latest version of the pod; the metadata, spec and the conditions
+// other components set are left untouched, so agents can report what they
+// observe without racing other updates.
+func (s *Server) updatePodStatusInNamespace(c *gin.Context, namespace string) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "MISSING_PARAMETER",
+			Message: "Pod name is required",
+			Code:    http.StatusBadRequest,
+		})
+		return
 	}
 	
-	// Update in database
-	if err := s.repository.UpdateResource(resource); err != nil {
+	var status types.PodStatus
+	if err := c.ShouldBindJSON(&status); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "Invalid JSON format",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+	
+	// Apply the status to the latest version of the pod
+	var resource storage.Resource
+	err := storage.RetryOnConflict(func() error {
+		latest, err := s.repository.GetResource("Pod", namespace, name)
+		if err != nil {
+			return err
+		}
+		pod, err := s.resourceToPod(latest)
+		if err != nil {
+			return err
+		}
+		statusJSON, err := json.Marshal(mergePodStatus(pod.Status, status))
+		if err != nil {
+			return err
+		}
+		latest.Status = string(statusJSON)
+		if err := s.repository.UpdateResource(latest); err != nil {
+			return err
+		}
+		resource = latest
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "CONFLICT",
+				Message: "Pod is being modified concurrently, try again",
+				Code:    http.StatusConflict,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
 		c.JSON(http.StatusNotFound, ErrorResponse{
 			Error:   "RESOURCE_NOT_FOUND",
 			Message: "Pod not found",
@@ -328,9 +464,58 @@ func (s *Server) updatePodInNamespace(c *gin.Context, namespace string) {
 		return
 	}
 	
+	// Return the pod as it was stored
+	if updated, err := s.repository.GetResource("Pod", namespace, name); err == nil {
+		resource = updated
+	}
+	
+	pod, err := s.resourceToPod(resource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "DESERIALIZATION_ERROR",
+			Message: "Failed to deserialize pod",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	
 	c.JSON(http.StatusOK, pod)
 }
 
+// agentPodConditions are the pod conditions node agents report
+var agentPodConditions = map[string]bool{"Ready": true, "ContainersReady": true}
+
+// mergePodStatus merges the fields of a reported status that node agents own
+// into the current status of a pod: the container statuses, pod IP, start
+// time, readiness conditions and phase. A pod that is terminating or has
+// finished keeps its phase, so a report can't bring it back to life.
+func mergePodStatus(current, reported types.PodStatus) types.PodStatus {
+	merged := current
+	merged.ContainerStatuses = reported.ContainerStatuses
+	merged.PodIP = reported.PodIP
+	merged.StartTime = reported.StartTime
+	
+	switch current.Phase {
+	case types.PodPhaseTerminating, "Failed", "Succeeded":
+	default:
+		merged.Phase = reported.Phase
+	}
+	
+	merged.Conditions = nil
+	for _, condition := range current.Conditions {
+		if !agentPodConditions[condition.Type] {
+			merged.Conditions = append(merged.Conditions, condition)
+		}
+	}
+	for _, condition := range reported.Conditions {
+		if agentPodConditions[condition.Type] {
+			merged.Conditions = append(merged.Conditions, condition)
+		}
+	}
+	return merged
+}
+
 // deletePod handles DELETE /api/v1/pods/{name}
 func (s *Server) deletePod(c *gin.Context) {
 	s.deletePodFromNamespace(c, "default")
@@ -345,7 +530,11 @@ func (s *Server) deleteNamespacedPod(c *gin.Context) {
 	s.deletePodFromNamespace(c, namespace)
 }
 
-// deletePodFromNamespace deletes a pod from the specified namespace
+// deletePodFromNamespace deletes a pod from the specified namespace. A pod on
+// a node is deleted gracefully: it gets a deletionTimestamp and the
+// Terminating phase, and the node agent removes it once its containers have
+// shut down. The grace period is the pod's terminationGracePeriodSeconds
+// unless ?gracePeriodSeconds= overrides it; 0 deletes the pod at once.
 func (s *Server) deletePodFromNamespace(c *gin.Context, namespace string) {
 	name := c.Param("name")
 	if name == "" {
@@ -357,8 +546,31 @@ func (s *Server) deletePodFromNamespace(c *gin.Context, namespace string) {
 		return
 	}
 	
-	// Delete from database
-	if err := s.repository.DeleteResource("Pod", namespace, name); err != nil {
+	var gracePeriod *int64
+	if value := c.Query("gracePeriodSeconds"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || seconds < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "INVALID_PARAMETER",
+				Message: "gracePeriodSeconds must be a non-negative integer",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		gracePeriod = &seconds
+	}
+	
+	pod, err := storage.DeletePodGracefully(s.repository, namespace, name, gracePeriod)
+	if err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "CONFLICT",
+				Message: "Pod is being modified concurrently, try again",
+				Code:    http.StatusConflict,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
 		c.JSON(http.StatusNotFound, ErrorResponse{
 			Error:   "RESOURCE_NOT_FOUND",
 			Message: "Pod not found",
@@ -367,9 +579,17 @@ func (s *Server) deletePodFromNamespace(c *gin.Context, namespace string) {
 		})
 		return
 	}
+	if pod == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Pod deleted successfully",
+		})
+		return
+	}
 	
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Pod deleted successfully",
+		"message":                    "Pod is terminating",
+		"deletionTimestamp":          pod.Metadata.DeletionTimestamp,
+		"deletionGracePeriodSeconds": pod.Metadata.DeletionGracePeriodSeconds,
 	})
 }
 
@@ -389,21 +609,40 @@ func (s *Server) listNamespacedPods(c *gin.Context) {
 
 // listPodsInNamespace lists pods in the specified namespace
 func (s *Server) listPodsInNamespace(c *gin.Context, namespace string) {
+	// Only return the objects matching the request's selectors
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+	
+	// Only return the requested page
+	options, ok := listOptions(c)
+	if !ok {
+		return
+	}
+	
+	// Stream changes instead of listing when watching
+	if isWatchRequest(c) {
+		s.watchResources(c, "Pod", namespace, filter, func(resource storage.Resource) (interface{}, error) {
+			return s.resourceToPod(resource)
+		})
+		return
+	}
+	
+	// Read the version before listing so a watch started from it misses nothing
+	metadata := s.listMetadata()
+	
 	// Get from database
-	resources, err := s.repository.ListResources("Pod", namespace)
+	page, err := s.repository.ListResourcesPage("Pod", namespace, options)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "DATABASE_ERROR",
-			Message: "Failed to list pods",
-			Code:    http.StatusInternalServerError,
-			Details: map[string]string{"error": err.Error()},
-		})
+		listError(c, err, "Failed to list pods")
 		return
 	}
+	setContinue(metadata, page.Continue)
 	
 	// Convert to pods
 	var pods []types.Pod
-	for _, resource := range resources {
+	for _, resource := range page.Resources {
 		pod, err := s.resourceToPod(resource)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -414,12 +653,16 @@ func (s *Server) listPodsInNamespace(c *gin.Context, namespace string) {
 			})
 			return
 		}
+		if !matchesFilter(filter, pod) {
+			continue
+		}
 		pods = append(pods, *pod)
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
 		"apiVersion": "v1",
 		"kind":       "PodList",
+		"metadata":   metadata,
 		"items":      pods,
 	})
 }
@@ -0,0 +1,444 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// priorityClassSpec is the part of a PriorityClass stored as its spec
+type priorityClassSpec struct {
+	Value         int32  `json:"value"`
+	GlobalDefault bool   `json:"globalDefault,omitempty"`
+	Description   string `json:"description,omitempty"`
+}
+
+// createPriorityClass handles POST /api/v1/priorityclasses
+func (s *Server) createPriorityClass(c *gin.Context) {
+	var class types.PriorityClass
+
+	if err := c.ShouldBindJSON(&class); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "Invalid JSON format",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+
+	// Validate the priority class
+	if err := s.validatePriorityClass(&class); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "PriorityClass validation failed",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+
+	// Set metadata
+	now := time.Now()
+	class.APIVersion = "v1"
+	class.Kind = "PriorityClass"
+	class.Metadata.UID = uuid.New().String()
+	class.Metadata.CreatedAt = now
+	class.Metadata.UpdatedAt = now
+
+	resource, err := priorityClassToResource(&class)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "SERIALIZATION_ERROR",
+			Message: "Failed to serialize priority class",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	resource.ID = class.Metadata.UID
+	resource.CreatedAt = now
+	resource.UpdatedAt = now
+
+	// Save to database
+	if err := s.repository.CreateResource(resource); err != nil {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "RESOURCE_EXISTS",
+			Message: "PriorityClass already exists",
+			Code:    http.StatusConflict,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Return the version the resource was stored with
+	if created, err := s.repository.GetResource("PriorityClass", "", class.Metadata.Name); err == nil {
+		class.Metadata.ResourceVersion = storage.FormatResourceVersion(created.ResourceVersion)
+	}
+
+	c.JSON(http.StatusCreated, class)
+}
+
+// getPriorityClass handles GET /api/v1/priorityclasses/{name}
+func (s *Server) getPriorityClass(c *gin.Context) {
+	name := c.Param("name")
+
+	resource, err := s.repository.GetResource("PriorityClass", "", name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: "PriorityClass not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	class, err := s.resourceToPriorityClass(resource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "DESERIALIZATION_ERROR",
+			Message: "Failed to deserialize priority class",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, class)
+}
+
+// updatePriorityClass handles PUT /api/v1/priorityclasses/{name}
+func (s *Server) updatePriorityClass(c *gin.Context) {
+	name := c.Param("name")
+
+	var class types.PriorityClass
+	if err := c.ShouldBindJSON(&class); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "Invalid JSON format",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+
+	// Validate name matches URL parameter
+	if class.Metadata.Name != name {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "NAME_MISMATCH",
+			Message: "PriorityClass name does not match URL parameter",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Validate the priority class
+	if err := s.validatePriorityClass(&class); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "PriorityClass validation failed",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+
+	// Only apply the update to the version the client read, if it sent one
+	resourceVersion, err := storage.ParseResourceVersion(class.Metadata.ResourceVersion)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "Invalid resourceVersion",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+
+	class.APIVersion = "v1"
+	class.Kind = "PriorityClass"
+	class.Metadata.UpdatedAt = time.Now()
+
+	resource, err := priorityClassToResource(&class)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "SERIALIZATION_ERROR",
+			Message: "Failed to serialize priority class",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	resource.ResourceVersion = resourceVersion
+
+	// Update in database
+	if err := s.repository.UpdateResource(resource); err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "CONFLICT",
+				Message: "PriorityClass has been modified, get the latest version and try again",
+				Code:    http.StatusConflict,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: "PriorityClass not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Return the version the update was stored with
+	if updated, err := s.repository.GetResource("PriorityClass", "", name); err == nil {
+		class.Metadata.ResourceVersion = storage.FormatResourceVersion(updated.ResourceVersion)
+	}
+
+	c.JSON(http.StatusOK, class)
+}
+
+// patchPriorityClass handles PATCH /api/v1/priorityclasses/{name}
+func (s *Server) patchPriorityClass(c *gin.Context) {
+	convert := func(resource storage.Resource) (interface{}, error) {
+		return s.resourceToPriorityClass(resource)
+	}
+	s.patchResource(c, "PriorityClass", "", convert, func(data []byte) (*patchedObject, error) {
+		var class types.PriorityClass
+		if err := json.Unmarshal(data, &class); err != nil {
+			return nil, err
+		}
+		return &patchedObject{
+			object:   &class,
+			metadata: &class.Metadata,
+			spec: &priorityClassSpec{
+				Value:         class.Value,
+				GlobalDefault: class.GlobalDefault,
+				Description:   class.Description,
+			},
+			status:   struct{}{},
+			validate: func() error { return s.validatePriorityClass(&class) },
+		}, nil
+	})
+}
+
+// deletePriorityClass handles DELETE /api/v1/priorityclasses/{name}. Pods
+// keep the priority they were created with.
+func (s *Server) deletePriorityClass(c *gin.Context) {
+	name := c.Param("name")
+
+	if err := s.repository.DeleteResource("PriorityClass", "", name); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: "PriorityClass not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "PriorityClass deleted successfully",
+	})
+}
+
+// listPriorityClasses handles GET /api/v1/priorityclasses
+func (s *Server) listPriorityClasses(c *gin.Context) {
+	// Only return the objects matching the request's selectors
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+
+	// Only return the requested page
+	options, ok := listOptions(c)
+	if !ok {
+		return
+	}
+
+	// Stream changes instead of listing when watching
+	if isWatchRequest(c) {
+		s.watchResources(c, "PriorityClass", "", filter, func(resource storage.Resource) (interface{}, error) {
+			return s.resourceToPriorityClass(resource)
+		})
+		return
+	}
+
+	// Read the version before listing so a watch started from it misses nothing
+	metadata := s.listMetadata()
+
+	page, err := s.repository.ListResourcesPage("PriorityClass", "", options)
+	if err != nil {
+		listError(c, err, "Failed to list priority classes")
+		return
+	}
+	setContinue(metadata, page.Continue)
+
+	var classes []types.PriorityClass
+	for _, resource := range page.Resources {
+		class, err := s.resourceToPriorityClass(resource)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "DESERIALIZATION_ERROR",
+				Message: "Failed to deserialize priority class",
+				Code:    http.StatusInternalServerError,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
+		if !matchesFilter(filter, class) {
+			continue
+		}
+		classes = append(classes, *class)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"apiVersion": "v1",
+		"kind":       "PriorityClassList",
+		"metadata":   metadata,
+		"items":      classes,
+	})
+}
+
+// validatePriorityClass validates a priority class and checks that it doesn't
+// become a second global default
+func (s *Server) validatePriorityClass(class *types.PriorityClass) error {
+	if err := types.ValidatePriorityClass(class); err != nil {
+		return err
+	}
+	if !class.GlobalDefault {
+		return nil
+	}
+
+	classes, err := s.priorityClasses()
+	if err != nil {
+		return err
+	}
+	for _, existing := range classes {
+		if existing.GlobalDefault && existing.Metadata.Name != class.Metadata.Name {
+			return types.ValidationErrors{{
+				Field:   "globalDefault",
+				Message: fmt.Sprintf("priority class %s is already the global default", existing.Metadata.Name),
+			}}
+		}
+	}
+	return nil
+}
+
+// priorityClasses returns every priority class
+func (s *Server) priorityClasses() ([]*types.PriorityClass, error) {
+	resources, err := s.repository.ListResources("PriorityClass", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list priority classes: %w", err)
+	}
+
+	classes := make([]*types.PriorityClass, 0, len(resources))
+	for _, resource := range resources {
+		class, err := s.resourceToPriorityClass(resource)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, class)
+	}
+	return classes, nil
+}
+
+// resolvePodPriority sets the priority of a new pod from its priority class,
+// or from the global default class if it names none. A pod naming an unknown
+// class, or asking for a priority other than its class's, is rejected: an
+// error response is written and false is returned.
+func (s *Server) resolvePodPriority(c *gin.Context, pod *types.Pod) bool {
+	classes, err := s.priorityClasses()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "DATABASE_ERROR",
+			Message: "Failed to resolve pod priority",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return false
+	}
+
+	var priority int32
+	class := types.FindPriorityClass(classes, pod.Spec.PriorityClassName)
+	switch {
+	case class != nil:
+		pod.Spec.PriorityClassName = class.Metadata.Name
+		priority = class.Value
+	case pod.Spec.PriorityClassName != "":
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "Pod validation failed",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": fmt.Sprintf("no priority class named %s", pod.Spec.PriorityClassName)},
+		})
+		return false
+	}
+
+	if pod.Spec.Priority != nil && *pod.Spec.Priority != priority {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "Pod validation failed",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": fmt.Sprintf("priority %d doesn't match the priority %d of the pod's priority class", *pod.Spec.Priority, priority)},
+		})
+		return false
+	}
+	pod.Spec.Priority = &priority
+	return true
+}
+
+// priorityClassToResource converts a PriorityClass to a storage resource
+func priorityClassToResource(class *types.PriorityClass) (storage.Resource, error) {
+	metadataJSON, err := json.Marshal(class.Metadata)
+	if err != nil {
+		return storage.Resource{}, fmt.Errorf("failed to serialize priority class metadata: %w", err)
+	}
+
+	specJSON, err := json.Marshal(priorityClassSpec{
+		Value:         class.Value,
+		GlobalDefault: class.GlobalDefault,
+		Description:   class.Description,
+	})
+	if err != nil {
+		return storage.Resource{}, fmt.Errorf("failed to serialize priority class spec: %w", err)
+	}
+
+	return storage.Resource{
+		Kind:     "PriorityClass",
+		Name:     class.Metadata.Name,
+		Metadata: string(metadataJSON),
+		Spec:     string(specJSON),
+	}, nil
+}
+
+// resourceToPriorityClass converts a storage resource to a PriorityClass
+func (s *Server) resourceToPriorityClass(resource storage.Resource) (*types.PriorityClass, error) {
+	var metadata types.ObjectMeta
+	if err := json.Unmarshal([]byte(resource.Metadata), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal priority class metadata: %w", err)
+	}
+
+	var spec priorityClassSpec
+	if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal priority class spec: %w", err)
+	}
+
+	return &types.PriorityClass{
+		APIVersion:    "v1",
+		Kind:          "PriorityClass",
+		Metadata:      metadata,
+		Value:         spec.Value,
+		GlobalDefault: spec.GlobalDefault,
+		Description:   spec.Description,
+	}, nil
+}
@@ -0,0 +1,477 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// createReplicaSet handles POST /api/v1/replicasets
+func (s *Server) createReplicaSet(c *gin.Context) {
+	s.createReplicaSetInNamespace(c, "default")
+}
+
+// createNamespacedReplicaSet handles POST /api/v1/namespaces/{namespace}/replicasets
+func (s *Server) createNamespacedReplicaSet(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.createReplicaSetInNamespace(c, namespace)
+}
+
+// createReplicaSetInNamespace creates a replica set in the specified namespace
+func (s *Server) createReplicaSetInNamespace(c *gin.Context, namespace string) {
+	var replicaSet types.ReplicaSet
+
+	if err := c.ShouldBindJSON(&replicaSet); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "Invalid JSON format",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+
+	// Set namespace if not provided
+	if replicaSet.Metadata.Namespace == "" {
+		replicaSet.Metadata.Namespace = namespace
+	}
+
+	// Validate namespace matches URL parameter
+	if replicaSet.Metadata.Namespace != namespace {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "NAMESPACE_MISMATCH",
+			Message: "ReplicaSet namespace does not match URL namespace",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Default the selector to the template labels
+	if replicaSet.Spec.Selector.Empty() && len(replicaSet.Spec.Template.Metadata.Labels) > 0 {
+		replicaSet.Spec.Selector.MatchLabels = make(map[string]string)
+		for key, value := range replicaSet.Spec.Template.Metadata.Labels {
+			replicaSet.Spec.Selector.MatchLabels[key] = value
+		}
+	}
+
+	// Validate the replica set
+	if err := types.ValidateReplicaSet(&replicaSet); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "ReplicaSet validation failed",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+
+	// Set metadata
+	now := time.Now()
+	replicaSet.APIVersion = "apps/v1"
+	replicaSet.Kind = "ReplicaSet"
+	replicaSet.Metadata.UID = uuid.New().String()
+	replicaSet.Metadata.CreatedAt = now
+	replicaSet.Metadata.UpdatedAt = now
+
+	// The replica set controller fills in the status once it has created the pods
+	replicaSet.Status = types.ReplicaSetStatus{}
+
+	resource, err := replicaSetToResource(&replicaSet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "SERIALIZATION_ERROR",
+			Message: "Failed to serialize replica set",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+	resource.CreatedAt = now
+	resource.UpdatedAt = now
+
+	// Save to database
+	if err := s.repository.CreateResource(resource); err != nil {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "RESOURCE_EXISTS",
+			Message: "ReplicaSet already exists",
+			Code:    http.StatusConflict,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Return the version the resource was stored with
+	if created, err := s.repository.GetResource("ReplicaSet", resource.Namespace, resource.Name); err == nil {
+		replicaSet.Metadata.ResourceVersion = storage.FormatResourceVersion(created.ResourceVersion)
+	}
+
+	c.JSON(http.StatusCreated, replicaSet)
+}
+
+// getReplicaSet handles GET /api/v1/replicasets/{name}
+func (s *Server) getReplicaSet(c *gin.Context) {
+	s.getReplicaSetFromNamespace(c, "default")
+}
+
+// getNamespacedReplicaSet handles GET /api/v1/namespaces/{namespace}/replicasets/{name}
+func (s *Server) getNamespacedReplicaSet(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.getReplicaSetFromNamespace(c, namespace)
+}
+
+// getReplicaSetFromNamespace gets a replica set from the specified namespace
+func (s *Server) getReplicaSetFromNamespace(c *gin.Context, namespace string) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "MISSING_PARAMETER",
+			Message: "ReplicaSet name is required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Get from database
+	resource, err := s.repository.GetResource("ReplicaSet", namespace, name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: "ReplicaSet not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Convert to ReplicaSet
+	replicaSet, err := s.resourceToReplicaSet(resource)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "DESERIALIZATION_ERROR",
+			Message: "Failed to deserialize replica set",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, replicaSet)
+}
+
+// updateReplicaSet handles PUT /api/v1/replicasets/{name}
+func (s *Server) updateReplicaSet(c *gin.Context) {
+	s.updateReplicaSetInNamespace(c, "default")
+}
+
+// updateNamespacedReplicaSet handles PUT /api/v1/namespaces/{namespace}/replicasets/{name}
+func (s *Server) updateNamespacedReplicaSet(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.updateReplicaSetInNamespace(c, namespace)
+}
+
+// updateReplicaSetInNamespace updates a replica set in the specified namespace
+func (s *Server) updateReplicaSetInNamespace(c *gin.Context, namespace string) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "MISSING_PARAMETER",
+			Message: "ReplicaSet name is required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var replicaSet types.ReplicaSet
+	if err := c.ShouldBindJSON(&replicaSet); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "Invalid JSON format",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+
+	// Validate name matches URL parameter
+	if replicaSet.Metadata.Name != name {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "NAME_MISMATCH",
+			Message: "ReplicaSet name does not match URL parameter",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Validate namespace
+	if replicaSet.Metadata.Namespace != namespace {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "NAMESPACE_MISMATCH",
+			Message: "ReplicaSet namespace does not match URL namespace",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Validate the replica set
+	if err := types.ValidateReplicaSet(&replicaSet); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "ReplicaSet validation failed",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+
+	// Update timestamp
+	replicaSet.Metadata.UpdatedAt = time.Now()
+
+	// Only apply the update to the version the client read, if it sent one
+	resourceVersion, err := storage.ParseResourceVersion(replicaSet.Metadata.ResourceVersion)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "Invalid resourceVersion",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+
+	resource, err := replicaSetToResource(&replicaSet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "SERIALIZATION_ERROR",
+			Message: "Failed to serialize replica set",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	resource.ResourceVersion = resourceVersion
+
+	// Update in database
+	if err := s.repository.UpdateResource(resource); err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "CONFLICT",
+				Message: "ReplicaSet has been modified, get the latest version and try again",
+				Code:    http.StatusConflict,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: "ReplicaSet not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Return the version the update was stored with
+	if updated, err := s.repository.GetResource("ReplicaSet", namespace, name); err == nil {
+		replicaSet.Metadata.ResourceVersion = storage.FormatResourceVersion(updated.ResourceVersion)
+	}
+
+	c.JSON(http.StatusOK, replicaSet)
+}
+
+// deleteReplicaSet handles DELETE /api/v1/replicasets/{name}
+func (s *Server) deleteReplicaSet(c *gin.Context) {
+	s.deleteReplicaSetFromNamespace(c, "default")
+}
+
+// deleteNamespacedReplicaSet handles DELETE /api/v1/namespaces/{namespace}/replicasets/{name}
+func (s *Server) deleteNamespacedReplicaSet(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.deleteReplicaSetFromNamespace(c, namespace)
+}
+
+// deleteReplicaSetFromNamespace deletes a replica set from the specified namespace
+func (s *Server) deleteReplicaSetFromNamespace(c *gin.Context, namespace string) {
+	name := c.Param("name")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "MISSING_PARAMETER",
+			Message: "ReplicaSet name is required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Delete from database; the replica set controller removes its pods
+	if err := s.repository.DeleteResource("ReplicaSet", namespace, name); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "RESOURCE_NOT_FOUND",
+			Message: "ReplicaSet not found",
+			Code:    http.StatusNotFound,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "ReplicaSet deleted successfully",
+	})
+}
+
+// listReplicaSets handles GET /api/v1/replicasets
+func (s *Server) listReplicaSets(c *gin.Context) {
+	s.listReplicaSetsInNamespace(c, "")
+}
+
+// listNamespacedReplicaSets handles GET /api/v1/namespaces/{namespace}/replicasets
+func (s *Server) listNamespacedReplicaSets(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	s.listReplicaSetsInNamespace(c, namespace)
+}
+
+// listReplicaSetsInNamespace lists replica sets in the specified namespace
+func (s *Server) listReplicaSetsInNamespace(c *gin.Context, namespace string) {
+	// Only return the objects matching the request's selectors
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+
+	// Only return the requested page
+	options, ok := listOptions(c)
+	if !ok {
+		return
+	}
+
+	// Stream changes instead of listing when watching
+	if isWatchRequest(c) {
+		s.watchResources(c, "ReplicaSet", namespace, filter, func(resource storage.Resource) (interface{}, error) {
+			return s.resourceToReplicaSet(resource)
+		})
+		return
+	}
+
+	// Read the version before listing so a watch started from it misses nothing
+	metadata := s.listMetadata()
+
+	// Get from database
+	page, err := s.repository.ListResourcesPage("ReplicaSet", namespace, options)
+	if err != nil {
+		listError(c, err, "Failed to list replica sets")
+		return
+	}
+	setContinue(metadata, page.Continue)
+
+	// Convert to replica sets
+	var replicaSets []types.ReplicaSet
+	for _, resource := range page.Resources {
+		replicaSet, err := s.resourceToReplicaSet(resource)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "DESERIALIZATION_ERROR",
+				Message: "Failed to deserialize replica set",
+				Code:    http.StatusInternalServerError,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
+		if !matchesFilter(filter, replicaSet) {
+			continue
+		}
+		replicaSets = append(replicaSets, *replicaSet)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"apiVersion": "apps/v1",
+		"kind":       "ReplicaSetList",
+		"metadata":   metadata,
+		"items":      replicaSets,
+	})
+}
+
+// resourceToReplicaSet converts a storage resource to a ReplicaSet
+func (s *Server) resourceToReplicaSet(resource storage.Resource) (*types.ReplicaSet, error) {
+	var metadata types.ObjectMeta
+	if resource.Metadata != "" {
+		if err := json.Unmarshal([]byte(resource.Metadata), &metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal replica set metadata: %w", err)
+		}
+	}
+
+	// Identity always comes from the stored row
+	metadata.Name = resource.Name
+	metadata.Namespace = resource.Namespace
+	metadata.UID = resource.ID
+	metadata.CreatedAt = resource.CreatedAt
+	metadata.UpdatedAt = resource.UpdatedAt
+
+	var spec types.ReplicaSetSpec
+	if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal replica set spec: %w", err)
+	}
+
+	var status types.ReplicaSetStatus
+	if resource.Status != "" {
+		if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal replica set status: %w", err)
+		}
+	}
+
+	return &types.ReplicaSet{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Metadata:   metadata,
+		Spec:       spec,
+		Status:     status,
+	}, nil
+}
+
+// replicaSetToResource converts a ReplicaSet to a storage resource
+func replicaSetToResource(replicaSet *types.ReplicaSet) (storage.Resource, error) {
+	metadataJSON, err := json.Marshal(replicaSet.Metadata)
+	if err != nil {
+		return storage.Resource{}, fmt.Errorf("failed to marshal replica set metadata: %w", err)
+	}
+
+	specJSON, err := json.Marshal(replicaSet.Spec)
+	if err != nil {
+		return storage.Resource{}, fmt.Errorf("failed to marshal replica set spec: %w", err)
+	}
+
+	statusJSON, err := json.Marshal(replicaSet.Status)
+	if err != nil {
+		return storage.Resource{}, fmt.Errorf("failed to marshal replica set status: %w", err)
+	}
+
+	return storage.Resource{
+		ID:        replicaSet.Metadata.UID,
+		Kind:      "ReplicaSet",
+		Namespace: replicaSet.Metadata.Namespace,
+		Name:      replicaSet.Metadata.Name,
+		Metadata:  string(metadataJSON),
+		Spec:      string(specJSON),
+		Status:    string(statusJSON),
+	}, nil
+}
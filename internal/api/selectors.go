@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// listFilter builds the filter of a list or watch request from its
+// labelSelector and fieldSelector query parameters. The filter is nil when
+// neither is set. When either is invalid an error response is written and
+// false is returned.
+func listFilter(c *gin.Context) (func(interface{}) bool, bool) {
+	labelSelector, err := types.ParseLabelSelector(c.Query("labelSelector"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_PARAMETER",
+			Message: "Invalid label selector",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"labelSelector": err.Error()},
+		})
+		return nil, false
+	}
+
+	fieldSelector, err := types.ParseFieldSelector(c.Query("fieldSelector"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_PARAMETER",
+			Message: "Invalid field selector",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"fieldSelector": err.Error()},
+		})
+		return nil, false
+	}
+
+	if labelSelector.Empty() && fieldSelector.Empty() {
+		return nil, true
+	}
+
+	return func(object interface{}) bool {
+		return labelSelector.Matches(objectLabels(object)) && fieldSelector.Matches(object)
+	}, true
+}
+
+// matchesFilter reports whether an object passes a filter. A nil filter
+// passes everything.
+func matchesFilter(filter func(interface{}) bool, object interface{}) bool {
+	return filter == nil || filter(object)
+}
+
+// objectLabels returns the labels of an API object
+func objectLabels(object interface{}) map[string]string {
+	switch object := object.(type) {
+	case *types.Pod:
+		return object.Metadata.Labels
+	case *types.Service:
+		return object.Metadata.Labels
+	case *types.Deployment:
+		return object.Metadata.Labels
+	case *types.ReplicaSet:
+		return object.Metadata.Labels
+	case *types.Node:
+		return object.Metadata.Labels
+	case *types.PriorityClass:
+		return object.Metadata.Labels
+	default:
+		return nil
+	}
+}
@@ -67,6 +67,8 @@ func (s *Server) setupRoutes() {
 		pods.POST("", s.createPod)
 		pods.GET("/:name", s.getPod)
 		pods.PUT("/:name", s.updatePod)
+		pods.PATCH("/:name", s.patchPod)
+		pods.PUT("/:name/status", s.updatePodStatus)
 		pods.DELETE("/:name", s.deletePod)
 		pods.GET("", s.listPods)
 	}
@@ -77,6 +79,8 @@ func (s *Server) setupRoutes() {
 		namespacedPods.POST("", s.createNamespacedPod)
 		namespacedPods.GET("/:name", s.getNamespacedPod)
 		namespacedPods.PUT("/:name", s.updateNamespacedPod)
+		namespacedPods.PATCH("/:name", s.patchNamespacedPod)
+		namespacedPods.PUT("/:name/status", s.updateNamespacedPodStatus)
 		namespacedPods.DELETE("/:name", s.deleteNamespacedPod)
 		namespacedPods.GET("", s.listNamespacedPods)
 	}
@@ -87,6 +91,7 @@ func (s *Server) setupRoutes() {
 		services.POST("", s.createService)
 		services.GET("/:name", s.getService)
 		services.PUT("/:name", s.updateService)
+		services.PATCH("/:name", s.patchService)
 		services.DELETE("/:name", s.deleteService)
 		services.GET("", s.listServices)
 	}
@@ -97,6 +102,7 @@ func (s *Server) setupRoutes() {
 		namespacedServices.POST("", s.createNamespacedService)
 		namespacedServices.GET("/:name", s.getNamespacedService)
 		namespacedServices.PUT("/:name", s.updateNamespacedService)
+		namespacedServices.PATCH("/:name", s.patchNamespacedService)
 		namespacedServices.DELETE("/:name", s.deleteNamespacedService)
 		namespacedServices.GET("", s.listNamespacedServices)
 	}
@@ -107,8 +113,11 @@ func (s *Server) setupRoutes() {
 		deployments.POST("", s.createDeployment)
 		deployments.GET("/:name", s.getDeployment)
 		deployments.PUT("/:name", s.updateDeployment)
+		deployments.PATCH("/:name", s.patchDeployment)
 		deployments.DELETE("/:name", s.deleteDeployment)
 		deployments.GET("", s.listDeployments)
+		deployments.GET("/:name/history", s.getDeploymentHistory)
+		deployments.POST("/:name/rollback", s.rollbackDeployment)
 	}
 	
 	// Namespaced deployment endpoints
@@ -117,8 +126,33 @@ func (s *Server) setupRoutes() {
 		namespacedDeployments.POST("", s.createNamespacedDeployment)
 		namespacedDeployments.GET("/:name", s.getNamespacedDeployment)
 		namespacedDeployments.PUT("/:name", s.updateNamespacedDeployment)
+		namespacedDeployments.PATCH("/:name", s.patchNamespacedDeployment)
 		namespacedDeployments.DELETE("/:name", s.deleteNamespacedDeployment)
 		namespacedDeployments.GET("", s.listNamespacedDeployments)
+		namespacedDeployments.GET("/:name/history", s.getNamespacedDeploymentHistory)
+		namespacedDeployments.POST("/:name/rollback", s.rollbackNamespacedDeployment)
+	}
+	
+	// ReplicaSet endpoints
+	replicaSets := v1.Group("/replicasets")
+	{
+		replicaSets.POST("", s.createReplicaSet)
+		replicaSets.GET("/:name", s.getReplicaSet)
+		replicaSets.PUT("/:name", s.updateReplicaSet)
+		replicaSets.PATCH("/:name", s.patchReplicaSet)
+		replicaSets.DELETE("/:name", s.deleteReplicaSet)
+		replicaSets.GET("", s.listReplicaSets)
+	}
+	
+	// Namespaced ReplicaSet endpoints
+	namespacedReplicaSets := v1.Group("/namespaces/:namespace/replicasets")
+	{
+		namespacedReplicaSets.POST("", s.createNamespacedReplicaSet)
+		namespacedReplicaSets.GET("/:name", s.getNamespacedReplicaSet)
+		namespacedReplicaSets.PUT("/:name", s.updateNamespacedReplicaSet)
+		namespacedReplicaSets.PATCH("/:name", s.patchNamespacedReplicaSet)
+		namespacedReplicaSets.DELETE("/:name", s.deleteNamespacedReplicaSet)
+		namespacedReplicaSets.GET("", s.listNamespacedReplicaSets)
 	}
 	
 	// Node endpoints (cluster-scoped, no namespace)
@@ -127,9 +161,22 @@ func (s *Server) setupRoutes() {
 		nodes.POST("", s.createNode)
 		nodes.GET("/:name", s.getNode)
 		nodes.PUT("/:name", s.updateNode)
+		nodes.PATCH("/:name", s.patchNode)
 		nodes.DELETE("/:name", s.deleteNode)
 		nodes.GET("", s.listNodes)
 		nodes.POST("/:name/heartbeat", s.updateNodeHeartbeat)
+		nodes.GET("/:name/pods", s.listNodePods)
+	}
+	
+	// PriorityClass endpoints (cluster-scoped, no namespace)
+	priorityClasses := v1.Group("/priorityclasses")
+	{
+		priorityClasses.POST("", s.createPriorityClass)
+		priorityClasses.GET("/:name", s.getPriorityClass)
+		priorityClasses.PUT("/:name", s.updatePriorityClass)
+		priorityClasses.PATCH("/:name", s.patchPriorityClass)
+		priorityClasses.DELETE("/:name", s.deletePriorityClass)
+		priorityClasses.GET("", s.listPriorityClasses)
 	}
 }
 
@@ -137,7 +184,7 @@ func (s *Server) setupRoutes() {
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		
 		if c.Request.Method == "OPTIONS" {
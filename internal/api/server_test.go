@@ -1,10 +1,15 @@
 package api
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
 	"testing"
 
 	"mini-k8s-orchestration/internal/storage"
@@ -352,6 +357,75 @@ func TestDeletePod(t *testing.T) {
 	}
 }
 
+func TestDeletePodGracefully(t *testing.T) {
+	server, repo := setupTestServer(t)
+	createTestNodePod(t, repo, "web", "node-1")
+	
+	deletePod := func(url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("DELETE", url, nil)
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		return rr
+	}
+	getPod := func() *types.Pod {
+		resource, err := repo.GetResource("Pod", "default", "web")
+		if err != nil {
+			t.Fatalf("Expected pod to still exist: %v", err)
+		}
+		pod, err := server.resourceToPod(resource)
+		if err != nil {
+			t.Fatalf("Failed to decode pod: %v", err)
+		}
+		return pod
+	}
+	
+	// A pod on a node is kept while its containers shut down
+	if rr := deletePod("/api/v1/pods/web"); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	pod := getPod()
+	if pod.Metadata.DeletionTimestamp == nil || pod.Status.Phase != types.PodPhaseTerminating {
+		t.Fatalf("Expected a terminating pod, got %+v %+v", pod.Metadata, pod.Status)
+	}
+	if grace := pod.Metadata.DeletionGracePeriodSeconds; grace == nil || *grace != types.DefaultTerminationGracePeriodSeconds {
+		t.Errorf("Expected the default grace period, got %v", grace)
+	}
+	deletedAt := *pod.Metadata.DeletionTimestamp
+	
+	// Deleting again may shorten the grace period but keeps the timestamp
+	if rr := deletePod("/api/v1/pods/web?gracePeriodSeconds=5"); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	pod = getPod()
+	if grace := pod.Metadata.DeletionGracePeriodSeconds; grace == nil || *grace != 5 {
+		t.Errorf("Expected a grace period of 5s, got %v", grace)
+	}
+	if !pod.Metadata.DeletionTimestamp.Equal(deletedAt) {
+		t.Errorf("Expected deletionTimestamp %v to be kept, got %v", deletedAt, pod.Metadata.DeletionTimestamp)
+	}
+	
+	// Updates can't clear the deletion
+	pod.Metadata.DeletionTimestamp = nil
+	if rr, _ := sendTestPod(t, server, "PUT", "/api/v1/pods/web", *pod); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if pod = getPod(); pod.Metadata.DeletionTimestamp == nil {
+		t.Error("Expected the update to keep the deletionTimestamp")
+	}
+	
+	if rr := deletePod("/api/v1/pods/web?gracePeriodSeconds=-1"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+	
+	// A grace period of 0 removes the pod at once
+	if rr := deletePod("/api/v1/pods/web?gracePeriodSeconds=0"); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if _, err := repo.GetResource("Pod", "default", "web"); err == nil {
+		t.Error("Expected pod to be deleted")
+	}
+}
+
 func TestCreateService(t *testing.T) {
 	server, _ := setupTestServer(t)
 	
@@ -703,4 +777,1085 @@ func TestNodeHeartbeat(t *testing.T) {
 	if response["message"] != "Node heartbeat updated successfully" {
 		t.Errorf("Expected success message, got %v", response["message"])
 	}
-}
\ No newline at end of file
+}
+func TestCreateReplicaSet(t *testing.T) {
+	server, _ := setupTestServer(t)
+	
+	replicaSet := types.ReplicaSet{
+		Metadata: types.ObjectMeta{
+			Name:      "test-replicaset",
+			Namespace: "default",
+		},
+		Spec: types.ReplicaSetSpec{
+			Replicas: 2,
+			Template: types.PodTemplateSpec{
+				Metadata: types.ObjectMeta{
+					Labels: map[string]string{"app": "test"},
+				},
+				Spec: types.PodSpec{
+					Containers: []types.Container{
+						{
+							Name:  "nginx",
+							Image: "nginx:latest",
+						},
+					},
+				},
+			},
+		},
+	}
+	
+	replicaSetJSON, err := json.Marshal(replicaSet)
+	if err != nil {
+		t.Fatalf("Failed to marshal replica set: %v", err)
+	}
+	
+	req, err := http.NewRequest("POST", "/api/v1/replicasets", bytes.NewBuffer(replicaSetJSON))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, status, rr.Body.String())
+	}
+	
+	req, _ = http.NewRequest("GET", "/api/v1/replicasets/test-replicaset", nil)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, status)
+	}
+	
+	var fetched types.ReplicaSet
+	if err := json.Unmarshal(rr.Body.Bytes(), &fetched); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	
+	// The selector defaults to the template labels
+	if fetched.Spec.Selector.MatchLabels["app"] != "test" {
+		t.Errorf("Expected selector to default to template labels, got %v", fetched.Spec.Selector.MatchLabels)
+	}
+}
+
+func TestDeploymentHistoryAndRollback(t *testing.T) {
+	server, repo := setupTestServer(t)
+	
+	template := func(image string) types.PodTemplateSpec {
+		return types.PodTemplateSpec{
+			Metadata: types.ObjectMeta{
+				Labels: map[string]string{"app": "web"},
+			},
+			Spec: types.PodSpec{
+				Containers: []types.Container{
+					{
+						Name:  "nginx",
+						Image: image,
+					},
+				},
+			},
+		}
+	}
+	
+	deployment := types.Deployment{
+		Metadata: types.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: types.DeploymentSpec{
+			Replicas: 1,
+			Template: template("nginx:1.26"),
+		},
+	}
+	deploymentJSON, _ := json.Marshal(deployment)
+	req, _ := http.NewRequest("POST", "/api/v1/deployments", bytes.NewBuffer(deploymentJSON))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Failed to create deployment: %s", rr.Body.String())
+	}
+	json.Unmarshal(rr.Body.Bytes(), &deployment)
+	
+	// Record two revisions the way the deployment controller does
+	for revision, image := range map[int]string{1: "nginx:1.25", 2: "nginx:1.26"} {
+		rsTemplate := template(image)
+		rsTemplate.Metadata.Labels[types.PodTemplateHashLabel] = fmt.Sprintf("hash%d", revision)
+		replicaSet := types.ReplicaSet{
+			Metadata: types.ObjectMeta{
+				Name:        fmt.Sprintf("web-hash%d", revision),
+				Namespace:   "default",
+				UID:         fmt.Sprintf("rs-%d", revision),
+				Annotations: map[string]string{types.RevisionAnnotation: fmt.Sprintf("%d", revision)},
+				OwnerReferences: []types.OwnerReference{
+					{Kind: "Deployment", Name: "web", UID: deployment.Metadata.UID, Controller: true},
+				},
+			},
+			Spec: types.ReplicaSetSpec{
+				Selector: types.LabelSelector{MatchLabels: rsTemplate.Metadata.Labels},
+				Template: rsTemplate,
+			},
+		}
+		resource, err := replicaSetToResource(&replicaSet)
+		if err != nil {
+			t.Fatalf("Failed to convert replica set: %v", err)
+		}
+		if err := repo.CreateResource(resource); err != nil {
+			t.Fatalf("Failed to create replica set: %v", err)
+		}
+	}
+	
+	req, _ = http.NewRequest("GET", "/api/v1/deployments/web/history", nil)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+	
+	var history struct {
+		Items []types.DeploymentRevision `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &history); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(history.Items) != 2 || history.Items[0].Revision != 1 || history.Items[1].Revision != 2 {
+		t.Fatalf("Expected revisions 1 and 2, got %+v", history.Items)
+	}
+	if _, ok := history.Items[0].Template.Metadata.Labels[types.PodTemplateHashLabel]; ok {
+		t.Errorf("Expected history template without the pod-template-hash label")
+	}
+	
+	// Unknown revisions are rejected
+	req, _ = http.NewRequest("POST", "/api/v1/deployments/web/rollback?revision=9", nil)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d for unknown revision, got %d", http.StatusNotFound, rr.Code)
+	}
+	
+	// Without a revision the deployment returns to the previous one
+	req, _ = http.NewRequest("POST", "/api/v1/deployments/web/rollback", nil)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	
+	req, _ = http.NewRequest("GET", "/api/v1/deployments/web", nil)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	var rolledBack types.Deployment
+	json.Unmarshal(rr.Body.Bytes(), &rolledBack)
+	if image := rolledBack.Spec.Template.Spec.Containers[0].Image; image != "nginx:1.25" {
+		t.Errorf("Expected template image nginx:1.25 after rollback, got %s", image)
+	}
+}
+
+func createTestWatchPod(t *testing.T, repo storage.Repository, name string) {
+	t.Helper()
+	
+	metadataJSON, _ := json.Marshal(types.ObjectMeta{Name: name, Namespace: "default"})
+	specJSON, _ := json.Marshal(types.PodSpec{
+		Containers: []types.Container{
+			{Name: "nginx", Image: "nginx:latest"},
+		},
+	})
+	
+	resource := storage.Resource{
+		ID:        name + "-123",
+		Kind:      "Pod",
+		Namespace: "default",
+		Name:      name,
+		Metadata:  string(metadataJSON),
+		Spec:      string(specJSON),
+		Status:    `{"phase":"Pending"}`,
+	}
+	
+	if err := repo.CreateResource(resource); err != nil {
+		t.Fatalf("Failed to create test pod %s: %v", name, err)
+	}
+}
+
+func readWatchEvent(t *testing.T, scanner *bufio.Scanner) (types.WatchEvent, types.Pod) {
+	t.Helper()
+	
+	if !scanner.Scan() {
+		t.Fatalf("Watch stream ended early: %v", scanner.Err())
+	}
+	
+	var event types.WatchEvent
+	if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+		t.Fatalf("Failed to unmarshal watch event: %v", err)
+	}
+	
+	var pod types.Pod
+	if err := json.Unmarshal(event.Object, &pod); err != nil {
+		t.Fatalf("Failed to unmarshal watched pod: %v", err)
+	}
+	
+	return event, pod
+}
+
+func TestWatchPods(t *testing.T) {
+	server, repo := setupTestServer(t)
+	
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+	
+	createTestWatchPod(t, repo, "pod1")
+	
+	// The list reports the version to resume watching from
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/pods", nil)
+	server.router.ServeHTTP(rr, req)
+	
+	var list struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if list.Metadata.ResourceVersion == "" {
+		t.Fatal("Expected resourceVersion in list metadata")
+	}
+	
+	// A watch without a resourceVersion starts with the current pods
+	resp, err := http.Get(ts.URL + "/api/v1/pods?watch=true&timeoutSeconds=5")
+	if err != nil {
+		t.Fatalf("Failed to start watch: %v", err)
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	
+	scanner := bufio.NewScanner(resp.Body)
+	event, pod := readWatchEvent(t, scanner)
+	if event.Type != types.WatchEventAdded || pod.Metadata.Name != "pod1" {
+		t.Errorf("Expected ADDED event for pod1, got %s for %s", event.Type, pod.Metadata.Name)
+	}
+	
+	createTestWatchPod(t, repo, "pod2")
+	if err := repo.DeleteResource("Pod", "default", "pod1"); err != nil {
+		t.Fatalf("Failed to delete pod: %v", err)
+	}
+	
+	event, pod = readWatchEvent(t, scanner)
+	if event.Type != types.WatchEventAdded || pod.Metadata.Name != "pod2" {
+		t.Errorf("Expected ADDED event for pod2, got %s for %s", event.Type, pod.Metadata.Name)
+	}
+	event, pod = readWatchEvent(t, scanner)
+	if event.Type != types.WatchEventDeleted || pod.Metadata.Name != "pod1" {
+		t.Errorf("Expected DELETED event for pod1, got %s for %s", event.Type, pod.Metadata.Name)
+	}
+	
+	// Resuming from the listed version replays the changes made since
+	resumed, err := http.Get(ts.URL + "/api/v1/namespaces/default/pods?watch=true&timeoutSeconds=5&resourceVersion=" + list.Metadata.ResourceVersion)
+	if err != nil {
+		t.Fatalf("Failed to resume watch: %v", err)
+	}
+	defer resumed.Body.Close()
+	
+	scanner = bufio.NewScanner(resumed.Body)
+	event, pod = readWatchEvent(t, scanner)
+	if event.Type != types.WatchEventAdded || pod.Metadata.Name != "pod2" {
+		t.Errorf("Expected ADDED event for pod2, got %s for %s", event.Type, pod.Metadata.Name)
+	}
+	event, _ = readWatchEvent(t, scanner)
+	if event.Type != types.WatchEventDeleted {
+		t.Errorf("Expected DELETED event, got %s", event.Type)
+	}
+}
+
+func TestWatchInvalidResourceVersion(t *testing.T) {
+	server, _ := setupTestServer(t)
+	
+	req, err := http.NewRequest("GET", "/api/v1/pods?watch=true&resourceVersion=abc", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, status)
+	}
+}
+
+func sendTestPod(t *testing.T, server *Server, method, url string, pod types.Pod) (*httptest.ResponseRecorder, types.Pod) {
+	t.Helper()
+	
+	podJSON, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod: %v", err)
+	}
+	
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(podJSON))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	
+	var response types.Pod
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	return rr, response
+}
+
+func TestUpdatePodConflict(t *testing.T) {
+	server, _ := setupTestServer(t)
+	
+	pod := types.Pod{
+		Metadata: types.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+		Spec: types.PodSpec{
+			Containers: []types.Container{
+				{Name: "nginx", Image: "nginx:latest"},
+			},
+		},
+	}
+	
+	rr, created := sendTestPod(t, server, "POST", "/api/v1/pods", pod)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	if created.Metadata.ResourceVersion == "" {
+		t.Fatal("Expected resourceVersion to be set on the created pod")
+	}
+	
+	// An update based on the created version succeeds and returns a new version
+	update := created
+	update.Metadata.Labels = map[string]string{"app": "web"}
+	rr, updated := sendTestPod(t, server, "PUT", "/api/v1/pods/test-pod", update)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if updated.Metadata.ResourceVersion == "" || updated.Metadata.ResourceVersion == created.Metadata.ResourceVersion {
+		t.Errorf("Expected a new resourceVersion, got %q", updated.Metadata.ResourceVersion)
+	}
+	
+	// Another update based on the original version is rejected
+	stale := created
+	stale.Metadata.Labels = map[string]string{"app": "api"}
+	rr, _ = sendTestPod(t, server, "PUT", "/api/v1/pods/test-pod", stale)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusConflict, rr.Code, rr.Body.String())
+	}
+	
+	var errorResponse ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &errorResponse); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if errorResponse.Error != "CONFLICT" {
+		t.Errorf("Expected error CONFLICT, got %s", errorResponse.Error)
+	}
+	
+	// Updates without a resourceVersion are applied unconditionally
+	stale.Metadata.ResourceVersion = ""
+	rr, _ = sendTestPod(t, server, "PUT", "/api/v1/pods/test-pod", stale)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+}
+
+
+func TestUpdatePodStatus(t *testing.T) {
+	server, _ := setupTestServer(t)
+	
+	pod := types.Pod{
+		Metadata: types.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+		Spec: types.PodSpec{
+			NodeName: "node-1",
+			Containers: []types.Container{
+				{Name: "nginx", Image: "nginx:latest"},
+			},
+		},
+	}
+	
+	rr, created := sendTestPod(t, server, "POST", "/api/v1/pods", pod)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	
+	status := types.PodStatus{
+		Phase: "Running",
+		PodIP: "172.17.0.2",
+		ContainerStatuses: []types.ContainerStatus{
+			{Name: "nginx", Ready: true, RestartCount: 2, Image: "nginx:latest"},
+		},
+	}
+	statusJSON, err := json.Marshal(status)
+	if err != nil {
+		t.Fatalf("Failed to marshal status: %v", err)
+	}
+	
+	req, err := http.NewRequest("PUT", "/api/v1/namespaces/default/pods/test-pod/status", bytes.NewBuffer(statusJSON))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	
+	var updated types.Pod
+	if err := json.Unmarshal(rr.Body.Bytes(), &updated); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	
+	// Only the status changes
+	if updated.Status.Phase != "Running" || updated.Status.PodIP != "172.17.0.2" {
+		t.Errorf("Expected Running pod at 172.17.0.2, got %s at %s", updated.Status.Phase, updated.Status.PodIP)
+	}
+	if len(updated.Status.ContainerStatuses) != 1 || updated.Status.ContainerStatuses[0].RestartCount != 2 {
+		t.Errorf("Expected container status with 2 restarts, got %+v", updated.Status.ContainerStatuses)
+	}
+	if updated.Spec.NodeName != "node-1" || len(updated.Spec.Containers) != 1 {
+		t.Errorf("Expected spec to be unchanged, got %+v", updated.Spec)
+	}
+	if updated.Metadata.ResourceVersion == created.Metadata.ResourceVersion {
+		t.Errorf("Expected a new resourceVersion, got %q", updated.Metadata.ResourceVersion)
+	}
+	
+	// Status updates for unknown pods are rejected
+	req, _ = http.NewRequest("PUT", "/api/v1/pods/missing/status", bytes.NewBuffer(statusJSON))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+
+func TestUpdatePodStatusMerge(t *testing.T) {
+	server, repo := setupTestServer(t)
+	createTestNodePod(t, repo, "web", "node-1")
+	
+	putStatus := func(status types.PodStatus) types.Pod {
+		statusJSON, _ := json.Marshal(status)
+		req, _ := http.NewRequest("PUT", "/api/v1/pods/web/status", bytes.NewBuffer(statusJSON))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		server.router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		var pod types.Pod
+		if err := json.Unmarshal(rr.Body.Bytes(), &pod); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return pod
+	}
+	setPhase := func(phase string) {
+		latest, err := repo.GetResource("Pod", "default", "web")
+		if err != nil {
+			t.Fatalf("Failed to get pod: %v", err)
+		}
+		latest.Status = fmt.Sprintf(`{"phase":%q,"conditions":[{"type":"PodScheduled","status":"True"}]}`, phase)
+		if err := repo.UpdateResource(latest); err != nil {
+			t.Fatalf("Failed to update pod: %v", err)
+		}
+	}
+	
+	// The agent's report, built from a stale read, keeps the scheduler's
+	// condition and adds its own
+	setPhase("Pending")
+	pod := putStatus(types.PodStatus{
+		Phase:      "Running",
+		PodIP:      "172.17.0.2",
+		Conditions: []types.PodCondition{{Type: "PodScheduled", Status: "False"}, {Type: "Ready", Status: "True"}},
+	})
+	if pod.Status.Phase != "Running" || pod.Status.PodIP != "172.17.0.2" {
+		t.Errorf("Expected Running pod at 172.17.0.2, got %s at %s", pod.Status.Phase, pod.Status.PodIP)
+	}
+	conditions := map[string]string{}
+	for _, condition := range pod.Status.Conditions {
+		conditions[condition.Type] = condition.Status
+	}
+	if conditions["PodScheduled"] != "True" || conditions["Ready"] != "True" || len(conditions) != 2 {
+		t.Errorf("Expected PodScheduled and Ready to be True, got %+v", pod.Status.Conditions)
+	}
+	
+	// Failed and terminating pods keep their phase
+	for _, phase := range []string{"Failed", "Succeeded", types.PodPhaseTerminating} {
+		setPhase(phase)
+		if pod := putStatus(types.PodStatus{Phase: "Running"}); pod.Status.Phase != phase {
+			t.Errorf("Expected phase %s to be kept, got %s", phase, pod.Status.Phase)
+		}
+	}
+}
+
+func createTestNodePod(t *testing.T, repo storage.Repository, name, nodeName string) storage.Resource {
+	t.Helper()
+	
+	metadataJSON, _ := json.Marshal(types.ObjectMeta{Name: name, Namespace: "default", UID: name + "-123"})
+	specJSON, _ := json.Marshal(types.PodSpec{
+		NodeName: nodeName,
+		Containers: []types.Container{
+			{Name: "nginx", Image: "nginx:latest"},
+		},
+	})
+	
+	resource := storage.Resource{
+		ID:        name + "-123",
+		Kind:      "Pod",
+		Namespace: "default",
+		Name:      name,
+		Metadata:  string(metadataJSON),
+		Spec:      string(specJSON),
+		Status:    `{"phase":"Pending"}`,
+	}
+	
+	if err := repo.CreateResource(resource); err != nil {
+		t.Fatalf("Failed to create test pod %s: %v", name, err)
+	}
+	return resource
+}
+
+func TestListNodePods(t *testing.T) {
+	server, repo := setupTestServer(t)
+	
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+	
+	for _, name := range []string{"node-1", "node-2"} {
+		node := &types.Node{Metadata: types.ObjectMeta{Name: name, UID: name + "-uid"}}
+		if err := repo.CreateNode(node); err != nil {
+			t.Fatalf("Failed to create node %s: %v", name, err)
+		}
+	}
+	
+	createTestNodePod(t, repo, "bound", "node-1")
+	createTestNodePod(t, repo, "elsewhere", "node-2")
+	createTestNodePod(t, repo, "assigned", "")
+	unscheduled := createTestNodePod(t, repo, "unscheduled", "")
+	
+	// Pods the scheduler assigned but has not bound yet belong to the node too
+	if err := repo.AssignPodToNode("assigned-123", "node-1-uid"); err != nil {
+		t.Fatalf("Failed to assign pod: %v", err)
+	}
+	
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/nodes/node-1/pods", nil)
+	server.router.ServeHTTP(rr, req)
+	
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	
+	var list struct {
+		Kind  string      `json:"kind"`
+		Items []types.Pod `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if list.Kind != "PodList" {
+		t.Errorf("Expected kind PodList, got %s", list.Kind)
+	}
+	
+	names := make(map[string]bool)
+	for _, pod := range list.Items {
+		names[pod.Metadata.Name] = true
+	}
+	if len(list.Items) != 2 || !names["bound"] || !names["assigned"] {
+		t.Errorf("Expected pods bound and assigned, got %v", names)
+	}
+	
+	// Unknown nodes are rejected
+	rr = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/nodes/missing/pods", nil)
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rr.Code)
+	}
+	
+	// The watch starts with the node's pods and then follows its bindings
+	resp, err := http.Get(ts.URL + "/api/v1/nodes/node-1/pods?watch=true&timeoutSeconds=5")
+	if err != nil {
+		t.Fatalf("Failed to start watch: %v", err)
+	}
+	defer resp.Body.Close()
+	
+	scanner := bufio.NewScanner(resp.Body)
+	for i := 0; i < 2; i++ {
+		event, pod := readWatchEvent(t, scanner)
+		if event.Type != types.WatchEventAdded || !names[pod.Metadata.Name] {
+			t.Errorf("Expected ADDED event for a listed pod, got %s for %s", event.Type, pod.Metadata.Name)
+		}
+	}
+	
+	// Binding a pod to the node adds it; changes on other nodes are not sent
+	specJSON, _ := json.Marshal(types.PodSpec{
+		NodeName: "node-1",
+		Containers: []types.Container{
+			{Name: "nginx", Image: "nginx:latest"},
+		},
+	})
+	unscheduled.Spec = string(specJSON)
+	if err := repo.UpdateResource(unscheduled); err != nil {
+		t.Fatalf("Failed to bind pod: %v", err)
+	}
+	if err := repo.DeleteResource("Pod", "default", "elsewhere"); err != nil {
+		t.Fatalf("Failed to delete pod: %v", err)
+	}
+	if err := repo.DeleteResource("Pod", "default", "bound"); err != nil {
+		t.Fatalf("Failed to delete pod: %v", err)
+	}
+	
+	event, pod := readWatchEvent(t, scanner)
+	if event.Type != types.WatchEventAdded || pod.Metadata.Name != "unscheduled" {
+		t.Errorf("Expected ADDED event for unscheduled, got %s for %s", event.Type, pod.Metadata.Name)
+	}
+	event, pod = readWatchEvent(t, scanner)
+	if event.Type != types.WatchEventDeleted || pod.Metadata.Name != "bound" {
+		t.Errorf("Expected DELETED event for bound, got %s for %s", event.Type, pod.Metadata.Name)
+	}
+}
+
+
+func TestListPodsWithSelectors(t *testing.T) {
+	server, _ := setupTestServer(t)
+	
+	for _, pod := range []struct {
+		name     string
+		labels   map[string]string
+		nodeName string
+	}{
+		{"web-1", map[string]string{"app": "web", "env": "prod"}, "node-1"},
+		{"web-2", map[string]string{"app": "web", "env": "staging"}, "node-2"},
+		{"api-1", map[string]string{"app": "api", "env": "prod"}, "node-1"},
+	} {
+		rr, _ := sendTestPod(t, server, "POST", "/api/v1/pods", types.Pod{
+			Metadata: types.ObjectMeta{Name: pod.name, Namespace: "default", Labels: pod.labels},
+			Spec: types.PodSpec{
+				NodeName:   pod.nodeName,
+				Containers: []types.Container{{Name: "nginx", Image: "nginx:latest"}},
+			},
+		})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Failed to create pod %s: %s", pod.name, rr.Body.String())
+		}
+	}
+	
+	tests := []struct {
+		query string
+		names []string
+	}{
+		{"labelSelector=" + url.QueryEscape("app=web"), []string{"web-1", "web-2"}},
+		{"labelSelector=" + url.QueryEscape("app!=web,env in (prod)"), []string{"api-1"}},
+		{"fieldSelector=" + url.QueryEscape("spec.nodeName=node-1"), []string{"api-1", "web-1"}},
+		{"labelSelector=" + url.QueryEscape("app=web") + "&fieldSelector=" + url.QueryEscape("spec.nodeName=node-1"), []string{"web-1"}},
+		{"labelSelector=" + url.QueryEscape("app=database"), nil},
+	}
+	
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/namespaces/default/pods?"+tt.query, nil)
+		server.router.ServeHTTP(rr, req)
+		
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s: expected status code %d, got %d: %s", tt.query, http.StatusOK, rr.Code, rr.Body.String())
+			continue
+		}
+		
+		var list struct {
+			Items []types.Pod `json:"items"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		
+		var names []string
+		for _, pod := range list.Items {
+			names = append(names, pod.Metadata.Name)
+		}
+		sort.Strings(names)
+		if strings.Join(names, ",") != strings.Join(tt.names, ",") {
+			t.Errorf("%s: expected pods %v, got %v", tt.query, tt.names, names)
+		}
+	}
+	
+	// Malformed selectors are rejected
+	for _, query := range []string{"labelSelector=" + url.QueryEscape("env in ()"), "fieldSelector=spec.nodeName"} {
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/pods?"+query, nil)
+		server.router.ServeHTTP(rr, req)
+		
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", query, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+
+func TestListPodsPagination(t *testing.T) {
+	server, repo := setupTestServer(t)
+	
+	for _, name := range []string{"pod-a", "pod-b", "pod-c"} {
+		createTestWatchPod(t, repo, name)
+	}
+	
+	var names []string
+	continueToken := ""
+	for pages := 0; pages < 5; pages++ {
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/pods?limit=2&continue="+url.QueryEscape(continueToken), nil)
+		server.router.ServeHTTP(rr, req)
+		
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+		
+		var list struct {
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+			Items []types.Pod `json:"items"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if len(list.Items) > 2 {
+			t.Errorf("Expected at most 2 pods per page, got %d", len(list.Items))
+		}
+		for _, pod := range list.Items {
+			names = append(names, pod.Metadata.Name)
+		}
+		
+		continueToken = list.Metadata.Continue
+		if continueToken == "" {
+			break
+		}
+	}
+	
+	if strings.Join(names, ",") != "pod-a,pod-b,pod-c" {
+		t.Errorf("Expected every pod exactly once, got %v", names)
+	}
+	
+	// Invalid limits and tokens are rejected
+	for _, query := range []string{"limit=-1", "limit=abc", "continue=garbage"} {
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/pods?"+query, nil)
+		server.router.ServeHTTP(rr, req)
+		
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", query, http.StatusBadRequest, rr.Code)
+		}
+	}
+}
+
+// sendTestPatch sends a patch of the given content type to the test server
+func sendTestPatch(t *testing.T, server *Server, url, contentType, patch string) *httptest.ResponseRecorder {
+	t.Helper()
+	
+	req, err := http.NewRequest("PATCH", url, strings.NewReader(patch))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestPatchDeployment(t *testing.T) {
+	server, _ := setupTestServer(t)
+	
+	deployment := types.Deployment{
+		Metadata: types.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "web", "tier": "frontend"},
+		},
+		Spec: types.DeploymentSpec{
+			Replicas: 2,
+			Selector: types.LabelSelector{
+				MatchLabels: map[string]string{"app": "web"},
+			},
+			Template: types.PodTemplateSpec{
+				Metadata: types.ObjectMeta{
+					Labels: map[string]string{"app": "web"},
+				},
+				Spec: types.PodSpec{
+					Containers: []types.Container{
+						{Name: "nginx", Image: "nginx:latest"},
+					},
+				},
+			},
+		},
+	}
+	
+	deploymentJSON, err := json.Marshal(deployment)
+	if err != nil {
+		t.Fatalf("Failed to marshal deployment: %v", err)
+	}
+	req, _ := http.NewRequest("POST", "/api/v1/deployments", bytes.NewBuffer(deploymentJSON))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	
+	var created types.Deployment
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	
+	// A merge patch changes only the fields it names, and null removes a label
+	rr = sendTestPatch(t, server, "/api/v1/deployments/web", "application/merge-patch+json",
+		`{"metadata": {"labels": {"tier": null, "env": "prod"}}, "spec": {"replicas": 5}}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	
+	var patched types.Deployment
+	if err := json.Unmarshal(rr.Body.Bytes(), &patched); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if patched.Spec.Replicas != 5 {
+		t.Errorf("Expected 5 replicas, got %d", patched.Spec.Replicas)
+	}
+	if labels := patched.Metadata.Labels; len(labels) != 2 || labels["app"] != "web" || labels["env"] != "prod" {
+		t.Errorf("Unexpected labels after merge patch: %v", labels)
+	}
+	if patched.Spec.Template.Spec.Containers[0].Image != "nginx:latest" {
+		t.Errorf("Expected the template to be unchanged, got %+v", patched.Spec.Template)
+	}
+	if patched.Metadata.UID != created.Metadata.UID {
+		t.Errorf("Expected UID %s to be kept, got %s", created.Metadata.UID, patched.Metadata.UID)
+	}
+	if patched.Metadata.ResourceVersion == created.Metadata.ResourceVersion {
+		t.Errorf("Expected a new resourceVersion, got %q", patched.Metadata.ResourceVersion)
+	}
+	
+	// A JSON patch applies its operations in order
+	rr = sendTestPatch(t, server, "/api/v1/namespaces/default/deployments/web", "application/json-patch+json",
+		`[{"op": "test", "path": "/spec/replicas", "value": 5},
+		  {"op": "replace", "path": "/spec/template/spec/containers/0/image", "value": "nginx:1.25"},
+		  {"op": "remove", "path": "/metadata/labels/env"}]`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	patched = types.Deployment{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &patched); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if image := patched.Spec.Template.Spec.Containers[0].Image; image != "nginx:1.25" {
+		t.Errorf("Expected image nginx:1.25, got %s", image)
+	}
+	if _, ok := patched.Metadata.Labels["env"]; ok {
+		t.Errorf("Expected env label to be removed, got %v", patched.Metadata.Labels)
+	}
+	
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		status      int
+		errorCode   string
+	}{
+		{"failed test operation", "application/json-patch+json", `[{"op": "test", "path": "/spec/replicas", "value": 1}]`, http.StatusBadRequest, "INVALID_PATCH"},
+		{"missing path", "application/json-patch+json", `[{"op": "remove", "path": "/metadata/annotations/missing"}]`, http.StatusBadRequest, "INVALID_PATCH"},
+		{"invalid result", "application/merge-patch+json", `{"spec": {"replicas": -1}}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"renamed", "application/merge-patch+json", `{"metadata": {"name": "other"}}`, http.StatusBadRequest, "NAME_MISMATCH"},
+		{"stale resourceVersion", "application/merge-patch+json", fmt.Sprintf(`{"metadata": {"resourceVersion": %q}, "spec": {"replicas": 1}}`, created.Metadata.ResourceVersion), http.StatusConflict, "CONFLICT"},
+		{"unsupported content type", "application/json", `{"spec": {"replicas": 1}}`, http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := sendTestPatch(t, server, "/api/v1/deployments/web", tt.contentType, tt.patch)
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+			
+			var errorResponse ErrorResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &errorResponse); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if errorResponse.Error != tt.errorCode {
+				t.Errorf("Expected error %s, got %s", tt.errorCode, errorResponse.Error)
+			}
+		})
+	}
+	
+	// Rejected patches leave the stored deployment alone
+	req, _ = http.NewRequest("GET", "/api/v1/deployments/web", nil)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	var stored types.Deployment
+	if err := json.Unmarshal(rr.Body.Bytes(), &stored); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if stored.Spec.Replicas != 5 || stored.Metadata.ResourceVersion != patched.Metadata.ResourceVersion {
+		t.Errorf("Expected the deployment to be unchanged, got %d replicas at version %s", stored.Spec.Replicas, stored.Metadata.ResourceVersion)
+	}
+	
+	rr = sendTestPatch(t, server, "/api/v1/deployments/missing", "application/merge-patch+json", `{}`)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d: %s", http.StatusNotFound, rr.Code, rr.Body.String())
+	}
+}
+
+func TestPatchNode(t *testing.T) {
+	server, repo := setupTestServer(t)
+	
+	node := &types.Node{
+		Metadata: types.ObjectMeta{Name: "node-1", UID: "node-1-uid"},
+		Status: types.NodeStatus{
+			Capacity: types.ResourceList{"cpu": "4"},
+		},
+	}
+	if err := repo.CreateNode(node); err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	
+	rr := sendTestPatch(t, server, "/api/v1/nodes/node-1", "application/merge-patch+json",
+		`{"metadata": {"labels": {"zone": "us-east-1a"}}, "spec": {"unschedulable": true}}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	
+	// Labels and spec are stored with the node, and survive a heartbeat
+	req, _ := http.NewRequest("POST", "/api/v1/nodes/node-1/heartbeat", strings.NewReader(`{"capacity": {"cpu": "8"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	
+	stored, err := repo.GetNode("node-1")
+	if err != nil {
+		t.Fatalf("Failed to get node: %v", err)
+	}
+	if stored.Metadata.Labels["zone"] != "us-east-1a" || !stored.Spec.Unschedulable {
+		t.Errorf("Expected the patched labels and spec to be stored, got %+v %+v", stored.Metadata, stored.Spec)
+	}
+	if stored.Status.Capacity["cpu"] != "8" {
+		t.Errorf("Expected the heartbeat status to be stored, got %v", stored.Status.Capacity)
+	}
+	
+	rr = sendTestPatch(t, server, "/api/v1/nodes/node-1", "application/json-patch+json",
+		`[{"op": "move", "from": "/metadata/labels/zone", "path": "/metadata/labels/region"}]`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var patched types.Node
+	if err := json.Unmarshal(rr.Body.Bytes(), &patched); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if labels := patched.Metadata.Labels; len(labels) != 1 || labels["region"] != "us-east-1a" {
+		t.Errorf("Unexpected labels after move: %v", labels)
+	}
+}
+
+func sendTestPriorityClass(t *testing.T, server *Server, class types.PriorityClass) *httptest.ResponseRecorder {
+	t.Helper()
+	
+	classJSON, err := json.Marshal(class)
+	if err != nil {
+		t.Fatalf("Failed to marshal priority class: %v", err)
+	}
+	
+	req, err := http.NewRequest("POST", "/api/v1/priorityclasses", bytes.NewBuffer(classJSON))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	
+	rr := httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestPriorityClasses(t *testing.T) {
+	server, _ := setupTestServer(t)
+	
+	for _, class := range []types.PriorityClass{
+		{Metadata: types.ObjectMeta{Name: "high"}, Value: 1000},
+		{Metadata: types.ObjectMeta{Name: "standard"}, Value: 100, GlobalDefault: true},
+	} {
+		if rr := sendTestPriorityClass(t, server, class); rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+		}
+	}
+	
+	// Only one class may be the global default
+	rr := sendTestPriorityClass(t, server, types.PriorityClass{Metadata: types.ObjectMeta{Name: "other"}, Value: 10, GlobalDefault: true})
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "already the global default") {
+		t.Errorf("Expected a second global default to be rejected, got %d: %s", rr.Code, rr.Body.String())
+	}
+	
+	req, _ := http.NewRequest("GET", "/api/v1/priorityclasses", nil)
+	rr = httptest.NewRecorder()
+	server.router.ServeHTTP(rr, req)
+	var list struct {
+		Items []types.PriorityClass `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(list.Items) != 2 {
+		t.Errorf("Expected 2 priority classes, got %d", len(list.Items))
+	}
+	
+	rr = sendTestPatch(t, server, "/api/v1/priorityclasses/high", "application/merge-patch+json", `{"description": "user facing"}`)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"description":"user facing"`) {
+		t.Errorf("Expected the patched description, got %d: %s", rr.Code, rr.Body.String())
+	}
+	
+	// Pods get the priority of their class, or of the global default
+	tests := []struct {
+		name              string
+		priorityClassName string
+		wantCode          int
+		wantClass         string
+		wantPriority      int32
+	}{
+		{"named-class", "high", http.StatusCreated, "high", 1000},
+		{"default-class", "", http.StatusCreated, "standard", 100},
+		{"unknown-class", "missing", http.StatusBadRequest, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := types.Pod{
+				Metadata: types.ObjectMeta{Name: tt.name},
+				Spec: types.PodSpec{
+					Containers:        []types.Container{{Name: "nginx", Image: "nginx:latest"}},
+					PriorityClassName: tt.priorityClassName,
+				},
+			}
+			rr, created := sendTestPod(t, server, "POST", "/api/v1/pods", pod)
+			if rr.Code != tt.wantCode {
+				t.Fatalf("Expected status code %d, got %d: %s", tt.wantCode, rr.Code, rr.Body.String())
+			}
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+			if created.Spec.PriorityClassName != tt.wantClass || types.PodPriority(&created) != tt.wantPriority {
+				t.Errorf("Expected class %s with priority %d, got %s with %d", tt.wantClass, tt.wantPriority, created.Spec.PriorityClassName, types.PodPriority(&created))
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -133,6 +134,11 @@ func (s *Server) createServiceInNamespace(c *gin.Context, namespace string) {
 		return
 	}
 	
+	// Return the version the resource was stored with
+	if created, err := s.repository.GetResource("Service", resource.Namespace, resource.Name); err == nil {
+		service.Metadata.ResourceVersion = storage.FormatResourceVersion(created.ResourceVersion)
+	}
+	
 	c.JSON(http.StatusCreated, service)
 }
 
@@ -260,6 +266,18 @@ func (s *Server) updateServiceInNamespace(c *gin.Context, namespace string) {
 	// Update timestamp
 	service.Metadata.UpdatedAt = time.Now()
 	
+	// Only apply the update to the version the client read, if it sent one
+	resourceVersion, err := storage.ParseResourceVersion(service.Metadata.ResourceVersion)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_ERROR",
+			Message: "Invalid resourceVersion",
+			Code:    http.StatusBadRequest,
+			Details: map[string]string{"validation": err.Error()},
+		})
+		return
+	}
+	
 	// Convert to storage resource
 	metadataJSON, err := json.Marshal(service.Metadata)
 	if err != nil {
@@ -300,8 +318,19 @@ func (s *Server) updateServiceInNamespace(c *gin.Context, namespace string) {
 		Status:    string(statusJSON),
 	}
 	
+	resource.ResourceVersion = resourceVersion
+	
 	// Update in database
 	if err := s.repository.UpdateResource(resource); err != nil {
+		if errors.Is(err, storage.ErrConflict) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "CONFLICT",
+				Message: "Service has been modified, get the latest version and try again",
+				Code:    http.StatusConflict,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
 		c.JSON(http.StatusNotFound, ErrorResponse{
 			Error:   "RESOURCE_NOT_FOUND",
 			Message: "Service not found",
@@ -311,6 +340,11 @@ func (s *Server) updateServiceInNamespace(c *gin.Context, namespace string) {
 		return
 	}
 	
+	// Return the version the update was stored with
+	if updated, err := s.repository.GetResource("Service", namespace, name); err == nil {
+		service.Metadata.ResourceVersion = storage.FormatResourceVersion(updated.ResourceVersion)
+	}
+	
 	c.JSON(http.StatusOK, service)
 }
 
@@ -372,21 +406,40 @@ func (s *Server) listNamespacedServices(c *gin.Context) {
 
 // listServicesInNamespace lists services in the specified namespace
 func (s *Server) listServicesInNamespace(c *gin.Context, namespace string) {
+	// Only return the objects matching the request's selectors
+	filter, ok := listFilter(c)
+	if !ok {
+		return
+	}
+	
+	// Only return the requested page
+	options, ok := listOptions(c)
+	if !ok {
+		return
+	}
+	
+	// Stream changes instead of listing when watching
+	if isWatchRequest(c) {
+		s.watchResources(c, "Service", namespace, filter, func(resource storage.Resource) (interface{}, error) {
+			return s.resourceToService(resource)
+		})
+		return
+	}
+	
+	// Read the version before listing so a watch started from it misses nothing
+	metadata := s.listMetadata()
+	
 	// Get from database
-	resources, err := s.repository.ListResources("Service", namespace)
+	page, err := s.repository.ListResourcesPage("Service", namespace, options)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "DATABASE_ERROR",
-			Message: "Failed to list services",
-			Code:    http.StatusInternalServerError,
-			Details: map[string]string{"error": err.Error()},
-		})
+		listError(c, err, "Failed to list services")
 		return
 	}
+	setContinue(metadata, page.Continue)
 	
 	// Convert to services
 	var services []types.Service
-	for _, resource := range resources {
+	for _, resource := range page.Resources {
 		service, err := s.resourceToService(resource)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -397,12 +450,16 @@ func (s *Server) listServicesInNamespace(c *gin.Context, namespace string) {
 			})
 			return
 		}
+		if !matchesFilter(filter, service) {
+			continue
+		}
 		services = append(services, *service)
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
 		"apiVersion": "v1",
 		"kind":       "ServiceList",
+		"metadata":   metadata,
 		"items":      services,
 	})
 }
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// isWatchRequest reports whether a list request asked to watch for changes
+func isWatchRequest(c *gin.Context) bool {
+	watch := c.Query("watch")
+	return watch == "true" || watch == "1"
+}
+
+// listMetadata returns the metadata of a list response. It must be computed
+// before listing so that a watch started from its resourceVersion can't miss
+// changes made while the list was read.
+func (s *Server) listMetadata() gin.H {
+	revision, err := s.repository.CurrentRevision()
+	if err != nil {
+		return gin.H{}
+	}
+	return gin.H{"resourceVersion": strconv.FormatInt(revision, 10)}
+}
+
+// watchResources handles GET ...?watch=true on a list route. Changes to the
+// resources of the given kind are streamed as newline-delimited JSON
+// WatchEvents. Without a resourceVersion the current objects are sent first
+// as ADDED events; with one, the stream resumes after that version.
+//
+// A non-nil filter restricts the stream to the objects it accepts. An object
+// that starts matching is sent as ADDED and one that stops matching as
+// DELETED, so the watcher's view stays consistent with a filtered list.
+func (s *Server) watchResources(c *gin.Context, kind, namespace string, filter func(interface{}) bool, convert func(storage.Resource) (interface{}, error)) {
+	var revision int64
+	initial := true
+	if value := c.Query("resourceVersion"); value != "" && value != "0" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "INVALID_PARAMETER",
+				Message: "resourceVersion must be a non-negative integer",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		revision = parsed
+		initial = false
+	} else {
+		current, err := s.repository.CurrentRevision()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "DATABASE_ERROR",
+				Message: "Failed to get current resource version",
+				Code:    http.StatusInternalServerError,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
+		revision = current
+	}
+
+	var timeout <-chan time.Time
+	if value := c.Query("timeoutSeconds"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "INVALID_PARAMETER",
+				Message: "timeoutSeconds must be a positive integer",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		timer := time.NewTimer(time.Duration(seconds) * time.Second)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	ctx := c.Request.Context()
+	events, err := s.repository.Watch(ctx, kind, namespace, revision)
+	if err != nil {
+		if errors.Is(err, storage.ErrRevisionCompacted) {
+			c.JSON(http.StatusGone, ErrorResponse{
+				Error:   "RESOURCE_VERSION_EXPIRED",
+				Message: "Requested resourceVersion is too old, list again to get a new one",
+				Code:    http.StatusGone,
+				Details: map[string]string{"resourceVersion": c.Query("resourceVersion")},
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "DATABASE_ERROR",
+			Message: "Failed to watch resources",
+			Code:    http.StatusInternalServerError,
+			Details: map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// seen records which objects the watcher currently knows to match
+	seen := make(map[string]bool)
+
+	var objects []interface{}
+	if initial {
+		objects, err = s.listWatchObjects(kind, namespace, filter, convert, seen)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "DATABASE_ERROR",
+				Message: "Failed to list resources",
+				Code:    http.StatusInternalServerError,
+				Details: map[string]string{"error": err.Error()},
+			})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "application/json")
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	encoder := json.NewEncoder(c.Writer)
+	send := func(eventType string, revision int64, object interface{}) bool {
+		data, err := json.Marshal(object)
+		if err != nil {
+			return false
+		}
+		event := types.WatchEvent{
+			Type:            eventType,
+			ResourceVersion: strconv.FormatInt(revision, 10),
+			Object:          data,
+		}
+		if err := encoder.Encode(event); err != nil {
+			return false
+		}
+		c.Writer.Flush()
+		return true
+	}
+
+	for _, object := range objects {
+		if !send(storage.EventAdded, revision, object) {
+			return
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			object, err := convert(event.Resource)
+			if err != nil {
+				continue
+			}
+			eventType := event.Type
+			if filter != nil {
+				key := event.Resource.Namespace + "/" + event.Resource.Name
+				if eventType, ok = filteredEventType(event.Type, filter(object), seen, key, initial); !ok {
+					continue
+				}
+			}
+			if !send(eventType, event.Revision, object) {
+				return
+			}
+		case <-timeout:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// filteredEventType translates a change to an object into the event a
+// filtered watcher should see, based on whether the object matched before
+// and matches now. It returns false when the watcher should not be told.
+func filteredEventType(eventType string, matches bool, seen map[string]bool, key string, initial bool) (string, bool) {
+	matched, known := seen[key]
+	if !known && !initial {
+		// Without an initial list only the event itself tells whether the
+		// watcher has seen the object before
+		matched = matches && eventType != storage.EventAdded
+	}
+
+	if eventType == storage.EventDeleted {
+		delete(seen, key)
+		return eventType, matched
+	}
+
+	if matches {
+		seen[key] = true
+	} else {
+		delete(seen, key)
+	}
+
+	switch {
+	case matches && matched:
+		return storage.EventModified, true
+	case matches:
+		return storage.EventAdded, true
+	case matched:
+		return storage.EventDeleted, true
+	default:
+		return "", false
+	}
+}
+
+// listWatchObjects lists the current objects of a kind accepted by filter for
+// the start of a watch, recording each of them in seen
+func (s *Server) listWatchObjects(kind, namespace string, filter func(interface{}) bool, convert func(storage.Resource) (interface{}, error), seen map[string]bool) ([]interface{}, error) {
+	var objects []interface{}
+	add := func(key string, object interface{}) {
+		if filter != nil && !filter(object) {
+			return
+		}
+		seen[key] = true
+		objects = append(objects, object)
+	}
+
+	// Nodes are kept in their own table
+	if kind == "Node" {
+		nodes, err := s.repository.ListNodes()
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			add("/"+node.Metadata.Name, node)
+		}
+		return objects, nil
+	}
+
+	resources, err := s.repository.ListResources(kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resources {
+		object, err := convert(resource)
+		if err != nil {
+			return nil, err
+		}
+		add(resource.Namespace+"/"+resource.Name, object)
+	}
+	return objects, nil
+}
+
+// resourceToNode converts the resource recorded in a node watch event to a Node
+func (s *Server) resourceToNode(resource storage.Resource) (*types.Node, error) {
+	var metadata types.ObjectMeta
+	if resource.Metadata != "" {
+		if err := json.Unmarshal([]byte(resource.Metadata), &metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal node metadata: %w", err)
+		}
+	}
+
+	var spec types.NodeSpec
+	if resource.Spec != "" {
+		if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal node spec: %w", err)
+		}
+	}
+
+	var status types.NodeStatus
+	if resource.Status != "" {
+		if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal node status: %w", err)
+		}
+	}
+
+	return &types.Node{
+		APIVersion: "v1",
+		Kind:       "Node",
+		Metadata:   metadata,
+		Spec:       spec,
+		Status:     status,
+	}, nil
+}
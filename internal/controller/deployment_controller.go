@@ -0,0 +1,781 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+const (
+	// defaultProgressDeadline is used when a deployment doesn't set progressDeadlineSeconds
+	defaultProgressDeadline = 600 * time.Second
+	// defaultRevisionHistoryLimit is used when a deployment doesn't set revisionHistoryLimit
+	defaultRevisionHistoryLimit = 10
+)
+
+// DeploymentController manages one ReplicaSet per pod template of every
+// Deployment, scaling them according to the deployment strategy so that
+// the pods roll over to the current template
+type DeploymentController struct {
+	repository storage.Repository
+	stopCh     chan struct{}
+	wg         sync.WaitGroup
+}
+
+// NewDeploymentController creates a new deployment controller
+func NewDeploymentController(repository storage.Repository) *DeploymentController {
+	return &DeploymentController{
+		repository: repository,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start starts the deployment controller
+func (dc *DeploymentController) Start() {
+	log.Println("Starting deployment controller")
+	dc.wg.Add(1)
+	go dc.run()
+}
+
+// Stop stops the deployment controller
+func (dc *DeploymentController) Stop() {
+	log.Println("Stopping deployment controller")
+	close(dc.stopCh)
+	dc.wg.Wait()
+}
+
+// run is the main controller loop
+func (dc *DeploymentController) run() {
+	defer dc.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Deployment, replica set and pod changes trigger reconciliation; the
+	// ticker is only a resync, which also catches rollouts that pass their
+	// progress deadline
+	changes := storage.NotifyChanges(ctx, dc.repository, "Deployment", "ReplicaSet", "Pod")
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-changes:
+			if err := dc.reconcileDeployments(); err != nil {
+				log.Printf("Error reconciling deployments: %v", err)
+			}
+		case <-ticker.C:
+			if err := dc.reconcileDeployments(); err != nil {
+				log.Printf("Error reconciling deployments: %v", err)
+			}
+		case <-dc.stopCh:
+			log.Println("Deployment controller stopped")
+			return
+		}
+	}
+}
+
+// ReconcileDeployments reconciles all deployments (public for testing)
+func (dc *DeploymentController) ReconcileDeployments() error {
+	return dc.reconcileDeployments()
+}
+
+// reconcileDeployments reconciles every deployment with the replica sets it owns
+func (dc *DeploymentController) reconcileDeployments() error {
+	// Get all deployments
+	deployments, err := dc.repository.ListResources("Deployment", "")
+	if err != nil {
+		return fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	// Get all replica sets
+	replicaSetResources, err := dc.repository.ListResources("ReplicaSet", "")
+	if err != nil {
+		return fmt.Errorf("failed to list replica sets: %w", err)
+	}
+
+	// Get all pods
+	pods, err := listPods(dc.repository)
+	if err != nil {
+		return err
+	}
+
+	replicaSets := make([]*replicaSetState, 0, len(replicaSetResources))
+	for _, replicaSetResource := range replicaSetResources {
+		replicaSet, err := resourceToReplicaSet(replicaSetResource)
+		if err != nil {
+			log.Printf("Failed to decode replica set %s/%s: %v", replicaSetResource.Namespace, replicaSetResource.Name, err)
+			continue
+		}
+		state := &replicaSetState{resource: replicaSetResource, replicaSet: replicaSet}
+		for _, pod := range pods {
+			// Terminating pods are kept: they still run until their grace
+			// period is over
+			if (pod.Status.Phase == "Failed" || pod.Status.Phase == "Succeeded") && !types.IsPodTerminating(pod) {
+				continue
+			}
+			if isControlledBy(pod, &replicaSet.Metadata, &replicaSet.Spec.Selector) {
+				state.pods = append(state.pods, pod)
+			}
+		}
+		replicaSets = append(replicaSets, state)
+	}
+
+	// Reconcile each deployment
+	existing := make(map[string]bool)
+	for _, deploymentResource := range deployments {
+		existing[deploymentResource.ID] = true
+		if err := dc.reconcileDeployment(deploymentResource, replicaSets); err != nil {
+			log.Printf("Failed to reconcile deployment %s/%s: %v",
+				deploymentResource.Namespace, deploymentResource.Name, err)
+		}
+	}
+
+	// Remove replica sets whose deployment has been deleted; the replica set
+	// controller then removes their pods
+	for _, state := range replicaSets {
+		meta := &state.replicaSet.Metadata
+		owner := controllerOf(meta)
+		if owner == nil || owner.Kind != "Deployment" || existing[owner.UID] {
+			continue
+		}
+		log.Printf("Deleting replica set %s/%s owned by deleted deployment %s", meta.Namespace, meta.Name, owner.Name)
+		if err := dc.repository.DeleteResource("ReplicaSet", meta.Namespace, meta.Name); err != nil {
+			log.Printf("Failed to delete orphaned replica set %s/%s: %v", meta.Namespace, meta.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// reconcileDeployment rolls a single deployment towards its current pod template
+// by scaling the replica sets it owns, and updates its status
+func (dc *DeploymentController) reconcileDeployment(deploymentResource storage.Resource, replicaSets []*replicaSetState) error {
+	deployment, err := resourceToDeployment(deploymentResource)
+	if err != nil {
+		return err
+	}
+
+	if selector := deploymentSelector(deployment); selector.Empty() {
+		return fmt.Errorf("deployment has an empty selector")
+	}
+
+	// Split the owned replica sets into the one running the current template and the old ones
+	hash := templateHash(&deployment.Spec.Template)
+	rollout := &deploymentRollout{deployment: deployment, hash: hash}
+	var maxRevision int64
+	for _, state := range replicaSets {
+		ref := controllerOf(&state.replicaSet.Metadata)
+		if ref == nil || ref.UID != deployment.Metadata.UID || state.replicaSet.Metadata.Namespace != deployment.Metadata.Namespace {
+			continue
+		}
+		maxRevision = max(maxRevision, replicaSetRevision(state.replicaSet))
+		if state.replicaSet.Metadata.Labels[types.PodTemplateHashLabel] == hash {
+			rollout.newRS = state
+		} else {
+			rollout.oldRSs = append(rollout.oldRSs, state)
+		}
+	}
+	sort.Slice(rollout.oldRSs, func(i, j int) bool {
+		return replicaSetRevision(rollout.oldRSs[i].replicaSet) < replicaSetRevision(rollout.oldRSs[j].replicaSet)
+	})
+
+	if rollout.newRS == nil {
+		rollout.newRS, err = dc.createReplicaSet(deployment, hash, maxRevision+1)
+		if err != nil {
+			return err
+		}
+		rollout.progressed = true
+	} else if replicaSetRevision(rollout.newRS.replicaSet) < maxRevision {
+		// Rolling back to an old template makes its replica set the newest revision
+		if err := dc.setReplicaSetRevision(rollout.newRS, maxRevision+1); err != nil {
+			return err
+		}
+	}
+
+	if deployment.Spec.Strategy.Type == types.RecreateDeploymentStrategyType {
+		dc.rolloutRecreate(rollout)
+	} else {
+		dc.rolloutRolling(rollout)
+	}
+
+	dc.cleanupOldReplicaSets(rollout)
+
+	return dc.updateDeploymentStatus(deploymentResource, rollout)
+}
+
+// replicaSetState is a replica set together with the pods it controls,
+// including the ones that are still terminating
+type replicaSetState struct {
+	resource   storage.Resource
+	replicaSet *types.ReplicaSet
+	pods       []*types.Pod
+}
+
+// replicas returns the desired replica count of the replica set
+func (s *replicaSetState) replicas() int {
+	return int(s.replicaSet.Spec.Replicas)
+}
+
+// available returns the number of ready pods that the replica set will keep
+func (s *replicaSetState) available() int {
+	return min(countReadyPods(s.pods), s.replicas())
+}
+
+// activePods returns the number of pods that are not being deleted
+func (s *replicaSetState) activePods() int {
+	count := 0
+	for _, pod := range s.pods {
+		if !types.IsPodTerminating(pod) {
+			count++
+		}
+	}
+	return count
+}
+
+// deploymentRollout tracks the replica sets of a deployment during a single reconcile pass
+type deploymentRollout struct {
+	deployment *types.Deployment
+	hash       string
+	newRS      *replicaSetState   // replica set running the current template
+	oldRSs     []*replicaSetState // replica sets of previous templates, oldest first
+	progressed bool               // whether any replica set was created or scaled
+}
+
+// totalReplicas returns the desired replica count summed over all replica sets
+func (r *deploymentRollout) totalReplicas() int {
+	total := r.newRS.replicas()
+	for _, state := range r.oldRSs {
+		total += state.replicas()
+	}
+	return total
+}
+
+// rolloutRecreate scales every old replica set down to zero and waits for
+// their pods, terminating ones included, to go away before scaling up the
+// new one
+func (dc *DeploymentController) rolloutRecreate(rollout *deploymentRollout) {
+	oldPods := 0
+	for _, state := range rollout.oldRSs {
+		dc.scaleReplicaSet(rollout, state, 0)
+		oldPods += len(state.pods)
+	}
+	if oldPods > 0 {
+		return
+	}
+
+	dc.scaleReplicaSet(rollout, rollout.newRS, int(rollout.deployment.Spec.Replicas))
+}
+
+// rolloutRolling moves replicas from the old replica sets to the new one while
+// keeping the total within maxSurge and the ready pods within maxUnavailable
+func (dc *DeploymentController) rolloutRolling(rollout *deploymentRollout) {
+	desired := int(rollout.deployment.Spec.Replicas)
+	maxSurge, maxUnavailable := rollingUpdateLimits(rollout.deployment)
+	newRS := rollout.newRS
+
+	// Scale up the new replica set, never exceeding desired + maxSurge in total
+	if newRS.replicas() > desired {
+		dc.scaleReplicaSet(rollout, newRS, desired)
+	} else if newRS.replicas() < desired {
+		scaleUp := min(desired+maxSurge-rollout.totalReplicas(), desired-newRS.replicas())
+		if scaleUp > 0 {
+			dc.scaleReplicaSet(rollout, newRS, newRS.replicas()+scaleUp)
+		}
+	}
+
+	if len(rollout.oldRSs) == 0 {
+		return
+	}
+
+	// Scale down the old replica sets without dropping below desired - maxUnavailable ready pods
+	minAvailable := max(0, desired-maxUnavailable)
+	newUnavailable := newRS.replicas() - newRS.available()
+	maxScaledDown := rollout.totalReplicas() - minAvailable - newUnavailable
+	if maxScaledDown <= 0 {
+		return
+	}
+
+	// Replicas that are not ready don't count towards availability, so remove them first
+	for _, state := range rollout.oldRSs {
+		unhealthy := min(state.replicas()-state.available(), maxScaledDown)
+		if unhealthy <= 0 {
+			continue
+		}
+		dc.scaleReplicaSet(rollout, state, state.replicas()-unhealthy)
+		maxScaledDown -= unhealthy
+	}
+
+	available := newRS.available()
+	for _, state := range rollout.oldRSs {
+		available += state.available()
+	}
+	count := min(maxScaledDown, available-minAvailable)
+	for _, state := range rollout.oldRSs {
+		if count <= 0 {
+			break
+		}
+		scaleDown := min(state.replicas(), count)
+		dc.scaleReplicaSet(rollout, state, state.replicas()-scaleDown)
+		count -= scaleDown
+	}
+}
+
+// scaleReplicaSet sets the desired replica count of a replica set
+func (dc *DeploymentController) scaleReplicaSet(rollout *deploymentRollout, state *replicaSetState, replicas int) {
+	if state.replicas() == replicas {
+		return
+	}
+
+	meta := &state.replicaSet.Metadata
+	log.Printf("Scaling replica set %s/%s from %d to %d", meta.Namespace, meta.Name, state.replicas(), replicas)
+
+	spec := state.replicaSet.Spec
+	spec.Replicas = int32(replicas)
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		log.Printf("Failed to marshal replica set spec: %v", err)
+		return
+	}
+
+	resource := state.resource
+	resource.Spec = string(specJSON)
+	if err := updateResource(dc.repository, &resource); err != nil {
+		log.Printf("Failed to scale replica set %s/%s: %v", meta.Namespace, meta.Name, err)
+		return
+	}
+
+	state.resource = resource
+	state.replicaSet.Spec = spec
+	rollout.progressed = true
+}
+
+// createReplicaSet creates an empty replica set for the deployment's current pod template
+func (dc *DeploymentController) createReplicaSet(deployment *types.Deployment, hash string, revision int64) (*replicaSetState, error) {
+	now := time.Now()
+
+	labels := make(map[string]string)
+	for key, value := range deployment.Spec.Template.Metadata.Labels {
+		labels[key] = value
+	}
+	labels[types.PodTemplateHashLabel] = hash
+
+	deploymentSelector := deploymentSelector(deployment)
+	selector := types.LabelSelector{
+		MatchLabels:      make(map[string]string),
+		MatchExpressions: append([]types.LabelSelectorRequirement(nil), deploymentSelector.MatchExpressions...),
+	}
+	for key, value := range deploymentSelector.MatchLabels {
+		selector.MatchLabels[key] = value
+	}
+	selector.MatchLabels[types.PodTemplateHashLabel] = hash
+
+	template := deployment.Spec.Template
+	template.Metadata.Labels = labels
+
+	replicaSet := &types.ReplicaSet{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Metadata: types.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%s", deployment.Metadata.Name, hash),
+			Namespace:   deployment.Metadata.Namespace,
+			Labels:      labels,
+			Annotations: map[string]string{types.RevisionAnnotation: strconv.FormatInt(revision, 10)},
+			UID:         uuid.New().String(),
+			OwnerReferences: []types.OwnerReference{
+				{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       deployment.Metadata.Name,
+					UID:        deployment.Metadata.UID,
+					Controller: true,
+				},
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		Spec: types.ReplicaSetSpec{
+			Replicas: 0,
+			Selector: selector,
+			Template: template,
+		},
+	}
+
+	resource, err := replicaSetToResource(replicaSet)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Creating replica set %s/%s at revision %d", replicaSet.Metadata.Namespace, replicaSet.Metadata.Name, revision)
+	if err := dc.repository.CreateResource(resource); err != nil {
+		return nil, fmt.Errorf("failed to create replica set: %w", err)
+	}
+
+	return &replicaSetState{resource: resource, replicaSet: replicaSet}, nil
+}
+
+// setReplicaSetRevision records a new rollout revision on a replica set
+func (dc *DeploymentController) setReplicaSetRevision(state *replicaSetState, revision int64) error {
+	meta := state.replicaSet.Metadata
+	annotations := make(map[string]string)
+	for key, value := range meta.Annotations {
+		annotations[key] = value
+	}
+	annotations[types.RevisionAnnotation] = strconv.FormatInt(revision, 10)
+	meta.Annotations = annotations
+
+	metadataJSON, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal replica set metadata: %w", err)
+	}
+
+	resource := state.resource
+	resource.Metadata = string(metadataJSON)
+	if err := updateResource(dc.repository, &resource); err != nil {
+		return fmt.Errorf("failed to update replica set revision: %w", err)
+	}
+
+	state.resource = resource
+	state.replicaSet.Metadata = meta
+	return nil
+}
+
+// cleanupOldReplicaSets deletes the oldest scaled-down replica sets beyond the revision history limit
+func (dc *DeploymentController) cleanupOldReplicaSets(rollout *deploymentRollout) {
+	limit := defaultRevisionHistoryLimit
+	if rollout.deployment.Spec.RevisionHistoryLimit != nil {
+		limit = int(*rollout.deployment.Spec.RevisionHistoryLimit)
+	}
+
+	var candidates []*replicaSetState
+	for _, state := range rollout.oldRSs {
+		if state.replicas() == 0 && len(state.pods) == 0 {
+			candidates = append(candidates, state)
+		}
+	}
+
+	deleted := make(map[*replicaSetState]bool)
+	for i := 0; i < len(candidates)-limit; i++ {
+		meta := &candidates[i].replicaSet.Metadata
+		log.Printf("Deleting old replica set %s/%s", meta.Namespace, meta.Name)
+		if err := dc.repository.DeleteResource("ReplicaSet", meta.Namespace, meta.Name); err != nil {
+			log.Printf("Failed to delete old replica set %s/%s: %v", meta.Namespace, meta.Name, err)
+			continue
+		}
+		deleted[candidates[i]] = true
+	}
+
+	if len(deleted) == 0 {
+		return
+	}
+	var remaining []*replicaSetState
+	for _, state := range rollout.oldRSs {
+		if !deleted[state] {
+			remaining = append(remaining, state)
+		}
+	}
+	rollout.oldRSs = remaining
+}
+
+// updateDeploymentStatus writes the observed replica counts and conditions back to the deployment
+func (dc *DeploymentController) updateDeploymentStatus(deploymentResource storage.Resource, rollout *deploymentRollout) error {
+	deployment := rollout.deployment
+	desired := deployment.Spec.Replicas
+	newReady := int32(countReadyPods(rollout.newRS.pods))
+	ready := newReady
+	replicas := int32(rollout.newRS.activePods())
+	complete := true
+	for _, state := range rollout.oldRSs {
+		ready += int32(countReadyPods(state.pods))
+		replicas += int32(state.activePods())
+		if state.replicas() > 0 || len(state.pods) > 0 {
+			complete = false
+		}
+	}
+
+	previous := deployment.Status
+	status := deployment.Status
+	status.Replicas = replicas
+	status.UpdatedReplicas = int32(rollout.newRS.activePods())
+	status.ReadyReplicas = ready
+	status.AvailableReplicas = ready
+	status.UnavailableReplicas = desired - ready
+	if status.UnavailableReplicas < 0 {
+		status.UnavailableReplicas = 0
+	}
+
+	now := time.Now()
+	minAvailable := desired
+	if deployment.Spec.Strategy.Type != types.RecreateDeploymentStrategyType {
+		_, maxUnavailable := rollingUpdateLimits(deployment)
+		minAvailable = max(0, desired-int32(maxUnavailable))
+	}
+	if ready >= minAvailable {
+		status.Conditions = setDeploymentCondition(status.Conditions, types.DeploymentCondition{
+			Type:    "Available",
+			Status:  "True",
+			Reason:  "MinimumReplicasAvailable",
+			Message: "Deployment has minimum availability",
+		}, now)
+	} else {
+		status.Conditions = setDeploymentCondition(status.Conditions, types.DeploymentCondition{
+			Type:    "Available",
+			Status:  "False",
+			Reason:  "MinimumReplicasUnavailable",
+			Message: "Deployment does not have minimum availability",
+		}, now)
+	}
+
+	// A rollout makes progress whenever replica sets are scaled or pods are added, removed or become ready
+	progressed := rollout.progressed ||
+		status.Replicas != previous.Replicas ||
+		status.UpdatedReplicas != previous.UpdatedReplicas ||
+		status.ReadyReplicas != previous.ReadyReplicas
+	progressing := getDeploymentCondition(status.Conditions, "Progressing")
+
+	switch {
+	case complete && status.UpdatedReplicas == desired && newReady >= desired:
+		status.Conditions = setDeploymentCondition(status.Conditions, types.DeploymentCondition{
+			Type:    "Progressing",
+			Status:  "True",
+			Reason:  "NewReplicaSetAvailable",
+			Message: fmt.Sprintf("Deployment %q has successfully progressed", deployment.Metadata.Name),
+		}, now)
+	case progressed || progressing == nil || progressing.Reason == "NewReplicaSetAvailable":
+		status.Conditions = setDeploymentCondition(status.Conditions, types.DeploymentCondition{
+			Type:    "Progressing",
+			Status:  "True",
+			Reason:  "ReplicaSetUpdated",
+			Message: fmt.Sprintf("Deployment %q is progressing: %d of %d updated replicas ready", deployment.Metadata.Name, newReady, desired),
+		}, now)
+		// The progress deadline is measured from the last time the rollout moved
+		getDeploymentCondition(status.Conditions, "Progressing").LastUpdateTime = now
+	case progressing.Status == "True" && now.Sub(progressing.LastUpdateTime) > progressDeadline(deployment):
+		log.Printf("Deployment %s/%s exceeded its progress deadline", deployment.Metadata.Namespace, deployment.Metadata.Name)
+		status.Conditions = setDeploymentCondition(status.Conditions, types.DeploymentCondition{
+			Type:    "Progressing",
+			Status:  "False",
+			Reason:  "ProgressDeadlineExceeded",
+			Message: fmt.Sprintf("Deployment %q has timed out progressing", deployment.Metadata.Name),
+		}, now)
+	}
+
+	statusJSON, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal deployment status: %w", err)
+	}
+
+	// Record the revision of the current template on the deployment
+	revision := rollout.newRS.replicaSet.Metadata.Annotations[types.RevisionAnnotation]
+	metadataChanged := deployment.Metadata.Annotations[types.RevisionAnnotation] != revision
+	if metadataChanged {
+		meta := deployment.Metadata
+		meta.Annotations = make(map[string]string)
+		for key, value := range deployment.Metadata.Annotations {
+			meta.Annotations[key] = value
+		}
+		meta.Annotations[types.RevisionAnnotation] = revision
+		metadataJSON, err := json.Marshal(meta)
+		if err != nil {
+			return fmt.Errorf("failed to marshal deployment metadata: %w", err)
+		}
+		deploymentResource.Metadata = string(metadataJSON)
+	}
+
+	// Avoid rewriting the deployment when nothing changed
+	if !metadataChanged && string(statusJSON) == deploymentResource.Status {
+		return nil
+	}
+
+	deploymentResource.Status = string(statusJSON)
+	if err := dc.repository.UpdateResource(deploymentResource); err != nil {
+		return fmt.Errorf("failed to update deployment status: %w", err)
+	}
+
+	return nil
+}
+
+// getDeploymentCondition returns the condition of the given type, if present
+func getDeploymentCondition(conditions []types.DeploymentCondition, conditionType string) *types.DeploymentCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// setDeploymentCondition sets a condition, keeping the transition time when the status is unchanged
+func setDeploymentCondition(conditions []types.DeploymentCondition, condition types.DeploymentCondition, now time.Time) []types.DeploymentCondition {
+	for i, existing := range conditions {
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+			return conditions
+		}
+		condition.LastUpdateTime = now
+		condition.LastTransitionTime = existing.LastTransitionTime
+		if existing.Status != condition.Status {
+			condition.LastTransitionTime = now
+		}
+		conditions[i] = condition
+		return conditions
+	}
+
+	condition.LastUpdateTime = now
+	condition.LastTransitionTime = now
+	return append(conditions, condition)
+}
+
+// deploymentSelector returns the selector of the deployment's pods, falling
+// back to the labels of its pod template
+func deploymentSelector(deployment *types.Deployment) types.LabelSelector {
+	if !deployment.Spec.Selector.Empty() {
+		return deployment.Spec.Selector
+	}
+	return types.LabelSelector{MatchLabels: deployment.Spec.Template.Metadata.Labels}
+}
+
+// rollingUpdateLimits returns the absolute maxSurge and maxUnavailable of a
+// rolling update, defaulting both to 25% of the desired replicas
+func rollingUpdateLimits(deployment *types.Deployment) (int, int) {
+	if ru := deployment.Spec.Strategy.RollingUpdate; ru != nil {
+		return int(ru.MaxSurge), int(ru.MaxUnavailable)
+	}
+
+	replicas := int(deployment.Spec.Replicas)
+	maxSurge := (replicas*25 + 99) / 100
+	maxUnavailable := replicas * 25 / 100
+	if maxSurge == 0 && maxUnavailable == 0 {
+		// Some progress must always be possible
+		maxUnavailable = 1
+	}
+	return maxSurge, maxUnavailable
+}
+
+// progressDeadline returns how long a rollout may go without progress
+func progressDeadline(deployment *types.Deployment) time.Duration {
+	if deployment.Spec.ProgressDeadlineSeconds != nil {
+		return time.Duration(*deployment.Spec.ProgressDeadlineSeconds) * time.Second
+	}
+	return defaultProgressDeadline
+}
+
+// replicaSetRevision returns the rollout revision recorded on a replica set
+func replicaSetRevision(replicaSet *types.ReplicaSet) int64 {
+	revision, _ := strconv.ParseInt(replicaSet.Metadata.Annotations[types.RevisionAnnotation], 10, 64)
+	return revision
+}
+
+// templateHash returns a short hash identifying a pod template
+func templateHash(template *types.PodTemplateSpec) string {
+	data, _ := json.Marshal(template)
+	hasher := fnv.New32a()
+	hasher.Write(data)
+	return fmt.Sprintf("%08x", hasher.Sum32())
+}
+
+// resourceToDeployment converts a storage resource to a Deployment
+func resourceToDeployment(resource storage.Resource) (*types.Deployment, error) {
+	var metadata types.ObjectMeta
+	if resource.Metadata != "" {
+		if err := json.Unmarshal([]byte(resource.Metadata), &metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal deployment metadata: %w", err)
+		}
+	}
+	metadata.Name = resource.Name
+	metadata.Namespace = resource.Namespace
+	metadata.UID = resource.ID
+
+	var spec types.DeploymentSpec
+	if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal deployment spec: %w", err)
+	}
+
+	var status types.DeploymentStatus
+	if resource.Status != "" {
+		if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal deployment status: %w", err)
+		}
+	}
+
+	return &types.Deployment{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   metadata,
+		Spec:       spec,
+		Status:     status,
+	}, nil
+}
+
+// resourceToPod converts a storage resource to a Pod
+func resourceToPod(resource storage.Resource) (*types.Pod, error) {
+	var metadata types.ObjectMeta
+	if resource.Metadata != "" {
+		if err := json.Unmarshal([]byte(resource.Metadata), &metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pod metadata: %w", err)
+		}
+	}
+	metadata.Name = resource.Name
+	metadata.Namespace = resource.Namespace
+	metadata.UID = resource.ID
+
+	var spec types.PodSpec
+	if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pod spec: %w", err)
+	}
+
+	var status types.PodStatus
+	if resource.Status != "" {
+		if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pod status: %w", err)
+		}
+	}
+
+	return &types.Pod{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Metadata:   metadata,
+		Spec:       spec,
+		Status:     status,
+	}, nil
+}
+
+// podToResource converts a Pod to a storage resource
+func podToResource(pod *types.Pod) (storage.Resource, error) {
+	metadataJSON, err := json.Marshal(pod.Metadata)
+	if err != nil {
+		return storage.Resource{}, fmt.Errorf("failed to marshal pod metadata: %w", err)
+	}
+
+	specJSON, err := json.Marshal(pod.Spec)
+	if err != nil {
+		return storage.Resource{}, fmt.Errorf("failed to marshal pod spec: %w", err)
+	}
+
+	statusJSON, err := json.Marshal(pod.Status)
+	if err != nil {
+		return storage.Resource{}, fmt.Errorf("failed to marshal pod status: %w", err)
+	}
+
+	return storage.Resource{
+		ID:        pod.Metadata.UID,
+		Kind:      "Pod",
+		Namespace: pod.Metadata.Namespace,
+		Name:      pod.Metadata.Name,
+		Metadata:  string(metadataJSON),
+		Spec:      string(specJSON),
+		Status:    string(statusJSON),
+	}, nil
+}
@@ -0,0 +1,572 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+func createTestDeployment(name, namespace string, replicas int32) storage.Resource {
+	labels := map[string]string{"app": name}
+
+	metadata := types.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+		UID:       "deployment-" + name,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	spec := types.DeploymentSpec{
+		Replicas: replicas,
+		Selector: types.LabelSelector{MatchLabels: labels},
+		Template: types.PodTemplateSpec{
+			Metadata: types.ObjectMeta{Labels: labels},
+			Spec: types.PodSpec{
+				Containers: []types.Container{
+					{
+						Name:  "web",
+						Image: "nginx:latest",
+					},
+				},
+			},
+		},
+	}
+
+	metadataJSON, _ := json.Marshal(metadata)
+	specJSON, _ := json.Marshal(spec)
+	statusJSON, _ := json.Marshal(types.DeploymentStatus{})
+
+	return storage.Resource{
+		ID:        metadata.UID,
+		Kind:      "Deployment",
+		Namespace: namespace,
+		Name:      name,
+		Metadata:  string(metadataJSON),
+		Spec:      string(specJSON),
+		Status:    string(statusJSON),
+		CreatedAt: metadata.CreatedAt,
+		UpdatedAt: metadata.UpdatedAt,
+	}
+}
+
+// syncTestDeployments runs the deployment and replica set controllers the way
+// they interleave in the API server
+func syncTestDeployments(t *testing.T, dc *DeploymentController, rsc *ReplicaSetController) {
+	if err := dc.reconcileDeployments(); err != nil {
+		t.Fatalf("Failed to reconcile deployments: %v", err)
+	}
+	if err := rsc.reconcileReplicaSets(); err != nil {
+		t.Fatalf("Failed to reconcile replica sets: %v", err)
+	}
+	if err := dc.reconcileDeployments(); err != nil {
+		t.Fatalf("Failed to reconcile deployments: %v", err)
+	}
+}
+
+func listTestReplicaSets(t *testing.T, repo *MockRepository) []*types.ReplicaSet {
+	resources, err := repo.ListResources("ReplicaSet", "")
+	if err != nil {
+		t.Fatalf("Failed to list replica sets: %v", err)
+	}
+
+	var replicaSets []*types.ReplicaSet
+	for _, resource := range resources {
+		replicaSet, err := resourceToReplicaSet(resource)
+		if err != nil {
+			t.Fatalf("Failed to decode replica set: %v", err)
+		}
+		replicaSets = append(replicaSets, replicaSet)
+	}
+	return replicaSets
+}
+
+func listTestPods(t *testing.T, repo *MockRepository) []*types.Pod {
+	resources, err := repo.ListResources("Pod", "")
+	if err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+
+	var pods []*types.Pod
+	for _, resource := range resources {
+		pod, err := resourceToPod(resource)
+		if err != nil {
+			t.Fatalf("Failed to decode pod: %v", err)
+		}
+		pods = append(pods, pod)
+	}
+	return pods
+}
+
+func setTestPodStatus(t *testing.T, repo *MockRepository, pod *types.Pod, status types.PodStatus) {
+	pod.Status = status
+	resource, err := podToResource(pod)
+	if err != nil {
+		t.Fatalf("Failed to encode pod: %v", err)
+	}
+	if err := repo.UpdateResource(resource); err != nil {
+		t.Fatalf("Failed to update pod: %v", err)
+	}
+}
+
+// removeTerminatingTestPods deletes the terminating pods the way node agents
+// do once their containers have shut down
+func removeTerminatingTestPods(t *testing.T, repo *MockRepository) {
+	for _, pod := range listTestPods(t, repo) {
+		if types.IsPodTerminating(pod) {
+			if err := repo.DeleteResource("Pod", pod.Metadata.Namespace, pod.Metadata.Name); err != nil {
+				t.Fatalf("Failed to delete pod: %v", err)
+			}
+		}
+	}
+}
+
+func getTestDeployment(t *testing.T, repo *MockRepository, name string) *types.Deployment {
+	resource, err := repo.GetResource("Deployment", "default", name)
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	deployment, err := resourceToDeployment(resource)
+	if err != nil {
+		t.Fatalf("Failed to decode deployment: %v", err)
+	}
+	return deployment
+}
+
+func TestDeploymentController_ScaleUp(t *testing.T) {
+	repo := NewMockRepository()
+	dc := NewDeploymentController(repo)
+	rsc := NewReplicaSetController(repo)
+
+	repo.CreateResource(createTestDeployment("web", "default", 3))
+
+	syncTestDeployments(t, dc, rsc)
+
+	replicaSets := listTestReplicaSets(t, repo)
+	if len(replicaSets) != 1 {
+		t.Fatalf("Expected 1 replica set, got %d", len(replicaSets))
+	}
+	if owner := controllerOf(&replicaSets[0].Metadata); owner == nil || owner.UID != "deployment-web" {
+		t.Errorf("Expected replica set to be owned by deployment-web, got %v", owner)
+	}
+	if replicaSets[0].Spec.Replicas != 3 {
+		t.Errorf("Expected replica set to have 3 replicas, got %d", replicaSets[0].Spec.Replicas)
+	}
+
+	pods := listTestPods(t, repo)
+	if len(pods) != 3 {
+		t.Fatalf("Expected 3 pods, got %d", len(pods))
+	}
+
+	for _, pod := range pods {
+		if pod.Metadata.Labels["app"] != "web" {
+			t.Errorf("Expected pod %s to carry template labels, got %v", pod.Metadata.Name, pod.Metadata.Labels)
+		}
+		owner := controllerOf(&pod.Metadata)
+		if owner == nil || owner.Kind != "ReplicaSet" || owner.UID != replicaSets[0].Metadata.UID {
+			t.Errorf("Expected pod %s to be owned by replica set %s, got %v", pod.Metadata.Name, replicaSets[0].Metadata.Name, owner)
+		}
+		if pod.Status.Phase != "Pending" {
+			t.Errorf("Expected pod %s phase Pending, got %s", pod.Metadata.Name, pod.Status.Phase)
+		}
+	}
+
+	// A second pass must not create more pods
+	syncTestDeployments(t, dc, rsc)
+	if pods := listTestPods(t, repo); len(pods) != 3 {
+		t.Errorf("Expected 3 pods after second reconcile, got %d", len(pods))
+	}
+
+	deployment := getTestDeployment(t, repo, "web")
+	if deployment.Status.Replicas != 3 {
+		t.Errorf("Expected status replicas 3, got %d", deployment.Status.Replicas)
+	}
+	if deployment.Status.UnavailableReplicas != 3 {
+		t.Errorf("Expected 3 unavailable replicas, got %d", deployment.Status.UnavailableReplicas)
+	}
+}
+
+func TestDeploymentController_ScaleDown(t *testing.T) {
+	repo := NewMockRepository()
+	dc := NewDeploymentController(repo)
+	rsc := NewReplicaSetController(repo)
+
+	deploymentResource := createTestDeployment("web", "default", 3)
+	repo.CreateResource(deploymentResource)
+
+	syncTestDeployments(t, dc, rsc)
+
+	// Mark one pod as running and ready so it is kept
+	pods := listTestPods(t, repo)
+	readyPod := pods[0]
+	readyPod.Spec.NodeName = "node-1"
+	setTestPodStatus(t, repo, readyPod, types.PodStatus{
+		Phase:      "Running",
+		PodIP:      "10.0.0.1",
+		Conditions: []types.PodCondition{{Type: "Ready", Status: "True"}},
+	})
+
+	// Scale the deployment down to one replica
+	var spec types.DeploymentSpec
+	json.Unmarshal([]byte(deploymentResource.Spec), &spec)
+	spec.Replicas = 1
+	specJSON, _ := json.Marshal(spec)
+	deploymentResource.Spec = string(specJSON)
+	repo.UpdateResource(deploymentResource)
+
+	syncTestDeployments(t, dc, rsc)
+
+	pods = listTestPods(t, repo)
+	if len(pods) != 1 {
+		t.Fatalf("Expected 1 pod, got %d", len(pods))
+	}
+	if pods[0].Metadata.Name != readyPod.Metadata.Name {
+		t.Errorf("Expected ready pod %s to survive scale down, got %s", readyPod.Metadata.Name, pods[0].Metadata.Name)
+	}
+
+	deployment := getTestDeployment(t, repo, "web")
+	if deployment.Status.ReadyReplicas != 1 || deployment.Status.AvailableReplicas != 1 {
+		t.Errorf("Expected 1 ready and available replica, got %d/%d",
+			deployment.Status.ReadyReplicas, deployment.Status.AvailableReplicas)
+	}
+	if deployment.Status.UnavailableReplicas != 0 {
+		t.Errorf("Expected 0 unavailable replicas, got %d", deployment.Status.UnavailableReplicas)
+	}
+
+	available := false
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == "Available" && condition.Status == "True" {
+			available = true
+		}
+	}
+	if !available {
+		t.Errorf("Expected Available condition to be True, got %v", deployment.Status.Conditions)
+	}
+}
+
+func TestDeploymentController_ReplacesFailedPods(t *testing.T) {
+	repo := NewMockRepository()
+	dc := NewDeploymentController(repo)
+	rsc := NewReplicaSetController(repo)
+
+	repo.CreateResource(createTestDeployment("web", "default", 2))
+
+	syncTestDeployments(t, dc, rsc)
+
+	pods := listTestPods(t, repo)
+	failedPod := pods[0]
+	setTestPodStatus(t, repo, failedPod, types.PodStatus{Phase: "Failed"})
+
+	syncTestDeployments(t, dc, rsc)
+
+	pods = listTestPods(t, repo)
+	if len(pods) != 2 {
+		t.Fatalf("Expected 2 pods, got %d", len(pods))
+	}
+	for _, pod := range pods {
+		if pod.Metadata.Name == failedPod.Metadata.Name {
+			t.Errorf("Expected failed pod %s to be replaced", failedPod.Metadata.Name)
+		}
+	}
+}
+
+func TestDeploymentController_DeletesOrphanedReplicaSets(t *testing.T) {
+	repo := NewMockRepository()
+	dc := NewDeploymentController(repo)
+	rsc := NewReplicaSetController(repo)
+
+	repo.CreateResource(createTestDeployment("web", "default", 2))
+
+	syncTestDeployments(t, dc, rsc)
+
+	// Unrelated pods must be left alone
+	repo.CreateResource(createTestPod("standalone", "default", map[string]string{"app": "web"}, true, "10.0.0.9"))
+
+	repo.DeleteResource("Deployment", "default", "web")
+
+	syncTestDeployments(t, dc, rsc)
+
+	if replicaSets := listTestReplicaSets(t, repo); len(replicaSets) != 0 {
+		t.Errorf("Expected replica sets of the deleted deployment to be removed, got %d", len(replicaSets))
+	}
+
+	pods := listTestPods(t, repo)
+	if len(pods) != 1 || pods[0].Metadata.Name != "standalone" {
+		t.Errorf("Expected only the standalone pod to remain, got %d pods", len(pods))
+	}
+}
+
+func updateTestDeploymentSpec(t *testing.T, repo *MockRepository, name string, update func(spec *types.DeploymentSpec)) {
+	resource, err := repo.GetResource("Deployment", "default", name)
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	var spec types.DeploymentSpec
+	if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
+		t.Fatalf("Failed to decode deployment spec: %v", err)
+	}
+	update(&spec)
+	specJSON, _ := json.Marshal(spec)
+	resource.Spec = string(specJSON)
+	if err := repo.UpdateResource(resource); err != nil {
+		t.Fatalf("Failed to update deployment: %v", err)
+	}
+}
+
+func markTestPodsReady(t *testing.T, repo *MockRepository) {
+	for i, pod := range listTestPods(t, repo) {
+		if isPodReady(pod) {
+			continue
+		}
+		pod.Spec.NodeName = "node-1"
+		setTestPodStatus(t, repo, pod, types.PodStatus{
+			Phase:      "Running",
+			PodIP:      fmt.Sprintf("10.0.0.%d", i+1),
+			Conditions: []types.PodCondition{{Type: "Ready", Status: "True"}},
+		})
+	}
+}
+
+func TestDeploymentController_RollingUpdate(t *testing.T) {
+	repo := NewMockRepository()
+	dc := NewDeploymentController(repo)
+	rsc := NewReplicaSetController(repo)
+
+	repo.CreateResource(createTestDeployment("web", "default", 3))
+	updateTestDeploymentSpec(t, repo, "web", func(spec *types.DeploymentSpec) {
+		spec.Strategy = types.DeploymentStrategy{
+			Type:          types.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: &types.RollingUpdateStrategy{MaxSurge: 1, MaxUnavailable: 0},
+		}
+	})
+
+	syncTestDeployments(t, dc, rsc)
+	markTestPodsReady(t, repo)
+	oldHash := listTestPods(t, repo)[0].Metadata.Labels[types.PodTemplateHashLabel]
+
+	updateTestDeploymentSpec(t, repo, "web", func(spec *types.DeploymentSpec) {
+		spec.Template.Spec.Containers[0].Image = "nginx:1.27"
+	})
+
+	for step := 0; step < 10; step++ {
+		syncTestDeployments(t, dc, rsc)
+		removeTerminatingTestPods(t, repo)
+
+		pods := listTestPods(t, repo)
+		if len(pods) > 4 {
+			t.Fatalf("Step %d: expected at most 4 pods with maxSurge 1, got %d", step, len(pods))
+		}
+		ready := 0
+		for _, pod := range pods {
+			if isPodReady(pod) {
+				ready++
+			}
+		}
+		if ready < 3 {
+			t.Fatalf("Step %d: expected at least 3 ready pods with maxUnavailable 0, got %d", step, ready)
+		}
+
+		markTestPodsReady(t, repo)
+	}
+
+	pods := listTestPods(t, repo)
+	if len(pods) != 3 {
+		t.Fatalf("Expected 3 pods after rollout, got %d", len(pods))
+	}
+	for _, pod := range pods {
+		if pod.Metadata.Labels[types.PodTemplateHashLabel] == oldHash {
+			t.Errorf("Expected pod %s to be replaced", pod.Metadata.Name)
+		}
+		if pod.Spec.Containers[0].Image != "nginx:1.27" {
+			t.Errorf("Expected pod %s to run nginx:1.27, got %s", pod.Metadata.Name, pod.Spec.Containers[0].Image)
+		}
+	}
+
+	deployment := getTestDeployment(t, repo, "web")
+	if deployment.Status.UpdatedReplicas != 3 {
+		t.Errorf("Expected 3 updated replicas, got %d", deployment.Status.UpdatedReplicas)
+	}
+	progressing := getDeploymentCondition(deployment.Status.Conditions, "Progressing")
+	if progressing == nil || progressing.Reason != "NewReplicaSetAvailable" {
+		t.Errorf("Expected rollout to complete, got %v", progressing)
+	}
+}
+
+func TestDeploymentController_Recreate(t *testing.T) {
+	repo := NewMockRepository()
+	dc := NewDeploymentController(repo)
+	rsc := NewReplicaSetController(repo)
+
+	repo.CreateResource(createTestDeployment("web", "default", 2))
+	updateTestDeploymentSpec(t, repo, "web", func(spec *types.DeploymentSpec) {
+		spec.Strategy = types.DeploymentStrategy{Type: types.RecreateDeploymentStrategyType}
+	})
+
+	syncTestDeployments(t, dc, rsc)
+	markTestPodsReady(t, repo)
+
+	updateTestDeploymentSpec(t, repo, "web", func(spec *types.DeploymentSpec) {
+		spec.Template.Spec.Containers[0].Image = "nginx:1.27"
+	})
+
+	// All old pods are removed before any new pod is created
+	syncTestDeployments(t, dc, rsc)
+	for _, pod := range listTestPods(t, repo) {
+		if !types.IsPodTerminating(pod) {
+			t.Fatalf("Expected old pods to be deleted first, got %s", pod.Metadata.Name)
+		}
+	}
+	removeTerminatingTestPods(t, repo)
+
+	syncTestDeployments(t, dc, rsc)
+	pods := listTestPods(t, repo)
+	if len(pods) != 2 {
+		t.Fatalf("Expected 2 new pods, got %d", len(pods))
+	}
+	for _, pod := range pods {
+		if pod.Spec.Containers[0].Image != "nginx:1.27" {
+			t.Errorf("Expected pod %s to run nginx:1.27, got %s", pod.Metadata.Name, pod.Spec.Containers[0].Image)
+		}
+	}
+}
+
+func TestDeploymentController_RecreateWaitsForTerminatingPods(t *testing.T) {
+	repo := NewMockRepository()
+	dc := NewDeploymentController(repo)
+	rsc := NewReplicaSetController(repo)
+
+	repo.CreateResource(createTestDeployment("web", "default", 2))
+	updateTestDeploymentSpec(t, repo, "web", func(spec *types.DeploymentSpec) {
+		spec.Strategy = types.DeploymentStrategy{Type: types.RecreateDeploymentStrategyType}
+	})
+
+	syncTestDeployments(t, dc, rsc)
+	markTestPodsReady(t, repo)
+
+	updateTestDeploymentSpec(t, repo, "web", func(spec *types.DeploymentSpec) {
+		spec.Template.Spec.Containers[0].Image = "nginx:1.27"
+	})
+	syncTestDeployments(t, dc, rsc)
+
+	// One of the old pods has shut down, the other is still in its grace period
+	terminating := listTestPods(t, repo)
+	repo.DeleteResource("Pod", terminating[0].Metadata.Namespace, terminating[0].Metadata.Name)
+	syncTestDeployments(t, dc, rsc)
+	syncTestDeployments(t, dc, rsc)
+	pods := listTestPods(t, repo)
+	if len(pods) != 1 || pods[0].Metadata.Name != terminating[1].Metadata.Name {
+		t.Fatalf("Expected only the terminating old pod while it shuts down, got %d pods", len(pods))
+	}
+
+	// The new pods are created once it is gone
+	removeTerminatingTestPods(t, repo)
+	syncTestDeployments(t, dc, rsc)
+	if pods := listTestPods(t, repo); len(pods) != 2 {
+		t.Fatalf("Expected 2 new pods, got %d", len(pods))
+	}
+}
+
+func TestDeploymentController_ProgressDeadlineExceeded(t *testing.T) {
+	repo := NewMockRepository()
+	dc := NewDeploymentController(repo)
+	rsc := NewReplicaSetController(repo)
+
+	repo.CreateResource(createTestDeployment("web", "default", 2))
+
+	syncTestDeployments(t, dc, rsc)
+
+	// Pretend the pods have been stuck pending for longer than the deadline
+	resource, _ := repo.GetResource("Deployment", "default", "web")
+	deployment, _ := resourceToDeployment(resource)
+	progressing := getDeploymentCondition(deployment.Status.Conditions, "Progressing")
+	if progressing == nil || progressing.Status != "True" {
+		t.Fatalf("Expected Progressing condition to be True, got %v", progressing)
+	}
+	progressing.LastUpdateTime = time.Now().Add(-defaultProgressDeadline - time.Minute)
+	statusJSON, _ := json.Marshal(deployment.Status)
+	resource.Status = string(statusJSON)
+	repo.UpdateResource(resource)
+
+	syncTestDeployments(t, dc, rsc)
+
+	deployment = getTestDeployment(t, repo, "web")
+	progressing = getDeploymentCondition(deployment.Status.Conditions, "Progressing")
+	if progressing == nil || progressing.Status != "False" || progressing.Reason != "ProgressDeadlineExceeded" {
+		t.Errorf("Expected ProgressDeadlineExceeded, got %v", progressing)
+	}
+
+	// Progress resets the condition
+	markTestPodsReady(t, repo)
+	syncTestDeployments(t, dc, rsc)
+	deployment = getTestDeployment(t, repo, "web")
+	progressing = getDeploymentCondition(deployment.Status.Conditions, "Progressing")
+	if progressing == nil || progressing.Status != "True" {
+		t.Errorf("Expected Progressing condition to be True after progress, got %v", progressing)
+	}
+}
+
+func TestDeploymentController_RevisionHistory(t *testing.T) {
+	repo := NewMockRepository()
+	dc := NewDeploymentController(repo)
+	rsc := NewReplicaSetController(repo)
+
+	repo.CreateResource(createTestDeployment("web", "default", 1))
+	updateTestDeploymentSpec(t, repo, "web", func(spec *types.DeploymentSpec) {
+		limit := int32(1)
+		spec.RevisionHistoryLimit = &limit
+		spec.Strategy = types.DeploymentStrategy{Type: types.RecreateDeploymentStrategyType}
+	})
+
+	rollout := func(image string) {
+		updateTestDeploymentSpec(t, repo, "web", func(spec *types.DeploymentSpec) {
+			spec.Template.Spec.Containers[0].Image = image
+		})
+		for i := 0; i < 3; i++ {
+			syncTestDeployments(t, dc, rsc)
+		}
+	}
+
+	rollout("nginx:1.25")
+	firstHash := listTestReplicaSets(t, repo)[0].Metadata.Labels[types.PodTemplateHashLabel]
+	rollout("nginx:1.26")
+	rollout("nginx:1.27")
+
+	// Only the current replica set and one old one are kept
+	replicaSets := listTestReplicaSets(t, repo)
+	if len(replicaSets) != 2 {
+		t.Fatalf("Expected 2 replica sets with a history limit of 1, got %d", len(replicaSets))
+	}
+	revisions := make(map[string]int64)
+	for _, replicaSet := range replicaSets {
+		revisions[replicaSet.Spec.Template.Spec.Containers[0].Image] = replicaSetRevision(replicaSet)
+	}
+	if revisions["nginx:1.26"] != 2 || revisions["nginx:1.27"] != 3 {
+		t.Errorf("Expected revisions 2 and 3 to be kept, got %v", revisions)
+	}
+
+	// Returning to an earlier template reuses its replica set as the newest revision
+	rollout("nginx:1.26")
+	replicaSets = listTestReplicaSets(t, repo)
+	for _, replicaSet := range replicaSets {
+		if replicaSet.Metadata.Labels[types.PodTemplateHashLabel] == firstHash {
+			t.Errorf("Expected the first replica set to have been cleaned up")
+		}
+		image := replicaSet.Spec.Template.Spec.Containers[0].Image
+		if image == "nginx:1.26" && (replicaSetRevision(replicaSet) != 4 || replicaSet.Spec.Replicas != 1) {
+			t.Errorf("Expected nginx:1.26 to be revision 4 with 1 replica, got revision %d with %d replicas",
+				replicaSetRevision(replicaSet), replicaSet.Spec.Replicas)
+		}
+		if image == "nginx:1.27" && replicaSet.Spec.Replicas != 0 {
+			t.Errorf("Expected nginx:1.27 to be scaled down, got %d replicas", replicaSet.Spec.Replicas)
+		}
+	}
+
+	deployment := getTestDeployment(t, repo, "web")
+	if deployment.Metadata.Annotations[types.RevisionAnnotation] != "4" {
+		t.Errorf("Expected deployment revision 4, got %q", deployment.Metadata.Annotations[types.RevisionAnnotation])
+	}
+}
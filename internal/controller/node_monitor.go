@@ -67,7 +67,9 @@ func (nm *NodeMonitor) CheckNodeHealth() error {
 	return nm.checkNodeHealth()
 }
 
-// checkNodeHealth checks the health of all nodes
+// checkNodeHealth checks the health of all nodes, taints the nodes that
+// stopped sending heartbeats and evicts pods that don't tolerate the
+// NoExecute taints of their node
 func (nm *NodeMonitor) checkNodeHealth() error {
 	// Get all nodes
 	nodes, err := nm.repository.ListNodes()
@@ -77,6 +79,8 @@ func (nm *NodeMonitor) checkNodeHealth() error {
 
 	now := time.Now()
 	for _, node := range nodes {
+		changed := false
+
 		// Find the Ready condition
 		var readyCondition *types.NodeCondition
 		for i := range node.Status.Conditions {
@@ -105,125 +109,259 @@ func (nm *NodeMonitor) checkNodeHealth() error {
 			// Node is not healthy
 			if readyCondition.Status != "False" {
 				log.Printf("Node %s is not healthy (last heartbeat: %v ago)", node.Metadata.Name, timeSinceHeartbeat)
-				
+
 				// Update node status
 				readyCondition.Status = "False"
 				readyCondition.LastTransitionTime = now
 				readyCondition.Reason = "NodeNotReady"
 				readyCondition.Message = fmt.Sprintf("Node has not sent a heartbeat for %v", timeSinceHeartbeat)
-				
-				// Update node in database
-				if err := nm.repository.UpdateNode(node); err != nil {
-					log.Printf("Failed to update node status: %v", err)
-				}
-				
-				// Handle node failure
-				if err := nm.handleNodeFailure(node); err != nil {
-					log.Printf("Failed to handle node failure: %v", err)
-				}
+				changed = true
 			}
 		} else if readyCondition.Status != "True" {
 			// Node is healthy again
 			log.Printf("Node %s is healthy again", node.Metadata.Name)
-			
+
 			// Update node status
 			readyCondition.Status = "True"
 			readyCondition.LastTransitionTime = now
 			readyCondition.Reason = "NodeReady"
 			readyCondition.Message = "Node is ready"
-			
+			changed = true
+		}
+
+		// Nodes that aren't ready carry a NoExecute taint, so their pods are
+		// evicted unless they tolerate it
+		if reconcileNotReadyTaint(node, readyCondition.Status == "True", now) {
+			changed = true
+		}
+
+		if changed {
 			// Update node in database
 			if err := nm.repository.UpdateNode(node); err != nil {
 				log.Printf("Failed to update node status: %v", err)
 			}
 		}
+
+		if err := nm.evictPods(node, now); err != nil {
+			log.Printf("Failed to evict pods from node %s: %v", node.Metadata.Name, err)
+		}
 	}
 
 	return nil
 }
 
-// handleNodeFailure handles a node failure
-func (nm *NodeMonitor) handleNodeFailure(node *types.Node) error {
+// reconcileNotReadyTaint adds the not-ready NoExecute taint to a node that
+// isn't ready and removes it from one that is. NoExecute taints added
+// without a time are stamped with now, which their tolerationSeconds count
+// from. It reports whether the taints changed.
+func reconcileNotReadyTaint(node *types.Node, ready bool, now time.Time) bool {
+	changed := false
+	notReady := types.Taint{Key: types.TaintNodeNotReady, Effect: types.TaintEffectNoExecute}
+
+	var taints []types.Taint
+	found := false
+	for _, taint := range node.Spec.Taints {
+		if taint.MatchTaint(&notReady) {
+			if ready {
+				changed = true
+				continue
+			}
+			found = true
+		}
+		if taint.Effect == types.TaintEffectNoExecute && taint.TimeAdded == nil {
+			added := now
+			taint.TimeAdded = &added
+			changed = true
+		}
+		taints = append(taints, taint)
+	}
+
+	if !ready && !found {
+		added := now
+		notReady.TimeAdded = &added
+		taints = append(taints, notReady)
+		changed = true
+	}
+
+	node.Spec.Taints = taints
+	return changed
+}
+
+// evictPods evicts the pods on a node that don't tolerate its NoExecute
+// taints, or whose tolerationSeconds have run out
+func (nm *NodeMonitor) evictPods(node *types.Node, now time.Time) error {
+	var taints []types.Taint
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == types.TaintEffectNoExecute {
+			taints = append(taints, taint)
+		}
+	}
+	if len(taints) == 0 {
+		return nil
+	}
+
 	// Get pod assignments for this node
 	assignments, err := nm.repository.ListPodAssignmentsByNode(node.Metadata.UID)
 	if err != nil {
 		return err
 	}
+	assigned := make(map[string]*storage.PodAssignment, len(assignments))
+	for _, assignment := range assignments {
+		assigned[assignment.PodID] = assignment
+	}
 
-	log.Printf("Found %d pods on failed node %s", len(assignments), node.Metadata.Name)
+	resources, err := nm.repository.ListResources("Pod", "")
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
 
-	// Mark pods as failed
-	for _, assignment := range assignments {
-		// Update pod assignment status
-		if err := nm.repository.UpdatePodAssignmentStatus(assignment.PodID, "NodeFailed"); err != nil {
-			log.Printf("Failed to update pod assignment status: %v", err)
+	for _, resource := range resources {
+		var spec types.PodSpec
+		if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
+			log.Printf("Failed to unmarshal pod spec: %v", err)
+			continue
+		}
+		assignment := assigned[resource.ID]
+		if spec.NodeName != node.Metadata.Name && assignment == nil {
+			continue
+		}
+
+		var status types.PodStatus
+		if resource.Status != "" {
+			if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
+				log.Printf("Failed to unmarshal pod status: %v", err)
+				continue
+			}
+		}
+		if status.Phase == "Failed" || status.Phase == "Succeeded" {
+			continue
+		}
+
+		deadline, taint, evict := evictionDeadline(spec.Tolerations, taints, now)
+		if !evict || now.Before(deadline) {
 			continue
 		}
 
-		// Get all pods
-		resources, err := nm.repository.ListResources("Pod", "")
+		// Apply the change to the latest version of the pod, retrying
+		// if another component updates it at the same time
+		err := storage.RetryOnConflict(func() error {
+			latest, err := nm.repository.GetResource("Pod", resource.Namespace, resource.Name)
+			if err != nil {
+				return err
+			}
+			return nm.markPodFailed(latest, node.Metadata.Name, taint)
+		})
 		if err != nil {
-			log.Printf("Failed to list pods: %v", err)
+			log.Printf("Failed to update pod: %v", err)
 			continue
 		}
 
-		// Find pod with matching ID
-		for _, resource := range resources {
-			if resource.ID == assignment.PodID {
-				// Parse pod spec and status
-				var spec types.PodSpec
-				if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
-					log.Printf("Failed to unmarshal pod spec: %v", err)
-					continue
-				}
-
-				var status types.PodStatus
-				if resource.Status != "" {
-					if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
-						log.Printf("Failed to unmarshal pod status: %v", err)
-						continue
-					}
-				}
-
-				// Update pod status
-				status.Phase = "Failed"
-				status.Conditions = append(status.Conditions, types.PodCondition{
-					Type:               "NodeFailed",
-					Status:             "True",
-					LastTransitionTime: time.Now(),
-					Reason:             "NodeNotReady",
-					Message:            fmt.Sprintf("Node %s is not ready", node.Metadata.Name),
-				})
-
-				// Clear node name to allow rescheduling
-				spec.NodeName = ""
-
-				// Update pod in database
-				specJSON, err := json.Marshal(spec)
-				if err != nil {
-					log.Printf("Failed to marshal pod spec: %v", err)
-					continue
-				}
-
-				statusJSON, err := json.Marshal(status)
-				if err != nil {
-					log.Printf("Failed to marshal pod status: %v", err)
-					continue
-				}
-
-				resource.Spec = string(specJSON)
-				resource.Status = string(statusJSON)
-
-				if err := nm.repository.UpdateResource(resource); err != nil {
-					log.Printf("Failed to update pod: %v", err)
-					continue
-				}
-
-				log.Printf("Marked pod %s/%s as failed due to node failure", resource.Namespace, resource.Name)
-				break
+		// Drop the assignment so the node's agent stops running the pod
+		if assignment != nil {
+			if err := nm.repository.DeletePodAssignment(resource.ID); err != nil {
+				log.Printf("Failed to delete pod assignment: %v", err)
 			}
 		}
+
+		log.Printf("Evicted pod %s/%s from node %s due to taint %s", resource.Namespace, resource.Name, node.Metadata.Name, taint.String())
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// evictionDeadline returns when a pod with the given tolerations has to leave
+// a node with the given NoExecute taints, and the taint that forces it out.
+// A taint the pod doesn't tolerate evicts it right away; one it tolerates
+// evicts it tolerationSeconds after the taint was added, taking the shortest
+// matching toleration. evict is false if the pod tolerates every taint
+// forever.
+func evictionDeadline(tolerations []types.Toleration, taints []types.Taint, now time.Time) (deadline time.Time, taint *types.Taint, evict bool) {
+	for i := range taints {
+		current := &taints[i]
+		added := now
+		if current.TimeAdded != nil {
+			added = *current.TimeAdded
+		}
+
+		var taintDeadline time.Time
+		tolerated := false
+		forever := false
+		for _, toleration := range tolerations {
+			if !toleration.ToleratesTaint(current) {
+				continue
+			}
+			tolerated = true
+			if toleration.TolerationSeconds == nil {
+				forever = true
+				continue
+			}
+			until := added.Add(time.Duration(*toleration.TolerationSeconds) * time.Second)
+			if taintDeadline.IsZero() || until.Before(taintDeadline) {
+				taintDeadline = until
+			}
+		}
+
+		switch {
+		case !tolerated:
+			taintDeadline = now
+		case taintDeadline.IsZero() && forever:
+			continue
+		}
+
+		if !evict || taintDeadline.Before(deadline) {
+			deadline, taint, evict = taintDeadline, current, true
+		}
+	}
+	return deadline, taint, evict
+}
+
+// markPodFailed marks a pod evicted from a node as failed and clears its node so it can be rescheduled
+func (nm *NodeMonitor) markPodFailed(resource storage.Resource, nodeName string, taint *types.Taint) error {
+	// Parse pod spec and status
+	var spec types.PodSpec
+	if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
+		return fmt.Errorf("failed to unmarshal pod spec: %w", err)
+	}
+
+	var status types.PodStatus
+	if resource.Status != "" {
+		if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
+			return fmt.Errorf("failed to unmarshal pod status: %w", err)
+		}
+	}
+
+	// Update pod status
+	status.Phase = "Failed"
+	condition := types.PodCondition{
+		Type:               "NodeFailed",
+		Status:             "True",
+		LastTransitionTime: time.Now(),
+		Reason:             "NodeNotReady",
+		Message:            fmt.Sprintf("Node %s is not ready", nodeName),
+	}
+	if taint.Key != types.TaintNodeNotReady {
+		condition.Type = "DisruptionTarget"
+		condition.Reason = "NoExecuteTaint"
+		condition.Message = fmt.Sprintf("Evicted from node %s by taint %s", nodeName, taint.String())
+	}
+	status.Conditions = append(status.Conditions, condition)
+
+	// Clear node name to allow rescheduling
+	spec.NodeName = ""
+
+	// Update pod in database
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pod spec: %w", err)
+	}
+
+	statusJSON, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pod status: %w", err)
+	}
+
+	resource.Spec = string(specJSON)
+	resource.Status = string(statusJSON)
+
+	return nm.repository.UpdateResource(resource)
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
@@ -166,6 +167,36 @@ func (r *MockRepository) ListPodAssignmentsByNode(nodeID string) ([]*storage.Pod
 	return assignments, nil
 }
 
+func (r *MockRepository) ListResourcesPage(kind, namespace string, options storage.ListOptions) (*storage.ResourcePage, error) {
+	resources, err := r.ListResources(kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &storage.ResourcePage{Resources: resources}, nil
+}
+
+func (r *MockRepository) ListNodesPage(options storage.ListOptions) (*storage.NodePage, error) {
+	nodes, err := r.ListNodes()
+	if err != nil {
+		return nil, err
+	}
+	return &storage.NodePage{Nodes: nodes}, nil
+}
+
+func (r *MockRepository) CurrentRevision() (int64, error) {
+	return 0, nil
+}
+
+// Watch never reports changes; tests drive reconciliation directly
+func (r *MockRepository) Watch(ctx context.Context, kind, namespace string, revision int64) (<-chan storage.WatchEvent, error) {
+	events := make(chan storage.WatchEvent)
+	go func() {
+		<-ctx.Done()
+		close(events)
+	}()
+	return events, nil
+}
+
 func TestNodeMonitor(t *testing.T) {
 	// Create mock repository
 	repo := NewMockRepository()
@@ -339,6 +370,11 @@ func TestNodeMonitor(t *testing.T) {
 	if nodeFailedCondition.Status != "True" {
 		t.Errorf("Expected NodeFailed condition to be True, got %s", nodeFailedCondition.Status)
 	}
+	
+	// The evicted pod no longer belongs to the node
+	if assignment, _ := repo.GetPodAssignment(pod.Metadata.UID); assignment != nil {
+		t.Errorf("Expected the pod assignment to be deleted, got %+v", assignment)
+	}
 }
 
 // Helper function to get node condition by type
@@ -418,4 +454,139 @@ func TestNodeMonitorWithNoReadyCondition(t *testing.T) {
 	if readyCondition.Status != "True" {
 		t.Errorf("Expected Ready condition status to be True (newly added), got %s", readyCondition.Status)
 	}
-}
\ No newline at end of file
+}
+// createTaintTestPod stores a running pod bound to a node
+func createTaintTestPod(repo *MockRepository, name, nodeName string, tolerations []types.Toleration) {
+	spec, _ := json.Marshal(types.PodSpec{
+		Containers:  []types.Container{{Name: "nginx", Image: "nginx:latest"}},
+		NodeName:    nodeName,
+		Tolerations: tolerations,
+	})
+	status, _ := json.Marshal(types.PodStatus{Phase: "Running"})
+
+	repo.CreateResource(storage.Resource{
+		ID:        name + "-uid",
+		Kind:      "Pod",
+		Namespace: "default",
+		Name:      name,
+		Spec:      string(spec),
+		Status:    string(status),
+	})
+}
+
+func getPodStatus(t *testing.T, repo *MockRepository, name string) types.PodStatus {
+	t.Helper()
+
+	resource, err := repo.GetResource("Pod", "default", name)
+	if err != nil {
+		t.Fatalf("Failed to get pod %s: %v", name, err)
+	}
+	var status types.PodStatus
+	if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
+		t.Fatalf("Failed to unmarshal pod status: %v", err)
+	}
+	return status
+}
+
+func TestNodeMonitorNoExecuteTaint(t *testing.T) {
+	repo := NewMockRepository()
+	monitor := NewNodeMonitor(repo, 1*time.Minute)
+
+	seconds := func(value int64) *int64 { return &value }
+	added := time.Now().Add(-2 * time.Minute)
+	node := &types.Node{
+		Metadata: types.ObjectMeta{Name: "tainted-node", UID: "tainted-node-uid"},
+		Spec: types.NodeSpec{
+			Taints: []types.Taint{{Key: "maintenance", Effect: types.TaintEffectNoExecute, TimeAdded: &added}},
+		},
+		Status: types.NodeStatus{
+			Conditions: []types.NodeCondition{{Type: "Ready", Status: "True", LastHeartbeatTime: time.Now()}},
+		},
+	}
+	repo.CreateNode(node)
+
+	createTaintTestPod(repo, "intolerant", "tainted-node", nil)
+	createTaintTestPod(repo, "expired", "tainted-node", []types.Toleration{
+		{Key: "maintenance", Operator: types.TolerationOpExists, Effect: types.TaintEffectNoExecute, TolerationSeconds: seconds(60)},
+	})
+	createTaintTestPod(repo, "waiting", "tainted-node", []types.Toleration{
+		{Key: "maintenance", Operator: types.TolerationOpExists, Effect: types.TaintEffectNoExecute, TolerationSeconds: seconds(3600)},
+	})
+	createTaintTestPod(repo, "forever", "tainted-node", []types.Toleration{
+		{Key: "maintenance", Operator: types.TolerationOpExists},
+	})
+	createTaintTestPod(repo, "elsewhere", "other-node", nil)
+
+	if err := monitor.checkNodeHealth(); err != nil {
+		t.Fatalf("Failed to check node health: %v", err)
+	}
+
+	expected := map[string]string{
+		"intolerant": "Failed",
+		"expired":    "Failed",
+		"waiting":    "Running",
+		"forever":    "Running",
+		"elsewhere":  "Running",
+	}
+	for name, phase := range expected {
+		status := getPodStatus(t, repo, name)
+		if status.Phase != phase {
+			t.Errorf("Expected pod %s to be %s, got %s", name, phase, status.Phase)
+		}
+		if phase == "Failed" && getPodCondition(status, "DisruptionTarget") == nil {
+			t.Errorf("Expected pod %s to have a DisruptionTarget condition", name)
+		}
+	}
+}
+
+func TestNodeMonitorNotReadyTaint(t *testing.T) {
+	repo := NewMockRepository()
+	heartbeatTimeout := 1 * time.Minute
+	monitor := NewNodeMonitor(repo, heartbeatTimeout)
+
+	node := &types.Node{
+		Metadata: types.ObjectMeta{Name: "flaky-node", UID: "flaky-node-uid"},
+		Status: types.NodeStatus{
+			Conditions: []types.NodeCondition{{Type: "Ready", Status: "True", LastHeartbeatTime: time.Now().Add(-2 * heartbeatTimeout)}},
+		},
+	}
+	repo.CreateNode(node)
+
+	// Tolerates the not-ready taint for five minutes
+	seconds := int64(300)
+	createTaintTestPod(repo, "patient", "flaky-node", []types.Toleration{
+		{Key: types.TaintNodeNotReady, Operator: types.TolerationOpExists, Effect: types.TaintEffectNoExecute, TolerationSeconds: &seconds},
+	})
+
+	if err := monitor.checkNodeHealth(); err != nil {
+		t.Fatalf("Failed to check node health: %v", err)
+	}
+
+	updated, _ := repo.GetNode("flaky-node")
+	if len(updated.Spec.Taints) != 1 || updated.Spec.Taints[0].Key != types.TaintNodeNotReady || updated.Spec.Taints[0].TimeAdded == nil {
+		t.Fatalf("Expected a timestamped not-ready taint, got %+v", updated.Spec.Taints)
+	}
+	if status := getPodStatus(t, repo, "patient"); status.Phase != "Running" {
+		t.Errorf("Expected the tolerating pod to keep running, got %s", status.Phase)
+	}
+
+	// Once the toleration runs out the pod is evicted
+	past := time.Now().Add(-10 * time.Minute)
+	updated.Spec.Taints[0].TimeAdded = &past
+	if err := monitor.checkNodeHealth(); err != nil {
+		t.Fatalf("Failed to check node health: %v", err)
+	}
+	status := getPodStatus(t, repo, "patient")
+	if status.Phase != "Failed" || getPodCondition(status, "NodeFailed") == nil {
+		t.Errorf("Expected the pod to be evicted with a NodeFailed condition, got %s", status.Phase)
+	}
+
+	// The taint is removed when the node recovers
+	updated.Status.Conditions[0].LastHeartbeatTime = time.Now()
+	if err := monitor.checkNodeHealth(); err != nil {
+		t.Fatalf("Failed to check node health: %v", err)
+	}
+	if recovered, _ := repo.GetNode("flaky-node"); len(recovered.Spec.Taints) != 0 {
+		t.Errorf("Expected the not-ready taint to be removed, got %+v", recovered.Spec.Taints)
+	}
+}
@@ -0,0 +1,449 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// ReplicaSetController creates and deletes pods so that every ReplicaSet
+// runs the number of replicas described by its pod template
+type ReplicaSetController struct {
+	repository storage.Repository
+	stopCh     chan struct{}
+	wg         sync.WaitGroup
+}
+
+// NewReplicaSetController creates a new replica set controller
+func NewReplicaSetController(repository storage.Repository) *ReplicaSetController {
+	return &ReplicaSetController{
+		repository: repository,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start starts the replica set controller
+func (rc *ReplicaSetController) Start() {
+	log.Println("Starting replica set controller")
+	rc.wg.Add(1)
+	go rc.run()
+}
+
+// Stop stops the replica set controller
+func (rc *ReplicaSetController) Stop() {
+	log.Println("Stopping replica set controller")
+	close(rc.stopCh)
+	rc.wg.Wait()
+}
+
+// run is the main controller loop
+func (rc *ReplicaSetController) run() {
+	defer rc.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Replica set and pod changes trigger reconciliation; the ticker is only a resync
+	changes := storage.NotifyChanges(ctx, rc.repository, "ReplicaSet", "Pod")
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-changes:
+			if err := rc.reconcileReplicaSets(); err != nil {
+				log.Printf("Error reconciling replica sets: %v", err)
+			}
+		case <-ticker.C:
+			if err := rc.reconcileReplicaSets(); err != nil {
+				log.Printf("Error reconciling replica sets: %v", err)
+			}
+		case <-rc.stopCh:
+			log.Println("Replica set controller stopped")
+			return
+		}
+	}
+}
+
+// ReconcileReplicaSets reconciles all replica sets (public for testing)
+func (rc *ReplicaSetController) ReconcileReplicaSets() error {
+	return rc.reconcileReplicaSets()
+}
+
+// reconcileReplicaSets reconciles every replica set with the pods it owns
+func (rc *ReplicaSetController) reconcileReplicaSets() error {
+	// Get all replica sets
+	replicaSets, err := rc.repository.ListResources("ReplicaSet", "")
+	if err != nil {
+		return fmt.Errorf("failed to list replica sets: %w", err)
+	}
+
+	// Get all pods
+	pods, err := listPods(rc.repository)
+	if err != nil {
+		return err
+	}
+
+	// Reconcile each replica set
+	existing := make(map[string]bool)
+	for _, replicaSetResource := range replicaSets {
+		existing[replicaSetResource.ID] = true
+		if err := rc.reconcileReplicaSet(replicaSetResource, pods); err != nil {
+			log.Printf("Failed to reconcile replica set %s/%s: %v",
+				replicaSetResource.Namespace, replicaSetResource.Name, err)
+		}
+	}
+
+	// Remove pods whose replica set has been deleted
+	for _, pod := range pods {
+		owner := controllerOf(&pod.Metadata)
+		if owner == nil || owner.Kind != "ReplicaSet" || existing[owner.UID] || types.IsPodTerminating(pod) {
+			continue
+		}
+		log.Printf("Deleting pod %s/%s owned by deleted replica set %s",
+			pod.Metadata.Namespace, pod.Metadata.Name, owner.Name)
+		if _, err := storage.DeletePodGracefully(rc.repository, pod.Metadata.Namespace, pod.Metadata.Name, nil); err != nil {
+			log.Printf("Failed to delete orphaned pod %s/%s: %v", pod.Metadata.Namespace, pod.Metadata.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// reconcileReplicaSet scales the pods of a single replica set and updates its status
+func (rc *ReplicaSetController) reconcileReplicaSet(replicaSetResource storage.Resource, pods []*types.Pod) error {
+	replicaSet, err := resourceToReplicaSet(replicaSetResource)
+	if err != nil {
+		return err
+	}
+
+	selector := &replicaSet.Spec.Selector
+	if selector.Empty() {
+		return fmt.Errorf("replica set has an empty selector")
+	}
+
+	// Collect the pods this replica set owns, replacing the ones that have finished
+	var active []*types.Pod
+	for _, pod := range pods {
+		// Pods being deleted are already on their way out, so they are
+		// replaced rather than counted
+		if !isControlledBy(pod, &replicaSet.Metadata, selector) || types.IsPodTerminating(pod) {
+			continue
+		}
+		if pod.Status.Phase == "Failed" || pod.Status.Phase == "Succeeded" {
+			log.Printf("Deleting %s pod %s/%s of replica set %s",
+				pod.Status.Phase, pod.Metadata.Namespace, pod.Metadata.Name, replicaSet.Metadata.Name)
+			if _, err := storage.DeletePodGracefully(rc.repository, pod.Metadata.Namespace, pod.Metadata.Name, nil); err != nil {
+				log.Printf("Failed to delete pod %s/%s: %v", pod.Metadata.Namespace, pod.Metadata.Name, err)
+				active = append(active, pod)
+			}
+			continue
+		}
+		active = append(active, pod)
+	}
+
+	// Scale up or down towards the desired replica count
+	diff := int(replicaSet.Spec.Replicas) - len(active)
+	if diff > 0 {
+		log.Printf("Creating %d pods for replica set %s/%s", diff, replicaSet.Metadata.Namespace, replicaSet.Metadata.Name)
+		for i := 0; i < diff; i++ {
+			pod, err := rc.createPod(replicaSet)
+			if err != nil {
+				log.Printf("Failed to create pod for replica set %s/%s: %v",
+					replicaSet.Metadata.Namespace, replicaSet.Metadata.Name, err)
+				continue
+			}
+			active = append(active, pod)
+		}
+	} else if diff < 0 {
+		log.Printf("Deleting %d pods of replica set %s/%s", -diff, replicaSet.Metadata.Namespace, replicaSet.Metadata.Name)
+		sortPodsForDeletion(active)
+		var remaining []*types.Pod
+		for i, pod := range active {
+			if i < -diff {
+				_, err := storage.DeletePodGracefully(rc.repository, pod.Metadata.Namespace, pod.Metadata.Name, nil)
+				if err == nil {
+					continue
+				}
+				log.Printf("Failed to delete pod %s/%s: %v", pod.Metadata.Namespace, pod.Metadata.Name, err)
+			}
+			remaining = append(remaining, pod)
+		}
+		active = remaining
+	}
+
+	return rc.updateReplicaSetStatus(replicaSetResource, replicaSet, active)
+}
+
+// createPod creates a new pod from the replica set's pod template
+func (rc *ReplicaSetController) createPod(replicaSet *types.ReplicaSet) (*types.Pod, error) {
+	now := time.Now()
+
+	labels := make(map[string]string)
+	for key, value := range replicaSet.Spec.Template.Metadata.Labels {
+		labels[key] = value
+	}
+
+	pod := &types.Pod{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Metadata: types.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", replicaSet.Metadata.Name, uuid.New().String()[:5]),
+			Namespace: replicaSet.Metadata.Namespace,
+			Labels:    labels,
+			UID:       uuid.New().String(),
+			OwnerReferences: []types.OwnerReference{
+				{
+					APIVersion: "apps/v1",
+					Kind:       "ReplicaSet",
+					Name:       replicaSet.Metadata.Name,
+					UID:        replicaSet.Metadata.UID,
+					Controller: true,
+				},
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		Spec: replicaSet.Spec.Template.Spec,
+		Status: types.PodStatus{
+			Phase: "Pending",
+			Conditions: []types.PodCondition{
+				{
+					Type:               "PodScheduled",
+					Status:             "False",
+					LastTransitionTime: now,
+					Reason:             "Unschedulable",
+					Message:            "Pod is waiting to be scheduled",
+				},
+			},
+		},
+	}
+
+	resource, err := podToResource(pod)
+	if err != nil {
+		return nil, err
+	}
+	resource.CreatedAt = now
+	resource.UpdatedAt = now
+
+	if err := rc.repository.CreateResource(resource); err != nil {
+		return nil, fmt.Errorf("failed to create pod: %w", err)
+	}
+
+	return pod, nil
+}
+
+// updateReplicaSetStatus writes the observed replica counts back to the replica set
+func (rc *ReplicaSetController) updateReplicaSetStatus(replicaSetResource storage.Resource, replicaSet *types.ReplicaSet, pods []*types.Pod) error {
+	ready := int32(countReadyPods(pods))
+
+	status := replicaSet.Status
+	status.Replicas = int32(len(pods))
+	status.ReadyReplicas = ready
+	status.AvailableReplicas = ready
+
+	statusJSON, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal replica set status: %w", err)
+	}
+
+	// Avoid rewriting the replica set when nothing changed
+	if string(statusJSON) == replicaSetResource.Status {
+		return nil
+	}
+
+	if err := updateStatus(rc.repository, replicaSetResource, string(statusJSON)); err != nil {
+		return fmt.Errorf("failed to update replica set status: %w", err)
+	}
+
+	return nil
+}
+
+// listPods lists and decodes every pod in the cluster, skipping the ones that can't be decoded
+func listPods(repository storage.Repository) ([]*types.Pod, error) {
+	podResources, err := repository.ListResources("Pod", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	pods := make([]*types.Pod, 0, len(podResources))
+	for _, podResource := range podResources {
+		pod, err := resourceToPod(podResource)
+		if err != nil {
+			log.Printf("Failed to decode pod %s/%s: %v", podResource.Namespace, podResource.Name, err)
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+// updateResource writes a resource and refreshes its resource version, so that
+// the caller can keep updating its copy. ErrConflict is returned if the copy
+// is stale, either before the write or because another write followed it.
+func updateResource(repository storage.Repository, resource *storage.Resource) error {
+	if err := repository.UpdateResource(*resource); err != nil {
+		return err
+	}
+
+	stored, err := repository.GetResource(resource.Kind, resource.Namespace, resource.Name)
+	if err != nil {
+		return err
+	}
+	if stored.Spec != resource.Spec || stored.Status != resource.Status {
+		return fmt.Errorf("%w: %s/%s/%s", storage.ErrConflict, resource.Kind, resource.Namespace, resource.Name)
+	}
+
+	*resource = stored
+	return nil
+}
+
+// updateStatus writes the status of a resource. Controllers own the status of
+// the resources they manage, so when the resource was modified since it was
+// read the status is written to its latest version instead.
+func updateStatus(repository storage.Repository, resource storage.Resource, status string) error {
+	resource.Status = status
+	return storage.RetryOnConflict(func() error {
+		err := repository.UpdateResource(resource)
+		if errors.Is(err, storage.ErrConflict) {
+			latest, getErr := repository.GetResource(resource.Kind, resource.Namespace, resource.Name)
+			if getErr != nil {
+				return getErr
+			}
+			latest.Status = status
+			resource = latest
+		}
+		return err
+	})
+}
+
+// isControlledBy checks if a pod is controlled by the owner and still matches its selector
+func isControlledBy(pod *types.Pod, owner *types.ObjectMeta, selector *types.LabelSelector) bool {
+	if pod.Metadata.Namespace != owner.Namespace {
+		return false
+	}
+	ref := controllerOf(&pod.Metadata)
+	if ref == nil || ref.UID != owner.UID {
+		return false
+	}
+	return selector.Matches(pod.Metadata.Labels)
+}
+
+// controllerOf returns the controlling owner reference of an object, if any
+func controllerOf(meta *types.ObjectMeta) *types.OwnerReference {
+	for i := range meta.OwnerReferences {
+		if meta.OwnerReferences[i].Controller {
+			return &meta.OwnerReferences[i]
+		}
+	}
+	return nil
+}
+
+// countReadyPods returns the number of ready pods
+func countReadyPods(pods []*types.Pod) int {
+	count := 0
+	for _, pod := range pods {
+		if isPodReady(pod) {
+			count++
+		}
+	}
+	return count
+}
+
+// sortPodsForDeletion orders pods so the cheapest ones to lose come first:
+// unscheduled before scheduled, pending before running, not ready before ready,
+// and newer before older
+func sortPodsForDeletion(pods []*types.Pod) {
+	rank := func(pod *types.Pod) int {
+		switch {
+		case pod.Spec.NodeName == "":
+			return 0
+		case pod.Status.Phase != "Running":
+			return 1
+		case !isPodReady(pod):
+			return 2
+		default:
+			return 3
+		}
+	}
+
+	sort.SliceStable(pods, func(i, j int) bool {
+		ri, rj := rank(pods[i]), rank(pods[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return pods[i].Metadata.CreatedAt.After(pods[j].Metadata.CreatedAt)
+	})
+}
+
+// resourceToReplicaSet converts a storage resource to a ReplicaSet
+func resourceToReplicaSet(resource storage.Resource) (*types.ReplicaSet, error) {
+	var metadata types.ObjectMeta
+	if resource.Metadata != "" {
+		if err := json.Unmarshal([]byte(resource.Metadata), &metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal replica set metadata: %w", err)
+		}
+	}
+	metadata.Name = resource.Name
+	metadata.Namespace = resource.Namespace
+	metadata.UID = resource.ID
+
+	var spec types.ReplicaSetSpec
+	if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal replica set spec: %w", err)
+	}
+
+	var status types.ReplicaSetStatus
+	if resource.Status != "" {
+		if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal replica set status: %w", err)
+		}
+	}
+
+	return &types.ReplicaSet{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Metadata:   metadata,
+		Spec:       spec,
+		Status:     status,
+	}, nil
+}
+
+// replicaSetToResource converts a ReplicaSet to a storage resource
+func replicaSetToResource(replicaSet *types.ReplicaSet) (storage.Resource, error) {
+	metadataJSON, err := json.Marshal(replicaSet.Metadata)
+	if err != nil {
+		return storage.Resource{}, fmt.Errorf("failed to marshal replica set metadata: %w", err)
+	}
+
+	specJSON, err := json.Marshal(replicaSet.Spec)
+	if err != nil {
+		return storage.Resource{}, fmt.Errorf("failed to marshal replica set spec: %w", err)
+	}
+
+	statusJSON, err := json.Marshal(replicaSet.Status)
+	if err != nil {
+		return storage.Resource{}, fmt.Errorf("failed to marshal replica set status: %w", err)
+	}
+
+	return storage.Resource{
+		ID:        replicaSet.Metadata.UID,
+		Kind:      "ReplicaSet",
+		Namespace: replicaSet.Metadata.Namespace,
+		Name:      replicaSet.Metadata.Name,
+		Metadata:  string(metadataJSON),
+		Spec:      string(specJSON),
+		Status:    string(statusJSON),
+		CreatedAt: replicaSet.Metadata.CreatedAt,
+		UpdatedAt: replicaSet.Metadata.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+func createTestReplicaSet(name, namespace string, replicas int32) storage.Resource {
+	labels := map[string]string{"app": name}
+
+	metadata := types.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+		UID:       "replicaset-" + name,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	spec := types.ReplicaSetSpec{
+		Replicas: replicas,
+		Selector: types.LabelSelector{MatchLabels: labels},
+		Template: types.PodTemplateSpec{
+			Metadata: types.ObjectMeta{Labels: labels},
+			Spec: types.PodSpec{
+				Containers: []types.Container{
+					{
+						Name:  "web",
+						Image: "nginx:latest",
+					},
+				},
+			},
+		},
+	}
+
+	metadataJSON, _ := json.Marshal(metadata)
+	specJSON, _ := json.Marshal(spec)
+	statusJSON, _ := json.Marshal(types.ReplicaSetStatus{})
+
+	return storage.Resource{
+		ID:        metadata.UID,
+		Kind:      "ReplicaSet",
+		Namespace: namespace,
+		Name:      name,
+		Metadata:  string(metadataJSON),
+		Spec:      string(specJSON),
+		Status:    string(statusJSON),
+		CreatedAt: metadata.CreatedAt,
+		UpdatedAt: metadata.UpdatedAt,
+	}
+}
+
+func TestReplicaSetController_Scale(t *testing.T) {
+	repo := NewMockRepository()
+	rsc := NewReplicaSetController(repo)
+
+	replicaSetResource := createTestReplicaSet("web", "default", 3)
+	repo.CreateResource(replicaSetResource)
+
+	if err := rsc.reconcileReplicaSets(); err != nil {
+		t.Fatalf("Failed to reconcile replica sets: %v", err)
+	}
+
+	pods := listTestPods(t, repo)
+	if len(pods) != 3 {
+		t.Fatalf("Expected 3 pods, got %d", len(pods))
+	}
+	for _, pod := range pods {
+		owner := controllerOf(&pod.Metadata)
+		if owner == nil || owner.Kind != "ReplicaSet" || owner.UID != "replicaset-web" {
+			t.Errorf("Expected pod %s to be owned by replicaset-web, got %v", pod.Metadata.Name, owner)
+		}
+	}
+
+	// Keep the ready pod when scaling down
+	readyPod := pods[0]
+	readyPod.Spec.NodeName = "node-1"
+	setTestPodStatus(t, repo, readyPod, types.PodStatus{
+		Phase:      "Running",
+		PodIP:      "10.0.0.1",
+		Conditions: []types.PodCondition{{Type: "Ready", Status: "True"}},
+	})
+
+	var spec types.ReplicaSetSpec
+	json.Unmarshal([]byte(replicaSetResource.Spec), &spec)
+	spec.Replicas = 1
+	specJSON, _ := json.Marshal(spec)
+	replicaSetResource.Spec = string(specJSON)
+	repo.UpdateResource(replicaSetResource)
+
+	if err := rsc.reconcileReplicaSets(); err != nil {
+		t.Fatalf("Failed to reconcile replica sets: %v", err)
+	}
+
+	pods = listTestPods(t, repo)
+	if len(pods) != 1 || pods[0].Metadata.Name != readyPod.Metadata.Name {
+		t.Fatalf("Expected only the ready pod to remain, got %d pods", len(pods))
+	}
+
+	resource, _ := repo.GetResource("ReplicaSet", "default", "web")
+	replicaSet, err := resourceToReplicaSet(resource)
+	if err != nil {
+		t.Fatalf("Failed to decode replica set: %v", err)
+	}
+	if replicaSet.Status.Replicas != 1 || replicaSet.Status.ReadyReplicas != 1 {
+		t.Errorf("Expected 1 replica and 1 ready replica, got %d/%d",
+			replicaSet.Status.Replicas, replicaSet.Status.ReadyReplicas)
+	}
+}
+
+func TestReplicaSetController_DeletesBoundPodsGracefully(t *testing.T) {
+	repo := NewMockRepository()
+	rsc := NewReplicaSetController(repo)
+
+	replicaSetResource := createTestReplicaSet("web", "default", 1)
+	repo.CreateResource(replicaSetResource)
+
+	if err := rsc.reconcileReplicaSets(); err != nil {
+		t.Fatalf("Failed to reconcile replica sets: %v", err)
+	}
+	pod := listTestPods(t, repo)[0]
+	pod.Spec.NodeName = "node-1"
+	setTestPodStatus(t, repo, pod, types.PodStatus{Phase: "Running"})
+
+	var spec types.ReplicaSetSpec
+	json.Unmarshal([]byte(replicaSetResource.Spec), &spec)
+	spec.Replicas = 0
+	specJSON, _ := json.Marshal(spec)
+	replicaSetResource.Spec = string(specJSON)
+	repo.UpdateResource(replicaSetResource)
+
+	// The pod's agent has to shut its containers down before it goes away
+	if err := rsc.reconcileReplicaSets(); err != nil {
+		t.Fatalf("Failed to reconcile replica sets: %v", err)
+	}
+	pods := listTestPods(t, repo)
+	if len(pods) != 1 || !types.IsPodTerminating(pods[0]) || pods[0].Status.Phase != types.PodPhaseTerminating {
+		t.Fatalf("Expected the bound pod to be terminating, got %d pods", len(pods))
+	}
+	if *pods[0].Metadata.DeletionGracePeriodSeconds != types.DefaultTerminationGracePeriodSeconds {
+		t.Errorf("Expected the default grace period, got %d", *pods[0].Metadata.DeletionGracePeriodSeconds)
+	}
+}
+
+func TestReplicaSetController_DeletesOrphanedPods(t *testing.T) {
+	repo := NewMockRepository()
+	rsc := NewReplicaSetController(repo)
+
+	repo.CreateResource(createTestReplicaSet("web", "default", 2))
+
+	if err := rsc.reconcileReplicaSets(); err != nil {
+		t.Fatalf("Failed to reconcile replica sets: %v", err)
+	}
+
+	// Unrelated pods must be left alone
+	repo.CreateResource(createTestPod("standalone", "default", map[string]string{"app": "web"}, true, "10.0.0.9"))
+
+	repo.DeleteResource("ReplicaSet", "default", "web")
+
+	if err := rsc.reconcileReplicaSets(); err != nil {
+		t.Fatalf("Failed to reconcile replica sets: %v", err)
+	}
+
+	pods := listTestPods(t, repo)
+	if len(pods) != 1 || pods[0].Metadata.Name != "standalone" {
+		t.Errorf("Expected only the standalone pod to remain, got %d pods", len(pods))
+	}
+}
+
+func TestSortPodsForDeletion(t *testing.T) {
+	now := time.Now()
+	ready := &types.Pod{
+		Metadata: types.ObjectMeta{Name: "ready", CreatedAt: now.Add(-time.Hour)},
+		Spec:     types.PodSpec{NodeName: "node-1"},
+		Status: types.PodStatus{
+			Phase:      "Running",
+			PodIP:      "10.0.0.1",
+			Conditions: []types.PodCondition{{Type: "Ready", Status: "True"}},
+		},
+	}
+	running := &types.Pod{
+		Metadata: types.ObjectMeta{Name: "running", CreatedAt: now},
+		Spec:     types.PodSpec{NodeName: "node-1"},
+		Status:   types.PodStatus{Phase: "Running"},
+	}
+	scheduled := &types.Pod{
+		Metadata: types.ObjectMeta{Name: "scheduled", CreatedAt: now},
+		Spec:     types.PodSpec{NodeName: "node-1"},
+		Status:   types.PodStatus{Phase: "Scheduled"},
+	}
+	unscheduled := &types.Pod{
+		Metadata: types.ObjectMeta{Name: "unscheduled", CreatedAt: now.Add(-2 * time.Hour)},
+		Status:   types.PodStatus{Phase: "Pending"},
+	}
+
+	pods := []*types.Pod{ready, running, scheduled, unscheduled}
+	sortPodsForDeletion(pods)
+
+	expected := []string{"unscheduled", "scheduled", "running", "ready"}
+	for i, name := range expected {
+		if pods[i].Metadata.Name != name {
+			t.Errorf("Expected pod %d to be %s, got %s", i, name, pods[i].Metadata.Name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -44,11 +45,20 @@ func (sc *ServiceController) Stop() {
 func (sc *ServiceController) run() {
 	defer sc.wg.Done()
 
-	ticker := time.NewTicker(10 * time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Service and pod changes trigger reconciliation; the ticker is only a resync
+	changes := storage.NotifyChanges(ctx, sc.repository, "Service", "Pod")
+	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
+		case <-changes:
+			if err := sc.reconcileServices(); err != nil {
+				log.Printf("Error reconciling services: %v", err)
+			}
 		case <-ticker.C:
 			if err := sc.reconcileServices(); err != nil {
 				log.Printf("Error reconciling services: %v", err)
@@ -107,7 +117,7 @@ func (sc *ServiceController) reconcileService(serviceResource storage.Resource,
 	}
 
 	// Find matching pods based on label selector
-	matchingPods, err := sc.findMatchingPods(serviceSpec.Selector, pods, serviceResource.Namespace)
+	matchingPods, err := sc.findMatchingPods(serviceSpec.LabelSelector(), pods, serviceResource.Namespace)
 	if err != nil {
 		return fmt.Errorf("failed to find matching pods: %w", err)
 	}
@@ -133,8 +143,7 @@ func (sc *ServiceController) reconcileService(serviceResource storage.Resource,
 		}
 
 		// Update service in database
-		serviceResource.Status = string(statusJSON)
-		if err := sc.repository.UpdateResource(serviceResource); err != nil {
+		if err := updateStatus(sc.repository, serviceResource, string(statusJSON)); err != nil {
 			return fmt.Errorf("failed to update service: %w", err)
 		}
 	}
@@ -143,7 +152,7 @@ func (sc *ServiceController) reconcileService(serviceResource storage.Resource,
 }
 
 // findMatchingPods finds pods that match the service selector
-func (sc *ServiceController) findMatchingPods(selector map[string]string, pods []storage.Resource, namespace string) ([]storage.Resource, error) {
+func (sc *ServiceController) findMatchingPods(selector types.LabelSelector, pods []storage.Resource, namespace string) ([]storage.Resource, error) {
 	var matchingPods []storage.Resource
 
 	for _, podResource := range pods {
@@ -195,22 +204,27 @@ func (sc *ServiceController) findMatchingPods(selector map[string]string, pods [
 }
 
 // matchesSelector checks if pod labels match the service selector
-func (sc *ServiceController) matchesSelector(podLabels, selector map[string]string) bool {
-	if len(selector) == 0 {
+func (sc *ServiceController) matchesSelector(podLabels map[string]string, selector types.LabelSelector) bool {
+	if selector.Empty() {
 		return false // Empty selector matches nothing
 	}
 
-	for key, value := range selector {
-		if podLabels[key] != value {
-			return false
-		}
-	}
-
-	return true
+	return selector.Matches(podLabels)
 }
 
 // isPodReady checks if a pod is ready to receive traffic
 func (sc *ServiceController) isPodReady(pod *types.Pod) bool {
+	return isPodReady(pod)
+}
+
+// isPodReady checks if a pod is running, has an IP and reports itself ready
+func isPodReady(pod *types.Pod) bool {
+	// A pod being deleted stops receiving traffic right away, whatever its
+	// node agent last reported
+	if types.IsPodTerminating(pod) {
+		return false
+	}
+
 	// Check if pod is in Running phase
 	if pod.Status.Phase != "Running" {
 		return false
@@ -157,7 +157,7 @@ func TestServiceController_matchesSelector(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := sc.matchesSelector(tt.podLabels, tt.selector)
+			result := sc.matchesSelector(tt.podLabels, types.LabelSelector{MatchLabels: tt.selector})
 			if result != tt.shouldMatch {
 				t.Errorf("matchesSelector() = %v, want %v", result, tt.shouldMatch)
 			}
@@ -167,6 +167,7 @@ func TestServiceController_matchesSelector(t *testing.T) {
 
 func TestServiceController_isPodReady(t *testing.T) {
 	sc := NewServiceController(NewMockRepository())
+	deletedAt := time.Now()
 
 	tests := []struct {
 		name      string
@@ -232,6 +233,20 @@ func TestServiceController_isPodReady(t *testing.T) {
 			},
 			shouldBeReady: false,
 		},
+		{
+			name: "terminating pod",
+			pod: &types.Pod{
+				Metadata: types.ObjectMeta{DeletionTimestamp: &deletedAt},
+				Status: types.PodStatus{
+					Phase: "Running",
+					PodIP: "10.0.0.1",
+					Conditions: []types.PodCondition{
+						{Type: "Ready", Status: "True"},
+					},
+				},
+			},
+			shouldBeReady: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -296,7 +311,7 @@ func TestServiceController_findMatchingPods(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			matches, err := sc.findMatchingPods(tt.selector, pods, tt.namespace)
+			matches, err := sc.findMatchingPods(types.LabelSelector{MatchLabels: tt.selector}, pods, tt.namespace)
 			if err != nil {
 				t.Fatalf("findMatchingPods() error = %v", err)
 			}
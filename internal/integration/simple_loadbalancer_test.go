@@ -53,9 +53,54 @@ func TestSimpleLoadBalancerIntegration(t *testing.T) {
 		UpdatedAt: now,
 	}
 
+	// Create ready pods backing the endpoints, since the service controller
+	// reacts to the new service right away and keeps endpoints in sync with pods
+	for i, podIP := range []string{"10.0.0.1", "10.0.0.2"} {
+		podMetadata := types.ObjectMeta{
+			Name:      fmt.Sprintf("%s-pod-%d", serviceName, i),
+			Namespace: "default",
+			UID:       fmt.Sprintf("%s-pod-%d", serviceMetadata.UID, i),
+			Labels:    map[string]string{"app": serviceName},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		podSpec := types.PodSpec{
+			Containers: []types.Container{
+				{
+					Name:  "app",
+					Image: "nginx:latest",
+					Ports: []types.ContainerPort{{ContainerPort: 8080}},
+				},
+			},
+		}
+		podStatus := types.PodStatus{
+			Phase: "Running",
+			PodIP: podIP,
+		}
+
+		podMetadataJSON, _ := json.Marshal(podMetadata)
+		podSpecJSON, _ := json.Marshal(podSpec)
+		podStatusJSON, _ := json.Marshal(podStatus)
+
+		err = repo.CreateResource(storage.Resource{
+			ID:        podMetadata.UID,
+			Kind:      "Pod",
+			Namespace: podMetadata.Namespace,
+			Name:      podMetadata.Name,
+			Metadata:  string(podMetadataJSON),
+			Spec:      string(podSpecJSON),
+			Status:    string(podStatusJSON),
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pod: %v", err)
+		}
+	}
+
 	// Create service spec
 	serviceSpec := types.ServiceSpec{
-		Selector: map[string]string{"app": "test"},
+		Selector: map[string]string{"app": serviceName},
 		Ports: []types.ServicePort{
 			{
 				Name:       "http",
@@ -109,8 +154,7 @@ func TestSimpleLoadBalancerIntegration(t *testing.T) {
 	// Wait for load balancer to pick up the service
 	time.Sleep(2 * time.Second)
 
-	// Manually trigger service update to ensure load balancer picks up the service
-	// This is needed because the watcher runs every 5 seconds
+	// Manually trigger service update to ensure load balancer has the latest endpoints
 	err = lb.UpdateServices()
 	if err != nil {
 		t.Fatalf("Failed to update services: %v", err)
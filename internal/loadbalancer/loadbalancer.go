@@ -165,11 +165,20 @@ func (lb *LoadBalancer) extractServiceInfo(r *http.Request) (string, string) {
 func (lb *LoadBalancer) watchServices() {
 	defer lb.wg.Done()
 
-	ticker := time.NewTicker(5 * time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Service changes trigger an update; the ticker is only a resync
+	changes := storage.NotifyChanges(ctx, lb.repository, "Service")
+	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
+		case <-changes:
+			if err := lb.updateServices(); err != nil {
+				log.Printf("Error updating services: %v", err)
+			}
 		case <-ticker.C:
 			if err := lb.updateServices(); err != nil {
 				log.Printf("Error updating services: %v", err)
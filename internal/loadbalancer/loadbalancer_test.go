@@ -1,6 +1,7 @@
 package loadbalancer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -171,6 +172,36 @@ func (m *MockRepository) ListPodAssignmentsByNode(nodeID string) ([]*storage.Pod
 	return assignments, nil
 }
 
+func (m *MockRepository) ListResourcesPage(kind, namespace string, options storage.ListOptions) (*storage.ResourcePage, error) {
+	resources, err := m.ListResources(kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &storage.ResourcePage{Resources: resources}, nil
+}
+
+func (m *MockRepository) ListNodesPage(options storage.ListOptions) (*storage.NodePage, error) {
+	nodes, err := m.ListNodes()
+	if err != nil {
+		return nil, err
+	}
+	return &storage.NodePage{Nodes: nodes}, nil
+}
+
+func (m *MockRepository) CurrentRevision() (int64, error) {
+	return 0, nil
+}
+
+// Watch never reports changes; tests drive reconciliation directly
+func (m *MockRepository) Watch(ctx context.Context, kind, namespace string, revision int64) (<-chan storage.WatchEvent, error) {
+	events := make(chan storage.WatchEvent)
+	go func() {
+		<-ctx.Done()
+		close(events)
+	}()
+	return events, nil
+}
+
 // Helper function to create a test service with endpoints
 func createTestServiceWithEndpoints(name, namespace string, endpoints []types.Endpoint) storage.Resource {
 	metadata := types.ObjectMeta{
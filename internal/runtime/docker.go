@@ -79,18 +79,10 @@ func (d *DockerRuntime) CreateContainer(ctx context.Context, spec *ContainerSpec
 		}
 	}
 	
-	// Convert restart policy
-	restartPolicy := container.RestartPolicy{}
-	switch spec.RestartPolicy {
-	case "Always":
-		restartPolicy.Name = "always"
-	case "OnFailure":
-		restartPolicy.Name = "on-failure"
-	case "Never":
-		restartPolicy.Name = "no"
-	default:
-		restartPolicy.Name = "always"
-	}
+	// The node agent applies the restart policy itself, so that it knows
+	// when and why containers restart; Docker must not restart them behind
+	// its back
+	restartPolicy := container.RestartPolicy{Name: "no"}
 	
 	// Create container configuration
 	config := &container.Config{
@@ -98,12 +90,16 @@ func (d *DockerRuntime) CreateContainer(ctx context.Context, spec *ContainerSpec
 		Env:          env,
 		ExposedPorts: exposedPorts,
 		Labels:       spec.Labels,
+		WorkingDir:   spec.WorkingDir,
 	}
 	
-	// Set command and args if provided
+	// As in Kubernetes, the command replaces the image's entrypoint and the
+	// args its command. Docker drops the image's command when the entrypoint
+	// is replaced.
 	if len(spec.Command) > 0 {
-		config.Cmd = append(spec.Command, spec.Args...)
-	} else if len(spec.Args) > 0 {
+		config.Entrypoint = spec.Command
+	}
+	if len(spec.Args) > 0 {
 		config.Cmd = spec.Args
 	}
 	
@@ -179,6 +175,17 @@ func (d *DockerRuntime) GetContainerStatus(ctx context.Context, containerID stri
 		ExitCode: int32(inspect.State.ExitCode),
 		Error:    inspect.State.Error,
 	}
+	status.RestartCount = int32(inspect.RestartCount)
+	
+	// Containers on user-defined networks only have an address on those networks
+	if inspect.NetworkSettings != nil {
+		status.IPAddress = inspect.NetworkSettings.IPAddress
+		for _, network := range inspect.NetworkSettings.Networks {
+			if status.IPAddress == "" && network != nil {
+				status.IPAddress = network.IPAddress
+			}
+		}
+	}
 	
 	if inspect.State.StartedAt != "" {
 		if startTime, err := parseDockerTime(inspect.State.StartedAt); err == nil {
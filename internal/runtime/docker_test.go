@@ -18,8 +18,11 @@ func TestPodToContainerSpecs(t *testing.T) {
 		Spec: types.PodSpec{
 			Containers: []types.Container{
 				{
-					Name:  "nginx",
-					Image: "nginx:latest",
+					Name:       "nginx",
+					Image:      "nginx:latest",
+					Command:    []string{"nginx"},
+					Args:       []string{"-g", "daemon off;"},
+					WorkingDir: "/usr/share/nginx",
 					Ports: []types.ContainerPort{
 						{
 							ContainerPort: 80,
@@ -70,6 +73,17 @@ func TestPodToContainerSpecs(t *testing.T) {
 		t.Errorf("Expected restart policy 'Always', got '%s'", spec.RestartPolicy)
 	}
 	
+	// Test command, args and working directory
+	if len(spec.Command) != 1 || spec.Command[0] != "nginx" {
+		t.Errorf("Expected command [nginx], got %v", spec.Command)
+	}
+	if len(spec.Args) != 2 || spec.Args[1] != "daemon off;" {
+		t.Errorf("Expected args [-g daemon off;], got %v", spec.Args)
+	}
+	if spec.WorkingDir != "/usr/share/nginx" {
+		t.Errorf("Expected working dir '/usr/share/nginx', got '%s'", spec.WorkingDir)
+	}
+	
 	// Test environment variables
 	if len(spec.Env) != 1 {
 		t.Errorf("Expected 1 env var, got %d", len(spec.Env))
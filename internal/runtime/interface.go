@@ -35,12 +35,13 @@ type ContainerRuntime interface {
 type ContainerSpec struct {
 	Name         string
 	Image        string
-	Command      []string
-	Args         []string
+	Command      []string // overrides the image's entrypoint
+	Args         []string // overrides the image's command
+	WorkingDir   string
 	Env          []EnvVar
 	Ports        []PortMapping
 	Resources    *ResourceConstraints
-	RestartPolicy string
+	RestartPolicy string // applied by the node agent, not the runtime
 	Labels       map[string]string
 	NetworkMode  string
 }
@@ -81,6 +82,7 @@ type ContainerStatus struct {
 	Error       string
 	RestartCount int32
 	Ports       []PortMapping
+	IPAddress   string // Address of the container on its network
 }
 
 // ContainerInfo represents basic information about a container
@@ -111,12 +113,13 @@ func PodToContainerSpecs(pod *types.Pod) ([]*ContainerSpec, error) {
 	
 	for _, container := range pod.Spec.Containers {
 		spec := &ContainerSpec{
-			Name:    container.Name,
-			Image:   container.Image,
-			Command: []string{}, // Will be set from container.Command if available
-			Args:    []string{}, // Will be set from container.Args if available
-			Env:     make([]EnvVar, len(container.Env)),
-			Ports:   make([]PortMapping, len(container.Ports)),
+			Name:       container.Name,
+			Image:      container.Image,
+			Command:    container.Command,
+			Args:       container.Args,
+			WorkingDir: container.WorkingDir,
+			Env:        make([]EnvVar, len(container.Env)),
+			Ports:      make([]PortMapping, len(container.Ports)),
 			Labels: map[string]string{
 				"pod.name":      pod.Metadata.Name,
 				"pod.namespace": pod.Metadata.Namespace,
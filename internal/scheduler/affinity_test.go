@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// affinityNodes returns two nodes in zone a and one in zone b, each labelled
+// with its hostname
+func affinityNodes() []*NodeInfo {
+	return newNodeInfos([]*types.Node{
+		testNode("node-a1", "4", "8Gi", map[string]string{"zone": "a", "hostname": "node-a1"}),
+		testNode("node-a2", "4", "8Gi", map[string]string{"zone": "a", "hostname": "node-a2"}),
+		testNode("node-b1", "4", "8Gi", map[string]string{"zone": "b", "hostname": "node-b1"}),
+	})
+}
+
+func labelledPod(name string, labels map[string]string) *types.Pod {
+	pod := testPod(name, "100m", "64Mi")
+	pod.Metadata.Labels = labels
+	return pod
+}
+
+func appTerm(app, topologyKey string) types.PodAffinityTerm {
+	return types.PodAffinityTerm{
+		LabelSelector: &types.LabelSelector{MatchLabels: map[string]string{"app": app}},
+		TopologyKey:   topologyKey,
+	}
+}
+
+// feasibleNodes returns the names of the nodes passing the scheduler's filters
+func feasibleNodes(t *testing.T, scheduler *Scheduler, pod *types.Pod, nodeInfos []*NodeInfo) []string {
+	t.Helper()
+
+	state := NewCycleState()
+	if status := scheduler.profiles[DefaultSchedulerName].RunPreFilterPlugins(state, pod, nodeInfos); !status.IsSuccess() {
+		t.Fatalf("PreFilter failed: %v", status.AsError())
+	}
+	var names []string
+	for _, nodeInfo := range nodeInfos {
+		if scheduler.profiles[DefaultSchedulerName].RunFilterPlugins(state, pod, nodeInfo).IsSuccess() {
+			names = append(names, nodeInfo.Node.Metadata.Name)
+		}
+	}
+	return names
+}
+
+func TestNodeAffinity(t *testing.T) {
+	scheduler := NewScheduler(NewMockRepository())
+
+	pod := testPod("web", "100m", "64Mi")
+	pod.Spec.Affinity = &types.Affinity{NodeAffinity: &types.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &types.NodeSelector{NodeSelectorTerms: []types.NodeSelectorTerm{
+			{MatchExpressions: []types.LabelSelectorRequirement{{Key: "zone", Operator: types.LabelSelectorOpIn, Values: []string{"a"}}}},
+		}},
+		PreferredDuringSchedulingIgnoredDuringExecution: []types.PreferredSchedulingTerm{
+			{Weight: 50, Preference: types.NodeSelectorTerm{MatchExpressions: []types.LabelSelectorRequirement{
+				{Key: "hostname", Operator: types.LabelSelectorOpNotIn, Values: []string{"node-a1"}},
+			}}},
+		},
+	}}
+
+	if names := feasibleNodes(t, scheduler, pod, affinityNodes()); strings.Join(names, ",") != "node-a1,node-a2" {
+		t.Errorf("Expected the zone a nodes to be feasible, got %v", names)
+	}
+
+	selected, err := scheduler.selectNode(NewCycleState(), pod, affinityNodes())
+	if err != nil {
+		t.Fatalf("Failed to select node: %v", err)
+	}
+	if selected.Node.Metadata.Name != "node-a2" {
+		t.Errorf("Expected the preferred node-a2, got %s", selected.Node.Metadata.Name)
+	}
+}
+
+func TestPodAntiAffinitySpreadsReplicas(t *testing.T) {
+	scheduler := NewScheduler(NewMockRepository())
+	nodeInfos := affinityNodes()
+	nodeInfos[0].AddPod(labelledPod("web-1", map[string]string{"app": "web"}))
+
+	pod := labelledPod("web-2", map[string]string{"app": "web"})
+	pod.Spec.Affinity = &types.Affinity{PodAntiAffinity: &types.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []types.PodAffinityTerm{appTerm("web", "hostname")},
+	}}
+
+	if names := feasibleNodes(t, scheduler, pod, nodeInfos); strings.Join(names, ",") != "node-a2,node-b1" {
+		t.Errorf("Expected web-2 to avoid node-a1, got %v", names)
+	}
+
+	// Spreading by zone rules out the whole zone
+	pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = []types.PodAffinityTerm{appTerm("web", "zone")}
+	if names := feasibleNodes(t, scheduler, pod, nodeInfos); strings.Join(names, ",") != "node-b1" {
+		t.Errorf("Expected web-2 to avoid zone a, got %v", names)
+	}
+
+	// Placed pods' anti-affinity applies to new pods too
+	nodeInfos = affinityNodes()
+	guarded := labelledPod("guarded", map[string]string{"app": "db"})
+	guarded.Spec.Affinity = &types.Affinity{PodAntiAffinity: &types.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []types.PodAffinityTerm{appTerm("web", "zone")},
+	}}
+	nodeInfos[2].AddPod(guarded)
+
+	_, err := scheduler.selectNode(NewCycleState(), labelledPod("web-3", map[string]string{"app": "web"}), nodeInfos[2:])
+	if err == nil || !strings.Contains(err.Error(), "existing pods anti-affinity rules") {
+		t.Errorf("Expected the existing pod's anti-affinity to block node-b1, got %v", err)
+	}
+}
+
+func TestPodAffinityColocates(t *testing.T) {
+	scheduler := NewScheduler(NewMockRepository())
+
+	pod := labelledPod("app", map[string]string{"app": "web"})
+	pod.Spec.Affinity = &types.Affinity{PodAffinity: &types.PodAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []types.PodAffinityTerm{appTerm("cache", "zone")},
+	}}
+
+	// Without a cache anywhere the pod can't be placed
+	if names := feasibleNodes(t, scheduler, pod, affinityNodes()); len(names) != 0 {
+		t.Errorf("Expected no feasible nodes without a cache, got %v", names)
+	}
+
+	nodeInfos := affinityNodes()
+	nodeInfos[2].AddPod(labelledPod("cache", map[string]string{"app": "cache"}))
+	if names := feasibleNodes(t, scheduler, pod, nodeInfos); strings.Join(names, ",") != "node-b1" {
+		t.Errorf("Expected the pod to join the cache in zone b, got %v", names)
+	}
+
+	// The first pod of a group that selects itself may go anywhere
+	first := labelledPod("cache-1", map[string]string{"app": "cache"})
+	first.Spec.Affinity = &types.Affinity{PodAffinity: &types.PodAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []types.PodAffinityTerm{appTerm("cache", "zone")},
+	}}
+	if names := feasibleNodes(t, scheduler, first, affinityNodes()); len(names) != 3 {
+		t.Errorf("Expected every node to be feasible for the first pod, got %v", names)
+	}
+}
+
+func TestPreferredPodAffinity(t *testing.T) {
+	scheduler := NewScheduler(NewMockRepository())
+	nodeInfos := affinityNodes()
+	nodeInfos[1].AddPod(labelledPod("cache", map[string]string{"app": "cache"}))
+
+	pod := labelledPod("app", map[string]string{"app": "web"})
+	pod.Spec.Affinity = &types.Affinity{PodAffinity: &types.PodAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []types.WeightedPodAffinityTerm{
+			{Weight: 100, PodAffinityTerm: appTerm("cache", "hostname")},
+		},
+	}}
+
+	selected, err := scheduler.selectNode(NewCycleState(), pod, nodeInfos)
+	if err != nil {
+		t.Fatalf("Failed to select node: %v", err)
+	}
+	if selected.Node.Metadata.Name != "node-a2" {
+		t.Errorf("Expected the pod next to the cache on node-a2, got %s", selected.Node.Metadata.Name)
+	}
+}
@@ -0,0 +1,320 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// DefaultAssumedPodTTL is how long a pod that was bound by the scheduler is
+// kept on its node before the binding shows up in storage
+const DefaultAssumedPodTTL = 30 * time.Second
+
+// Cache keeps the pods bound to each node and the resources they request.
+// It is built by Sync and kept up to date by applying pod and node events.
+// Pods the scheduler has chosen a node for are assumed on that node straight
+// away, so pods scheduled after them see the capacity as taken even before
+// the binding is stored.
+type Cache struct {
+	mu sync.Mutex
+	// nodes holds the node infos by node name
+	nodes map[string]*NodeInfo
+	// podNodes maps the UID of every bound or assumed pod to its node name
+	podNodes map[string]string
+	// pods holds the bound pods by UID
+	pods map[string]*types.Pod
+	// assumed holds the assumed pods by UID
+	assumed map[string]*assumedPod
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// assumedPod is a pod placed on a node by the scheduler whose binding hasn't
+// been observed in storage yet
+type assumedPod struct {
+	pod      *types.Pod
+	nodeName string
+	// bindingFinished is set once the bind plugins have returned, starting
+	// the expiry clock
+	bindingFinished bool
+	deadline        time.Time
+}
+
+// NewCache creates an empty cache. Assumed pods expire ttl after their
+// binding finished unless the binding has been observed by then.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		nodes:    make(map[string]*NodeInfo),
+		podNodes: make(map[string]string),
+		pods:     make(map[string]*types.Pod),
+		assumed:  make(map[string]*assumedPod),
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// Sync rebuilds the cache from storage. A pod counts against a node when its
+// spec names the node or it has a pod assignment to it, unless it has
+// finished. Deleted pods drop out, and assumed pods are kept until their
+// binding is observed or they expire. Events are held back while the cache
+// is rebuilt, so none are lost to the listing.
+func (c *Cache) Sync(repository storage.Repository) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	nodes, err := repository.ListNodes()
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	nodeInfos := make(map[string]*NodeInfo, len(nodes))
+	assignedNodes := make(map[string]string)
+	for _, node := range nodes {
+		nodeInfos[node.Metadata.Name] = &NodeInfo{Node: node}
+
+		assignments, err := repository.ListPodAssignmentsByNode(node.Metadata.UID)
+		if err != nil {
+			return fmt.Errorf("failed to list pod assignments of node %s: %w", node.Metadata.Name, err)
+		}
+		for _, assignment := range assignments {
+			assignedNodes[assignment.PodID] = node.Metadata.Name
+		}
+	}
+
+	resources, err := repository.ListResources("Pod", "")
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	// Preemption compares the priorities of the pods on each node
+	classes, err := listPriorityClasses(repository)
+	if err != nil {
+		return err
+	}
+
+	podNodes := make(map[string]string)
+	pods := make(map[string]*types.Pod)
+	for _, resource := range resources {
+		pod, err := resourceToPod(resource)
+		if err != nil {
+			log.Printf("Failed to decode pod: %v", err)
+			continue
+		}
+		if pod.Status.Phase == "Succeeded" || pod.Status.Phase == "Failed" {
+			continue
+		}
+
+		nodeName := pod.Spec.NodeName
+		if nodeName == "" {
+			nodeName = assignedNodes[pod.Metadata.UID]
+		}
+		if nodeName == "" {
+			continue
+		}
+
+		resolvePriority(pod, classes)
+		podNodes[pod.Metadata.UID] = nodeName
+		pods[pod.Metadata.UID] = pod
+		if nodeInfo, ok := nodeInfos[nodeName]; ok {
+			nodeInfo.AddPod(pod)
+		}
+	}
+
+	now := c.now()
+	for uid, assumed := range c.assumed {
+		if _, ok := podNodes[uid]; ok {
+			// The binding is stored, so the pod is now counted from storage
+			delete(c.assumed, uid)
+			continue
+		}
+		if assumed.bindingFinished && now.After(assumed.deadline) {
+			log.Printf("Assumed pod %s/%s expired without being bound to node %s", assumed.pod.Metadata.Namespace, assumed.pod.Metadata.Name, assumed.nodeName)
+			delete(c.assumed, uid)
+			continue
+		}
+
+		podNodes[uid] = assumed.nodeName
+		if nodeInfo, ok := nodeInfos[assumed.nodeName]; ok {
+			nodeInfo.AddPod(assumed.pod)
+		}
+	}
+
+	c.nodes = nodeInfos
+	c.podNodes = podNodes
+	c.pods = pods
+	return nil
+}
+
+// UpdatePod applies an added or updated pod. A pod counts against the node
+// its spec names until it finishes; one that got its node from an assignment
+// keeps it. An assumed pod stays on its node until its binding is observed.
+func (c *Cache) UpdatePod(pod *types.Pod) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	uid := pod.Metadata.UID
+	nodeName := pod.Spec.NodeName
+	if _, bound := c.pods[uid]; bound && nodeName == "" {
+		nodeName = c.podNodes[uid]
+	}
+	if nodeName == "" {
+		return
+	}
+
+	c.removePod(pod)
+	if pod.Status.Phase == "Succeeded" || pod.Status.Phase == "Failed" {
+		return
+	}
+	c.pods[uid] = pod
+	c.podNodes[uid] = nodeName
+	if nodeInfo, ok := c.nodes[nodeName]; ok {
+		nodeInfo.AddPod(pod)
+	}
+}
+
+// RemovePod applies a deleted pod
+func (c *Cache) RemovePod(pod *types.Pod) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removePod(pod)
+}
+
+// removePod takes a bound or assumed pod off its node. Callers must hold
+// c.mu.
+func (c *Cache) removePod(pod *types.Pod) {
+	uid := pod.Metadata.UID
+	nodeName, ok := c.podNodes[uid]
+	if !ok {
+		return
+	}
+
+	delete(c.podNodes, uid)
+	delete(c.pods, uid)
+	delete(c.assumed, uid)
+	if nodeInfo, ok := c.nodes[nodeName]; ok {
+		nodeInfo.RemovePod(pod)
+	}
+}
+
+// UpdateNode applies an added or updated node, keeping the pods on it
+func (c *Cache) UpdateNode(node *types.Node) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := node.Metadata.Name
+	if nodeInfo, ok := c.nodes[name]; ok {
+		nodeInfo.Node = node
+		return
+	}
+
+	// Pods may already be bound to a node that is only now added
+	nodeInfo := &NodeInfo{Node: node}
+	for uid, nodeName := range c.podNodes {
+		if nodeName != name {
+			continue
+		}
+		if pod, ok := c.pods[uid]; ok {
+			nodeInfo.AddPod(pod)
+		} else if assumed, ok := c.assumed[uid]; ok {
+			nodeInfo.AddPod(assumed.pod)
+		}
+	}
+	c.nodes[name] = nodeInfo
+}
+
+// RemoveNode applies a deleted node. Its pods are kept, in case it comes
+// back.
+func (c *Cache) RemoveNode(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.nodes, name)
+}
+
+// AssumePod places a pod on a node in the cache ahead of its binding
+func (c *Cache) AssumePod(pod *types.Pod, nodeName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	uid := pod.Metadata.UID
+	if existing, ok := c.podNodes[uid]; ok {
+		return fmt.Errorf("pod %s/%s is already on node %s", pod.Metadata.Namespace, pod.Metadata.Name, existing)
+	}
+
+	c.assumed[uid] = &assumedPod{pod: pod, nodeName: nodeName}
+	c.podNodes[uid] = nodeName
+	if nodeInfo, ok := c.nodes[nodeName]; ok {
+		nodeInfo.AddPod(pod)
+	}
+	return nil
+}
+
+// ForgetPod removes an assumed pod whose binding failed
+func (c *Cache) ForgetPod(pod *types.Pod) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	uid := pod.Metadata.UID
+	assumed, ok := c.assumed[uid]
+	if !ok {
+		return
+	}
+
+	delete(c.assumed, uid)
+	delete(c.podNodes, uid)
+	if nodeInfo, ok := c.nodes[assumed.nodeName]; ok {
+		nodeInfo.RemovePod(pod)
+	}
+}
+
+// FinishBinding marks an assumed pod as bound. It stays on its node until
+// the binding is observed, or until the TTL runs out.
+func (c *Cache) FinishBinding(pod *types.Pod) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if assumed, ok := c.assumed[pod.Metadata.UID]; ok {
+		assumed.bindingFinished = true
+		assumed.deadline = c.now().Add(c.ttl)
+	}
+}
+
+// IsAssumed reports whether a pod is assumed and not yet observed as bound
+func (c *Cache) IsAssumed(pod *types.Pod) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.assumed[pod.Metadata.UID]
+	return ok
+}
+
+// Snapshot returns a copy of the node infos, sorted by node name, for a
+// scheduling cycle to work on. Assumed pods whose binding wasn't observed in
+// time are dropped first.
+func (c *Cache) Snapshot() []*NodeInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for _, assumed := range c.assumed {
+		if assumed.bindingFinished && now.After(assumed.deadline) {
+			log.Printf("Assumed pod %s/%s expired without being bound to node %s", assumed.pod.Metadata.Namespace, assumed.pod.Metadata.Name, assumed.nodeName)
+			c.removePod(assumed.pod)
+		}
+	}
+
+	nodeInfos := make([]*NodeInfo, 0, len(c.nodes))
+	for _, nodeInfo := range c.nodes {
+		nodeInfos = append(nodeInfos, nodeInfo.clone())
+	}
+	sort.Slice(nodeInfos, func(i, j int) bool {
+		return nodeInfos[i].Node.Metadata.Name < nodeInfos[j].Node.Metadata.Name
+	})
+	return nodeInfos
+}
@@ -0,0 +1,231 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+func snapshotNode(t *testing.T, cache *Cache, name string) *NodeInfo {
+	t.Helper()
+
+	for _, nodeInfo := range cache.Snapshot() {
+		if nodeInfo.Node.Metadata.Name == name {
+			return nodeInfo
+		}
+	}
+	t.Fatalf("Node %s not in cache snapshot", name)
+	return nil
+}
+
+func TestCacheSyncCountsBoundPods(t *testing.T) {
+	repo := NewMockRepository()
+	repo.CreateNode(testNode("node-a", "4", "8Gi", nil))
+	repo.CreateNode(testNode("node-b", "4", "8Gi", nil))
+
+	// Bound through the pod spec
+	bound := testPod("bound", "1", "1Gi")
+	bound.Spec.NodeName = "node-a"
+	repo.pods[bound.Metadata.UID] = bound
+
+	// Bound through a pod assignment only
+	assigned := testPod("assigned", "500m", "512Mi")
+	repo.pods[assigned.Metadata.UID] = assigned
+	repo.AssignPodToNode(assigned.Metadata.UID, "node-a-uid")
+
+	// Finished pods no longer hold resources
+	finished := testPod("finished", "2", "2Gi")
+	finished.Spec.NodeName = "node-a"
+	finished.Status.Phase = "Succeeded"
+	repo.pods[finished.Metadata.UID] = finished
+
+	cache := NewCache(DefaultAssumedPodTTL)
+	if err := cache.Sync(repo); err != nil {
+		t.Fatalf("Failed to sync cache: %v", err)
+	}
+
+	nodeA := snapshotNode(t, cache, "node-a")
+	if len(nodeA.Pods) != 2 || nodeA.Requested.MilliCPU != 1500 || nodeA.Requested.Memory != 1536*1024*1024 {
+		t.Errorf("Unexpected node-a accounting: %d pods, %+v", len(nodeA.Pods), nodeA.Requested)
+	}
+	if nodeB := snapshotNode(t, cache, "node-b"); len(nodeB.Pods) != 0 {
+		t.Errorf("Expected no pods on node-b, got %d", len(nodeB.Pods))
+	}
+
+	// Deleted pods drop out on the next sync
+	delete(repo.pods, bound.Metadata.UID)
+	if err := cache.Sync(repo); err != nil {
+		t.Fatalf("Failed to sync cache: %v", err)
+	}
+	if nodeA := snapshotNode(t, cache, "node-a"); nodeA.Requested.MilliCPU != 500 {
+		t.Errorf("Expected 500m requested on node-a after delete, got %dm", nodeA.Requested.MilliCPU)
+	}
+}
+
+func TestCacheAssumedPods(t *testing.T) {
+	repo := NewMockRepository()
+	repo.CreateNode(testNode("node-a", "4", "8Gi", nil))
+
+	cache := NewCache(time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	if err := cache.Sync(repo); err != nil {
+		t.Fatalf("Failed to sync cache: %v", err)
+	}
+
+	pod := testPod("web", "1", "1Gi")
+	if err := cache.AssumePod(pod, "node-a"); err != nil {
+		t.Fatalf("Failed to assume pod: %v", err)
+	}
+	if err := cache.AssumePod(pod, "node-a"); err == nil {
+		t.Error("Expected assuming a pod twice to fail")
+	}
+	if nodeA := snapshotNode(t, cache, "node-a"); nodeA.Requested.MilliCPU != 1000 {
+		t.Errorf("Expected the assumed pod to be counted, got %dm", nodeA.Requested.MilliCPU)
+	}
+
+	// A failed binding gives the capacity back
+	cache.ForgetPod(pod)
+	if nodeA := snapshotNode(t, cache, "node-a"); nodeA.Requested.MilliCPU != 0 {
+		t.Errorf("Expected the forgotten pod to be released, got %dm", nodeA.Requested.MilliCPU)
+	}
+
+	// Assumed pods survive a sync until the binding is observed or expires
+	if err := cache.AssumePod(pod, "node-a"); err != nil {
+		t.Fatalf("Failed to assume pod: %v", err)
+	}
+	cache.FinishBinding(pod)
+	if err := cache.Sync(repo); err != nil {
+		t.Fatalf("Failed to sync cache: %v", err)
+	}
+	if !cache.IsAssumed(pod) || snapshotNode(t, cache, "node-a").Requested.MilliCPU != 1000 {
+		t.Error("Expected the assumed pod to be kept before its deadline")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := cache.Sync(repo); err != nil {
+		t.Fatalf("Failed to sync cache: %v", err)
+	}
+	if cache.IsAssumed(pod) || snapshotNode(t, cache, "node-a").Requested.MilliCPU != 0 {
+		t.Error("Expected the assumed pod to expire after its deadline")
+	}
+}
+
+func TestCacheAppliesEvents(t *testing.T) {
+	cache := NewCache(DefaultAssumedPodTTL)
+	cache.UpdateNode(testNode("node-a", "4", "8Gi", nil))
+
+	// Pending pods don't count against any node
+	pending := testPod("pending", "1", "1Gi")
+	cache.UpdatePod(pending)
+	if nodeA := snapshotNode(t, cache, "node-a"); len(nodeA.Pods) != 0 {
+		t.Errorf("Expected no pods on node-a, got %d", len(nodeA.Pods))
+	}
+
+	// Observing the binding of an assumed pod replaces it
+	if err := cache.AssumePod(pending, "node-a"); err != nil {
+		t.Fatalf("Failed to assume pod: %v", err)
+	}
+	bound := testPod("pending", "1", "1Gi")
+	bound.Spec.NodeName = "node-a"
+	cache.UpdatePod(bound)
+	if cache.IsAssumed(pending) {
+		t.Error("Expected the observed binding to replace the assumed pod")
+	}
+	if nodeA := snapshotNode(t, cache, "node-a"); len(nodeA.Pods) != 1 || nodeA.Requested.MilliCPU != 1000 {
+		t.Errorf("Unexpected node-a accounting: %d pods, %+v", len(nodeA.Pods), nodeA.Requested)
+	}
+
+	// Pods bound before their node is known are counted once it is added
+	other := testPod("other", "2", "1Gi")
+	other.Spec.NodeName = "node-b"
+	cache.UpdatePod(other)
+	cache.UpdateNode(testNode("node-b", "4", "8Gi", nil))
+	if nodeB := snapshotNode(t, cache, "node-b"); nodeB.Requested.MilliCPU != 2000 {
+		t.Errorf("Expected 2000m requested on node-b, got %dm", nodeB.Requested.MilliCPU)
+	}
+
+	// Updating a node keeps its pods
+	cache.UpdateNode(testNode("node-b", "8", "16Gi", nil))
+	if nodeB := snapshotNode(t, cache, "node-b"); nodeB.Requested.MilliCPU != 2000 || nodeB.Node.Status.Allocatable["cpu"] != "8" {
+		t.Errorf("Unexpected node-b after update: %+v requested of %v", nodeB.Requested, nodeB.Node.Status.Allocatable)
+	}
+
+	// Finished and deleted pods release their capacity
+	finished := testPod("pending", "1", "1Gi")
+	finished.Spec.NodeName = "node-a"
+	finished.Status.Phase = "Succeeded"
+	cache.UpdatePod(finished)
+	if nodeA := snapshotNode(t, cache, "node-a"); nodeA.Requested.MilliCPU != 0 {
+		t.Errorf("Expected the finished pod to be released, got %dm", nodeA.Requested.MilliCPU)
+	}
+	cache.RemovePod(other)
+	if nodeB := snapshotNode(t, cache, "node-b"); nodeB.Requested.MilliCPU != 0 {
+		t.Errorf("Expected the deleted pod to be released, got %dm", nodeB.Requested.MilliCPU)
+	}
+
+	cache.RemoveNode("node-b")
+	if len(cache.Snapshot()) != 1 {
+		t.Errorf("Expected only node-a in the snapshot, got %d nodes", len(cache.Snapshot()))
+	}
+}
+
+func TestSchedulePendingPodsNoDoubleBooking(t *testing.T) {
+	repo := NewMockRepository()
+	repo.CreateNode(testNode("node-a", "4", "8Gi", nil))
+	repo.CreateNode(testNode("node-b", "4", "8Gi", nil))
+
+	// node-b already runs a pod taking most of its CPU
+	existing := testPod("existing", "3", "1Gi")
+	existing.Spec.NodeName = "node-b"
+	repo.pods[existing.Metadata.UID] = existing
+
+	// Each pod fits on an empty node, but no two fit on the same node
+	for _, name := range []string{"first", "second"} {
+		pod := testPod(name, "3", "1Gi")
+		repo.pods[pod.Metadata.UID] = pod
+	}
+
+	scheduler := NewScheduler(repo)
+	if err := scheduler.schedulePendingPods(); err != nil {
+		t.Fatalf("Failed to schedule pods: %v", err)
+	}
+
+	// Only one of the pods fits, on node-a; the other must stay pending
+	var assigned []string
+	for _, name := range []string{"first", "second"} {
+		if nodeID, ok := repo.podAssignments[name+"-uid"]; ok {
+			if nodeID != "node-a-uid" {
+				t.Errorf("Expected %s on node-a, got %s", name, nodeID)
+			}
+			assigned = append(assigned, name)
+		}
+	}
+	if len(assigned) != 1 {
+		t.Errorf("Expected exactly one pod to be scheduled, got %v", assigned)
+	}
+}
+
+func TestNodeResourcesFitUsesFreeCapacity(t *testing.T) {
+	nodeInfo := &NodeInfo{Node: testNode("node-a", "2", "4Gi", nil)}
+	nodeInfo.AddPod(testPod("existing", "1500m", "1Gi"))
+
+	plugin := &NodeResourcesFit{}
+	state := NewCycleState()
+	pod := testPod("web", "1", "1Gi")
+	if status := plugin.PreFilter(state, pod, []*NodeInfo{nodeInfo}); !status.IsSuccess() {
+		t.Fatalf("PreFilter failed: %v", status.AsError())
+	}
+
+	status := plugin.Filter(state, pod, nodeInfo)
+	if status.Code() != Unschedulable || status.Message() != "Insufficient cpu" {
+		t.Errorf("Expected Insufficient cpu, got %v", status.AsError())
+	}
+
+	// Once the existing pod is gone the node fits again
+	nodeInfo.RemovePod(&types.Pod{Metadata: types.ObjectMeta{UID: "existing-uid"}})
+	if status := plugin.Filter(state, pod, nodeInfo); !status.IsSuccess() {
+		t.Errorf("Expected the pod to fit, got %v", status.AsError())
+	}
+}
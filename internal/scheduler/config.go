@@ -0,0 +1,150 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// DefaultSchedulerName is the name of the default scheduling profile
+const DefaultSchedulerName = "default-scheduler"
+
+// Config is the scheduler configuration file. It holds one or more profiles,
+// each choosing the plugins that schedule its pods:
+//
+//	{
+//	  "profiles": [
+//	    {
+//	      "schedulerName": "default-scheduler",
+//	      "plugins": [
+//	        {"name": "NodeSelector"},
+//	        {"name": "TaintToleration", "weight": 1},
+//	        {"name": "NodeResourcesFit", "weight": 1},
+//	        {"name": "NodeResourcesBalancedAllocation", "weight": 2},
+//	        {"name": "DefaultBinder"}
+//	      ]
+//	    }
+//	  ]
+//	}
+type Config struct {
+	Profiles []Profile `json:"profiles"`
+}
+
+// Profile is a named set of enabled plugins
+type Profile struct {
+	SchedulerName string `json:"schedulerName"`
+	// Plugins are run at every extension point they implement, in order
+	Plugins []PluginConfig `json:"plugins"`
+	// Extenders are called over HTTP after the plugins, in order
+	Extenders []ExtenderConfig `json:"extenders,omitempty"`
+}
+
+// PluginConfig enables a plugin in a profile
+type PluginConfig struct {
+	Name string `json:"name"`
+	// Weight multiplies the plugin's node scores; 0 means 1
+	Weight int64 `json:"weight,omitempty"`
+	// Args are passed to the plugin's factory
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// DefaultProfile returns the resource-aware profile used when no config file
+// is given
+func DefaultProfile() Profile {
+	return Profile{
+		SchedulerName: DefaultSchedulerName,
+		Plugins: []PluginConfig{
+			{Name: NodeSelectorName},
+			{Name: NodeAffinityName, Weight: 1},
+			{Name: InterPodAffinityName, Weight: 1},
+			{Name: PodTopologySpreadName, Weight: 2},
+			{Name: TaintTolerationName, Weight: 1},
+			{Name: NodeResourcesFitName, Weight: 1},
+			{Name: NodeResourcesBalancedAllocationName, Weight: 1},
+			{Name: DefaultPreemptionName},
+			{Name: DefaultBinderName},
+		},
+	}
+}
+
+// BasicProfile returns a profile that ignores resources: it only honours node
+// selectors, affinity, topology spread constraints and taints, and spreads
+// pods over the matching nodes by the hash of their names
+func BasicProfile() Profile {
+	return Profile{
+		SchedulerName: DefaultSchedulerName,
+		Plugins: []PluginConfig{
+			{Name: NodeSelectorName},
+			{Name: NodeAffinityName},
+			{Name: InterPodAffinityName},
+			{Name: PodTopologySpreadName},
+			{Name: TaintTolerationName},
+			{Name: DefaultBinderName},
+		},
+	}
+}
+
+// LoadConfig reads and validates a scheduler configuration file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scheduler config: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse scheduler config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Validate checks that the profiles are named uniquely and enable plugins
+// with sensible weights. Plugin names are checked when the profile is built.
+func (c *Config) Validate() error {
+	if len(c.Profiles) == 0 {
+		return fmt.Errorf("scheduler config has no profiles")
+	}
+
+	names := make(map[string]bool)
+	for i, profile := range c.Profiles {
+		if profile.SchedulerName == "" {
+			return fmt.Errorf("profile %d has no schedulerName", i)
+		}
+		if names[profile.SchedulerName] {
+			return fmt.Errorf("profile %s is defined more than once", profile.SchedulerName)
+		}
+		names[profile.SchedulerName] = true
+
+		if len(profile.Plugins) == 0 {
+			return fmt.Errorf("profile %s enables no plugins", profile.SchedulerName)
+		}
+		for _, plugin := range profile.Plugins {
+			if plugin.Name == "" {
+				return fmt.Errorf("profile %s has a plugin without a name", profile.SchedulerName)
+			}
+			if plugin.Weight < 0 {
+				return fmt.Errorf("profile %s: plugin %s has negative weight %d", profile.SchedulerName, plugin.Name, plugin.Weight)
+			}
+		}
+		for _, extender := range profile.Extenders {
+			if err := extender.validate(); err != nil {
+				return fmt.Errorf("profile %s: %w", profile.SchedulerName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Profile returns the profile with the given scheduler name
+func (c *Config) Profile(name string) (Profile, bool) {
+	for _, profile := range c.Profiles {
+		if profile.SchedulerName == name {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
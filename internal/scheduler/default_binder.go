@@ -0,0 +1,128 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// DefaultBinderName is the name of the DefaultBinder plugin
+const DefaultBinderName = "DefaultBinder"
+
+// DefaultBinder records the pod's node assignment and sets spec.nodeName
+type DefaultBinder struct {
+	repository storage.Repository
+}
+
+// NewDefaultBinder creates the DefaultBinder plugin
+func NewDefaultBinder(args json.RawMessage, handle Handle) (Plugin, error) {
+	return &DefaultBinder{repository: handle.Repository()}, nil
+}
+
+// Name returns the name of the plugin
+func (b *DefaultBinder) Name() string {
+	return DefaultBinderName
+}
+
+// Bind assigns the pod to the node and updates the pod
+func (b *DefaultBinder) Bind(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status {
+	selectedNode := nodeInfo.Node
+
+	// Assign pod to node
+	if err := b.repository.AssignPodToNode(pod.Metadata.UID, selectedNode.Metadata.UID); err != nil {
+		return AsStatus(fmt.Errorf("failed to assign pod to node: %w", err))
+	}
+
+	// Bind the pod, re-reading it if another component updated it since it was listed
+	latest := pod
+	err := storage.RetryOnConflict(func() error {
+		if latest == nil {
+			resource, err := b.repository.GetResource("Pod", pod.Metadata.Namespace, pod.Metadata.Name)
+			if err != nil {
+				return err
+			}
+			if latest, err = resourceToPod(resource); err != nil {
+				return err
+			}
+			if latest.Spec.NodeName != "" {
+				return fmt.Errorf("pod was bound to node %s concurrently", latest.Spec.NodeName)
+			}
+		}
+		err := b.bindPod(latest, selectedNode)
+		latest = nil
+		return err
+	})
+	if err != nil {
+		// Release the assignment so the pod can be scheduled again
+		if err := b.repository.DeletePodAssignment(pod.Metadata.UID); err != nil {
+			log.Printf("Failed to delete pod assignment: %v", err)
+		}
+		return AsStatus(fmt.Errorf("failed to update pod: %w", err))
+	}
+
+	return nil
+}
+
+// bindPod records the node a pod was scheduled to on the pod. The binding is
+// made on a copy, so the pod is only changed once it is stored and a pod that
+// failed to bind can be scheduled again.
+func (b *DefaultBinder) bindPod(pod *types.Pod, selectedNode *types.Node) error {
+	// Update pod status with node name
+	bound := *pod
+	bound.Spec.NodeName = selectedNode.Metadata.Name
+	bound.Status.Phase = "Scheduled"
+	bound.Status.NominatedNodeName = ""
+
+	// Replace the PodScheduled condition a failed attempt may have set
+	conditions := append([]types.PodCondition(nil), pod.Status.Conditions...)
+	bound.Status.Conditions, _ = setPodCondition(conditions, types.PodCondition{
+		Type:    "PodScheduled",
+		Status:  "True",
+		Reason:  "Scheduled",
+		Message: fmt.Sprintf("Successfully assigned %s/%s to %s", pod.Metadata.Namespace, pod.Metadata.Name, selectedNode.Metadata.Name),
+	}, time.Now())
+
+	// Convert to storage resource
+	metadataJSON, err := json.Marshal(bound.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pod metadata: %w", err)
+	}
+
+	specJSON, err := json.Marshal(bound.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pod spec: %w", err)
+	}
+
+	statusJSON, err := json.Marshal(bound.Status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pod status: %w", err)
+	}
+
+	resource := storage.Resource{
+		Kind:      "Pod",
+		Namespace: pod.Metadata.Namespace,
+		Name:      pod.Metadata.Name,
+		Metadata:  string(metadataJSON),
+		Spec:      string(specJSON),
+		Status:    string(statusJSON),
+	}
+
+	// Only apply the binding to the version of the pod that was read
+	resourceVersion, err := storage.ParseResourceVersion(pod.Metadata.ResourceVersion)
+	if err != nil {
+		return err
+	}
+	resource.ResourceVersion = resourceVersion
+
+	// Update pod in storage
+	if err := b.repository.UpdateResource(resource); err != nil {
+		return err
+	}
+	pod.Spec = bound.Spec
+	pod.Status = bound.Status
+	return nil
+}
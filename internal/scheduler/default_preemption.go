@@ -0,0 +1,172 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// DefaultPreemptionName is the name of the DefaultPreemption plugin
+const DefaultPreemptionName = "DefaultPreemption"
+
+// errReasonNoPreemptionVictims is why preemption fails when evicting lower
+// priority pods wouldn't let the pod fit on any node
+const errReasonNoPreemptionVictims = "preemption: no node would fit the pod after evicting lower priority pods"
+
+// DefaultPreemption makes room for a pod that fits no node by evicting pods
+// of lower priority. It picks the node where the fewest and least important
+// pods have to go, evicts them and nominates the node for the pod.
+type DefaultPreemption struct {
+	handle Handle
+}
+
+// NewDefaultPreemption creates the DefaultPreemption plugin
+func NewDefaultPreemption(args json.RawMessage, handle Handle) (Plugin, error) {
+	return &DefaultPreemption{handle: handle}, nil
+}
+
+// Name returns the name of the plugin
+func (p *DefaultPreemption) Name() string {
+	return DefaultPreemptionName
+}
+
+// preemptionCandidate is a node the pod fits on once victims are evicted
+type preemptionCandidate struct {
+	nodeInfo *NodeInfo
+	victims  []*types.Pod
+	// highestPriority is the priority of the most important victim
+	highestPriority int32
+	// prioritySum adds up the victims' priorities, each offset to be
+	// positive so that every victim adds to the cost
+	prioritySum int64
+}
+
+// newPreemptionCandidate summarizes the victims of evicting pods from a node
+func newPreemptionCandidate(nodeInfo *NodeInfo, victims []*types.Pod) *preemptionCandidate {
+	candidate := &preemptionCandidate{
+		nodeInfo:        nodeInfo,
+		victims:         victims,
+		highestPriority: math.MinInt32,
+	}
+	for _, victim := range victims {
+		priority := types.PodPriority(victim)
+		if priority > candidate.highestPriority {
+			candidate.highestPriority = priority
+		}
+		candidate.prioritySum += int64(priority) + math.MaxInt32 + 1
+	}
+	return candidate
+}
+
+// less reports whether evicting c's victims is less disruptive than evicting
+// other's: their most important victim matters less, then their priorities
+// add up to less, then there are fewer of them
+func (c *preemptionCandidate) less(other *preemptionCandidate) bool {
+	if c.highestPriority != other.highestPriority {
+		return c.highestPriority < other.highestPriority
+	}
+	if c.prioritySum != other.prioritySum {
+		return c.prioritySum < other.prioritySum
+	}
+	return len(c.victims) < len(other.victims)
+}
+
+// PostFilter finds the node where preemption disrupts the least, evicts the
+// victims there and returns the node's name
+func (p *DefaultPreemption) PostFilter(state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo, fitErr *FitError) (string, *Status) {
+	var best *preemptionCandidate
+	for i := range nodeInfos {
+		candidate, status := p.selectVictimsOnNode(pod, nodeInfos, i)
+		if !status.IsSuccess() {
+			return "", status
+		}
+		if candidate != nil && (best == nil || candidate.less(best)) {
+			best = candidate
+		}
+	}
+	if best == nil {
+		return "", NewStatus(Unschedulable, errReasonNoPreemptionVictims)
+	}
+
+	nodeName := best.nodeInfo.Node.Metadata.Name
+	for _, victim := range best.victims {
+		// Like any deletion of a pod on a node, this is graceful: the victim
+		// keeps its resources until the node's agent has removed it
+		if _, err := storage.DeletePodGracefully(p.handle.Repository(), victim.Metadata.Namespace, victim.Metadata.Name, nil); err != nil {
+			return "", AsStatus(fmt.Errorf("failed to preempt pod %s/%s: %w", victim.Metadata.Namespace, victim.Metadata.Name, err))
+		}
+		log.Printf("Preempted pod %s/%s on node %s for pod %s/%s", victim.Metadata.Namespace, victim.Metadata.Name, nodeName, pod.Metadata.Namespace, pod.Metadata.Name)
+	}
+	return nodeName, nil
+}
+
+// selectVictimsOnNode returns the pods of lower priority that have to leave
+// node nodeInfos[i] for the pod to fit there, or nil if evicting them all
+// wouldn't be enough. Victims are kept in priority order and as many as
+// possible are spared, the most important first.
+func (p *DefaultPreemption) selectVictimsOnNode(pod *types.Pod, nodeInfos []*NodeInfo, i int) (*preemptionCandidate, *Status) {
+	priority := types.PodPriority(pod)
+	nodeInfo := nodeInfos[i].clone()
+
+	var potentialVictims []*types.Pod
+	for _, existing := range nodeInfo.Pods {
+		if types.PodPriority(existing) < priority {
+			potentialVictims = append(potentialVictims, existing)
+		}
+	}
+	if len(potentialVictims) == 0 {
+		return nil, nil
+	}
+	for _, victim := range potentialVictims {
+		nodeInfo.RemovePod(victim)
+	}
+
+	// Filter against the cluster as it would be with the node changed
+	trial := append(append(append([]*NodeInfo(nil), nodeInfos[:i]...), nodeInfo), nodeInfos[i+1:]...)
+	fits, status := p.fits(pod, trial, nodeInfo)
+	if !fits || !status.IsSuccess() {
+		return nil, status
+	}
+
+	sort.SliceStable(potentialVictims, func(a, b int) bool {
+		return types.PodPriority(potentialVictims[a]) > types.PodPriority(potentialVictims[b])
+	})
+
+	var victims []*types.Pod
+	for _, victim := range potentialVictims {
+		nodeInfo.AddPod(victim)
+		fits, status := p.fits(pod, trial, nodeInfo)
+		if !status.IsSuccess() {
+			return nil, status
+		}
+		if !fits {
+			nodeInfo.RemovePod(victim)
+			victims = append(victims, victim)
+		}
+	}
+	return newPreemptionCandidate(nodeInfos[i], victims), nil
+}
+
+// fits reports whether the pod passes the filters on nodeInfo, one of
+// nodeInfos
+func (p *DefaultPreemption) fits(pod *types.Pod, nodeInfos []*NodeInfo, nodeInfo *NodeInfo) (bool, *Status) {
+	state := NewCycleState()
+	status := p.handle.RunPreFilterPlugins(state, pod, nodeInfos)
+	if status.Code() == Unschedulable {
+		return false, nil
+	}
+	if !status.IsSuccess() {
+		return false, status
+	}
+
+	status = p.handle.RunFilterPlugins(state, pod, nodeInfo)
+	if status.Code() == Unschedulable {
+		return false, nil
+	}
+	return status.IsSuccess(), status
+}
@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+func priorityPod(name, cpu string, priority int32) *types.Pod {
+	pod := testPod(name, cpu, "64Mi")
+	pod.Spec.Priority = &priority
+	return pod
+}
+
+func boundPod(name, cpu string, priority int32, nodeName string) *types.Pod {
+	pod := priorityPod(name, cpu, priority)
+	pod.Spec.NodeName = nodeName
+	pod.Status.Phase = "Running"
+	return pod
+}
+
+func TestPendingPodsOrderedByPriority(t *testing.T) {
+	repo := NewMockRepository()
+	repo.priorityClasses = []*types.PriorityClass{
+		{Metadata: types.ObjectMeta{Name: "critical"}, Value: 1000},
+		{Metadata: types.ObjectMeta{Name: "standard"}, Value: 100, GlobalDefault: true},
+	}
+
+	created := time.Now()
+	add := func(pod *types.Pod, age time.Duration) {
+		pod.Metadata.CreatedAt = created.Add(-age)
+		repo.pods[pod.Metadata.UID] = pod
+	}
+	add(priorityPod("batch", "100m", 10), 3*time.Minute)
+	add(testPod("default-old", "100m", "64Mi"), 2*time.Minute)
+	add(testPod("default-new", "100m", "64Mi"), time.Minute)
+	critical := testPod("critical", "100m", "64Mi")
+	critical.Spec.PriorityClassName = "critical"
+	add(critical, 0)
+
+	pods, err := NewScheduler(repo).getPendingPods()
+	if err != nil {
+		t.Fatalf("Failed to get pending pods: %v", err)
+	}
+
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Metadata.Name)
+	}
+	if got := strings.Join(names, ","); got != "critical,default-old,default-new,batch" {
+		t.Errorf("Expected pods by priority then age, got %s", got)
+	}
+	if priority := types.PodPriority(pods[1]); priority != 100 {
+		t.Errorf("Expected the global default priority 100, got %d", priority)
+	}
+}
+
+func TestPreemptionMinimalDisruption(t *testing.T) {
+	repo := NewMockRepository()
+	repo.CreateNode(testNode("node-a", "4", "8Gi", nil))
+	repo.CreateNode(testNode("node-b", "4", "8Gi", nil))
+
+	// Making room on node-a takes one low priority pod; on node-b, two
+	for _, pod := range []*types.Pod{
+		boundPod("mid-a", "2", 50, "node-a"),
+		boundPod("low-a", "2", 10, "node-a"),
+		boundPod("low-b1", "1", 10, "node-b"),
+		boundPod("low-b2", "1", 10, "node-b"),
+		boundPod("low-b3", "2", 10, "node-b"),
+	} {
+		repo.pods[pod.Metadata.UID] = pod
+	}
+	high := priorityPod("high", "2", 100)
+	repo.pods[high.Metadata.UID] = high
+	victim := repo.pods["low-a-uid"]
+
+	scheduler := NewScheduler(repo)
+	if err := scheduler.schedulePendingPods(); err != nil {
+		t.Fatalf("Failed to schedule pods: %v", err)
+	}
+
+	if !types.IsPodTerminating(victim) || victim.Status.Phase != types.PodPhaseTerminating {
+		t.Error("Expected low-a to be preempted gracefully")
+	}
+	for _, name := range []string{"mid-a", "low-b1", "low-b2", "low-b3"} {
+		if _, ok := repo.pods[name+"-uid"]; !ok {
+			t.Errorf("Expected %s to keep running", name)
+		}
+	}
+	if high.Status.NominatedNodeName != "node-a" {
+		t.Errorf("Expected high to be nominated to node-a, got %q", high.Status.NominatedNodeName)
+	}
+
+	// The victim's deletion retries the pod once its backoff is over, and
+	// with the victim gone it is bound to its nominated node
+	delete(repo.pods, victim.Metadata.UID)
+	scheduler.handlePodEvent(storage.WatchEvent{Type: storage.EventDeleted, Resource: podResource(victim.Metadata.UID, victim)})
+	advanceQueueClock(scheduler.queue, DefaultPodInitialBackoff)
+	if err := scheduler.schedulePendingPods(); err != nil {
+		t.Fatalf("Failed to schedule pods: %v", err)
+	}
+	if nodeID := repo.podAssignments["high-uid"]; nodeID != "node-a-uid" {
+		t.Errorf("Expected high on node-a, got %q", nodeID)
+	}
+	if high.Status.NominatedNodeName != "" {
+		t.Errorf("Expected the nomination to be cleared on binding, got %q", high.Status.NominatedNodeName)
+	}
+}
+
+func TestPreemptionSparesEqualPriority(t *testing.T) {
+	repo := NewMockRepository()
+	repo.CreateNode(testNode("node-a", "4", "8Gi", nil))
+	running := boundPod("running", "3", 100, "node-a")
+	repo.pods[running.Metadata.UID] = running
+	pending := priorityPod("pending", "2", 100)
+	repo.pods[pending.Metadata.UID] = pending
+
+	if err := NewScheduler(repo).schedulePendingPods(); err != nil {
+		t.Fatalf("Failed to schedule pods: %v", err)
+	}
+	if _, ok := repo.pods["running-uid"]; !ok {
+		t.Error("Expected a pod of equal priority not to be preempted")
+	}
+	if pending.Status.NominatedNodeName != "" {
+		t.Errorf("Expected no nomination, got %q", pending.Status.NominatedNodeName)
+	}
+}
+
+func TestNominatedPodsKeepTheirRoom(t *testing.T) {
+	nominated := priorityPod("nominated", "3", 100)
+	nominated.Status.NominatedNodeName = "node-a"
+	pending := []*types.Pod{nominated}
+
+	// A lower priority pod sees the room as taken
+	nodeInfos := newNodeInfos([]*types.Node{testNode("node-a", "4", "8Gi", nil), testNode("node-b", "4", "8Gi", nil)})
+	low := priorityPod("low", "2", 10)
+	addNominatedPods(low, nodeInfos, pending)
+	if nodeInfos[0].Requested.MilliCPU != 3000 || nodeInfos[1].Requested.MilliCPU != 0 {
+		t.Errorf("Expected the nominated pod's 3 CPUs on node-a only, got %d and %d", nodeInfos[0].Requested.MilliCPU, nodeInfos[1].Requested.MilliCPU)
+	}
+
+	// A higher priority pod doesn't
+	nodeInfos = newNodeInfos([]*types.Node{testNode("node-a", "4", "8Gi", nil)})
+	addNominatedPods(priorityPod("higher", "2", 1000), nodeInfos, pending)
+	if nodeInfos[0].Requested.MilliCPU != 0 {
+		t.Errorf("Expected node-a to be free for a higher priority pod, got %d", nodeInfos[0].Requested.MilliCPU)
+	}
+}
@@ -0,0 +1,294 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"reflect"
+	"time"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// watchEvents passes every change to resources of kind made after revision
+// to handle, until ctx is done. Watches are resumed after errors. When the
+// history was compacted the missed events can't be replayed, so resync is
+// called to rebuild the state from a fresh listing instead.
+func watchEvents(ctx context.Context, repository storage.Repository, kind string, revision int64, handle func(storage.WatchEvent), resync func()) {
+	wait := func() bool {
+		select {
+		case <-time.After(time.Second):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for ctx.Err() == nil {
+		events, err := repository.Watch(ctx, kind, "", revision)
+		if errors.Is(err, storage.ErrRevisionCompacted) {
+			if current, err := repository.CurrentRevision(); err == nil {
+				revision = current
+				resync()
+				continue
+			}
+		}
+		if err != nil {
+			log.Printf("Failed to watch %s: %v", kind, err)
+			if !wait() {
+				return
+			}
+			continue
+		}
+
+		for event := range events {
+			revision = event.Revision
+			handle(event)
+		}
+
+		if !wait() {
+			return
+		}
+	}
+}
+
+// startEventHandlers feeds the scheduling queue from pod, node, priority
+// class and scheduler lease events until ctx is done. Pending pods are
+// queued; changes that may let unschedulable pods fit move them back to be
+// retried.
+func (s *Scheduler) startEventHandlers(ctx context.Context) error {
+	revision, err := s.repository.CurrentRevision()
+	if err != nil {
+		return err
+	}
+	s.resync()
+
+	handlers := map[string]func(storage.WatchEvent){
+		"Pod":              s.handlePodEvent,
+		"Node":             s.handleNodeEvent,
+		"PriorityClass":    s.handlePriorityClassEvent,
+		schedulerLeaseKind: s.handleSchedulerLeaseEvent,
+	}
+	for kind, handle := range handlers {
+		s.wg.Add(1)
+		go func(kind string, handle func(storage.WatchEvent)) {
+			defer s.wg.Done()
+			watchEvents(ctx, s.repository, kind, revision, handle, s.resync)
+		}(kind, handle)
+	}
+	return nil
+}
+
+// resync reloads the priority classes and queues every pending pod, and
+// retries the unschedulable pods since events may have been missed
+func (s *Scheduler) resync() {
+	s.loadPriorityClasses()
+
+	pendingPods, err := s.getPendingPods()
+	if err != nil {
+		log.Printf("Failed to list pending pods: %v", err)
+	}
+	for _, pod := range pendingPods {
+		s.queuePendingPod(pod)
+	}
+	s.queue.MoveAllToActiveOrBackoff(nil)
+	s.invalidateCache()
+}
+
+// loadPriorityClasses refreshes the priority classes that pods from events
+// are resolved against
+func (s *Scheduler) loadPriorityClasses() {
+	classes, err := listPriorityClasses(s.repository)
+	if err != nil {
+		log.Printf("Failed to load priority classes: %v", err)
+		return
+	}
+	s.mu.Lock()
+	s.priorityClasses = classes
+	s.mu.Unlock()
+}
+
+// invalidateCache makes the next scheduling cycle rebuild the cache from
+// storage. Pod and node events are applied to the cache as they come, so this
+// is only needed on startup and resync.
+func (s *Scheduler) invalidateCache() {
+	s.mu.Lock()
+	s.cacheStale = true
+	s.mu.Unlock()
+}
+
+// syncCache rebuilds the cache from storage if it was invalidated
+func (s *Scheduler) syncCache() error {
+	s.mu.Lock()
+	stale := s.cacheStale
+	s.cacheStale = false
+	s.mu.Unlock()
+	if !stale {
+		return nil
+	}
+
+	if err := s.cache.Sync(s.repository); err != nil {
+		s.invalidateCache()
+		return err
+	}
+	return nil
+}
+
+// needsScheduling reports whether a pod is waiting for a node
+func needsScheduling(pod *types.Pod) bool {
+	if pod.Spec.NodeName != "" {
+		return false
+	}
+	switch pod.Status.Phase {
+	case "Scheduled", "Succeeded", "Failed":
+		return false
+	}
+	return true
+}
+
+// holdsResources reports whether a pod takes up room on a node
+func holdsResources(pod *types.Pod) bool {
+	return pod.Spec.NodeName != "" && pod.Status.Phase != "Succeeded" && pod.Status.Phase != "Failed"
+}
+
+// handlePodEvent queues pending pods and drops the others from the queue.
+// Pods leaving their node free room for unschedulable pods, and pods
+// arriving on a node may satisfy the pod affinity of others.
+func (s *Scheduler) handlePodEvent(event storage.WatchEvent) {
+	pod, err := resourceToPod(event.Resource)
+	if err != nil {
+		log.Printf("Failed to decode pod from event: %v", err)
+		return
+	}
+	s.mu.Lock()
+	resolvePriority(pod, s.priorityClasses)
+	s.mu.Unlock()
+
+	if event.Type == storage.EventDeleted {
+		s.cache.RemovePod(pod)
+		s.queue.Delete(pod)
+		if holdsResources(pod) {
+			s.queue.MoveAllToActiveOrBackoff(nil)
+		}
+		return
+	}
+	s.cache.UpdatePod(pod)
+
+	if needsScheduling(pod) {
+		s.queuePendingPod(pod)
+		return
+	}
+	s.queue.Delete(pod)
+	switch {
+	case !holdsResources(pod) && pod.Spec.NodeName != "":
+		// The pod finished, so its requests no longer count
+		s.queue.MoveAllToActiveOrBackoff(nil)
+	case event.Type == storage.EventAdded || pod.Status.Phase == "Scheduled":
+		s.queue.MoveAllToActiveOrBackoff(hasPodAffinity)
+	}
+}
+
+// queuePendingPod queues a pod that needs scheduling if it names one of the
+// profiles this scheduler runs. A pod naming another scheduler is left to it,
+// or marked as waiting for a scheduler that isn't running.
+func (s *Scheduler) queuePendingPod(pod *types.Pod) {
+	if _, ok := s.frameworkForPod(pod); ok {
+		s.queue.Add(pod)
+		return
+	}
+	s.queue.Delete(pod)
+	s.recordSchedulerNotFound(pod)
+}
+
+// handleSchedulerLeaseEvent marks the pending pods of a profile whose
+// scheduler stopped, or whose lease expired, as waiting for a scheduler
+func (s *Scheduler) handleSchedulerLeaseEvent(event storage.WatchEvent) {
+	name := event.Resource.Name
+	if _, ok := s.profiles[name]; ok || event.Type != storage.EventDeleted {
+		return
+	}
+
+	pendingPods, err := s.getPendingPods()
+	if err != nil {
+		log.Printf("Failed to list pending pods: %v", err)
+		return
+	}
+	for _, pod := range pendingPods {
+		if podSchedulerName(pod) == name {
+			s.queuePendingPod(pod)
+		}
+	}
+}
+
+// hasPodAffinity reports whether a pod has required pod affinity terms,
+// which only a pod arriving on a node can satisfy
+func hasPodAffinity(pod *types.Pod) bool {
+	affinity := pod.Spec.Affinity
+	return affinity != nil && affinity.PodAffinity != nil && len(affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution) > 0
+}
+
+// nodeSchedulingProperties are the parts of a node scheduling decisions
+// depend on; heartbeats that change nothing else don't retry pods
+type nodeSchedulingProperties struct {
+	Labels        map[string]string
+	Unschedulable bool
+	Taints        []types.Taint
+	Allocatable   types.ResourceList
+	Ready         bool
+}
+
+// handleNodeEvent retries the unschedulable pods when a node is added or one
+// of its scheduling properties changes
+func (s *Scheduler) handleNodeEvent(event storage.WatchEvent) {
+	name := event.Resource.Name
+
+	if event.Type == storage.EventDeleted {
+		s.cache.RemoveNode(name)
+		s.mu.Lock()
+		delete(s.nodeProperties, name)
+		s.mu.Unlock()
+		return
+	}
+
+	var node types.Node
+	if err := json.Unmarshal([]byte(event.Resource.Metadata), &node.Metadata); err != nil {
+		log.Printf("Failed to decode node %s from event: %v", name, err)
+		return
+	}
+	if err := json.Unmarshal([]byte(event.Resource.Spec), &node.Spec); err != nil {
+		log.Printf("Failed to decode node %s from event: %v", name, err)
+		return
+	}
+	if event.Resource.Status != "" {
+		if err := json.Unmarshal([]byte(event.Resource.Status), &node.Status); err != nil {
+			log.Printf("Failed to decode node %s from event: %v", name, err)
+			return
+		}
+	}
+	node.Metadata.Name = name
+	s.cache.UpdateNode(&node)
+
+	properties := nodeSchedulingProperties{
+		Labels:        node.Metadata.Labels,
+		Unschedulable: node.Spec.Unschedulable,
+		Taints:        node.Spec.Taints,
+		Allocatable:   node.Status.Allocatable,
+		Ready:         isNodeReady(&node),
+	}
+
+	s.mu.Lock()
+	previous, known := s.nodeProperties[name]
+	s.nodeProperties[name] = properties
+	s.mu.Unlock()
+
+	if !known || !reflect.DeepEqual(previous, properties) {
+		s.queue.MoveAllToActiveOrBackoff(nil)
+	}
+}
+
+// handlePriorityClassEvent reloads the priority classes
+func (s *Scheduler) handlePriorityClassEvent(event storage.WatchEvent) {
+	s.loadPriorityClasses()
+}
@@ -0,0 +1,338 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+const (
+	// MaxExtenderPriority is the highest score an extender may give a node.
+	// Extender scores are scaled to MaxNodeScore before they are weighted.
+	MaxExtenderPriority int64 = 10
+	// DefaultExtenderTimeout is how long a call to an extender may take when
+	// its config sets no timeout
+	DefaultExtenderTimeout = 5 * time.Second
+)
+
+// ExtenderConfig configures an HTTP extender of a profile. The scheduler
+// POSTs to urlPrefix/verb for each verb set:
+//
+//	{
+//	  "urlPrefix": "http://127.0.0.1:8888/scheduler",
+//	  "filterVerb": "filter",
+//	  "prioritizeVerb": "prioritize",
+//	  "bindVerb": "bind",
+//	  "weight": 2,
+//	  "httpTimeout": "2s",
+//	  "ignorable": true
+//	}
+type ExtenderConfig struct {
+	URLPrefix string `json:"urlPrefix"`
+	// FilterVerb is called with the nodes that passed the filter plugins,
+	// and returns those the pod may run on
+	FilterVerb string `json:"filterVerb,omitempty"`
+	// PrioritizeVerb is called with the feasible nodes, and returns a score
+	// from 0 to MaxExtenderPriority for each
+	PrioritizeVerb string `json:"prioritizeVerb,omitempty"`
+	// BindVerb is called with the chosen node before the binding is recorded;
+	// an error fails the binding
+	BindVerb string `json:"bindVerb,omitempty"`
+	// Weight multiplies the extender's scores; 0 means 1
+	Weight int64 `json:"weight,omitempty"`
+	// HTTPTimeout bounds each call, e.g. "500ms"; empty means
+	// DefaultExtenderTimeout
+	HTTPTimeout string `json:"httpTimeout,omitempty"`
+	// Ignorable makes the scheduler skip the extender when a filter or
+	// prioritize call fails, instead of failing the scheduling attempt
+	Ignorable bool `json:"ignorable,omitempty"`
+}
+
+// validate checks that the extender has a URL, at least one verb, a
+// sensible weight and a valid timeout
+func (c *ExtenderConfig) validate() error {
+	if c.URLPrefix == "" {
+		return fmt.Errorf("extender has no urlPrefix")
+	}
+	if c.FilterVerb == "" && c.PrioritizeVerb == "" && c.BindVerb == "" {
+		return fmt.Errorf("extender %s has no verbs", c.URLPrefix)
+	}
+	if c.Weight < 0 {
+		return fmt.Errorf("extender %s has negative weight %d", c.URLPrefix, c.Weight)
+	}
+	if _, err := c.timeout(); err != nil {
+		return fmt.Errorf("extender %s: %w", c.URLPrefix, err)
+	}
+	return nil
+}
+
+// timeout parses the HTTP timeout of the extender
+func (c *ExtenderConfig) timeout() (time.Duration, error) {
+	if c.HTTPTimeout == "" {
+		return DefaultExtenderTimeout, nil
+	}
+	timeout, err := time.ParseDuration(c.HTTPTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid httpTimeout: %w", err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("httpTimeout must be positive, got %s", c.HTTPTimeout)
+	}
+	return timeout, nil
+}
+
+// ExtenderArgs is the body of filter and prioritize calls: the pod and the
+// candidate nodes
+type ExtenderArgs struct {
+	Pod   *types.Pod    `json:"pod"`
+	Nodes []*types.Node `json:"nodes"`
+}
+
+// ExtenderFilterResult is the response to a filter call. Nodes not listed in
+// NodeNames are filtered out, for the reason in FailedNodes if there is one.
+type ExtenderFilterResult struct {
+	NodeNames   []string          `json:"nodeNames"`
+	FailedNodes map[string]string `json:"failedNodes,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// HostPriority is the score an extender gives a node in response to a
+// prioritize call
+type HostPriority struct {
+	Host  string `json:"host"`
+	Score int64  `json:"score"`
+}
+
+// ExtenderBindingArgs is the body of a bind call
+type ExtenderBindingArgs struct {
+	PodName      string `json:"podName"`
+	PodNamespace string `json:"podNamespace"`
+	PodUID       string `json:"podUID"`
+	Node         string `json:"node"`
+}
+
+// ExtenderBindingResult is the response to a bind call
+type ExtenderBindingResult struct {
+	Error string `json:"error,omitempty"`
+}
+
+// errReasonExtenderRejected is why a node was filtered out when the
+// extender gave no reason
+const errReasonExtenderRejected = "node(s) were rejected by extender"
+
+// HTTPExtender calls an external HTTP endpoint to filter, score and bind
+// pods, for placement rules that depend on data the scheduler doesn't have
+type HTTPExtender struct {
+	config ExtenderConfig
+	weight int64
+	client *http.Client
+}
+
+// NewHTTPExtender creates an extender from its config
+func NewHTTPExtender(config ExtenderConfig) (*HTTPExtender, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	timeout, _ := config.timeout()
+	weight := config.Weight
+	if weight == 0 {
+		weight = 1
+	}
+	return &HTTPExtender{
+		config: config,
+		weight: weight,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Name identifies the extender by its URL prefix
+func (e *HTTPExtender) Name() string {
+	return e.config.URLPrefix
+}
+
+// IsIgnorable reports whether filter and prioritize failures are skipped
+func (e *HTTPExtender) IsIgnorable() bool {
+	return e.config.Ignorable
+}
+
+// IsBinder reports whether the extender is called to bind pods
+func (e *HTTPExtender) IsBinder() bool {
+	return e.config.BindVerb != ""
+}
+
+// Filter returns the nodes the extender accepts for the pod, and the reasons
+// the others were rejected for
+func (e *HTTPExtender) Filter(pod *types.Pod, nodeInfos []*NodeInfo) ([]*NodeInfo, map[string]string, error) {
+	if e.config.FilterVerb == "" {
+		return nodeInfos, nil, nil
+	}
+
+	var result ExtenderFilterResult
+	if err := e.send(e.config.FilterVerb, extenderArgs(pod, nodeInfos), &result); err != nil {
+		return nil, nil, err
+	}
+	if result.Error != "" {
+		return nil, nil, fmt.Errorf("extender %s: %s", e.Name(), result.Error)
+	}
+
+	accepted := make(map[string]bool, len(result.NodeNames))
+	for _, name := range result.NodeNames {
+		accepted[name] = true
+	}
+	var feasible []*NodeInfo
+	failed := make(map[string]string)
+	for _, nodeInfo := range nodeInfos {
+		name := nodeInfo.Node.Metadata.Name
+		if accepted[name] {
+			feasible = append(feasible, nodeInfo)
+			continue
+		}
+		reason := result.FailedNodes[name]
+		if reason == "" {
+			reason = errReasonExtenderRejected
+		}
+		failed[name] = reason
+	}
+	return feasible, failed, nil
+}
+
+// Prioritize returns the extender's scores for the nodes, by node name, and
+// its weight. Nodes the extender didn't score get 0.
+func (e *HTTPExtender) Prioritize(pod *types.Pod, nodeInfos []*NodeInfo) (map[string]int64, int64, error) {
+	if e.config.PrioritizeVerb == "" {
+		return nil, 0, nil
+	}
+
+	var result []HostPriority
+	if err := e.send(e.config.PrioritizeVerb, extenderArgs(pod, nodeInfos), &result); err != nil {
+		return nil, 0, err
+	}
+
+	scores := make(map[string]int64, len(result))
+	for _, priority := range result {
+		if priority.Score < 0 || priority.Score > MaxExtenderPriority {
+			return nil, 0, fmt.Errorf("extender %s gave node %s score %d, outside [0, %d]", e.Name(), priority.Host, priority.Score, MaxExtenderPriority)
+		}
+		scores[priority.Host] = priority.Score
+	}
+	return scores, e.weight, nil
+}
+
+// Bind asks the extender to bind the pod to the node
+func (e *HTTPExtender) Bind(pod *types.Pod, nodeName string) error {
+	if e.config.BindVerb == "" {
+		return nil
+	}
+
+	args := ExtenderBindingArgs{
+		PodName:      pod.Metadata.Name,
+		PodNamespace: pod.Metadata.Namespace,
+		PodUID:       pod.Metadata.UID,
+		Node:         nodeName,
+	}
+	var result ExtenderBindingResult
+	if err := e.send(e.config.BindVerb, args, &result); err != nil {
+		return err
+	}
+	if result.Error != "" {
+		return fmt.Errorf("extender %s: %s", e.Name(), result.Error)
+	}
+	return nil
+}
+
+// send POSTs args as JSON to the verb's URL and decodes the response into
+// result
+func (e *HTTPExtender) send(verb string, args interface{}, result interface{}) error {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("failed to marshal extender args: %w", err)
+	}
+
+	url := strings.TrimRight(e.config.URLPrefix, "/") + "/" + verb
+	resp, err := e.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("extender %s: %w", e.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("extender %s: %s returned status %d", e.Name(), verb, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("extender %s: failed to decode %s response: %w", e.Name(), verb, err)
+	}
+	return nil
+}
+
+// extenderArgs builds the body of a filter or prioritize call
+func extenderArgs(pod *types.Pod, nodeInfos []*NodeInfo) ExtenderArgs {
+	nodes := make([]*types.Node, 0, len(nodeInfos))
+	for _, nodeInfo := range nodeInfos {
+		nodes = append(nodes, nodeInfo.Node)
+	}
+	return ExtenderArgs{Pod: pod, Nodes: nodes}
+}
+
+// RunFilterExtenders narrows the feasible nodes down to those every extender
+// accepts, counting the rejections in fitErr. An ignorable extender that
+// fails is skipped.
+func (f *Framework) RunFilterExtenders(pod *types.Pod, feasible []*NodeInfo, fitErr *FitError) ([]*NodeInfo, error) {
+	for _, extender := range f.extenders {
+		if len(feasible) == 0 {
+			break
+		}
+		filtered, failed, err := extender.Filter(pod, feasible)
+		if err != nil {
+			if extender.IsIgnorable() {
+				log.Printf("Skipping extender %s: %v", extender.Name(), err)
+				continue
+			}
+			return nil, err
+		}
+		for _, reason := range failed {
+			fitErr.Reasons[reason]++
+		}
+		feasible = filtered
+	}
+	return feasible, nil
+}
+
+// RunPrioritizeExtenders adds the weighted scores of the extenders, scaled
+// to MaxNodeScore, to the node scores. An ignorable extender that fails is
+// skipped.
+func (f *Framework) RunPrioritizeExtenders(pod *types.Pod, nodeInfos []*NodeInfo, scores []NodeScore) error {
+	for _, extender := range f.extenders {
+		extenderScores, weight, err := extender.Prioritize(pod, nodeInfos)
+		if err != nil {
+			if extender.IsIgnorable() {
+				log.Printf("Skipping extender %s: %v", extender.Name(), err)
+				continue
+			}
+			return err
+		}
+		for i := range scores {
+			scores[i].Score += extenderScores[scores[i].Name] * weight * MaxNodeScore / MaxExtenderPriority
+		}
+	}
+	return nil
+}
+
+// RunBindExtenders asks the extenders with a bind verb to bind the pod to the
+// node. Bind failures are never ignored.
+func (f *Framework) RunBindExtenders(pod *types.Pod, nodeName string) error {
+	for _, extender := range f.extenders {
+		if !extender.IsBinder() {
+			continue
+		}
+		if err := extender.Bind(pod, nodeName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// fakeExtender is a stand-in extender server. It rejects the nodes listed in
+// rejected, scores nodes from scores and records the bindings it is asked
+// for.
+type fakeExtender struct {
+	mu       sync.Mutex
+	rejected map[string]string
+	scores   map[string]int64
+	bindErr  string
+	delay    time.Duration
+	bindings []ExtenderBindingArgs
+}
+
+func (e *fakeExtender) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(e.delay)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch strings.TrimPrefix(r.URL.Path, "/scheduler/") {
+	case "filter":
+		var args ExtenderArgs
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result := ExtenderFilterResult{FailedNodes: make(map[string]string)}
+		for _, node := range args.Nodes {
+			if reason, ok := e.rejected[node.Metadata.Name]; ok {
+				result.FailedNodes[node.Metadata.Name] = reason
+				continue
+			}
+			result.NodeNames = append(result.NodeNames, node.Metadata.Name)
+		}
+		json.NewEncoder(w).Encode(result)
+	case "prioritize":
+		var args ExtenderArgs
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var result []HostPriority
+		for _, node := range args.Nodes {
+			result = append(result, HostPriority{Host: node.Metadata.Name, Score: e.scores[node.Metadata.Name]})
+		}
+		json.NewEncoder(w).Encode(result)
+	case "bind":
+		var args ExtenderBindingArgs
+		if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		e.bindings = append(e.bindings, args)
+		json.NewEncoder(w).Encode(ExtenderBindingResult{Error: e.bindErr})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// extenderScheduler starts a stand-in extender and a scheduler whose default
+// profile calls it
+func extenderScheduler(t *testing.T, extender *fakeExtender, config ExtenderConfig) (*Scheduler, *MockRepository) {
+	server := httptest.NewServer(extender)
+	t.Cleanup(server.Close)
+
+	config.URLPrefix = server.URL + "/scheduler"
+	profile := DefaultProfile()
+	profile.Extenders = []ExtenderConfig{config}
+
+	repo := NewMockRepository()
+	scheduler, err := NewSchedulerWithProfile(repo, profile, NewInTreeRegistry())
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	return scheduler, repo
+}
+
+func extenderNodes() []*NodeInfo {
+	return newNodeInfos([]*types.Node{
+		testNode("rack-1", "4", "8Gi", nil),
+		testNode("rack-2", "4", "8Gi", nil),
+		testNode("rack-3", "4", "8Gi", nil),
+	})
+}
+
+func TestExtenderFilter(t *testing.T) {
+	extender := &fakeExtender{rejected: map[string]string{
+		"rack-1": "power budget exceeded",
+		"rack-2": "power budget exceeded",
+	}}
+	scheduler, _ := extenderScheduler(t, extender, ExtenderConfig{FilterVerb: "filter"})
+
+	selected, err := scheduler.selectNode(NewCycleState(), testPod("web", "1", "1Gi"), extenderNodes())
+	if err != nil {
+		t.Fatalf("Failed to select node: %v", err)
+	}
+	if selected.Node.Metadata.Name != "rack-3" {
+		t.Errorf("Expected rack-3, got %s", selected.Node.Metadata.Name)
+	}
+
+	// Nodes rejected by the extender are counted with the other reasons
+	extender.rejected["rack-3"] = "no licenses left"
+	_, err = scheduler.selectNode(NewCycleState(), testPod("web", "1", "1Gi"), extenderNodes())
+	expected := "0/3 nodes are available: 1 no licenses left, 2 power budget exceeded"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
+
+func TestExtenderPrioritizeWeight(t *testing.T) {
+	// The resource plugins prefer the emptier rack-1; the extender prefers
+	// rack-3 and outweighs them only with a weight
+	nodeInfos := extenderNodes()
+	nodeInfos[1].AddPod(testPod("existing-2", "2", "4Gi"))
+	nodeInfos[2].AddPod(testPod("existing-3", "2", "4Gi"))
+	extender := &fakeExtender{scores: map[string]int64{"rack-3": 4}}
+
+	tests := []struct {
+		weight   int64
+		expected string
+	}{
+		{1, "rack-1"},
+		{5, "rack-3"},
+	}
+	for _, tt := range tests {
+		scheduler, _ := extenderScheduler(t, extender, ExtenderConfig{PrioritizeVerb: "prioritize", Weight: tt.weight})
+		selected, err := scheduler.selectNode(NewCycleState(), testPod("web", "1", "1Gi"), nodeInfos)
+		if err != nil {
+			t.Fatalf("Failed to select node: %v", err)
+		}
+		if selected.Node.Metadata.Name != tt.expected {
+			t.Errorf("Weight %d: expected %s, got %s", tt.weight, tt.expected, selected.Node.Metadata.Name)
+		}
+	}
+}
+
+func TestExtenderTimeout(t *testing.T) {
+	extender := &fakeExtender{
+		rejected: map[string]string{"rack-1": "power budget exceeded"},
+		delay:    200 * time.Millisecond,
+	}
+
+	// A failing extender fails the attempt, unless it is ignorable
+	scheduler, _ := extenderScheduler(t, extender, ExtenderConfig{FilterVerb: "filter", HTTPTimeout: "20ms"})
+	_, err := scheduler.selectNode(NewCycleState(), testPod("web", "1", "1Gi"), extenderNodes())
+	if err == nil {
+		t.Fatal("Expected the extender timeout to fail the attempt")
+	}
+	if _, ok := err.(*FitError); ok {
+		t.Errorf("Expected a scheduler error, got FitError %v", err)
+	}
+
+	scheduler, _ = extenderScheduler(t, extender, ExtenderConfig{FilterVerb: "filter", HTTPTimeout: "20ms", Ignorable: true})
+	if _, err := scheduler.selectNode(NewCycleState(), testPod("web", "1", "1Gi"), extenderNodes()); err != nil {
+		t.Errorf("Expected the ignorable extender to be skipped, got %v", err)
+	}
+}
+
+func TestExtenderBind(t *testing.T) {
+	extender := &fakeExtender{}
+	scheduler, repo := extenderScheduler(t, extender, ExtenderConfig{BindVerb: "bind"})
+
+	pod := testPod("web", "1", "1Gi")
+	repo.pods[pod.Metadata.UID] = pod
+	if err := scheduler.schedulePod(pod, extenderNodes()[:1]); err != nil {
+		t.Fatalf("Failed to schedule pod: %v", err)
+	}
+	expected := ExtenderBindingArgs{PodName: "web", PodNamespace: "default", PodUID: "web-uid", Node: "rack-1"}
+	if len(extender.bindings) != 1 || extender.bindings[0] != expected {
+		t.Errorf("Expected binding %+v, got %+v", expected, extender.bindings)
+	}
+	if repo.podAssignments["web-uid"] != "rack-1-uid" {
+		t.Errorf("Expected web to be assigned to rack-1, got %q", repo.podAssignments["web-uid"])
+	}
+
+	// A bind error fails the binding
+	extender.bindErr = "license server unavailable"
+	other := testPod("other", "1", "1Gi")
+	repo.pods[other.Metadata.UID] = other
+	err := scheduler.schedulePod(other, extenderNodes()[:1])
+	if err == nil || !strings.Contains(err.Error(), "license server unavailable") {
+		t.Errorf("Expected the bind error, got %v", err)
+	}
+	if _, assigned := repo.podAssignments["other-uid"]; assigned {
+		t.Error("Expected other not to be assigned")
+	}
+	if scheduler.cache.IsAssumed(other) {
+		t.Error("Expected other to be forgotten by the cache")
+	}
+}
+
+func TestExtenderConfigValidation(t *testing.T) {
+	invalid := []ExtenderConfig{
+		{FilterVerb: "filter"},
+		{URLPrefix: "http://extender"},
+		{URLPrefix: "http://extender", FilterVerb: "filter", Weight: -1},
+		{URLPrefix: "http://extender", FilterVerb: "filter", HTTPTimeout: "soon"},
+		{URLPrefix: "http://extender", FilterVerb: "filter", HTTPTimeout: "0s"},
+	}
+	for _, config := range invalid {
+		if _, err := NewHTTPExtender(config); err == nil {
+			t.Errorf("Expected extender config %+v to be rejected", config)
+		}
+	}
+}
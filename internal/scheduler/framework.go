@@ -0,0 +1,467 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// MaxNodeScore is the highest score a score plugin may give a node after
+// normalization
+const MaxNodeScore int64 = 100
+
+// Code is the result of running a plugin
+type Code int
+
+const (
+	// Success means the plugin ran and, for filters, that the node fits
+	Success Code = iota
+	// Unschedulable means the pod doesn't fit; the reasons say why
+	Unschedulable
+	// Error means the plugin failed for a reason unrelated to the pod's fit
+	Error
+	// Skip means a bind plugin chose not to bind the pod
+	Skip
+)
+
+// Status is the outcome of running a plugin. A nil status is a success.
+type Status struct {
+	code    Code
+	reasons []string
+	err     error
+}
+
+// NewStatus returns a status with the given code and reasons
+func NewStatus(code Code, reasons ...string) *Status {
+	return &Status{code: code, reasons: reasons}
+}
+
+// AsStatus wraps an error in a status with code Error
+func AsStatus(err error) *Status {
+	if err == nil {
+		return nil
+	}
+	return &Status{code: Error, reasons: []string{err.Error()}, err: err}
+}
+
+// Code returns the code of the status
+func (s *Status) Code() Code {
+	if s == nil {
+		return Success
+	}
+	return s.code
+}
+
+// IsSuccess reports whether the status is a success
+func (s *Status) IsSuccess() bool {
+	return s.Code() == Success
+}
+
+// Reasons returns the reasons recorded in the status
+func (s *Status) Reasons() []string {
+	if s == nil {
+		return nil
+	}
+	return s.reasons
+}
+
+// Message joins the reasons of the status
+func (s *Status) Message() string {
+	return strings.Join(s.Reasons(), ", ")
+}
+
+// AsError returns the status as an error, or nil for a success
+func (s *Status) AsError() error {
+	if s.IsSuccess() {
+		return nil
+	}
+	if s.err != nil {
+		return s.err
+	}
+	return errors.New(s.Message())
+}
+
+// CycleState carries data between the extension points of a single
+// scheduling attempt, e.g. pod requests computed in PreFilter and used by
+// Filter and Score
+type CycleState struct {
+	data map[string]interface{}
+}
+
+// NewCycleState returns an empty cycle state
+func NewCycleState() *CycleState {
+	return &CycleState{data: make(map[string]interface{})}
+}
+
+// Read returns the value stored under key
+func (c *CycleState) Read(key string) (interface{}, error) {
+	value, ok := c.data[key]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in cycle state", key)
+	}
+	return value, nil
+}
+
+// Write stores a value under key
+func (c *CycleState) Write(key string, value interface{}) {
+	c.data[key] = value
+}
+
+// NodeInfo is a node as seen by the plugins of a scheduling cycle, with the
+// pods bound or assumed to it
+type NodeInfo struct {
+	Node *types.Node
+	Pods []*types.Pod
+	// Requested is the sum of the requests of Pods
+	Requested Resource
+}
+
+// Resource is an amount of CPU in millicores and memory in bytes
+type Resource struct {
+	MilliCPU int64
+	Memory   int64
+}
+
+// newNodeInfos wraps nodes without pods for a scheduling pass
+func newNodeInfos(nodes []*types.Node) []*NodeInfo {
+	nodeInfos := make([]*NodeInfo, 0, len(nodes))
+	for _, node := range nodes {
+		nodeInfos = append(nodeInfos, &NodeInfo{Node: node})
+	}
+	return nodeInfos
+}
+
+// AddPod adds a pod and its requests to the node
+func (n *NodeInfo) AddPod(pod *types.Pod) {
+	n.Pods = append(n.Pods, pod)
+	requests := podResourceRequests(pod)
+	n.Requested.MilliCPU += requests.MilliCPU
+	n.Requested.Memory += requests.Memory
+}
+
+// RemovePod removes a pod and its requests from the node
+func (n *NodeInfo) RemovePod(pod *types.Pod) {
+	for i, existing := range n.Pods {
+		if existing.Metadata.UID != pod.Metadata.UID {
+			continue
+		}
+		n.Pods = append(n.Pods[:i:i], n.Pods[i+1:]...)
+		requests := podResourceRequests(existing)
+		n.Requested.MilliCPU -= requests.MilliCPU
+		n.Requested.Memory -= requests.Memory
+		return
+	}
+}
+
+// clone copies the node info, so a scheduling cycle can change it without
+// affecting the cache
+func (n *NodeInfo) clone() *NodeInfo {
+	return &NodeInfo{
+		Node:      n.Node,
+		Pods:      append([]*types.Pod(nil), n.Pods...),
+		Requested: n.Requested,
+	}
+}
+
+// NodeScore is the score of a node for a pod
+type NodeScore struct {
+	Name  string
+	Score int64
+}
+
+// Plugin is implemented by every scheduling plugin. Plugins also implement
+// one or more of the extension point interfaces below, and are called at
+// every extension point they implement.
+type Plugin interface {
+	Name() string
+}
+
+// PreFilterPlugin checks a pod and prepares cycle state before any node is
+// considered. It sees every node of the cycle, so it can precompute data
+// that depends on the whole cluster, such as the pods in each topology
+// domain. A failure makes the pod unschedulable on every node.
+type PreFilterPlugin interface {
+	Plugin
+	PreFilter(state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo) *Status
+}
+
+// FilterPlugin rules out nodes the pod can't run on
+type FilterPlugin interface {
+	Plugin
+	Filter(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status
+}
+
+// PostFilterPlugin is called when no node passes filtering, to make room for
+// the pod, e.g. by preempting other pods. On success it returns the node the
+// pod is nominated to run on once room has been made.
+type PostFilterPlugin interface {
+	Plugin
+	PostFilter(state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo, fitErr *FitError) (string, *Status)
+}
+
+// ScorePlugin ranks the nodes that passed filtering. Scores range from 0 to
+// MaxNodeScore, unless the plugin also implements NormalizeScorePlugin.
+type ScorePlugin interface {
+	Plugin
+	Score(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) (int64, *Status)
+}
+
+// NormalizeScorePlugin is a score plugin that rescales its scores to the
+// range 0 to MaxNodeScore once every node is scored
+type NormalizeScorePlugin interface {
+	ScorePlugin
+	NormalizeScore(state *CycleState, pod *types.Pod, scores []NodeScore) *Status
+}
+
+// ReservePlugin is told when a node is chosen for a pod, before it is bound,
+// and again through Unreserve if binding fails
+type ReservePlugin interface {
+	Plugin
+	Reserve(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status
+	Unreserve(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo)
+}
+
+// BindPlugin binds a pod to the chosen node. Bind plugins run in order until
+// one of them doesn't return Skip.
+type BindPlugin interface {
+	Plugin
+	Bind(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status
+}
+
+// Handle gives plugins access to the cluster state the scheduler works with
+type Handle interface {
+	Repository() storage.Repository
+	// RunPreFilterPlugins and RunFilterPlugins run the profile's filters, so
+	// plugins can check whether a pod would fit a node with other pods
+	RunPreFilterPlugins(state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo) *Status
+	RunFilterPlugins(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status
+}
+
+// PluginFactory builds a plugin from its arguments in the scheduler config
+type PluginFactory func(args json.RawMessage, handle Handle) (Plugin, error)
+
+// Registry maps plugin names to their factories
+type Registry map[string]PluginFactory
+
+// Framework runs the plugins of a scheduling profile at each extension point
+type Framework struct {
+	profileName       string
+	repository        storage.Repository
+	preFilterPlugins  []PreFilterPlugin
+	filterPlugins     []FilterPlugin
+	postFilterPlugins []PostFilterPlugin
+	scorePlugins      []ScorePlugin
+	scoreWeights      map[string]int64
+	reservePlugins    []ReservePlugin
+	bindPlugins       []BindPlugin
+	extenders         []*HTTPExtender
+}
+
+// NewFramework builds the plugins of a profile from the registry
+func NewFramework(profile Profile, registry Registry, repository storage.Repository) (*Framework, error) {
+	f := &Framework{
+		profileName:  profile.SchedulerName,
+		repository:   repository,
+		scoreWeights: make(map[string]int64),
+	}
+
+	seen := make(map[string]bool)
+	for _, config := range profile.Plugins {
+		if seen[config.Name] {
+			return nil, fmt.Errorf("profile %s: plugin %s is enabled more than once", profile.SchedulerName, config.Name)
+		}
+		seen[config.Name] = true
+
+		factory, ok := registry[config.Name]
+		if !ok {
+			return nil, fmt.Errorf("profile %s: unknown plugin %s", profile.SchedulerName, config.Name)
+		}
+		plugin, err := factory(config.Args, f)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: failed to create plugin %s: %w", profile.SchedulerName, config.Name, err)
+		}
+
+		if p, ok := plugin.(PreFilterPlugin); ok {
+			f.preFilterPlugins = append(f.preFilterPlugins, p)
+		}
+		if p, ok := plugin.(FilterPlugin); ok {
+			f.filterPlugins = append(f.filterPlugins, p)
+		}
+		if p, ok := plugin.(PostFilterPlugin); ok {
+			f.postFilterPlugins = append(f.postFilterPlugins, p)
+		}
+		if p, ok := plugin.(ScorePlugin); ok {
+			f.scorePlugins = append(f.scorePlugins, p)
+			weight := config.Weight
+			if weight == 0 {
+				weight = 1
+			}
+			f.scoreWeights[p.Name()] = weight
+		}
+		if p, ok := plugin.(ReservePlugin); ok {
+			f.reservePlugins = append(f.reservePlugins, p)
+		}
+		if p, ok := plugin.(BindPlugin); ok {
+			f.bindPlugins = append(f.bindPlugins, p)
+		}
+	}
+
+	if len(f.bindPlugins) == 0 {
+		return nil, fmt.Errorf("profile %s: no bind plugin is enabled", profile.SchedulerName)
+	}
+
+	for _, config := range profile.Extenders {
+		extender, err := NewHTTPExtender(config)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", profile.SchedulerName, err)
+		}
+		f.extenders = append(f.extenders, extender)
+	}
+
+	return f, nil
+}
+
+// ProfileName returns the scheduler name of the framework's profile
+func (f *Framework) ProfileName() string {
+	return f.profileName
+}
+
+// Repository returns the repository the scheduler works with
+func (f *Framework) Repository() storage.Repository {
+	return f.repository
+}
+
+// RunPreFilterPlugins runs the PreFilter plugins until one of them fails
+func (f *Framework) RunPreFilterPlugins(state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo) *Status {
+	for _, plugin := range f.preFilterPlugins {
+		if status := plugin.PreFilter(state, pod, nodeInfos); !status.IsSuccess() {
+			return status
+		}
+	}
+	return nil
+}
+
+// RunFilterPlugins runs the Filter plugins for a node until one of them
+// rejects it
+func (f *Framework) RunFilterPlugins(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status {
+	for _, plugin := range f.filterPlugins {
+		if status := plugin.Filter(state, pod, nodeInfo); !status.IsSuccess() {
+			return status
+		}
+	}
+	return nil
+}
+
+// RunPostFilterPlugins runs the PostFilter plugins until one of them makes
+// room for the pod, and returns the node it nominated
+func (f *Framework) RunPostFilterPlugins(state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo, fitErr *FitError) (string, *Status) {
+	var reasons []string
+	for _, plugin := range f.postFilterPlugins {
+		nominatedNodeName, status := plugin.PostFilter(state, pod, nodeInfos, fitErr)
+		switch status.Code() {
+		case Success:
+			return nominatedNodeName, nil
+		case Unschedulable:
+			reasons = append(reasons, status.Reasons()...)
+		default:
+			return "", status
+		}
+	}
+	return "", NewStatus(Unschedulable, reasons...)
+}
+
+// RunScorePlugins scores each node with every Score plugin, normalizes the
+// scores and returns their weighted sum per node
+func (f *Framework) RunScorePlugins(state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo) ([]NodeScore, *Status) {
+	totals := make([]NodeScore, len(nodeInfos))
+	for i, nodeInfo := range nodeInfos {
+		totals[i].Name = nodeInfo.Node.Metadata.Name
+	}
+
+	for _, plugin := range f.scorePlugins {
+		scores := make([]NodeScore, len(nodeInfos))
+		for i, nodeInfo := range nodeInfos {
+			score, status := plugin.Score(state, pod, nodeInfo)
+			if !status.IsSuccess() {
+				return nil, NewStatus(Error, fmt.Sprintf("plugin %s failed to score node %s: %s", plugin.Name(), nodeInfo.Node.Metadata.Name, status.Message()))
+			}
+			scores[i] = NodeScore{Name: nodeInfo.Node.Metadata.Name, Score: score}
+		}
+
+		if normalizer, ok := plugin.(NormalizeScorePlugin); ok {
+			if status := normalizer.NormalizeScore(state, pod, scores); !status.IsSuccess() {
+				return nil, NewStatus(Error, fmt.Sprintf("plugin %s failed to normalize scores: %s", plugin.Name(), status.Message()))
+			}
+		}
+
+		weight := f.scoreWeights[plugin.Name()]
+		for i, score := range scores {
+			if score.Score < 0 || score.Score > MaxNodeScore {
+				return nil, NewStatus(Error, fmt.Sprintf("plugin %s gave node %s score %d, outside [0, %d]", plugin.Name(), score.Name, score.Score, MaxNodeScore))
+			}
+			totals[i].Score += score.Score * weight
+		}
+	}
+
+	return totals, nil
+}
+
+// RunReservePlugins runs the Reserve plugins. If one of them fails, the ones
+// that already ran are unreserved.
+func (f *Framework) RunReservePlugins(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status {
+	for i, plugin := range f.reservePlugins {
+		if status := plugin.Reserve(state, pod, nodeInfo); !status.IsSuccess() {
+			for j := i - 1; j >= 0; j-- {
+				f.reservePlugins[j].Unreserve(state, pod, nodeInfo)
+			}
+			return status
+		}
+	}
+	return nil
+}
+
+// RunUnreservePlugins undoes the Reserve plugins in reverse order
+func (f *Framework) RunUnreservePlugins(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) {
+	for i := len(f.reservePlugins) - 1; i >= 0; i-- {
+		f.reservePlugins[i].Unreserve(state, pod, nodeInfo)
+	}
+}
+
+// RunBindPlugins runs the Bind plugins until one of them handles the pod
+func (f *Framework) RunBindPlugins(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status {
+	for _, plugin := range f.bindPlugins {
+		status := plugin.Bind(state, pod, nodeInfo)
+		if status.Code() != Skip {
+			return status
+		}
+	}
+	return NewStatus(Error, "no bind plugin bound the pod")
+}
+
+// FitError is returned when no node can run a pod. It counts the reasons the
+// nodes were rejected for.
+type FitError struct {
+	NumNodes int
+	Reasons  map[string]int
+}
+
+// Error summarizes the reasons, e.g. "0/3 nodes are available: 2 Insufficient
+// cpu, 1 node(s) didn't match node selector"
+func (e *FitError) Error() string {
+	reasons := make([]string, 0, len(e.Reasons))
+	for reason, count := range e.Reasons {
+		reasons = append(reasons, fmt.Sprintf("%d %s", count, reason))
+	}
+	sort.Strings(reasons)
+
+	message := fmt.Sprintf("0/%d nodes are available", e.NumNodes)
+	if len(reasons) > 0 {
+		message += ": " + strings.Join(reasons, ", ")
+	}
+	return message
+}
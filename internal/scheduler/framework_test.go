@@ -0,0 +1,316 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// fixedScore is a score plugin that gives each node a preset score
+type fixedScore struct {
+	name   string
+	scores map[string]int64
+}
+
+func (p *fixedScore) Name() string {
+	return p.name
+}
+
+func (p *fixedScore) Score(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) (int64, *Status) {
+	return p.scores[nodeInfo.Node.Metadata.Name], nil
+}
+
+// recordingReserve records Reserve and Unreserve calls and can fail Reserve
+type recordingReserve struct {
+	name  string
+	fail  bool
+	calls *[]string
+}
+
+func (p *recordingReserve) Name() string {
+	return p.name
+}
+
+func (p *recordingReserve) Reserve(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status {
+	*p.calls = append(*p.calls, "reserve "+p.name)
+	if p.fail {
+		return NewStatus(Unschedulable, "reservation failed")
+	}
+	return nil
+}
+
+func (p *recordingReserve) Unreserve(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) {
+	*p.calls = append(*p.calls, "unreserve "+p.name)
+}
+
+func testNode(name, cpu, memory string, labels map[string]string) *types.Node {
+	return &types.Node{
+		Metadata: types.ObjectMeta{Name: name, UID: name + "-uid", Labels: labels},
+		Status: types.NodeStatus{
+			Conditions:  []types.NodeCondition{{Type: "Ready", Status: "True"}},
+			Allocatable: types.ResourceList{"cpu": cpu, "memory": memory},
+		},
+	}
+}
+
+func testPod(name, cpu, memory string) *types.Pod {
+	return &types.Pod{
+		Metadata: types.ObjectMeta{Name: name, Namespace: "default", UID: name + "-uid"},
+		Spec: types.PodSpec{
+			Containers: []types.Container{{
+				Name:  "app",
+				Image: "nginx:latest",
+				Resources: types.ResourceRequirements{
+					Requests: types.ResourceList{"cpu": cpu, "memory": memory},
+				},
+			}},
+		},
+	}
+}
+
+func TestFrameworkScoreWeights(t *testing.T) {
+	registry := NewInTreeRegistry()
+	registry["PreferA"] = func(args json.RawMessage, handle Handle) (Plugin, error) {
+		return &fixedScore{name: "PreferA", scores: map[string]int64{"node-a": 60, "node-b": 0}}, nil
+	}
+	registry["PreferB"] = func(args json.RawMessage, handle Handle) (Plugin, error) {
+		return &fixedScore{name: "PreferB", scores: map[string]int64{"node-a": 0, "node-b": 40}}, nil
+	}
+
+	nodeInfos := newNodeInfos([]*types.Node{
+		testNode("node-a", "4", "8Gi", nil),
+		testNode("node-b", "4", "8Gi", nil),
+	})
+	pod := testPod("web", "100m", "128Mi")
+	pod.Spec.SchedulerName = "test"
+
+	tests := []struct {
+		name     string
+		weightA  int64
+		weightB  int64
+		expected string
+	}{
+		{"default weights", 0, 0, "node-a"},
+		{"weighted towards b", 1, 2, "node-b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := Profile{
+				SchedulerName: "test",
+				Plugins: []PluginConfig{
+					{Name: "PreferA", Weight: tt.weightA},
+					{Name: "PreferB", Weight: tt.weightB},
+					{Name: DefaultBinderName},
+				},
+			}
+			scheduler, err := NewSchedulerWithProfile(NewMockRepository(), profile, registry)
+			if err != nil {
+				t.Fatalf("Failed to create scheduler: %v", err)
+			}
+
+			selected, err := scheduler.selectNode(NewCycleState(), pod, nodeInfos)
+			if err != nil {
+				t.Fatalf("Failed to select node: %v", err)
+			}
+			if selected.Node.Metadata.Name != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, selected.Node.Metadata.Name)
+			}
+		})
+	}
+}
+
+func TestFrameworkReserveRollback(t *testing.T) {
+	var calls []string
+	registry := NewInTreeRegistry()
+	registry["First"] = func(args json.RawMessage, handle Handle) (Plugin, error) {
+		return &recordingReserve{name: "First", calls: &calls}, nil
+	}
+	registry["Second"] = func(args json.RawMessage, handle Handle) (Plugin, error) {
+		return &recordingReserve{name: "Second", fail: true, calls: &calls}, nil
+	}
+
+	profile := Profile{
+		SchedulerName: "test",
+		Plugins:       []PluginConfig{{Name: "First"}, {Name: "Second"}, {Name: DefaultBinderName}},
+	}
+	repo := NewMockRepository()
+	scheduler, err := NewSchedulerWithProfile(repo, profile, registry)
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+
+	pod := testPod("web", "100m", "128Mi")
+	pod.Spec.SchedulerName = "test"
+	if err := scheduler.schedulePod(pod, newNodeInfos([]*types.Node{testNode("node-a", "4", "8Gi", nil)})); err == nil {
+		t.Fatal("Expected scheduling to fail when a reservation fails")
+	}
+
+	expected := []string{"reserve First", "reserve Second", "unreserve First"}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+	if _, assigned := repo.podAssignments[pod.Metadata.UID]; assigned {
+		t.Error("Expected the pod not to be bound")
+	}
+}
+
+// conflictingRepository rejects every pod update as conflicting
+type conflictingRepository struct {
+	*MockRepository
+}
+
+func (r *conflictingRepository) UpdateResource(resource storage.Resource) error {
+	return storage.ErrConflict
+}
+
+func TestDefaultBinderLeavesPodUnboundOnFailure(t *testing.T) {
+	repo := &conflictingRepository{NewMockRepository()}
+	pod := testPod("web", "100m", "128Mi")
+	repo.pods[pod.Metadata.UID] = pod
+
+	queued := testPod("web", "100m", "128Mi")
+	queued.Status.Phase = "Pending"
+	binder := &DefaultBinder{repository: repo}
+	if status := binder.Bind(NewCycleState(), queued, &NodeInfo{Node: testNode("node-a", "4", "8Gi", nil)}); status.IsSuccess() {
+		t.Fatal("Expected binding to fail")
+	}
+
+	// The pod goes back to the queue and must still need scheduling
+	if !needsScheduling(queued) || queued.Status.Phase != "Pending" || len(queued.Status.Conditions) != 0 {
+		t.Errorf("Expected the pod to be left unbound, got node %q and status %+v", queued.Spec.NodeName, queued.Status)
+	}
+	if _, assigned := repo.podAssignments[pod.Metadata.UID]; assigned {
+		t.Error("Expected the assignment to be released")
+	}
+}
+
+func TestSelectNodeFitError(t *testing.T) {
+	scheduler := NewScheduler(NewMockRepository())
+
+	nodeInfos := newNodeInfos([]*types.Node{
+		testNode("small-1", "1", "1Gi", map[string]string{"disk": "ssd"}),
+		testNode("small-2", "1", "8Gi", map[string]string{"disk": "ssd"}),
+		testNode("hdd", "8", "8Gi", map[string]string{"disk": "hdd"}),
+	})
+	pod := testPod("big", "2", "4Gi")
+	pod.Spec.NodeSelector = map[string]string{"disk": "ssd"}
+
+	_, err := scheduler.selectNode(NewCycleState(), pod, nodeInfos)
+	fitError, ok := err.(*FitError)
+	if !ok {
+		t.Fatalf("Expected a FitError, got %v", err)
+	}
+
+	expected := "0/3 nodes are available: 1 Insufficient memory, 1 node(s) didn't match node selector, 2 Insufficient cpu"
+	if fitError.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, fitError.Error())
+	}
+}
+
+func TestBasicProfileIgnoresResources(t *testing.T) {
+	scheduler, err := NewSchedulerWithProfile(NewMockRepository(), BasicProfile(), NewInTreeRegistry())
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+
+	pod := testPod("big", "16", "64Gi")
+	if _, err := scheduler.selectNode(NewCycleState(), pod, newNodeInfos([]*types.Node{testNode("small", "1", "1Gi", nil)})); err != nil {
+		t.Errorf("Expected the basic profile to ignore resources, got %v", err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "scheduler.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		return path
+	}
+
+	config, err := LoadConfig(write(t, `{
+		"profiles": [
+			{"schedulerName": "bin-packing", "plugins": [{"name": "NodeResourcesFit", "weight": 3}, {"name": "DefaultBinder"}]},
+			{"schedulerName": "spread", "plugins": [{"name": "NodeSelector"}, {"name": "DefaultBinder"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	profile, ok := config.Profile("bin-packing")
+	if !ok || len(profile.Plugins) != 2 || profile.Plugins[0].Weight != 3 {
+		t.Errorf("Unexpected bin-packing profile %+v", profile)
+	}
+	if _, err := NewFramework(profile, NewInTreeRegistry(), NewMockRepository()); err != nil {
+		t.Errorf("Failed to build profile: %v", err)
+	}
+
+	invalid := []struct {
+		name   string
+		config string
+	}{
+		{"no profiles", `{"profiles": []}`},
+		{"duplicate profile", `{"profiles": [{"schedulerName": "a", "plugins": [{"name": "DefaultBinder"}]}, {"schedulerName": "a", "plugins": [{"name": "DefaultBinder"}]}]}`},
+		{"negative weight", `{"profiles": [{"schedulerName": "a", "plugins": [{"name": "NodeResourcesFit", "weight": -1}]}]}`},
+		{"malformed", `{"profiles": `},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadConfig(write(t, tt.config)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	// Unknown plugins and profiles without a binder are rejected when built
+	for _, plugins := range [][]PluginConfig{
+		{{Name: "NoSuchPlugin"}, {Name: DefaultBinderName}},
+		{{Name: NodeSelectorName}},
+	} {
+		if _, err := NewFramework(Profile{SchedulerName: "a", Plugins: plugins}, NewInTreeRegistry(), NewMockRepository()); err == nil {
+			t.Errorf("Expected profile with plugins %+v to be rejected", plugins)
+		}
+	}
+}
+
+func TestTaintToleration(t *testing.T) {
+	scheduler, err := NewSchedulerWithProfile(NewMockRepository(), DefaultProfile(), NewInTreeRegistry())
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+
+	dedicated := testNode("dedicated", "4", "8Gi", nil)
+	dedicated.Spec.Taints = []types.Taint{{Key: "dedicated", Value: "gpu", Effect: types.TaintEffectNoSchedule}}
+	avoided := testNode("avoided", "4", "8Gi", nil)
+	avoided.Spec.Taints = []types.Taint{{Key: "spot", Effect: types.TaintEffectPreferNoSchedule}}
+	plain := testNode("plain", "4", "8Gi", nil)
+
+	// A pod without tolerations can't use the dedicated node and prefers the
+	// node without taints
+	pod := testPod("web", "1", "1Gi")
+	selected, err := scheduler.selectNode(NewCycleState(), pod, newNodeInfos([]*types.Node{dedicated, avoided, plain}))
+	if err != nil {
+		t.Fatalf("Failed to select node: %v", err)
+	}
+	if selected.Node.Metadata.Name != "plain" {
+		t.Errorf("Expected plain, got %s", selected.Node.Metadata.Name)
+	}
+
+	_, err = scheduler.selectNode(NewCycleState(), pod, newNodeInfos([]*types.Node{dedicated}))
+	if err == nil || !strings.Contains(err.Error(), "1 node(s) had untolerated taint {dedicated: gpu}") {
+		t.Errorf("Expected an untolerated taint error, got %v", err)
+	}
+
+	// A tolerating pod can use it
+	pod.Spec.Tolerations = []types.Toleration{{Key: "dedicated", Value: "gpu", Effect: types.TaintEffectNoSchedule}}
+	if _, err := scheduler.selectNode(NewCycleState(), pod, newNodeInfos([]*types.Node{dedicated})); err != nil {
+		t.Errorf("Expected the tolerating pod to fit, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// InterPodAffinityName is the name of the InterPodAffinity plugin
+const InterPodAffinityName = "InterPodAffinity"
+
+// preFilterInterPodAffinityKey is the cycle state key of the topology domains
+// computed by the InterPodAffinity plugin
+const preFilterInterPodAffinityKey = "PreFilter" + InterPodAffinityName
+
+// Reasons the InterPodAffinity filter rejects a node for
+const (
+	errReasonAffinityRulesNotMatch             = "node(s) didn't match pod affinity rules"
+	errReasonAntiAffinityRulesNotMatch         = "node(s) didn't match pod anti-affinity rules"
+	errReasonExistingAntiAffinityRulesNotMatch = "node(s) didn't satisfy existing pods anti-affinity rules"
+)
+
+// topologyPair is a topology domain: the nodes whose label key has value
+type topologyPair struct {
+	key   string
+	value string
+}
+
+// interPodAffinityState is what the InterPodAffinity PreFilter learns about
+// the pods already placed in each topology domain
+type interPodAffinityState struct {
+	// affinityDomains holds, for each required affinity term of the pod, the
+	// domains running a pod the term selects
+	affinityDomains []map[topologyPair]bool
+	// antiAffinityDomains are the domains running a pod selected by one of
+	// the pod's required anti-affinity terms
+	antiAffinityDomains map[topologyPair]bool
+	// existingAntiAffinityDomains are the domains of placed pods whose
+	// required anti-affinity terms select the pod
+	existingAntiAffinityDomains map[topologyPair]bool
+	// preferredScores sums, per domain, the weights of the pod's preferred
+	// affinity terms less those of its preferred anti-affinity terms, for
+	// every placed pod the terms select
+	preferredScores map[topologyPair]int64
+}
+
+// InterPodAffinity places pods relative to the pods already bound to nodes:
+// required pod affinity and anti-affinity filter nodes by the pods in their
+// topology domain, the required anti-affinity of placed pods keeps matching
+// pods out of their domain, and preferred terms score the nodes
+type InterPodAffinity struct{}
+
+// NewInterPodAffinity creates the InterPodAffinity plugin
+func NewInterPodAffinity(args json.RawMessage, handle Handle) (Plugin, error) {
+	return &InterPodAffinity{}, nil
+}
+
+// Name returns the name of the plugin
+func (p *InterPodAffinity) Name() string {
+	return InterPodAffinityName
+}
+
+// podAffinityTerms returns the pod's required and preferred affinity and
+// anti-affinity terms
+func podAffinityTerms(pod *types.Pod) (affinity, antiAffinity []types.PodAffinityTerm, preferredAffinity, preferredAntiAffinity []types.WeightedPodAffinityTerm) {
+	if pod.Spec.Affinity == nil {
+		return nil, nil, nil, nil
+	}
+	if podAffinity := pod.Spec.Affinity.PodAffinity; podAffinity != nil {
+		affinity = podAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		preferredAffinity = podAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	}
+	if podAntiAffinity := pod.Spec.Affinity.PodAntiAffinity; podAntiAffinity != nil {
+		antiAffinity = podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		preferredAntiAffinity = podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	}
+	return affinity, antiAffinity, preferredAffinity, preferredAntiAffinity
+}
+
+// nodeTopologyPair returns the topology domain of a node for a key, and false
+// if the node doesn't have the label
+func nodeTopologyPair(node *types.Node, key string) (topologyPair, bool) {
+	value, ok := node.Metadata.Labels[key]
+	return topologyPair{key: key, value: value}, ok
+}
+
+// PreFilter records the topology domains of the pods bound to every node
+func (p *InterPodAffinity) PreFilter(state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo) *Status {
+	affinity, antiAffinity, preferredAffinity, preferredAntiAffinity := podAffinityTerms(pod)
+
+	s := &interPodAffinityState{
+		affinityDomains:             make([]map[topologyPair]bool, len(affinity)),
+		antiAffinityDomains:         make(map[topologyPair]bool),
+		existingAntiAffinityDomains: make(map[topologyPair]bool),
+		preferredScores:             make(map[topologyPair]int64),
+	}
+	for i := range s.affinityDomains {
+		s.affinityDomains[i] = make(map[topologyPair]bool)
+	}
+
+	namespace := pod.Metadata.Namespace
+	for _, nodeInfo := range nodeInfos {
+		for _, existing := range nodeInfo.Pods {
+			for i, term := range affinity {
+				if pair, ok := nodeTopologyPair(nodeInfo.Node, term.TopologyKey); ok && term.Matches(existing, namespace) {
+					s.affinityDomains[i][pair] = true
+				}
+			}
+			for _, term := range antiAffinity {
+				if pair, ok := nodeTopologyPair(nodeInfo.Node, term.TopologyKey); ok && term.Matches(existing, namespace) {
+					s.antiAffinityDomains[pair] = true
+				}
+			}
+			for _, weighted := range preferredAffinity {
+				term := weighted.PodAffinityTerm
+				if pair, ok := nodeTopologyPair(nodeInfo.Node, term.TopologyKey); ok && term.Matches(existing, namespace) {
+					s.preferredScores[pair] += int64(weighted.Weight)
+				}
+			}
+			for _, weighted := range preferredAntiAffinity {
+				term := weighted.PodAffinityTerm
+				if pair, ok := nodeTopologyPair(nodeInfo.Node, term.TopologyKey); ok && term.Matches(existing, namespace) {
+					s.preferredScores[pair] -= int64(weighted.Weight)
+				}
+			}
+
+			// Anti-affinity is symmetric: placed pods keep the pods they
+			// select out of their domain
+			_, existingAntiAffinity, _, _ := podAffinityTerms(existing)
+			for _, term := range existingAntiAffinity {
+				if pair, ok := nodeTopologyPair(nodeInfo.Node, term.TopologyKey); ok && term.Matches(pod, existing.Metadata.Namespace) {
+					s.existingAntiAffinityDomains[pair] = true
+				}
+			}
+		}
+	}
+
+	state.Write(preFilterInterPodAffinityKey, s)
+	return nil
+}
+
+// getInterPodAffinityState reads the state written by PreFilter
+func getInterPodAffinityState(state *CycleState) (*interPodAffinityState, error) {
+	value, err := state.Read(preFilterInterPodAffinityKey)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := value.(*interPodAffinityState)
+	if !ok {
+		return nil, fmt.Errorf("%s has unexpected type %T", preFilterInterPodAffinityKey, value)
+	}
+	return s, nil
+}
+
+// Filter rejects nodes in a domain the pod's required terms or the placed
+// pods' anti-affinity rule out
+func (p *InterPodAffinity) Filter(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status {
+	s, err := getInterPodAffinityState(state)
+	if err != nil {
+		return AsStatus(err)
+	}
+	node := nodeInfo.Node
+
+	for pair := range s.existingAntiAffinityDomains {
+		if value, ok := node.Metadata.Labels[pair.key]; ok && value == pair.value {
+			return NewStatus(Unschedulable, errReasonExistingAntiAffinityRulesNotMatch)
+		}
+	}
+
+	affinity, antiAffinity, _, _ := podAffinityTerms(pod)
+	for _, term := range antiAffinity {
+		if pair, ok := nodeTopologyPair(node, term.TopologyKey); ok && s.antiAffinityDomains[pair] {
+			return NewStatus(Unschedulable, errReasonAntiAffinityRulesNotMatch)
+		}
+	}
+
+	if len(affinity) == 0 {
+		return nil
+	}
+
+	// The first pod of a group that selects itself has nothing to join yet,
+	// so it may go to any node in a matching topology
+	firstOfGroup := true
+	for i, term := range affinity {
+		if len(s.affinityDomains[i]) > 0 || !term.Matches(pod, pod.Metadata.Namespace) {
+			firstOfGroup = false
+			break
+		}
+	}
+
+	for i, term := range affinity {
+		pair, ok := nodeTopologyPair(node, term.TopologyKey)
+		if !ok {
+			return NewStatus(Unschedulable, errReasonAffinityRulesNotMatch)
+		}
+		if !firstOfGroup && !s.affinityDomains[i][pair] {
+			return NewStatus(Unschedulable, errReasonAffinityRulesNotMatch)
+		}
+	}
+	return nil
+}
+
+// Score sums the preferred term weights of the node's domains;
+// NormalizeScore scales the sums, which may be negative
+func (p *InterPodAffinity) Score(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) (int64, *Status) {
+	s, err := getInterPodAffinityState(state)
+	if err != nil {
+		return 0, AsStatus(err)
+	}
+
+	var score int64
+	for pair, weight := range s.preferredScores {
+		if value, ok := nodeInfo.Node.Metadata.Labels[pair.key]; ok && value == pair.value {
+			score += weight
+		}
+	}
+	return score, nil
+}
+
+// NormalizeScore maps the lowest score to 0 and the highest to MaxNodeScore.
+// If every node scores the same, none is preferred.
+func (p *InterPodAffinity) NormalizeScore(state *CycleState, pod *types.Pod, scores []NodeScore) *Status {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	minScore, maxScore := scores[0].Score, scores[0].Score
+	for _, score := range scores[1:] {
+		if score.Score < minScore {
+			minScore = score.Score
+		}
+		if score.Score > maxScore {
+			maxScore = score.Score
+		}
+	}
+
+	for i := range scores {
+		if maxScore == minScore {
+			scores[i].Score = 0
+			continue
+		}
+		scores[i].Score = (scores[i].Score - minScore) * MaxNodeScore / (maxScore - minScore)
+	}
+	return nil
+}
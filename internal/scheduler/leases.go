@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"mini-k8s-orchestration/internal/storage"
+)
+
+// schedulerLeaseKind is the kind of the leases through which running
+// schedulers claim the profiles they run. Each lease is named after a
+// profile, so schedulers running different profiles of the same config file
+// leave each other's pods alone.
+const schedulerLeaseKind = "SchedulerLease"
+
+// schedulerLeaseDuration is how long a lease claims a profile after it was
+// last renewed
+const schedulerLeaseDuration = 30 * time.Second
+
+// schedulerLease is the spec of a lease
+type schedulerLease struct {
+	HolderIdentity string    `json:"holderIdentity"`
+	RenewTime      time.Time `json:"renewTime"`
+}
+
+// renewLeases claims the profiles this scheduler runs for another
+// schedulerLeaseDuration, and drops the leases of schedulers that stopped
+// renewing theirs
+func (s *Scheduler) renewLeases() {
+	resources, err := s.repository.ListResources(schedulerLeaseKind, "")
+	if err != nil {
+		log.Printf("Failed to list scheduler leases: %v", err)
+		return
+	}
+
+	now := time.Now()
+	existing := make(map[string]storage.Resource, len(resources))
+	for _, resource := range resources {
+		existing[resource.Name] = resource
+		if _, ok := s.profiles[resource.Name]; ok {
+			continue
+		}
+		if lease, err := decodeSchedulerLease(resource); err == nil && now.Sub(lease.RenewTime) <= schedulerLeaseDuration {
+			continue
+		}
+		// Deleting the lease lets every scheduler mark the profile's pods
+		log.Printf("Scheduler lease of profile %s expired", resource.Name)
+		if err := s.repository.DeleteResource(schedulerLeaseKind, "", resource.Name); err != nil {
+			log.Printf("Failed to delete scheduler lease %s: %v", resource.Name, err)
+		}
+	}
+
+	spec, err := json.Marshal(schedulerLease{HolderIdentity: s.identity, RenewTime: now})
+	if err != nil {
+		log.Printf("Failed to marshal scheduler lease: %v", err)
+		return
+	}
+	for _, name := range s.profileNames() {
+		resource, ok := existing[name]
+		if !ok {
+			err = s.repository.CreateResource(storage.Resource{
+				ID:   uuid.New().String(),
+				Kind: schedulerLeaseKind,
+				Name: name,
+				Spec: string(spec),
+			})
+		} else {
+			// Another scheduler running the same profile may hold the lease,
+			// so it is taken over unconditionally
+			resource.Spec = string(spec)
+			resource.ResourceVersion = 0
+			err = s.repository.UpdateResource(resource)
+		}
+		if err != nil {
+			log.Printf("Failed to renew scheduler lease %s: %v", name, err)
+		}
+	}
+}
+
+// keepLeases renews the leases of this scheduler's profiles well before they
+// expire, until the scheduler stops
+func (s *Scheduler) keepLeases() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(schedulerLeaseDuration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.renewLeases()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// releaseLeases gives up the leases this scheduler holds, so pods naming its
+// profiles are marked as waiting for a scheduler right away
+func (s *Scheduler) releaseLeases() {
+	for name := range s.profiles {
+		resource, err := s.repository.GetResource(schedulerLeaseKind, "", name)
+		if err != nil {
+			continue
+		}
+		if lease, err := decodeSchedulerLease(resource); err != nil || lease.HolderIdentity != s.identity {
+			continue
+		}
+		if err := s.repository.DeleteResource(schedulerLeaseKind, "", name); err != nil {
+			log.Printf("Failed to release scheduler lease %s: %v", name, err)
+		}
+	}
+}
+
+// profileClaimed reports whether a running scheduler holds an unexpired
+// lease on the named profile
+func (s *Scheduler) profileClaimed(name string) (bool, error) {
+	resources, err := s.repository.ListResources(schedulerLeaseKind, "")
+	if err != nil {
+		return false, fmt.Errorf("failed to list scheduler leases: %w", err)
+	}
+	for _, resource := range resources {
+		if resource.Name != name {
+			continue
+		}
+		lease, err := decodeSchedulerLease(resource)
+		if err != nil {
+			return false, err
+		}
+		return time.Since(lease.RenewTime) <= schedulerLeaseDuration, nil
+	}
+	return false, nil
+}
+
+// decodeSchedulerLease decodes the spec of a lease
+func decodeSchedulerLease(resource storage.Resource) (*schedulerLease, error) {
+	lease := &schedulerLease{}
+	if err := json.Unmarshal([]byte(resource.Spec), lease); err != nil {
+		return nil, fmt.Errorf("failed to decode scheduler lease %s: %w", resource.Name, err)
+	}
+	return lease, nil
+}
@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// countingRepository counts the pod updates made through it
+type countingRepository struct {
+	*MockRepository
+	podUpdates int
+}
+
+func (r *countingRepository) UpdateResource(resource storage.Resource) error {
+	if resource.Kind == "Pod" {
+		r.podUpdates++
+	}
+	return r.MockRepository.UpdateResource(resource)
+}
+
+func podScheduledCondition(pod *types.Pod) *types.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == "PodScheduled" {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func TestSchedulersRunningDifferentProfiles(t *testing.T) {
+	repo := &countingRepository{MockRepository: NewMockRepository()}
+	repo.CreateNode(testNode("node-a", "4", "8Gi", nil))
+
+	spreading, err := NewSchedulerWithProfiles(repo, []Profile{DefaultProfile()}, NewInTreeRegistry())
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	packing, err := NewSchedulerWithProfiles(repo, []Profile{binPackingProfile()}, NewInTreeRegistry())
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	spreading.renewLeases()
+	packing.renewLeases()
+
+	spread := testPod("spread", "500m", "512Mi")
+	packed := testPod("packed", "500m", "512Mi")
+	packed.Spec.SchedulerName = "bin-packing"
+	orphan := testPod("orphan", "500m", "512Mi")
+	orphan.Spec.SchedulerName = "gpu-scheduler"
+	for _, pod := range []*types.Pod{spread, packed, orphan} {
+		pod.Status.Phase = "Pending"
+		repo.pods[pod.Metadata.UID] = pod
+	}
+
+	// Each scheduler leaves the pods of the other's profile alone
+	if err := spreading.schedulePendingPods(); err != nil {
+		t.Fatalf("Failed to schedule pending pods: %v", err)
+	}
+	if _, assigned := repo.podAssignments["spread-uid"]; !assigned {
+		t.Error("Expected spread to be scheduled")
+	}
+	if condition := podScheduledCondition(packed); condition != nil {
+		t.Errorf("Expected packed to be left to its scheduler, got %+v", condition)
+	}
+	condition := podScheduledCondition(orphan)
+	if condition == nil || condition.Reason != "SchedulerNotFound" {
+		t.Fatalf("Expected a SchedulerNotFound condition on orphan, got %+v", orphan.Status.Conditions)
+	}
+
+	// The other scheduler agrees on the orphan's condition, so it only
+	// writes the binding of its own pod
+	updates := repo.podUpdates
+	if err := packing.schedulePendingPods(); err != nil {
+		t.Fatalf("Failed to schedule pending pods: %v", err)
+	}
+	if _, assigned := repo.podAssignments["packed-uid"]; !assigned {
+		t.Error("Expected packed to be scheduled")
+	}
+	if writes := repo.podUpdates - updates; writes != 1 {
+		t.Errorf("Expected only the binding to be written, got %d pod updates", writes)
+	}
+
+	// Once a scheduler stops, pods naming its profile are marked
+	packing.releaseLeases()
+	late := testPod("late", "500m", "512Mi")
+	late.Spec.SchedulerName = "bin-packing"
+	late.Status.Phase = "Pending"
+	repo.pods[late.Metadata.UID] = late
+	spreading.handleSchedulerLeaseEvent(storage.WatchEvent{Type: storage.EventDeleted, Resource: storage.Resource{Kind: schedulerLeaseKind, Name: "bin-packing"}})
+	if condition := podScheduledCondition(late); condition == nil || condition.Reason != "SchedulerNotFound" {
+		t.Errorf("Expected a SchedulerNotFound condition on late, got %+v", late.Status.Conditions)
+	}
+}
+
+func TestSchedulerLeasesExpire(t *testing.T) {
+	repo := NewMockRepository()
+	spreading, err := NewSchedulerWithProfiles(repo, []Profile{DefaultProfile()}, NewInTreeRegistry())
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	packing, err := NewSchedulerWithProfiles(repo, []Profile{binPackingProfile()}, NewInTreeRegistry())
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	packing.renewLeases()
+	if claimed, err := spreading.profileClaimed("bin-packing"); err != nil || !claimed {
+		t.Fatalf("Expected bin-packing to be claimed, got %v, %v", claimed, err)
+	}
+
+	// A scheduler that stopped renewing its lease no longer claims the profile
+	key := resourceKey(schedulerLeaseKind, "", "bin-packing")
+	lease := repo.resources[key]
+	lease.Spec = `{"holderIdentity":"gone","renewTime":"` + time.Now().Add(-time.Minute).Format(time.RFC3339) + `"}`
+	repo.resources[key] = lease
+	if claimed, _ := spreading.profileClaimed("bin-packing"); claimed {
+		t.Error("Expected an expired lease not to claim the profile")
+	}
+
+	// and its lease is dropped by the next renewal of another scheduler
+	spreading.renewLeases()
+	if _, ok := repo.resources[key]; ok {
+		t.Error("Expected the expired lease to be deleted")
+	}
+	if _, ok := repo.resources[resourceKey(schedulerLeaseKind, "", DefaultSchedulerName)]; !ok {
+		t.Error("Expected the default profile to be claimed")
+	}
+}
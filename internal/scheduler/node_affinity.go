@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"encoding/json"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// NodeAffinityName is the name of the NodeAffinity plugin
+const NodeAffinityName = "NodeAffinity"
+
+// NodeAffinity filters out nodes that don't match the pod's required node
+// affinity, and prefers the nodes matching the most weight of its preferred
+// terms
+type NodeAffinity struct{}
+
+// NewNodeAffinity creates the NodeAffinity plugin
+func NewNodeAffinity(args json.RawMessage, handle Handle) (Plugin, error) {
+	return &NodeAffinity{}, nil
+}
+
+// Name returns the name of the plugin
+func (p *NodeAffinity) Name() string {
+	return NodeAffinityName
+}
+
+// nodeAffinity returns the pod's node affinity, or nil if it has none
+func nodeAffinity(pod *types.Pod) *types.NodeAffinity {
+	if pod.Spec.Affinity == nil {
+		return nil
+	}
+	return pod.Spec.Affinity.NodeAffinity
+}
+
+// Filter rejects nodes that match none of the required node selector terms
+func (p *NodeAffinity) Filter(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status {
+	affinity := nodeAffinity(pod)
+	if affinity == nil || affinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return nil
+	}
+
+	if !affinity.RequiredDuringSchedulingIgnoredDuringExecution.Matches(nodeInfo.Node.Metadata.Labels) {
+		return NewStatus(Unschedulable, "node(s) didn't match Pod's node affinity")
+	}
+	return nil
+}
+
+// Score sums the weights of the preferred terms the node matches;
+// NormalizeScore scales the sums
+func (p *NodeAffinity) Score(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) (int64, *Status) {
+	affinity := nodeAffinity(pod)
+	if affinity == nil {
+		return 0, nil
+	}
+
+	var score int64
+	for _, preferred := range affinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		if preferred.Preference.Matches(nodeInfo.Node.Metadata.Labels) {
+			score += int64(preferred.Weight)
+		}
+	}
+	return score, nil
+}
+
+// NormalizeScore gives the highest scoring node MaxNodeScore and scales the
+// others in proportion
+func (p *NodeAffinity) NormalizeScore(state *CycleState, pod *types.Pod, scores []NodeScore) *Status {
+	var maxScore int64
+	for _, score := range scores {
+		if score.Score > maxScore {
+			maxScore = score.Score
+		}
+	}
+	if maxScore == 0 {
+		return nil
+	}
+
+	for i := range scores {
+		scores[i].Score = scores[i].Score * MaxNodeScore / maxScore
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// Names of the resource plugins
+const (
+	NodeResourcesFitName                = "NodeResourcesFit"
+	NodeResourcesBalancedAllocationName = "NodeResourcesBalancedAllocation"
+)
+
+// preFilterResourcesKey is the cycle state key of the pod's requests
+const preFilterResourcesKey = "PreFilter" + NodeResourcesFitName
+
+// podRequests are the summed CPU (millicores) and memory (bytes) requests of
+// a pod's containers
+type podRequests struct {
+	cpu    int64
+	memory int64
+}
+
+// computePodRequests returns the pod's requests from cycle state, computing
+// and storing them on first use
+func computePodRequests(state *CycleState, pod *types.Pod) (*podRequests, error) {
+	if value, err := state.Read(preFilterResourcesKey); err == nil {
+		return value.(*podRequests), nil
+	}
+
+	cpu, memory, err := calculatePodResourceRequests(pod)
+	if err != nil {
+		return nil, err
+	}
+	requests := &podRequests{cpu: cpu, memory: memory}
+	state.Write(preFilterResourcesKey, requests)
+	return requests, nil
+}
+
+// fraction returns the share of capacity a request takes. A request on a
+// node without capacity counts as taking all of it.
+func fraction(request, capacity int64) float64 {
+	if capacity <= 0 {
+		if request > 0 {
+			return 1
+		}
+		return 0
+	}
+	return float64(request) / float64(capacity)
+}
+
+// Scoring strategies of the NodeResourcesFit plugin
+const (
+	// LeastAllocated favours the emptiest nodes, spreading pods out
+	LeastAllocated = "LeastAllocated"
+	// MostAllocated favours the fullest nodes, bin-packing pods
+	MostAllocated = "MostAllocated"
+	// RequestedToCapacityRatio scores the allocated share of each resource
+	// by a custom utilization shape
+	RequestedToCapacityRatio = "RequestedToCapacityRatio"
+)
+
+// NodeResourcesFitArgs are the arguments of the NodeResourcesFit plugin:
+//
+//	{"scoringStrategy": {"type": "MostAllocated", "resources": [{"name": "cpu", "weight": 2}, {"name": "memory", "weight": 1}]}}
+type NodeResourcesFitArgs struct {
+	ScoringStrategy *ScoringStrategy `json:"scoringStrategy,omitempty"`
+}
+
+// ScoringStrategy chooses how NodeResourcesFit scores the nodes that fit
+type ScoringStrategy struct {
+	// Type is LeastAllocated, MostAllocated or RequestedToCapacityRatio;
+	// empty means LeastAllocated
+	Type string `json:"type,omitempty"`
+	// Resources are the resources scored and their weights; empty means cpu
+	// and memory with weight 1
+	Resources []ResourceSpec `json:"resources,omitempty"`
+	// Shape maps utilization to score for RequestedToCapacityRatio
+	Shape []UtilizationShapePoint `json:"shape,omitempty"`
+}
+
+// ResourceSpec weighs a resource in the node score
+type ResourceSpec struct {
+	Name   string `json:"name"`
+	Weight int64  `json:"weight,omitempty"`
+}
+
+// UtilizationShapePoint is a point of the RequestedToCapacityRatio shape.
+// Utilization is a percentage and Score ranges from 0 to MaxNodeScore;
+// utilizations between points are interpolated linearly.
+type UtilizationShapePoint struct {
+	Utilization int64 `json:"utilization"`
+	Score       int64 `json:"score"`
+}
+
+// defaultResources are the resources scored when the strategy names none
+var defaultResources = []ResourceSpec{{Name: "cpu", Weight: 1}, {Name: "memory", Weight: 1}}
+
+// NodeResourcesFit filters out nodes without enough free CPU or memory for
+// the pod, and scores the nodes that fit by the share of their resources
+// allocated once it is placed, according to its scoring strategy
+type NodeResourcesFit struct {
+	strategy  string
+	resources []ResourceSpec
+	shape     []UtilizationShapePoint
+}
+
+// NewNodeResourcesFit creates the NodeResourcesFit plugin
+func NewNodeResourcesFit(args json.RawMessage, handle Handle) (Plugin, error) {
+	p := &NodeResourcesFit{strategy: LeastAllocated, resources: defaultResources}
+	if len(args) == 0 {
+		return p, nil
+	}
+
+	var fitArgs NodeResourcesFitArgs
+	if err := json.Unmarshal(args, &fitArgs); err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+	strategy := fitArgs.ScoringStrategy
+	if strategy == nil {
+		return p, nil
+	}
+
+	switch strategy.Type {
+	case "", LeastAllocated:
+	case MostAllocated:
+		p.strategy = MostAllocated
+	case RequestedToCapacityRatio:
+		if err := validateShape(strategy.Shape); err != nil {
+			return nil, err
+		}
+		p.strategy = RequestedToCapacityRatio
+		p.shape = strategy.Shape
+	default:
+		return nil, fmt.Errorf("unknown scoring strategy %s", strategy.Type)
+	}
+
+	if len(strategy.Resources) > 0 {
+		p.resources = nil
+		for _, resource := range strategy.Resources {
+			if resource.Name != "cpu" && resource.Name != "memory" {
+				return nil, fmt.Errorf("unsupported resource %s; only cpu and memory can be scored", resource.Name)
+			}
+			if resource.Weight < 0 {
+				return nil, fmt.Errorf("resource %s has negative weight %d", resource.Name, resource.Weight)
+			}
+			if resource.Weight == 0 {
+				resource.Weight = 1
+			}
+			p.resources = append(p.resources, resource)
+		}
+	}
+	return p, nil
+}
+
+// validateShape checks that a utilization shape has points with increasing
+// utilizations from 0 to 100 and scores from 0 to MaxNodeScore
+func validateShape(shape []UtilizationShapePoint) error {
+	if len(shape) == 0 {
+		return fmt.Errorf("%s needs a shape", RequestedToCapacityRatio)
+	}
+	for i, point := range shape {
+		if point.Utilization < 0 || point.Utilization > 100 {
+			return fmt.Errorf("shape utilization %d is outside [0, 100]", point.Utilization)
+		}
+		if point.Score < 0 || point.Score > MaxNodeScore {
+			return fmt.Errorf("shape score %d is outside [0, %d]", point.Score, MaxNodeScore)
+		}
+		if i > 0 && point.Utilization <= shape[i-1].Utilization {
+			return fmt.Errorf("shape utilizations must be increasing")
+		}
+	}
+	return nil
+}
+
+// Name returns the name of the plugin
+func (p *NodeResourcesFit) Name() string {
+	return NodeResourcesFitName
+}
+
+// PreFilter computes the pod's requests once for all nodes
+func (p *NodeResourcesFit) PreFilter(state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo) *Status {
+	if _, err := computePodRequests(state, pod); err != nil {
+		return NewStatus(Unschedulable, fmt.Sprintf("invalid resource requests: %v", err))
+	}
+	return nil
+}
+
+// Filter rejects nodes whose free resources, allocatable less the requests of
+// the pods already there, are smaller than the pod's requests
+func (p *NodeResourcesFit) Filter(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status {
+	requests, err := computePodRequests(state, pod)
+	if err != nil {
+		return AsStatus(err)
+	}
+
+	cpu, memory, err := getNodeAvailableResources(nodeInfo)
+	if err != nil {
+		return NewStatus(Unschedulable, err.Error())
+	}
+
+	var reasons []string
+	if cpu < requests.cpu {
+		reasons = append(reasons, "Insufficient cpu")
+	}
+	if memory < requests.memory {
+		reasons = append(reasons, "Insufficient memory")
+	}
+	if len(reasons) > 0 {
+		return NewStatus(Unschedulable, reasons...)
+	}
+	return nil
+}
+
+// Score scores the share of each resource allocated once the pod is placed
+// by the scoring strategy, and returns the weighted average over the
+// resources
+func (p *NodeResourcesFit) Score(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) (int64, *Status) {
+	requests, err := computePodRequests(state, pod)
+	if err != nil {
+		return 0, AsStatus(err)
+	}
+
+	cpu, memory, err := getNodeAllocatableResources(nodeInfo.Node)
+	if err != nil {
+		return 0, AsStatus(err)
+	}
+
+	var score, weights float64
+	for _, resource := range p.resources {
+		var share float64
+		switch resource.Name {
+		case "cpu":
+			share = fraction(nodeInfo.Requested.MilliCPU+requests.cpu, cpu)
+		case "memory":
+			share = fraction(nodeInfo.Requested.Memory+requests.memory, memory)
+		}
+		score += p.scoreShare(math.Min(share, 1)) * float64(resource.Weight)
+		weights += float64(resource.Weight)
+	}
+	if weights == 0 {
+		return 0, nil
+	}
+	return int64(score / weights), nil
+}
+
+// scoreShare scores the allocated share of a resource, from 0 to 1
+func (p *NodeResourcesFit) scoreShare(share float64) float64 {
+	switch p.strategy {
+	case MostAllocated:
+		return share * float64(MaxNodeScore)
+	case RequestedToCapacityRatio:
+		return shapeScore(p.shape, share*100)
+	default:
+		return (1 - share) * float64(MaxNodeScore)
+	}
+}
+
+// shapeScore interpolates the score of a utilization between the points of
+// a shape. Utilizations outside the shape get the score of the nearest end.
+func shapeScore(shape []UtilizationShapePoint, utilization float64) float64 {
+	first, last := shape[0], shape[len(shape)-1]
+	if utilization <= float64(first.Utilization) {
+		return float64(first.Score)
+	}
+	for i := 1; i < len(shape); i++ {
+		lower, upper := shape[i-1], shape[i]
+		if utilization <= float64(upper.Utilization) {
+			ratio := (utilization - float64(lower.Utilization)) / float64(upper.Utilization-lower.Utilization)
+			return float64(lower.Score) + ratio*float64(upper.Score-lower.Score)
+		}
+	}
+	return float64(last.Score)
+}
+
+// NodeResourcesBalancedAllocation prefers nodes whose CPU and memory would be
+// allocated in similar shares with the pod, so neither runs out while the
+// other sits idle
+type NodeResourcesBalancedAllocation struct{}
+
+// NewNodeResourcesBalancedAllocation creates the NodeResourcesBalancedAllocation plugin
+func NewNodeResourcesBalancedAllocation(args json.RawMessage, handle Handle) (Plugin, error) {
+	return &NodeResourcesBalancedAllocation{}, nil
+}
+
+// Name returns the name of the plugin
+func (p *NodeResourcesBalancedAllocation) Name() string {
+	return NodeResourcesBalancedAllocationName
+}
+
+// Score is highest when the allocated CPU and memory shares are equal
+func (p *NodeResourcesBalancedAllocation) Score(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) (int64, *Status) {
+	requests, err := computePodRequests(state, pod)
+	if err != nil {
+		return 0, AsStatus(err)
+	}
+
+	cpu, memory, err := getNodeAllocatableResources(nodeInfo.Node)
+	if err != nil {
+		return 0, AsStatus(err)
+	}
+
+	cpuFraction := math.Min(fraction(nodeInfo.Requested.MilliCPU+requests.cpu, cpu), 1)
+	memoryFraction := math.Min(fraction(nodeInfo.Requested.Memory+requests.memory, memory), 1)
+	return int64((1 - math.Abs(cpuFraction-memoryFraction)) * float64(MaxNodeScore)), nil
+}
@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNodeResourcesFitScoringStrategies(t *testing.T) {
+	// node-a has most of its CPU requested, node-b most of its memory
+	nodeInfoA := &NodeInfo{Node: testNode("node-a", "4", "8Gi", nil)}
+	nodeInfoA.AddPod(testPod("cpu-heavy", "3", "1Gi"))
+	nodeInfoB := &NodeInfo{Node: testNode("node-b", "4", "8Gi", nil)}
+	nodeInfoB.AddPod(testPod("memory-heavy", "500m", "6Gi"))
+	nodeInfoC := &NodeInfo{Node: testNode("node-c", "4", "8Gi", nil)}
+
+	tests := []struct {
+		name     string
+		args     string
+		expected string
+	}{
+		{"default", ``, "node-c"},
+		{"least allocated", `{"scoringStrategy": {"type": "LeastAllocated"}}`, "node-c"},
+		{"most allocated", `{"scoringStrategy": {"type": "MostAllocated"}}`, "node-a"},
+		{"most allocated memory", `{"scoringStrategy": {"type": "MostAllocated", "resources": [{"name": "cpu", "weight": 1}, {"name": "memory", "weight": 5}]}}`, "node-b"},
+		{"requested to capacity ratio", `{"scoringStrategy": {"type": "RequestedToCapacityRatio", "shape": [{"utilization": 0, "score": 0}, {"utilization": 100, "score": 100}]}}`, "node-a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin, err := NewNodeResourcesFit(json.RawMessage(tt.args), nil)
+			if err != nil {
+				t.Fatalf("Failed to create plugin: %v", err)
+			}
+			fit := plugin.(*NodeResourcesFit)
+
+			state := NewCycleState()
+			pod := testPod("web", "100m", "128Mi")
+			best, bestScore := "", int64(-1)
+			for _, nodeInfo := range []*NodeInfo{nodeInfoA, nodeInfoB, nodeInfoC} {
+				score, status := fit.Score(state, pod, nodeInfo)
+				if !status.IsSuccess() {
+					t.Fatalf("Score failed: %v", status.AsError())
+				}
+				if score > bestScore {
+					best, bestScore = nodeInfo.Node.Metadata.Name, score
+				}
+			}
+			if best != tt.expected {
+				t.Errorf("Expected %s to score highest, got %s", tt.expected, best)
+			}
+		})
+	}
+
+	for _, args := range []string{
+		`{"scoringStrategy": {"type": "Random"}}`,
+		`{"scoringStrategy": {"type": "MostAllocated", "resources": [{"name": "gpu"}]}}`,
+		`{"scoringStrategy": {"type": "RequestedToCapacityRatio"}}`,
+		`{"scoringStrategy": {"type": "RequestedToCapacityRatio", "shape": [{"utilization": 50, "score": 10}, {"utilization": 20, "score": 0}]}}`,
+	} {
+		if _, err := NewNodeResourcesFit(json.RawMessage(args), nil); err == nil {
+			t.Errorf("Expected args %s to be rejected", args)
+		}
+	}
+}
+
+func TestShapeScore(t *testing.T) {
+	shape := []UtilizationShapePoint{{Utilization: 20, Score: 0}, {Utilization: 60, Score: 100}, {Utilization: 100, Score: 20}}
+	tests := []struct {
+		utilization float64
+		expected    float64
+	}{
+		{0, 0},
+		{40, 50},
+		{60, 100},
+		{80, 60},
+		{100, 20},
+	}
+	for _, tt := range tests {
+		if score := shapeScore(shape, tt.utilization); score != tt.expected {
+			t.Errorf("Expected score %v at %v%%, got %v", tt.expected, tt.utilization, score)
+		}
+	}
+}
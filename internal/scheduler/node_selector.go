@@ -0,0 +1,32 @@
+package scheduler
+
+import (
+	"encoding/json"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// NodeSelectorName is the name of the NodeSelector plugin
+const NodeSelectorName = "NodeSelector"
+
+// NodeSelector filters out nodes whose labels don't match the pod's
+// spec.nodeSelector
+type NodeSelector struct{}
+
+// NewNodeSelector creates the NodeSelector plugin
+func NewNodeSelector(args json.RawMessage, handle Handle) (Plugin, error) {
+	return &NodeSelector{}, nil
+}
+
+// Name returns the name of the plugin
+func (p *NodeSelector) Name() string {
+	return NodeSelectorName
+}
+
+// Filter rejects nodes that don't carry every label of the node selector
+func (p *NodeSelector) Filter(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status {
+	if !matchNodeSelector(nodeInfo.Node, pod.Spec.NodeSelector) {
+		return NewStatus(Unschedulable, "node(s) didn't match node selector")
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// PodTopologySpreadName is the name of the PodTopologySpread plugin
+const PodTopologySpreadName = "PodTopologySpread"
+
+// preFilterPodTopologySpreadKey is the cycle state key of the pod counts
+// computed by the PodTopologySpread plugin
+const preFilterPodTopologySpreadKey = "PreFilter" + PodTopologySpreadName
+
+// Reasons the PodTopologySpread filter rejects a node for
+const (
+	errReasonTopologySpreadMissingLabel = "node(s) didn't match pod topology spread constraints (missing required label)"
+	errReasonTopologySpreadSkew         = "node(s) didn't match pod topology spread constraints"
+)
+
+// topologySpreadState holds, for each topology spread constraint of the pod,
+// the number of matching pods in every domain of its topology key
+type topologySpreadState struct {
+	// counts maps each domain's label value to its matching pods, per
+	// constraint. Every eligible domain is present, even without pods.
+	counts []map[string]int
+	// minCounts is the smallest count of any eligible domain, per constraint
+	minCounts []int
+}
+
+// PodTopologySpread spreads the pods matched by the pod's topology spread
+// constraints evenly over topology domains. DoNotSchedule constraints filter
+// out nodes whose domain would exceed maxSkew; ScheduleAnyway constraints
+// prefer the nodes whose domain has the fewest matching pods.
+type PodTopologySpread struct{}
+
+// NewPodTopologySpread creates the PodTopologySpread plugin
+func NewPodTopologySpread(args json.RawMessage, handle Handle) (Plugin, error) {
+	return &PodTopologySpread{}, nil
+}
+
+// Name returns the name of the plugin
+func (p *PodTopologySpread) Name() string {
+	return PodTopologySpreadName
+}
+
+// eligibleForSpread reports whether a node counts as a domain for the pod:
+// nodes the pod's node selector or required node affinity rule out are
+// ignored, so they don't hold the minimum at zero
+func eligibleForSpread(pod *types.Pod, node *types.Node) bool {
+	if !matchNodeSelector(node, pod.Spec.NodeSelector) {
+		return false
+	}
+	affinity := nodeAffinity(pod)
+	if affinity != nil && affinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		return affinity.RequiredDuringSchedulingIgnoredDuringExecution.Matches(node.Metadata.Labels)
+	}
+	return true
+}
+
+// spreadMatches reports whether a pod is counted by a constraint of a pod in
+// namespace
+func spreadMatches(constraint *types.TopologySpreadConstraint, pod *types.Pod, namespace string) bool {
+	if constraint.LabelSelector == nil || pod.Metadata.Namespace != namespace {
+		return false
+	}
+	return constraint.LabelSelector.Matches(pod.Metadata.Labels)
+}
+
+// PreFilter counts the matching pods in every domain of each constraint
+func (p *PodTopologySpread) PreFilter(state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo) *Status {
+	constraints := pod.Spec.TopologySpreadConstraints
+	s := &topologySpreadState{
+		counts:    make([]map[string]int, len(constraints)),
+		minCounts: make([]int, len(constraints)),
+	}
+
+	for i := range constraints {
+		constraint := &constraints[i]
+		counts := make(map[string]int)
+		for _, nodeInfo := range nodeInfos {
+			value, ok := nodeInfo.Node.Metadata.Labels[constraint.TopologyKey]
+			if !ok || !eligibleForSpread(pod, nodeInfo.Node) {
+				continue
+			}
+			matching := 0
+			for _, existing := range nodeInfo.Pods {
+				if spreadMatches(constraint, existing, pod.Metadata.Namespace) {
+					matching++
+				}
+			}
+			counts[value] += matching
+		}
+
+		first := true
+		for _, count := range counts {
+			if first || count < s.minCounts[i] {
+				s.minCounts[i] = count
+				first = false
+			}
+		}
+		s.counts[i] = counts
+	}
+
+	state.Write(preFilterPodTopologySpreadKey, s)
+	return nil
+}
+
+// getTopologySpreadState reads the state written by PreFilter
+func getTopologySpreadState(state *CycleState) (*topologySpreadState, error) {
+	value, err := state.Read(preFilterPodTopologySpreadKey)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := value.(*topologySpreadState)
+	if !ok {
+		return nil, fmt.Errorf("%s has unexpected type %T", preFilterPodTopologySpreadKey, value)
+	}
+	return s, nil
+}
+
+// Filter rejects nodes without the topology label of a DoNotSchedule
+// constraint, and nodes whose domain would exceed the constraint's maxSkew
+// with the pod placed there
+func (p *PodTopologySpread) Filter(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status {
+	constraints := pod.Spec.TopologySpreadConstraints
+	if len(constraints) == 0 {
+		return nil
+	}
+	s, err := getTopologySpreadState(state)
+	if err != nil {
+		return AsStatus(err)
+	}
+
+	for i := range constraints {
+		constraint := &constraints[i]
+		if constraint.WhenUnsatisfiable != types.DoNotSchedule {
+			continue
+		}
+
+		value, ok := nodeInfo.Node.Metadata.Labels[constraint.TopologyKey]
+		if !ok {
+			return NewStatus(Unschedulable, errReasonTopologySpreadMissingLabel)
+		}
+
+		selfMatch := 0
+		if spreadMatches(constraint, pod, pod.Metadata.Namespace) {
+			selfMatch = 1
+		}
+		skew := s.counts[i][value] + selfMatch - s.minCounts[i]
+		if skew > int(constraint.MaxSkew) {
+			return NewStatus(Unschedulable, errReasonTopologySpreadSkew)
+		}
+	}
+	return nil
+}
+
+// Score sums the matching pods in the node's domain of every ScheduleAnyway
+// constraint, or returns -1 if the node lacks one of their topology labels.
+// NormalizeScore turns the sums into scores favouring the emptiest domains.
+func (p *PodTopologySpread) Score(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) (int64, *Status) {
+	constraints := pod.Spec.TopologySpreadConstraints
+	if len(constraints) == 0 {
+		return 0, nil
+	}
+	s, err := getTopologySpreadState(state)
+	if err != nil {
+		return 0, AsStatus(err)
+	}
+
+	var score int64
+	for i := range constraints {
+		constraint := &constraints[i]
+		if constraint.WhenUnsatisfiable != types.ScheduleAnyway {
+			continue
+		}
+
+		value, ok := nodeInfo.Node.Metadata.Labels[constraint.TopologyKey]
+		if !ok {
+			return -1, nil
+		}
+		score += int64(s.counts[i][value])
+	}
+	return score, nil
+}
+
+// NormalizeScore gives the nodes with the fewest matching pods MaxNodeScore
+// and those with the most 0. Nodes missing a topology label score 0.
+func (p *PodTopologySpread) NormalizeScore(state *CycleState, pod *types.Pod, scores []NodeScore) *Status {
+	minScore, maxScore := int64(-1), int64(-1)
+	for _, score := range scores {
+		if score.Score < 0 {
+			continue
+		}
+		if minScore < 0 || score.Score < minScore {
+			minScore = score.Score
+		}
+		if score.Score > maxScore {
+			maxScore = score.Score
+		}
+	}
+
+	for i := range scores {
+		switch {
+		case scores[i].Score < 0:
+			scores[i].Score = 0
+		case maxScore == minScore:
+			scores[i].Score = MaxNodeScore
+		default:
+			scores[i].Score = (maxScore - scores[i].Score) * MaxNodeScore / (maxScore - minScore)
+		}
+	}
+	return nil
+}
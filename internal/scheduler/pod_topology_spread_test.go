@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"testing"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+func spreadConstraint(topologyKey, whenUnsatisfiable string) types.TopologySpreadConstraint {
+	return types.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       topologyKey,
+		WhenUnsatisfiable: whenUnsatisfiable,
+		LabelSelector:     &types.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+	}
+}
+
+func TestPodTopologySpreadEvensZones(t *testing.T) {
+	repo := NewMockRepository()
+	repo.CreateNode(testNode("node-a1", "4", "8Gi", map[string]string{"zone": "a"}))
+	repo.CreateNode(testNode("node-a2", "4", "8Gi", map[string]string{"zone": "a"}))
+	repo.CreateNode(testNode("node-b1", "4", "8Gi", map[string]string{"zone": "b"}))
+	repo.CreateNode(testNode("node-c1", "4", "8Gi", map[string]string{"zone": "c"}))
+
+	for _, name := range []string{"web-1", "web-2", "web-3", "web-4", "web-5", "web-6", "web-7", "web-8", "web-9"} {
+		pod := labelledPod(name, map[string]string{"app": "web"})
+		pod.Spec.TopologySpreadConstraints = []types.TopologySpreadConstraint{spreadConstraint("zone", types.DoNotSchedule)}
+		repo.pods[pod.Metadata.UID] = pod
+	}
+
+	scheduler := NewScheduler(repo)
+	if err := scheduler.schedulePendingPods(); err != nil {
+		t.Fatalf("Failed to schedule pods: %v", err)
+	}
+
+	zones := map[string]string{"node-a1-uid": "a", "node-a2-uid": "a", "node-b1-uid": "b", "node-c1-uid": "c"}
+	perZone := make(map[string]int)
+	for _, nodeID := range repo.podAssignments {
+		perZone[zones[nodeID]]++
+	}
+	if perZone["a"] != 3 || perZone["b"] != 3 || perZone["c"] != 3 {
+		t.Errorf("Expected 3 replicas per zone, got %v", perZone)
+	}
+}
+
+func TestPodTopologySpreadFilter(t *testing.T) {
+	scheduler := NewScheduler(NewMockRepository())
+	nodeInfos := newNodeInfos([]*types.Node{
+		testNode("node-a", "4", "8Gi", map[string]string{"zone": "a"}),
+		testNode("node-b", "4", "8Gi", map[string]string{"zone": "b"}),
+		testNode("unlabelled", "4", "8Gi", nil),
+	})
+	nodeInfos[0].AddPod(labelledPod("web-1", map[string]string{"app": "web"}))
+	// Pods in other namespaces or with other labels don't count
+	other := labelledPod("web-other", map[string]string{"app": "web"})
+	other.Metadata.Namespace = "other"
+	nodeInfos[1].AddPod(other)
+	nodeInfos[1].AddPod(labelledPod("db-1", map[string]string{"app": "db"}))
+
+	pod := labelledPod("web-2", map[string]string{"app": "web"})
+	pod.Spec.TopologySpreadConstraints = []types.TopologySpreadConstraint{spreadConstraint("zone", types.DoNotSchedule)}
+	if names := feasibleNodes(t, scheduler, pod, nodeInfos); len(names) != 1 || names[0] != "node-b" {
+		t.Errorf("Expected only node-b to keep the skew within 1, got %v", names)
+	}
+
+	// A node selector narrows the domains, so zone b no longer holds the
+	// minimum at zero
+	pod.Spec.NodeSelector = map[string]string{"zone": "a"}
+	if names := feasibleNodes(t, scheduler, pod, nodeInfos); len(names) != 1 || names[0] != "node-a" {
+		t.Errorf("Expected node-a to be feasible within its only domain, got %v", names)
+	}
+}
+
+func TestPodTopologySpreadScheduleAnyway(t *testing.T) {
+	scheduler := NewScheduler(NewMockRepository())
+	nodeInfos := newNodeInfos([]*types.Node{
+		testNode("node-a", "4", "8Gi", map[string]string{"zone": "a"}),
+		testNode("node-b", "4", "8Gi", map[string]string{"zone": "b"}),
+	})
+	nodeInfos[1].AddPod(labelledPod("web-1", map[string]string{"app": "web"}))
+	nodeInfos[1].AddPod(labelledPod("web-2", map[string]string{"app": "web"}))
+	nodeInfos[1].AddPod(labelledPod("web-3", map[string]string{"app": "web"}))
+
+	// The skew would be exceeded, but the constraint only prefers node-a
+	pod := labelledPod("web-4", map[string]string{"app": "web"})
+	pod.Spec.TopologySpreadConstraints = []types.TopologySpreadConstraint{spreadConstraint("zone", types.ScheduleAnyway)}
+	pod.Spec.NodeSelector = map[string]string{"zone": "b"}
+	if names := feasibleNodes(t, scheduler, pod, nodeInfos); len(names) != 1 || names[0] != "node-b" {
+		t.Errorf("Expected node-b to stay feasible, got %v", names)
+	}
+
+	pod.Spec.NodeSelector = nil
+	selected, err := scheduler.selectNode(NewCycleState(), pod, nodeInfos)
+	if err != nil {
+		t.Fatalf("Failed to select node: %v", err)
+	}
+	if selected.Node.Metadata.Name != "node-a" {
+		t.Errorf("Expected the emptier node-a, got %s", selected.Node.Metadata.Name)
+	}
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// listPriorityClasses returns every priority class in storage
+func listPriorityClasses(repository storage.Repository) ([]*types.PriorityClass, error) {
+	resources, err := repository.ListResources("PriorityClass", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list priority classes: %w", err)
+	}
+
+	classes := make([]*types.PriorityClass, 0, len(resources))
+	for _, resource := range resources {
+		// The class's value and default flag are stored as its spec
+		class := &types.PriorityClass{}
+		if err := json.Unmarshal([]byte(resource.Spec), class); err != nil {
+			log.Printf("Failed to decode priority class %s: %v", resource.Name, err)
+			continue
+		}
+		class.Metadata.Name = resource.Name
+		classes = append(classes, class)
+	}
+	return classes, nil
+}
+
+// resolvePriority sets the priority of a pod stored without one, such as a
+// pod created by a controller, from its priority class
+func resolvePriority(pod *types.Pod, classes []*types.PriorityClass) {
+	if pod.Spec.Priority != nil {
+		return
+	}
+	var priority int32
+	if class := types.FindPriorityClass(classes, pod.Spec.PriorityClassName); class != nil {
+		priority = class.Value
+	}
+	pod.Spec.Priority = &priority
+}
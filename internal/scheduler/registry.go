@@ -0,0 +1,17 @@
+package scheduler
+
+// NewInTreeRegistry returns the registry of the plugins built into the
+// scheduler
+func NewInTreeRegistry() Registry {
+	return Registry{
+		NodeSelectorName:                    NewNodeSelector,
+		TaintTolerationName:                 NewTaintToleration,
+		NodeAffinityName:                    NewNodeAffinity,
+		InterPodAffinityName:                NewInterPodAffinity,
+		PodTopologySpreadName:               NewPodTopologySpread,
+		NodeResourcesFitName:                NewNodeResourcesFit,
+		NodeResourcesBalancedAllocationName: NewNodeResourcesBalancedAllocation,
+		DefaultPreemptionName:               NewDefaultPreemption,
+		DefaultBinderName:                   NewDefaultBinder,
+	}
+}
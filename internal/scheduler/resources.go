@@ -2,97 +2,12 @@ package scheduler
 
 import (
 	"fmt"
-	"log"
-	"math"
 	"strconv"
 	"strings"
 
 	"mini-k8s-orchestration/pkg/types"
 )
 
-// ResourceScheduler implements a resource-aware scheduling algorithm
-type ResourceScheduler struct {
-	// Base scheduler to extend
-	*Scheduler
-}
-
-// NewResourceScheduler creates a new resource-aware scheduler
-func NewResourceScheduler(baseScheduler *Scheduler) *ResourceScheduler {
-	return &ResourceScheduler{
-		Scheduler: baseScheduler,
-	}
-}
-
-// selectNode selects a node for the pod based on available resources
-func (s *ResourceScheduler) selectNode(pod *types.Pod, nodes []*types.Node) (*types.Node, error) {
-	if len(nodes) == 0 {
-		return nil, fmt.Errorf("no nodes available")
-	}
-
-	// Filter nodes based on node selector
-	var eligibleNodes []*types.Node
-	if len(pod.Spec.NodeSelector) > 0 {
-		for _, node := range nodes {
-			if matchNodeSelector(node, pod.Spec.NodeSelector) {
-				eligibleNodes = append(eligibleNodes, node)
-			}
-		}
-		if len(eligibleNodes) == 0 {
-			return nil, fmt.Errorf("no nodes match node selector")
-		}
-	} else {
-		eligibleNodes = nodes
-	}
-
-	// Calculate pod resource requirements
-	cpuRequest, memoryRequest, err := calculatePodResourceRequests(pod)
-	if err != nil {
-		log.Printf("Warning: Failed to calculate pod resource requests: %v", err)
-		// Fall back to round-robin if resource calculation fails
-		nodeIndex := hashString(pod.Metadata.Name) % len(eligibleNodes)
-		return eligibleNodes[nodeIndex], nil
-	}
-
-	// Find the best node based on resource availability
-	var bestNode *types.Node
-	var bestScore float64 = -1
-
-	for _, node := range eligibleNodes {
-		// Check if node has enough resources
-		nodeCPU, nodeMemory, err := getNodeAvailableResources(node)
-		if err != nil {
-			log.Printf("Warning: Failed to get node resources: %v", err)
-			continue
-		}
-
-		// Skip node if it doesn't have enough resources
-		if nodeCPU < cpuRequest || nodeMemory < memoryRequest {
-			continue
-		}
-
-		// Calculate score based on resource fit
-		// We want to balance between using nodes efficiently and leaving room for future pods
-		// Lower score is better (less resource pressure)
-		cpuScore := float64(cpuRequest) / float64(nodeCPU)
-		memoryScore := float64(memoryRequest) / float64(nodeMemory)
-		
-		// Use the higher pressure resource as the score
-		score := math.Max(cpuScore, memoryScore)
-		
-		// Prefer nodes with less resource pressure
-		if bestScore == -1 || score < bestScore {
-			bestScore = score
-			bestNode = node
-		}
-	}
-
-	if bestNode == nil {
-		return nil, fmt.Errorf("no node with sufficient resources available")
-	}
-
-	return bestNode, nil
-}
-
 // calculatePodResourceRequests calculates the total CPU and memory requests for a pod
 func calculatePodResourceRequests(pod *types.Pod) (int64, int64, error) {
 	var totalCPU int64
@@ -121,8 +36,19 @@ func calculatePodResourceRequests(pod *types.Pod) (int64, int64, error) {
 	return totalCPU, totalMemory, nil
 }
 
-// getNodeAvailableResources gets the available CPU and memory resources for a node
-func getNodeAvailableResources(node *types.Node) (int64, int64, error) {
+// podResourceRequests returns the requests of a pod already bound or assumed
+// to a node. Invalid requests count as zero; such pods were only placed by
+// hand or before validation.
+func podResourceRequests(pod *types.Pod) Resource {
+	cpu, memory, err := calculatePodResourceRequests(pod)
+	if err != nil {
+		return Resource{}
+	}
+	return Resource{MilliCPU: cpu, Memory: memory}
+}
+
+// getNodeAllocatableResources gets the allocatable CPU and memory resources for a node
+func getNodeAllocatableResources(node *types.Node) (int64, int64, error) {
 	// Get allocatable resources
 	cpuStr, ok := node.Status.Allocatable["cpu"]
 	if !ok {
@@ -149,6 +75,17 @@ func getNodeAvailableResources(node *types.Node) (int64, int64, error) {
 	return cpu, memory, nil
 }
 
+// getNodeAvailableResources gets the CPU and memory resources of a node that
+// are not yet requested by the pods bound or assumed to it
+func getNodeAvailableResources(nodeInfo *NodeInfo) (int64, int64, error) {
+	cpu, memory, err := getNodeAllocatableResources(nodeInfo.Node)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return cpu - nodeInfo.Requested.MilliCPU, memory - nodeInfo.Requested.Memory, nil
+}
+
 // parseCPUResource parses a CPU resource string (e.g., "0.5", "500m") to millicores
 func parseCPUResource(cpuStr string) (int64, error) {
 	// Handle millicpu format (e.g., "500m")
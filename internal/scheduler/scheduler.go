@@ -1,103 +1,204 @@
 package scheduler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"mini-k8s-orchestration/internal/storage"
 	"mini-k8s-orchestration/pkg/types"
 )
 
 // Scheduler is responsible for assigning pods to nodes
 type Scheduler struct {
-	repository     storage.Repository
+	repository storage.Repository
+	// profiles are the frameworks of the profiles run, by scheduler name.
+	// Each one schedules the pods naming it in spec.schedulerName.
+	profiles       map[string]*Framework
+	cache          *Cache
+	queue          *SchedulingQueue
 	schedulingLock sync.Mutex
 	stopCh         chan struct{}
 	wg             sync.WaitGroup
+
+	// mu guards the state kept up to date by the event handlers
+	mu              sync.Mutex
+	priorityClasses []*types.PriorityClass
+	nodeProperties  map[string]nodeSchedulingProperties
+	// cacheStale is set when the cache has to be rebuilt from storage
+	cacheStale bool
+
+	// identity names this scheduler in the leases of the profiles it runs
+	identity string
 }
 
-// NewScheduler creates a new scheduler
+// NewScheduler creates a new scheduler running the default profile
 func NewScheduler(repository storage.Repository) *Scheduler {
-	return &Scheduler{
-		repository: repository,
-		stopCh:     make(chan struct{}),
+	scheduler, err := NewSchedulerWithProfile(repository, DefaultProfile(), NewInTreeRegistry())
+	if err != nil {
+		// The default profile only uses in-tree plugins
+		panic(fmt.Sprintf("invalid default scheduler profile: %v", err))
 	}
+	return scheduler
+}
+
+// NewSchedulerWithProfile creates a new scheduler running the plugins of a
+// profile, built from the registry
+func NewSchedulerWithProfile(repository storage.Repository, profile Profile, registry Registry) (*Scheduler, error) {
+	return NewSchedulerWithProfiles(repository, []Profile{profile}, registry)
+}
+
+// NewSchedulerWithProfiles creates a new scheduler running several profiles,
+// built from the registry. The profiles share the scheduling queue and the
+// cache, so pods scheduled by one count against the nodes for the others.
+func NewSchedulerWithProfiles(repository storage.Repository, profiles []Profile, registry Registry) (*Scheduler, error) {
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no scheduler profiles given")
+	}
+
+	frameworks := make(map[string]*Framework, len(profiles))
+	for _, profile := range profiles {
+		if _, ok := frameworks[profile.SchedulerName]; ok {
+			return nil, fmt.Errorf("profile %s is defined more than once", profile.SchedulerName)
+		}
+		framework, err := NewFramework(profile, registry, repository)
+		if err != nil {
+			return nil, err
+		}
+		frameworks[profile.SchedulerName] = framework
+	}
+
+	return &Scheduler{
+		repository:     repository,
+		profiles:       frameworks,
+		cache:          NewCache(DefaultAssumedPodTTL),
+		queue:          NewSchedulingQueue(),
+		stopCh:         make(chan struct{}),
+		nodeProperties: make(map[string]nodeSchedulingProperties),
+		cacheStale:     true,
+		identity:       uuid.New().String(),
+	}, nil
 }
 
 // Start starts the scheduler
 func (s *Scheduler) Start() {
-	log.Println("Starting scheduler")
+	log.Printf("Starting scheduler with profiles %s", strings.Join(s.profileNames(), ", "))
 	s.wg.Add(1)
 	go s.run()
 }
 
+// profileNames returns the names of the profiles run, sorted
+func (s *Scheduler) profileNames() []string {
+	names := make([]string, 0, len(s.profiles))
+	for name := range s.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// podSchedulerName returns the name of the scheduler profile a pod asks for
+func podSchedulerName(pod *types.Pod) string {
+	if pod.Spec.SchedulerName == "" {
+		return DefaultSchedulerName
+	}
+	return pod.Spec.SchedulerName
+}
+
+// frameworkForPod returns the framework of the profile the pod names, if
+// this scheduler runs it
+func (s *Scheduler) frameworkForPod(pod *types.Pod) (*Framework, bool) {
+	framework, ok := s.profiles[podSchedulerName(pod)]
+	return framework, ok
+}
+
 // Stop stops the scheduler
 func (s *Scheduler) Stop() {
 	log.Println("Stopping scheduler")
 	close(s.stopCh)
+	s.queue.Close()
 	s.wg.Wait()
+	s.releaseLeases()
 }
 
-// run is the main scheduling loop
+// run is the main scheduling loop. Pod and node events feed the scheduling
+// queue, and pods are scheduled one at a time as they become active.
 func (s *Scheduler) run() {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Claim the profiles first, so other schedulers leave their pods alone
+	s.renewLeases()
+	s.wg.Add(1)
+	go s.keepLeases()
 
 	for {
+		err := s.startEventHandlers(ctx)
+		if err == nil {
+			break
+		}
+		log.Printf("Failed to start scheduler event handlers: %v", err)
 		select {
-		case <-ticker.C:
-			if err := s.schedulePendingPods(); err != nil {
-				log.Printf("Error scheduling pods: %v", err)
-			}
+		case <-time.After(time.Second):
 		case <-s.stopCh:
-			log.Println("Scheduler stopped")
 			return
 		}
 	}
-}
 
-// schedulePendingPods schedules all pending pods
-func (s *Scheduler) schedulePendingPods() error {
-	// Get all pending pods
-	pendingPods, err := s.getPendingPods()
-	if err != nil {
-		return fmt.Errorf("failed to get pending pods: %w", err)
-	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.queue.Run(s.stopCh)
+	}()
 
-	if len(pendingPods) == 0 {
-		return nil
+	for {
+		podInfo, ok := s.queue.Pop()
+		if !ok {
+			log.Println("Scheduler stopped")
+			return
+		}
+		s.scheduleOne(podInfo)
 	}
+}
 
-	log.Printf("Found %d pending pods to schedule", len(pendingPods))
-
-	// Get all available nodes
-	nodes, err := s.getAvailableNodes()
-	if err != nil {
-		return fmt.Errorf("failed to get available nodes: %w", err)
+// scheduleOne runs a scheduling cycle for a pod popped from the queue, against
+// a fresh snapshot of the nodes. A pod that can't be scheduled goes back to
+// the queue to be retried after a backoff, or once the cluster changes.
+func (s *Scheduler) scheduleOne(podInfo *QueuedPodInfo) {
+	pod := podInfo.Pod
+	if err := s.syncCache(); err != nil {
+		log.Printf("Failed to sync scheduler cache: %v", err)
+		s.queue.AddUnschedulable(podInfo, false)
+		return
 	}
 
-	if len(nodes) == 0 {
-		return fmt.Errorf("no available nodes for scheduling")
-	}
+	nodeInfos := s.getAvailableNodes()
+	addNominatedPods(pod, nodeInfos, s.queue.NominatedPods())
 
-	// Schedule each pod
-	for _, pod := range pendingPods {
-		if err := s.schedulePod(pod, nodes); err != nil {
-			log.Printf("Failed to schedule pod %s/%s: %v", pod.Metadata.Namespace, pod.Metadata.Name, err)
-			continue
-		}
+	err := s.schedulePod(pod, nodeInfos)
+	if err == nil {
+		s.queue.Done(podInfo)
+		return
 	}
+	log.Printf("Failed to schedule pod %s/%s: %v", pod.Metadata.Namespace, pod.Metadata.Name, err)
 
-	return nil
+	var fitErr *FitError
+	s.queue.AddUnschedulable(podInfo, errors.As(err, &fitErr))
 }
 
-// schedulePod schedules a single pod to a node
-func (s *Scheduler) schedulePod(pod *types.Pod, nodes []*types.Node) error {
+// schedulePod schedules a single pod to a node. When it fails, the reason is
+// recorded in the pod's PodScheduled condition.
+func (s *Scheduler) schedulePod(pod *types.Pod, nodeInfos []*NodeInfo) error {
 	s.schedulingLock.Lock()
 	defer s.schedulingLock.Unlock()
 
@@ -107,87 +208,284 @@ func (s *Scheduler) schedulePod(pod *types.Pod, nodes []*types.Node) error {
 		// Pod is already assigned
 		return nil
 	}
+	if s.cache.IsAssumed(pod) {
+		// Pod is being bound
+		return nil
+	}
+
+	framework, ok := s.frameworkForPod(pod)
+	if !ok {
+		// The pod was claimed before it named another scheduler
+		return fmt.Errorf("no scheduler profile named %s is running", podSchedulerName(pod))
+	}
+
+	nominatedNodeName, err := s.runSchedulingCycle(framework, pod, nodeInfos)
+	if err != nil {
+		if err := recordSchedulingFailure(s.repository, pod, err, nominatedNodeName); err != nil {
+			log.Printf("Failed to update status of pod %s/%s: %v", pod.Metadata.Namespace, pod.Metadata.Name, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// runSchedulingCycle selects a node for the pod with the plugins of its
+// profile's framework and binds the pod to it. When no node fits, it tries to
+// make room by preempting lower priority pods and returns the node it
+// nominated along with the FitError; the pod is scheduled in a later cycle,
+// once the victims are gone.
+func (s *Scheduler) runSchedulingCycle(framework *Framework, pod *types.Pod, nodeInfos []*NodeInfo) (string, error) {
+	state := NewCycleState()
 
 	// Select a node for the pod
-	selectedNode, err := s.selectNode(pod, nodes)
+	selected, err := selectNodeWithFramework(framework, state, pod, nodeInfos)
 	if err != nil {
-		return fmt.Errorf("failed to select node: %w", err)
+		var fitErr *FitError
+		if !errors.As(err, &fitErr) {
+			return "", fmt.Errorf("failed to select node: %w", err)
+		}
+
+		nominatedNodeName, status := framework.RunPostFilterPlugins(state, pod, nodeInfos, fitErr)
+		switch {
+		case status.IsSuccess():
+			return nominatedNodeName, fmt.Errorf("%w; preempted pods on node %s", fitErr, nominatedNodeName)
+		case status.Code() == Unschedulable && status.Message() != "":
+			return "", fmt.Errorf("%w; %s", fitErr, status.Message())
+		case status.Code() == Unschedulable:
+			return "", fitErr
+		default:
+			return "", fmt.Errorf("%w; preemption failed: %v", fitErr, status.AsError())
+		}
 	}
 
-	// Assign pod to node
-	if err := s.repository.AssignPodToNode(pod.Metadata.UID, selectedNode.Metadata.UID); err != nil {
-		return fmt.Errorf("failed to assign pod to node: %w", err)
+	// Assume the pod on the node so its requests count against the node
+	// right away, then reserve the node and bind the pod to it
+	nodeName := selected.Node.Metadata.Name
+	if err := s.cache.AssumePod(pod, nodeName); err != nil {
+		return "", fmt.Errorf("failed to assume pod: %w", err)
+	}
+	if status := framework.RunReservePlugins(state, pod, selected); !status.IsSuccess() {
+		s.cache.ForgetPod(pod)
+		return "", fmt.Errorf("failed to reserve node %s: %w", nodeName, status.AsError())
 	}
+	if err := framework.RunBindExtenders(pod, nodeName); err != nil {
+		framework.RunUnreservePlugins(state, pod, selected)
+		s.cache.ForgetPod(pod)
+		return "", fmt.Errorf("failed to bind pod: %w", err)
+	}
+	if status := framework.RunBindPlugins(state, pod, selected); !status.IsSuccess() {
+		framework.RunUnreservePlugins(state, pod, selected)
+		s.cache.ForgetPod(pod)
+		return "", fmt.Errorf("failed to bind pod: %w", status.AsError())
+	}
+	s.cache.FinishBinding(pod)
 
-	// Update pod status with node name
-	pod.Spec.NodeName = selectedNode.Metadata.Name
-	pod.Status.Phase = "Scheduled"
+	log.Printf("Scheduled pod %s/%s to node %s", pod.Metadata.Namespace, pod.Metadata.Name, nodeName)
+	return "", nil
+}
 
-	// Add condition
-	now := time.Now()
-	pod.Status.Conditions = append(pod.Status.Conditions, types.PodCondition{
-		Type:               "PodScheduled",
-		Status:             "True",
-		LastTransitionTime: now,
-		Reason:             "Scheduled",
-		Message:            fmt.Sprintf("Successfully assigned %s/%s to %s", pod.Metadata.Namespace, pod.Metadata.Name, selectedNode.Metadata.Name),
-	})
+// recordSchedulingFailure sets the pod's PodScheduled condition to False with
+// the reason it couldn't be scheduled, along with the node it was nominated
+// to after preemption, if any. The pod is only written when the condition
+// changed.
+func recordSchedulingFailure(repository storage.Repository, pod *types.Pod, schedulingErr error, nominatedNodeName string) error {
+	reason := "SchedulerError"
+	var fitErr *FitError
+	if errors.As(schedulingErr, &fitErr) {
+		reason = "Unschedulable"
+	}
+	return setPodScheduledFalse(repository, pod, reason, schedulingErr.Error(), nominatedNodeName)
+}
 
-	// Convert to storage resource
-	specJSON, err := json.Marshal(pod.Spec)
+// recordSchedulerNotFound sets the PodScheduled condition of a pod naming a
+// scheduler profile that no running scheduler holds a lease on. The pod stays
+// Pending until it names a running profile or one named after it is started.
+// The message is the same from every scheduler, so they don't keep
+// rewriting each other's condition.
+func (s *Scheduler) recordSchedulerNotFound(pod *types.Pod) {
+	name := podSchedulerName(pod)
+	claimed, err := s.profileClaimed(name)
 	if err != nil {
-		return fmt.Errorf("failed to marshal pod spec: %w", err)
+		log.Printf("Failed to check for a scheduler running profile %s: %v", name, err)
+		return
+	}
+	if claimed {
+		// Another scheduler runs the profile
+		return
 	}
 
-	statusJSON, err := json.Marshal(pod.Status)
-	if err != nil {
-		return fmt.Errorf("failed to marshal pod status: %w", err)
+	message := fmt.Sprintf("no scheduler profile named %s is running", name)
+	if err := setPodScheduledFalse(s.repository, pod, "SchedulerNotFound", message, ""); err != nil {
+		log.Printf("Failed to update status of pod %s/%s: %v", pod.Metadata.Namespace, pod.Metadata.Name, err)
 	}
+}
 
-	resource := storage.Resource{
-		Kind:      "Pod",
-		Namespace: pod.Metadata.Namespace,
-		Name:      pod.Metadata.Name,
-		Spec:      string(specJSON),
-		Status:    string(statusJSON),
+// setPodScheduledFalse sets the pod's PodScheduled condition to False with
+// the given reason and message, and records the nominated node, if any
+func setPodScheduledFalse(repository storage.Repository, pod *types.Pod, reason, message, nominatedNodeName string) error {
+	condition := types.PodCondition{
+		Type:    "PodScheduled",
+		Status:  "False",
+		Reason:  reason,
+		Message: message,
 	}
 
-	// Update pod in storage
-	if err := s.repository.UpdateResource(resource); err != nil {
-		return fmt.Errorf("failed to update pod: %w", err)
+	return storage.RetryOnConflict(func() error {
+		resource, err := repository.GetResource("Pod", pod.Metadata.Namespace, pod.Metadata.Name)
+		if err != nil {
+			return err
+		}
+		var status types.PodStatus
+		if resource.Status != "" {
+			if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
+				return fmt.Errorf("failed to unmarshal pod status: %w", err)
+			}
+		}
+
+		conditions, changed := setPodCondition(status.Conditions, condition, time.Now())
+		status.Conditions = conditions
+		if nominatedNodeName != "" && status.NominatedNodeName != nominatedNodeName {
+			status.NominatedNodeName = nominatedNodeName
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+
+		statusJSON, err := json.Marshal(status)
+		if err != nil {
+			return fmt.Errorf("failed to marshal pod status: %w", err)
+		}
+		resource.Status = string(statusJSON)
+		if err := repository.UpdateResource(resource); err != nil {
+			return err
+		}
+		pod.Status.Conditions = status.Conditions
+		pod.Status.NominatedNodeName = status.NominatedNodeName
+		return nil
+	})
+}
+
+// setPodCondition sets a condition, replacing the one of the same type. The
+// transition time only moves when the condition's status changes. It reports
+// whether anything changed.
+func setPodCondition(conditions []types.PodCondition, condition types.PodCondition, now time.Time) ([]types.PodCondition, bool) {
+	for i, existing := range conditions {
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+			return conditions, false
+		}
+		condition.LastTransitionTime = existing.LastTransitionTime
+		if existing.Status != condition.Status {
+			condition.LastTransitionTime = now
+		}
+		conditions[i] = condition
+		return conditions, true
 	}
 
-	log.Printf("Scheduled pod %s/%s to node %s", pod.Metadata.Namespace, pod.Metadata.Name, selectedNode.Metadata.Name)
-	return nil
+	condition.LastTransitionTime = now
+	return append(conditions, condition), true
+}
+
+// addNominatedPods adds to their nominated nodes the pending pods that
+// preempted other pods and matter at least as much as pod, so pod doesn't
+// take the room made for them
+func addNominatedPods(pod *types.Pod, nodeInfos []*NodeInfo, pendingPods []*types.Pod) {
+	priority := types.PodPriority(pod)
+	for _, nominated := range pendingPods {
+		nodeName := nominated.Status.NominatedNodeName
+		if nodeName == "" || nominated.Metadata.UID == pod.Metadata.UID || types.PodPriority(nominated) < priority {
+			continue
+		}
+		for _, nodeInfo := range nodeInfos {
+			if nodeInfo.Node.Metadata.Name == nodeName {
+				nodeInfo.AddPod(nominated)
+				break
+			}
+		}
+	}
 }
 
-// selectNode selects a node for the pod using a simple round-robin algorithm
-func (s *Scheduler) selectNode(pod *types.Pod, nodes []*types.Node) (*types.Node, error) {
-	if len(nodes) == 0 {
-		return nil, fmt.Errorf("no nodes available")
+// selectNode runs the PreFilter, Filter and Score plugins of the profile the
+// pod names and returns the highest scoring node
+func (s *Scheduler) selectNode(state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo) (*NodeInfo, error) {
+	framework, ok := s.frameworkForPod(pod)
+	if !ok {
+		return nil, fmt.Errorf("no scheduler profile named %s is running", podSchedulerName(pod))
+	}
+	return selectNodeWithFramework(framework, state, pod, nodeInfos)
+}
+
+// selectNodeWithFramework runs the PreFilter, Filter and Score plugins of a
+// framework, followed by its extenders, and returns the highest scoring node.
+// Ties are broken by the hash of the pod name, so pods spread over equally
+// good nodes.
+func selectNodeWithFramework(framework *Framework, state *CycleState, pod *types.Pod, nodeInfos []*NodeInfo) (*NodeInfo, error) {
+	if len(nodeInfos) == 0 {
+		return nil, &FitError{NumNodes: 0}
+	}
+
+	if status := framework.RunPreFilterPlugins(state, pod, nodeInfos); !status.IsSuccess() {
+		if status.Code() == Unschedulable {
+			return nil, &FitError{NumNodes: len(nodeInfos), Reasons: map[string]int{status.Message(): len(nodeInfos)}}
+		}
+		return nil, status.AsError()
 	}
 
-	// Filter nodes based on node selector
-	var eligibleNodes []*types.Node
-	if len(pod.Spec.NodeSelector) > 0 {
-		for _, node := range nodes {
-			if matchNodeSelector(node, pod.Spec.NodeSelector) {
-				eligibleNodes = append(eligibleNodes, node)
+	// Filter out the nodes the pod can't run on, counting why
+	var feasible []*NodeInfo
+	fitError := &FitError{NumNodes: len(nodeInfos), Reasons: make(map[string]int)}
+	for _, nodeInfo := range nodeInfos {
+		status := framework.RunFilterPlugins(state, pod, nodeInfo)
+		switch status.Code() {
+		case Success:
+			feasible = append(feasible, nodeInfo)
+		case Unschedulable:
+			for _, reason := range status.Reasons() {
+				fitError.Reasons[reason]++
 			}
+		default:
+			return nil, fmt.Errorf("failed to filter node %s: %w", nodeInfo.Node.Metadata.Name, status.AsError())
 		}
-		if len(eligibleNodes) == 0 {
-			return nil, fmt.Errorf("no nodes match node selector")
+	}
+	feasible, err := framework.RunFilterExtenders(pod, feasible, fitError)
+	if err != nil {
+		return nil, err
+	}
+	if len(feasible) == 0 {
+		return nil, fitError
+	}
+	if len(feasible) == 1 {
+		return feasible[0], nil
+	}
+
+	scores, status := framework.RunScorePlugins(state, pod, feasible)
+	if !status.IsSuccess() {
+		return nil, status.AsError()
+	}
+	if err := framework.RunPrioritizeExtenders(pod, feasible, scores); err != nil {
+		return nil, err
+	}
+
+	var best []*NodeInfo
+	bestScore := int64(-1)
+	for i, score := range scores {
+		switch {
+		case score.Score > bestScore:
+			bestScore = score.Score
+			best = []*NodeInfo{feasible[i]}
+		case score.Score == bestScore:
+			best = append(best, feasible[i])
 		}
-	} else {
-		eligibleNodes = nodes
 	}
 
-	// Simple round-robin: use pod name hash to select node
-	nodeIndex := hashString(pod.Metadata.Name) % len(eligibleNodes)
-	return eligibleNodes[nodeIndex], nil
+	return best[hashString(pod.Metadata.Name)%len(best)], nil
 }
 
-// getPendingPods gets all pods that need to be scheduled
+// getPendingPods gets all pods that need to be scheduled, the highest
+// priority first and otherwise the oldest first
 func (s *Scheduler) getPendingPods() ([]*types.Pod, error) {
 	// Get all pods
 	resources, err := s.repository.ListResources("Pod", "")
@@ -195,62 +493,85 @@ func (s *Scheduler) getPendingPods() ([]*types.Pod, error) {
 		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
 
+	classes, err := listPriorityClasses(s.repository)
+	if err != nil {
+		return nil, err
+	}
+
 	var pendingPods []*types.Pod
 	for _, resource := range resources {
-		// Parse pod spec and status
-		var spec types.PodSpec
-		if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
-			log.Printf("Failed to unmarshal pod spec: %v", err)
+		pod, err := resourceToPod(resource)
+		if err != nil {
+			log.Printf("Failed to decode pod: %v", err)
 			continue
 		}
 
-		var status types.PodStatus
-		if resource.Status != "" {
-			if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
-				log.Printf("Failed to unmarshal pod status: %v", err)
-				continue
-			}
-		}
-
 		// Check if pod needs scheduling
-		if spec.NodeName == "" && status.Phase != "Scheduled" {
-			pod := &types.Pod{
-				APIVersion: "v1",
-				Kind:       "Pod",
-				Metadata: types.ObjectMeta{
-					Name:      resource.Name,
-					Namespace: resource.Namespace,
-					UID:       resource.ID,
-					CreatedAt: resource.CreatedAt,
-					UpdatedAt: resource.UpdatedAt,
-				},
-				Spec:   spec,
-				Status: status,
-			}
+		if needsScheduling(pod) {
+			resolvePriority(pod, classes)
 			pendingPods = append(pendingPods, pod)
 		}
 	}
 
+	sort.SliceStable(pendingPods, func(i, j int) bool {
+		pi, pj := types.PodPriority(pendingPods[i]), types.PodPriority(pendingPods[j])
+		if pi != pj {
+			return pi > pj
+		}
+		return pendingPods[i].Metadata.CreatedAt.Before(pendingPods[j].Metadata.CreatedAt)
+	})
+
 	return pendingPods, nil
 }
 
-// getAvailableNodes gets all available nodes
-func (s *Scheduler) getAvailableNodes() ([]*types.Node, error) {
-	// Get all nodes
-	nodes, err := s.repository.ListNodes()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list nodes: %w", err)
+// resourceToPod converts a storage resource to a Pod
+func resourceToPod(resource storage.Resource) (*types.Pod, error) {
+	// Parse pod metadata, spec and status
+	var metadata types.ObjectMeta
+	if resource.Metadata != "" {
+		if err := json.Unmarshal([]byte(resource.Metadata), &metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pod metadata: %w", err)
+		}
+	}
+	metadata.Name = resource.Name
+	metadata.Namespace = resource.Namespace
+	metadata.UID = resource.ID
+	metadata.ResourceVersion = storage.FormatResourceVersion(resource.ResourceVersion)
+	metadata.CreatedAt = resource.CreatedAt
+	metadata.UpdatedAt = resource.UpdatedAt
+
+	var spec types.PodSpec
+	if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pod spec: %w", err)
+	}
+
+	var status types.PodStatus
+	if resource.Status != "" {
+		if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pod status: %w", err)
+		}
 	}
 
-	var availableNodes []*types.Node
-	for _, node := range nodes {
+	return &types.Pod{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Metadata:   metadata,
+		Spec:       spec,
+		Status:     status,
+	}, nil
+}
+
+// getAvailableNodes gets a snapshot of the ready nodes from the cache
+func (s *Scheduler) getAvailableNodes() []*NodeInfo {
+	var availableNodes []*NodeInfo
+	for _, nodeInfo := range s.cache.Snapshot() {
 		// Check if node is ready
-		if isNodeReady(node) {
-			availableNodes = append(availableNodes, node)
+		if isNodeReady(nodeInfo.Node) {
+			availableNodes = append(availableNodes, nodeInfo)
 		}
 	}
 
-	return availableNodes, nil
+	return availableNodes
 }
 
 // isNodeReady checks if a node is ready
@@ -279,5 +600,6 @@ func hashString(s string) int {
 	for i := 0; i < len(s); i++ {
 		hash = 31*hash + int(s[i])
 	}
-	return hash
-}
\ No newline at end of file
+	// Keep the hash non-negative so it can be used as an index
+	return hash & math.MaxInt32
+}
@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
@@ -14,6 +15,13 @@ type MockRepository struct {
 	pods          map[string]*types.Pod
 	nodes         map[string]*types.Node
 	podAssignments map[string]string // podID -> nodeID
+	priorityClasses []*types.PriorityClass
+	// resources holds the resources of other kinds, by kind, namespace and name
+	resources map[string]storage.Resource
+}
+
+func resourceKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
 }
 
 func NewMockRepository() *MockRepository {
@@ -21,47 +29,119 @@ func NewMockRepository() *MockRepository {
 		pods:          make(map[string]*types.Pod),
 		nodes:         make(map[string]*types.Node),
 		podAssignments: make(map[string]string),
+		resources:     make(map[string]storage.Resource),
 	}
 }
 
 // Mock implementation of storage.Repository interface
 func (r *MockRepository) CreateResource(resource storage.Resource) error {
+	if resource.Kind != "Pod" {
+		r.resources[resourceKey(resource.Kind, resource.Namespace, resource.Name)] = resource
+	}
 	return nil
 }
 
 func (r *MockRepository) GetResource(kind, namespace, name string) (storage.Resource, error) {
+	if kind == "Pod" {
+		for id, pod := range r.pods {
+			if pod.Metadata.Namespace == namespace && pod.Metadata.Name == name {
+				return podResource(id, pod), nil
+			}
+		}
+	}
+	if resource, ok := r.resources[resourceKey(kind, namespace, name)]; ok {
+		return resource, nil
+	}
 	return storage.Resource{}, nil
 }
 
 func (r *MockRepository) UpdateResource(resource storage.Resource) error {
+	if resource.Kind != "Pod" {
+		key := resourceKey(resource.Kind, resource.Namespace, resource.Name)
+		if _, ok := r.resources[key]; ok {
+			r.resources[key] = resource
+		}
+		return nil
+	}
+	for _, pod := range r.pods {
+		if pod.Metadata.Namespace == resource.Namespace && pod.Metadata.Name == resource.Name {
+			var spec types.PodSpec
+			if err := json.Unmarshal([]byte(resource.Spec), &spec); err != nil {
+				return err
+			}
+			var status types.PodStatus
+			if err := json.Unmarshal([]byte(resource.Status), &status); err != nil {
+				return err
+			}
+			if resource.Metadata != "" {
+				if err := json.Unmarshal([]byte(resource.Metadata), &pod.Metadata); err != nil {
+					return err
+				}
+			}
+			pod.Spec = spec
+			pod.Status = status
+		}
+	}
 	return nil
 }
 
 func (r *MockRepository) DeleteResource(kind, namespace, name string) error {
+	if kind == "Pod" {
+		for id, pod := range r.pods {
+			if pod.Metadata.Namespace == namespace && pod.Metadata.Name == name {
+				delete(r.pods, id)
+			}
+		}
+	}
+	delete(r.resources, resourceKey(kind, namespace, name))
 	return nil
 }
 
+// podResource converts a pod to the resource storing it
+func podResource(id string, pod *types.Pod) storage.Resource {
+	metadata, _ := json.Marshal(pod.Metadata)
+	spec, _ := json.Marshal(pod.Spec)
+	status, _ := json.Marshal(pod.Status)
+
+	return storage.Resource{
+		ID:        id,
+		Kind:      "Pod",
+		Namespace: pod.Metadata.Namespace,
+		Name:      pod.Metadata.Name,
+		Metadata:  string(metadata),
+		Spec:      string(spec),
+		Status:    string(status),
+		CreatedAt: pod.Metadata.CreatedAt,
+		UpdatedAt: pod.Metadata.UpdatedAt,
+	}
+}
+
 func (r *MockRepository) ListResources(kind, namespace string) ([]storage.Resource, error) {
 	var resources []storage.Resource
 	
 	if kind == "Pod" {
 		for id, pod := range r.pods {
-			spec, _ := json.Marshal(pod.Spec)
-			status, _ := json.Marshal(pod.Status)
-			
+			resources = append(resources, podResource(id, pod))
+		}
+	}
+	
+	if kind == "PriorityClass" {
+		for _, class := range r.priorityClasses {
+			spec, _ := json.Marshal(class)
 			resources = append(resources, storage.Resource{
-				ID:        id,
-				Kind:      "Pod",
-				Namespace: pod.Metadata.Namespace,
-				Name:      pod.Metadata.Name,
-				Spec:      string(spec),
-				Status:    string(status),
-				CreatedAt: pod.Metadata.CreatedAt,
-				UpdatedAt: pod.Metadata.UpdatedAt,
+				Kind: "PriorityClass",
+				Name: class.Metadata.Name,
+				Spec: string(spec),
 			})
 		}
 	}
 	
+	for _, resource := range r.resources {
+		if resource.Kind == kind {
+			resources = append(resources, resource)
+		}
+	}
+	
 	return resources, nil
 }
 
@@ -146,6 +226,36 @@ func (r *MockRepository) ListPodAssignmentsByNode(nodeID string) ([]*storage.Pod
 	return assignments, nil
 }
 
+func (r *MockRepository) ListResourcesPage(kind, namespace string, options storage.ListOptions) (*storage.ResourcePage, error) {
+	resources, err := r.ListResources(kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &storage.ResourcePage{Resources: resources}, nil
+}
+
+func (r *MockRepository) ListNodesPage(options storage.ListOptions) (*storage.NodePage, error) {
+	nodes, err := r.ListNodes()
+	if err != nil {
+		return nil, err
+	}
+	return &storage.NodePage{Nodes: nodes}, nil
+}
+
+func (r *MockRepository) CurrentRevision() (int64, error) {
+	return 0, nil
+}
+
+// Watch never reports changes; tests drive reconciliation directly
+func (r *MockRepository) Watch(ctx context.Context, kind, namespace string, revision int64) (<-chan storage.WatchEvent, error) {
+	events := make(chan storage.WatchEvent)
+	go func() {
+		<-ctx.Done()
+		close(events)
+	}()
+	return events, nil
+}
+
 // Test the basic scheduler
 func TestScheduler(t *testing.T) {
 	// Create mock repository
@@ -235,7 +345,7 @@ func TestScheduler(t *testing.T) {
 	repo.pods[pod.Metadata.UID] = pod
 	
 	// Test scheduling
-	err := scheduler.schedulePod(pod, []*types.Node{node1, node2})
+	err := scheduler.schedulePod(pod, newNodeInfos([]*types.Node{node1, node2}))
 	if err != nil {
 		t.Fatalf("Failed to schedule pod: %v", err)
 	}
@@ -274,16 +384,13 @@ func TestScheduler(t *testing.T) {
 	}
 }
 
-// Test the resource scheduler
+// Test the resource-aware default profile
 func TestResourceScheduler(t *testing.T) {
 	// Create mock repository
 	repo := NewMockRepository()
 	
-	// Create base scheduler
-	baseScheduler := NewScheduler(repo)
-	
-	// Create resource scheduler
-	scheduler := NewResourceScheduler(baseScheduler)
+	// The default profile filters and scores nodes by their resources
+	scheduler := NewScheduler(repo)
 	
 	// Create test nodes with different resource capacities
 	node1 := &types.Node{
@@ -354,14 +461,14 @@ func TestResourceScheduler(t *testing.T) {
 	}
 	
 	// Test node selection
-	selectedNode, err := scheduler.selectNode(highResourcePod, []*types.Node{node1, node2})
+	selectedNode, err := scheduler.selectNode(NewCycleState(), highResourcePod, newNodeInfos([]*types.Node{node1, node2}))
 	if err != nil {
 		t.Fatalf("Failed to select node: %v", err)
 	}
 	
 	// The high resource pod should be scheduled on the large node
-	if selectedNode.Metadata.Name != "large-node" {
-		t.Errorf("Expected pod to be scheduled on 'large-node', got '%s'", selectedNode.Metadata.Name)
+	if selectedNode.Node.Metadata.Name != "large-node" {
+		t.Errorf("Expected pod to be scheduled on 'large-node', got '%s'", selectedNode.Node.Metadata.Name)
 	}
 	
 	// Create test pod with node selector
@@ -392,14 +499,14 @@ func TestResourceScheduler(t *testing.T) {
 	}
 	
 	// Test node selection with node selector
-	selectedNode, err = scheduler.selectNode(nodeSelectorPod, []*types.Node{node1, node2})
+	selectedNode, err = scheduler.selectNode(NewCycleState(), nodeSelectorPod, newNodeInfos([]*types.Node{node1, node2}))
 	if err != nil {
 		t.Fatalf("Failed to select node: %v", err)
 	}
 	
 	// The pod with node selector should be scheduled on the node with matching labels
-	if selectedNode.Metadata.Name != "large-node" {
-		t.Errorf("Expected pod to be scheduled on 'large-node', got '%s'", selectedNode.Metadata.Name)
+	if selectedNode.Node.Metadata.Name != "large-node" {
+		t.Errorf("Expected pod to be scheduled on 'large-node', got '%s'", selectedNode.Node.Metadata.Name)
 	}
 }
 
@@ -453,4 +560,80 @@ func TestResourceParsing(t *testing.T) {
 			t.Errorf("parseMemoryResource(%s) = %d, expected %d", tc.input, result, tc.expected)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// binPackingProfile is the default profile scoring nodes by MostAllocated
+func binPackingProfile() Profile {
+	profile := DefaultProfile()
+	profile.SchedulerName = "bin-packing"
+	profile.Plugins = append([]PluginConfig(nil), profile.Plugins...)
+	for i, plugin := range profile.Plugins {
+		if plugin.Name == NodeResourcesFitName {
+			profile.Plugins[i].Args = json.RawMessage(`{"scoringStrategy": {"type": "MostAllocated"}}`)
+		}
+	}
+	return profile
+}
+
+func TestSchedulerProfiles(t *testing.T) {
+	repo := NewMockRepository()
+	repo.CreateNode(testNode("node-a", "4", "8Gi", nil))
+	repo.CreateNode(testNode("node-b", "4", "8Gi", nil))
+	busy := testPod("busy", "2", "4Gi")
+	busy.Spec.NodeName = "node-a"
+	busy.Status.Phase = "Running"
+	repo.pods[busy.Metadata.UID] = busy
+	repo.podAssignments[busy.Metadata.UID] = "node-a-uid"
+
+	spread := testPod("spread", "500m", "512Mi")
+	packed := testPod("packed", "500m", "512Mi")
+	packed.Spec.SchedulerName = "bin-packing"
+	orphan := testPod("orphan", "500m", "512Mi")
+	orphan.Spec.SchedulerName = "gpu-scheduler"
+	for _, pod := range []*types.Pod{spread, packed, orphan} {
+		pod.Status.Phase = "Pending"
+		repo.pods[pod.Metadata.UID] = pod
+	}
+
+	scheduler, err := NewSchedulerWithProfiles(repo, []Profile{DefaultProfile(), binPackingProfile()}, NewInTreeRegistry())
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	if err := scheduler.schedulePendingPods(); err != nil {
+		t.Fatalf("Failed to schedule pending pods: %v", err)
+	}
+
+	// The default profile spreads pods to the emptier node, the bin-packing
+	// one packs them onto the busier node
+	if nodeID := repo.podAssignments["spread-uid"]; nodeID != "node-b-uid" {
+		t.Errorf("Expected spread on node-b, got %q", nodeID)
+	}
+	if nodeID := repo.podAssignments["packed-uid"]; nodeID != "node-a-uid" {
+		t.Errorf("Expected packed on node-a, got %q", nodeID)
+	}
+
+	// A pod naming a scheduler that isn't running stays pending
+	if _, assigned := repo.podAssignments["orphan-uid"]; assigned {
+		t.Error("Expected orphan not to be scheduled")
+	}
+	if orphan.Status.Phase != "Pending" {
+		t.Errorf("Expected orphan to stay Pending, got %s", orphan.Status.Phase)
+	}
+	var condition *types.PodCondition
+	for i := range orphan.Status.Conditions {
+		if orphan.Status.Conditions[i].Type == "PodScheduled" {
+			condition = &orphan.Status.Conditions[i]
+		}
+	}
+	if condition == nil || condition.Status != "False" || condition.Reason != "SchedulerNotFound" {
+		t.Fatalf("Expected a SchedulerNotFound condition, got %+v", orphan.Status.Conditions)
+	}
+	if condition.Message != "no scheduler profile named gpu-scheduler is running" {
+		t.Errorf("Unexpected condition message %q", condition.Message)
+	}
+
+	// Profiles must be named uniquely
+	if _, err := NewSchedulerWithProfiles(repo, []Profile{DefaultProfile(), BasicProfile()}, NewInTreeRegistry()); err == nil {
+		t.Error("Expected duplicate profiles to be rejected")
+	}
+}
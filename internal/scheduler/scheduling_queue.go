@@ -0,0 +1,419 @@
+package scheduler
+
+import (
+	"container/heap"
+	"reflect"
+	"sync"
+	"time"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+const (
+	// DefaultPodInitialBackoff is how long a pod waits before its first retry
+	DefaultPodInitialBackoff = 1 * time.Second
+	// DefaultPodMaxBackoff caps the backoff, which doubles with every
+	// failed attempt
+	DefaultPodMaxBackoff = 10 * time.Second
+	// DefaultPodMaxUnschedulableDuration is how long an unschedulable pod
+	// waits for a relevant cluster change before it is retried anyway
+	DefaultPodMaxUnschedulableDuration = 5 * time.Minute
+)
+
+// QueuedPodInfo is a pod waiting in the scheduling queue
+type QueuedPodInfo struct {
+	Pod *types.Pod
+	// Timestamp orders pods of equal priority; it is the pod's creation time
+	Timestamp time.Time
+	// Attempts counts the failed scheduling attempts, which set the backoff
+	Attempts int
+	// lastFailure is when the latest scheduling attempt failed
+	lastFailure time.Time
+	// cycle is the scheduling cycle the pod was last popped in
+	cycle int64
+}
+
+// podHeap is a heap of queued pods, indexed by pod UID
+type podHeap struct {
+	items []*QueuedPodInfo
+	index map[string]int
+	less  func(a, b *QueuedPodInfo) bool
+}
+
+func newPodHeap(less func(a, b *QueuedPodInfo) bool) *podHeap {
+	return &podHeap{index: make(map[string]int), less: less}
+}
+
+func (h *podHeap) Len() int           { return len(h.items) }
+func (h *podHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+
+func (h *podHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].Pod.Metadata.UID] = i
+	h.index[h.items[j].Pod.Metadata.UID] = j
+}
+
+func (h *podHeap) Push(x interface{}) {
+	podInfo := x.(*QueuedPodInfo)
+	h.index[podInfo.Pod.Metadata.UID] = len(h.items)
+	h.items = append(h.items, podInfo)
+}
+
+func (h *podHeap) Pop() interface{} {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.index, last.Pod.Metadata.UID)
+	return last
+}
+
+// get returns the queued pod with the given UID
+func (h *podHeap) get(uid string) (*QueuedPodInfo, bool) {
+	i, ok := h.index[uid]
+	if !ok {
+		return nil, false
+	}
+	return h.items[i], true
+}
+
+// add adds a pod, or moves it to its new place if it is already queued
+func (h *podHeap) add(podInfo *QueuedPodInfo) {
+	if i, ok := h.index[podInfo.Pod.Metadata.UID]; ok {
+		h.items[i] = podInfo
+		heap.Fix(h, i)
+		return
+	}
+	heap.Push(h, podInfo)
+}
+
+// remove removes the pod with the given UID, if it is queued
+func (h *podHeap) remove(uid string) {
+	if i, ok := h.index[uid]; ok {
+		heap.Remove(h, i)
+	}
+}
+
+// peek returns the first pod without removing it
+func (h *podHeap) peek() *QueuedPodInfo {
+	if len(h.items) == 0 {
+		return nil
+	}
+	return h.items[0]
+}
+
+// SchedulingQueue holds the pods waiting to be scheduled in three queues:
+//
+//   - the active queue holds the pods to try next, highest priority first
+//   - the backoff queue holds pods that failed recently, until their backoff
+//     expires
+//   - unschedulable pods wait for a cluster change that could let them fit,
+//     such as a node being added or a pod freeing its resources
+//
+// Pods are moved from the unschedulable pods to the backoff or active queue
+// by MoveAllToActiveOrBackoff, and when they have waited too long.
+type SchedulingQueue struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	activeQ           *podHeap
+	backoffQ          *podHeap
+	unschedulablePods map[string]*QueuedPodInfo
+	// inFlight holds the latest version of the pods popped and not yet done
+	// or requeued
+	inFlight map[string]*types.Pod
+
+	// schedulingCycle counts the pods popped. moveRequestCycle is the cycle
+	// of the last move request, so a pod that fails while a change it may
+	// have missed comes in is backed off instead of parked as unschedulable.
+	schedulingCycle  int64
+	moveRequestCycle int64
+
+	initialBackoff           time.Duration
+	maxBackoff               time.Duration
+	maxUnschedulableDuration time.Duration
+	now                      func() time.Time
+	closed                   bool
+}
+
+// NewSchedulingQueue creates an empty scheduling queue with the default
+// backoff
+func NewSchedulingQueue() *SchedulingQueue {
+	q := &SchedulingQueue{
+		unschedulablePods:        make(map[string]*QueuedPodInfo),
+		inFlight:                 make(map[string]*types.Pod),
+		initialBackoff:           DefaultPodInitialBackoff,
+		maxBackoff:               DefaultPodMaxBackoff,
+		maxUnschedulableDuration: DefaultPodMaxUnschedulableDuration,
+		now:                      time.Now,
+		moveRequestCycle:         -1,
+	}
+	q.cond = sync.NewCond(&q.mu)
+	q.activeQ = newPodHeap(func(a, b *QueuedPodInfo) bool {
+		pa, pb := types.PodPriority(a.Pod), types.PodPriority(b.Pod)
+		if pa != pb {
+			return pa > pb
+		}
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		return a.Pod.Metadata.Name < b.Pod.Metadata.Name
+	})
+	q.backoffQ = newPodHeap(func(a, b *QueuedPodInfo) bool {
+		return q.backoffExpiry(a).Before(q.backoffExpiry(b))
+	})
+	return q
+}
+
+// backoffDuration is the backoff after the pod's failed attempts: the
+// initial backoff, doubled for every further attempt, up to the maximum
+func (q *SchedulingQueue) backoffDuration(podInfo *QueuedPodInfo) time.Duration {
+	backoff := q.initialBackoff
+	for i := 1; i < podInfo.Attempts; i++ {
+		backoff *= 2
+		if backoff >= q.maxBackoff {
+			return q.maxBackoff
+		}
+	}
+	return backoff
+}
+
+// backoffExpiry is when the pod may be retried
+func (q *SchedulingQueue) backoffExpiry(podInfo *QueuedPodInfo) time.Time {
+	return podInfo.lastFailure.Add(q.backoffDuration(podInfo))
+}
+
+// isBackingOff reports whether the pod has to wait before its next attempt
+func (q *SchedulingQueue) isBackingOff(podInfo *QueuedPodInfo) bool {
+	return podInfo.Attempts > 0 && q.now().Before(q.backoffExpiry(podInfo))
+}
+
+// Add queues a pod that needs scheduling. A queued pod is updated in place;
+// an unschedulable one is retried if its spec or labels changed, since that
+// may let it fit.
+func (q *SchedulingQueue) Add(pod *types.Pod) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	uid := pod.Metadata.UID
+	if _, ok := q.inFlight[uid]; ok {
+		// The outcome of the attempt in flight decides where it goes
+		q.inFlight[uid] = pod
+		return
+	}
+	if podInfo, ok := q.activeQ.get(uid); ok {
+		podInfo.Pod = pod
+		q.activeQ.add(podInfo)
+		return
+	}
+	if podInfo, ok := q.backoffQ.get(uid); ok {
+		podInfo.Pod = pod
+		q.backoffQ.add(podInfo)
+		return
+	}
+	if podInfo, ok := q.unschedulablePods[uid]; ok {
+		changed := schedulingPropertiesChanged(podInfo.Pod, pod)
+		podInfo.Pod = pod
+		if changed {
+			delete(q.unschedulablePods, uid)
+			q.requeue(podInfo)
+		}
+		return
+	}
+
+	timestamp := pod.Metadata.CreatedAt
+	if timestamp.IsZero() {
+		timestamp = q.now()
+	}
+	q.activeQ.add(&QueuedPodInfo{Pod: pod, Timestamp: timestamp})
+	q.cond.Broadcast()
+}
+
+// schedulingPropertiesChanged reports whether a pod changed in a way that can
+// change where it fits. Status updates, such as the scheduler's own
+// conditions, don't count.
+func schedulingPropertiesChanged(old, updated *types.Pod) bool {
+	return !reflect.DeepEqual(old.Spec, updated.Spec) || !reflect.DeepEqual(old.Metadata.Labels, updated.Metadata.Labels)
+}
+
+// requeue puts a pod in the backoff queue if it is backing off, or else in
+// the active queue. The caller holds the lock.
+func (q *SchedulingQueue) requeue(podInfo *QueuedPodInfo) {
+	if q.isBackingOff(podInfo) {
+		q.backoffQ.add(podInfo)
+		return
+	}
+	q.activeQ.add(podInfo)
+	q.cond.Broadcast()
+}
+
+// Delete removes a pod from the queue, e.g. once it was deleted or bound
+func (q *SchedulingQueue) Delete(pod *types.Pod) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	uid := pod.Metadata.UID
+	q.activeQ.remove(uid)
+	q.backoffQ.remove(uid)
+	delete(q.unschedulablePods, uid)
+	delete(q.inFlight, uid)
+}
+
+// Pop removes and returns the first pod of the active queue, waiting for one
+// if it is empty. It returns false once the queue is closed.
+func (q *SchedulingQueue) Pop() (*QueuedPodInfo, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.activeQ.Len() == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return nil, false
+	}
+	return q.pop(), true
+}
+
+// pop takes the first active pod for a scheduling cycle. The caller holds
+// the lock.
+func (q *SchedulingQueue) pop() *QueuedPodInfo {
+	podInfo := heap.Pop(q.activeQ).(*QueuedPodInfo)
+	q.schedulingCycle++
+	podInfo.cycle = q.schedulingCycle
+	q.inFlight[podInfo.Pod.Metadata.UID] = podInfo.Pod
+	return podInfo
+}
+
+// Done ends the scheduling attempt of a pod that was bound
+func (q *SchedulingQueue) Done(podInfo *QueuedPodInfo) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.inFlight, podInfo.Pod.Metadata.UID)
+}
+
+// AddUnschedulable requeues a pod whose scheduling attempt failed, backing
+// it off. A pod that didn't fit waits for a cluster change among the
+// unschedulable pods, unless a change came in during its attempt; a pod
+// that failed for another reason is only backed off. Pods deleted during
+// the attempt are dropped.
+func (q *SchedulingQueue) AddUnschedulable(podInfo *QueuedPodInfo, unschedulable bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	uid := podInfo.Pod.Metadata.UID
+	latest, ok := q.inFlight[uid]
+	if !ok {
+		return
+	}
+	delete(q.inFlight, uid)
+	podInfo.Pod = latest
+
+	podInfo.Attempts++
+	podInfo.lastFailure = q.now()
+	if unschedulable && q.moveRequestCycle < podInfo.cycle {
+		q.unschedulablePods[uid] = podInfo
+		return
+	}
+	q.backoffQ.add(podInfo)
+}
+
+// MoveAllToActiveOrBackoff retries the unschedulable pods accepted by
+// matches, or all of them if it is nil, after a cluster change that may let
+// them fit. Pods still backing off go to the backoff queue.
+func (q *SchedulingQueue) MoveAllToActiveOrBackoff(matches func(*types.Pod) bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for uid, podInfo := range q.unschedulablePods {
+		if matches != nil && !matches(podInfo.Pod) {
+			continue
+		}
+		delete(q.unschedulablePods, uid)
+		q.requeue(podInfo)
+	}
+	q.moveRequestCycle = q.schedulingCycle
+}
+
+// flushBackoffQCompleted moves the pods whose backoff expired to the active
+// queue
+func (q *SchedulingQueue) flushBackoffQCompleted() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		podInfo := q.backoffQ.peek()
+		if podInfo == nil || q.isBackingOff(podInfo) {
+			return
+		}
+		heap.Pop(q.backoffQ)
+		q.activeQ.add(podInfo)
+		q.cond.Broadcast()
+	}
+}
+
+// flushUnschedulableLeftover retries the unschedulable pods that have waited
+// longer than the maximum unschedulable duration
+func (q *SchedulingQueue) flushUnschedulableLeftover() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	for uid, podInfo := range q.unschedulablePods {
+		if now.Sub(podInfo.lastFailure) > q.maxUnschedulableDuration {
+			delete(q.unschedulablePods, uid)
+			q.requeue(podInfo)
+		}
+	}
+}
+
+// Run flushes the backoff queue every second and the leftover unschedulable
+// pods every 30 seconds, until stopCh is closed
+func (q *SchedulingQueue) Run(stopCh <-chan struct{}) {
+	backoffTicker := time.NewTicker(time.Second)
+	defer backoffTicker.Stop()
+	leftoverTicker := time.NewTicker(30 * time.Second)
+	defer leftoverTicker.Stop()
+
+	for {
+		select {
+		case <-backoffTicker.C:
+			q.flushBackoffQCompleted()
+		case <-leftoverTicker.C:
+			q.flushUnschedulableLeftover()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// Close wakes up Pop, which then returns false
+func (q *SchedulingQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// NominatedPods returns the queued pods that preempted other pods and were
+// nominated to a node
+func (q *SchedulingQueue) NominatedPods() []*types.Pod {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var pods []*types.Pod
+	add := func(podInfo *QueuedPodInfo) {
+		if podInfo.Pod.Status.NominatedNodeName != "" {
+			pods = append(pods, podInfo.Pod)
+		}
+	}
+	for _, podInfo := range q.activeQ.items {
+		add(podInfo)
+	}
+	for _, podInfo := range q.backoffQ.items {
+		add(podInfo)
+	}
+	for _, podInfo := range q.unschedulablePods {
+		add(podInfo)
+	}
+	return pods
+}
@@ -0,0 +1,246 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"mini-k8s-orchestration/internal/storage"
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// advanceQueueClock moves the queue's clock forward and flushes the pods
+// whose backoff is over
+func advanceQueueClock(q *SchedulingQueue, d time.Duration) {
+	now := q.now().Add(d)
+	q.now = func() time.Time { return now }
+	q.flushBackoffQCompleted()
+}
+
+// nodeEvent is the watch event of a node change
+func nodeEvent(eventType string, node *types.Node) storage.WatchEvent {
+	metadata, _ := json.Marshal(node.Metadata)
+	spec, _ := json.Marshal(node.Spec)
+	status, _ := json.Marshal(node.Status)
+	return storage.WatchEvent{Type: eventType, Resource: storage.Resource{
+		ID:       node.Metadata.UID,
+		Kind:     "Node",
+		Name:     node.Metadata.Name,
+		Metadata: string(metadata),
+		Spec:     string(spec),
+		Status:   string(status),
+	}}
+}
+
+// tryPop is Pop without waiting: it returns false if no pod is active
+func (q *SchedulingQueue) tryPop() (*QueuedPodInfo, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.activeQ.Len() == 0 || q.closed {
+		return nil, false
+	}
+	return q.pop(), true
+}
+
+// schedulePendingPods queues every pending pod and schedules the pods that
+// are active, until none are left, so tests can run the scheduling loop
+// without watching storage
+func (s *Scheduler) schedulePendingPods() error {
+	pendingPods, err := s.getPendingPods()
+	if err != nil {
+		return fmt.Errorf("failed to get pending pods: %w", err)
+	}
+	for _, pod := range pendingPods {
+		s.queuePendingPod(pod)
+	}
+
+	// Bound pods may have changed without events reaching the scheduler
+	s.invalidateCache()
+	for {
+		podInfo, ok := s.queue.tryPop()
+		if !ok {
+			return nil
+		}
+		s.scheduleOne(podInfo)
+	}
+}
+
+func popName(t *testing.T, q *SchedulingQueue) string {
+	t.Helper()
+
+	podInfo, ok := q.tryPop()
+	if !ok {
+		t.Fatal("Expected an active pod")
+	}
+	return podInfo.Pod.Metadata.Name
+}
+
+func TestSchedulingQueueOrder(t *testing.T) {
+	q := NewSchedulingQueue()
+	created := time.Now()
+	for _, pod := range []*types.Pod{
+		priorityPod("low", "1", 10),
+		priorityPod("high-new", "1", 100),
+		priorityPod("high-old", "1", 100),
+	} {
+		pod.Metadata.CreatedAt = created
+		if pod.Metadata.Name == "high-old" {
+			pod.Metadata.CreatedAt = created.Add(-time.Minute)
+		}
+		q.Add(pod)
+	}
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		names = append(names, popName(t, q))
+	}
+	if got := strings.Join(names, ","); got != "high-old,high-new,low" {
+		t.Errorf("Expected pods by priority then age, got %s", got)
+	}
+	if _, ok := q.tryPop(); ok {
+		t.Error("Expected the active queue to be empty")
+	}
+}
+
+func TestSchedulingQueueBackoff(t *testing.T) {
+	q := NewSchedulingQueue()
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, backoff := range want {
+		if got := q.backoffDuration(&QueuedPodInfo{Attempts: i + 1}); got != backoff {
+			t.Errorf("Expected a backoff of %v after %d attempts, got %v", backoff, i+1, got)
+		}
+	}
+
+	// A pod that failed for a reason other than fit waits out its backoff only
+	q.Add(testPod("web", "1", "64Mi"))
+	podInfo, _ := q.tryPop()
+	q.AddUnschedulable(podInfo, false)
+	if _, ok := q.tryPop(); ok {
+		t.Fatal("Expected the pod to be backing off")
+	}
+	advanceQueueClock(q, time.Second)
+	if name := popName(t, q); name != "web" {
+		t.Errorf("Expected web to be retried after its backoff, got %s", name)
+	}
+}
+
+func TestSchedulingQueueUnschedulablePods(t *testing.T) {
+	q := NewSchedulingQueue()
+	pod := testPod("web", "8", "64Mi")
+	q.Add(pod)
+	podInfo, _ := q.tryPop()
+	q.AddUnschedulable(podInfo, true)
+
+	// Past its backoff the pod still waits for a cluster change
+	advanceQueueClock(q, time.Minute)
+	if _, ok := q.tryPop(); ok {
+		t.Fatal("Expected the pod to wait as unschedulable")
+	}
+
+	// Status updates don't retry it, spec changes do
+	updated := *pod
+	updated.Status.Conditions = []types.PodCondition{{Type: "PodScheduled", Status: "False"}}
+	q.Add(&updated)
+	if _, ok := q.tryPop(); ok {
+		t.Fatal("Expected a status update not to retry the pod")
+	}
+	resized := testPod("web", "2", "64Mi")
+	q.Add(resized)
+	podInfo, ok := q.tryPop()
+	if !ok || podInfo.Pod != resized {
+		t.Fatal("Expected a spec change to retry the pod")
+	}
+
+	// A move request retries the pod once its backoff is over
+	q.AddUnschedulable(podInfo, true)
+	q.MoveAllToActiveOrBackoff(nil)
+	if _, ok := q.tryPop(); ok {
+		t.Fatal("Expected the moved pod to back off first")
+	}
+	advanceQueueClock(q, 2*time.Second)
+	if name := popName(t, q); name != "web" {
+		t.Errorf("Expected web to be retried, got %s", name)
+	}
+}
+
+func TestSchedulingQueueMoveDuringCycle(t *testing.T) {
+	q := NewSchedulingQueue()
+	q.Add(testPod("web", "8", "64Mi"))
+	podInfo, _ := q.tryPop()
+
+	// A node added while the pod was being scheduled may have been missed,
+	// so the pod only backs off
+	q.MoveAllToActiveOrBackoff(nil)
+	q.AddUnschedulable(podInfo, true)
+	advanceQueueClock(q, time.Second)
+	if name := popName(t, q); name != "web" {
+		t.Errorf("Expected web to be retried, got %s", name)
+	}
+
+	// A pod deleted during its cycle is dropped
+	q.Delete(podInfo.Pod)
+	q.AddUnschedulable(podInfo, true)
+	q.MoveAllToActiveOrBackoff(nil)
+	advanceQueueClock(q, time.Minute)
+	if _, ok := q.tryPop(); ok {
+		t.Error("Expected the deleted pod not to be requeued")
+	}
+}
+
+func TestUnschedulablePodRetriedWhenNodeAdded(t *testing.T) {
+	repo := NewMockRepository()
+	repo.CreateNode(testNode("small", "1", "1Gi", nil))
+	pod := testPod("web", "2", "64Mi")
+	repo.pods[pod.Metadata.UID] = pod
+
+	scheduler := NewScheduler(repo)
+	if err := scheduler.schedulePendingPods(); err != nil {
+		t.Fatalf("Failed to schedule pods: %v", err)
+	}
+
+	if len(pod.Status.Conditions) != 1 {
+		t.Fatalf("Expected a PodScheduled condition, got %+v", pod.Status.Conditions)
+	}
+	condition := pod.Status.Conditions[0]
+	if condition.Type != "PodScheduled" || condition.Status != "False" || condition.Reason != "Unschedulable" {
+		t.Errorf("Expected PodScheduled=False with reason Unschedulable, got %+v", condition)
+	}
+	if !strings.Contains(condition.Message, "0/1 nodes are available: 1 Insufficient cpu") {
+		t.Errorf("Expected the message to explain the failure, got %q", condition.Message)
+	}
+
+	// The first event of a node retries the pod, since the node may be new
+	small := testNode("small", "1", "1Gi", nil)
+	scheduler.handleNodeEvent(nodeEvent(storage.EventModified, small))
+	advanceQueueClock(scheduler.queue, time.Second)
+	if err := scheduler.schedulePendingPods(); err != nil {
+		t.Fatalf("Failed to schedule pods: %v", err)
+	}
+
+	// Heartbeats that change nothing the scheduler looks at don't
+	scheduler.handleNodeEvent(nodeEvent(storage.EventModified, small))
+	advanceQueueClock(scheduler.queue, time.Minute)
+	if _, ok := scheduler.queue.tryPop(); ok {
+		t.Fatal("Expected an unchanged node not to retry the pod")
+	}
+
+	// A node it fits on does
+	large := testNode("large", "4", "8Gi", nil)
+	repo.CreateNode(large)
+	scheduler.handleNodeEvent(nodeEvent(storage.EventAdded, large))
+	advanceQueueClock(scheduler.queue, time.Minute)
+	if err := scheduler.schedulePendingPods(); err != nil {
+		t.Fatalf("Failed to schedule pods: %v", err)
+	}
+
+	if nodeID := repo.podAssignments["web-uid"]; nodeID != "large-uid" {
+		t.Errorf("Expected web on the large node, got %q", nodeID)
+	}
+	if len(pod.Status.Conditions) != 1 || pod.Status.Conditions[0].Status != "True" {
+		t.Errorf("Expected the condition to be replaced by PodScheduled=True, got %+v", pod.Status.Conditions)
+	}
+}
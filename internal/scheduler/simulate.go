@@ -0,0 +1,194 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// SimulationResult reports where a set of pods would be scheduled
+type SimulationResult struct {
+	// Placements are the pods that fit, in the order they were placed
+	Placements []PodPlacement `json:"placements"`
+	// Unschedulable are the pods that fit no node
+	Unschedulable []PodFailure `json:"unschedulable"`
+	// Nodes is the utilization of the ready nodes once the pods that fit
+	// are placed
+	Nodes []NodeUtilization `json:"nodes"`
+}
+
+// PodPlacement is the node a simulated pod would land on
+type PodPlacement struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Node      string `json:"node"`
+}
+
+// PodFailure is why a simulated pod wouldn't fit
+type PodFailure struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+}
+
+// NodeUtilization is the share of a node's allocatable resources requested
+// by its pods
+type NodeUtilization struct {
+	Name string `json:"name"`
+	// Pods counts the pods on the node, SimulatedPods those placed by the
+	// simulation
+	Pods          int `json:"pods"`
+	SimulatedPods int `json:"simulatedPods"`
+	// CPU is in millicores and memory in bytes
+	RequestedCPU      int64 `json:"requestedCPU"`
+	AllocatableCPU    int64 `json:"allocatableCPU"`
+	RequestedMemory   int64 `json:"requestedMemory"`
+	AllocatableMemory int64 `json:"allocatableMemory"`
+	// CPUPercent and MemoryPercent are the requested shares of allocatable
+	CPUPercent    float64 `json:"cpuPercent"`
+	MemoryPercent float64 `json:"memoryPercent"`
+}
+
+// Simulate runs pods through the filter and score pipeline of the profiles
+// they name, against a snapshot of the ready nodes and the pods bound to
+// them. Each pod that fits is placed on its node, so the pods after it see
+// the room it takes. Nothing is written: pods are neither reserved nor
+// bound, and no pods are preempted to make room.
+//
+// Pods are tried highest priority first, otherwise in the given order.
+func (s *Scheduler) Simulate(pods []*types.Pod) (*SimulationResult, error) {
+	cache := NewCache(DefaultAssumedPodTTL)
+	if err := cache.Sync(s.repository); err != nil {
+		return nil, err
+	}
+	var nodeInfos []*NodeInfo
+	for _, nodeInfo := range cache.Snapshot() {
+		if isNodeReady(nodeInfo.Node) {
+			nodeInfos = append(nodeInfos, nodeInfo)
+		}
+	}
+
+	classes, err := listPriorityClasses(s.repository)
+	if err != nil {
+		return nil, err
+	}
+	pods = prepareSimulatedPods(pods)
+	for _, pod := range pods {
+		resolvePriority(pod, classes)
+	}
+	sort.SliceStable(pods, func(i, j int) bool {
+		return types.PodPriority(pods[i]) > types.PodPriority(pods[j])
+	})
+
+	result := &SimulationResult{
+		Placements:    []PodPlacement{},
+		Unschedulable: []PodFailure{},
+		Nodes:         []NodeUtilization{},
+	}
+	simulated := make(map[string]int)
+	for _, pod := range pods {
+		failure := PodFailure{Namespace: pod.Metadata.Namespace, Name: pod.Metadata.Name}
+		if err := types.ValidatePod(pod); err != nil {
+			failure.Reason = fmt.Sprintf("invalid pod: %v", err)
+			result.Unschedulable = append(result.Unschedulable, failure)
+			continue
+		}
+		if name := pod.Spec.PriorityClassName; name != "" && types.FindPriorityClass(classes, name) == nil {
+			failure.Reason = fmt.Sprintf("priority class %s not found", name)
+			result.Unschedulable = append(result.Unschedulable, failure)
+			continue
+		}
+
+		selected, err := s.selectNode(NewCycleState(), pod, nodeInfos)
+		if err != nil {
+			var fitErr *FitError
+			if !errors.As(err, &fitErr) {
+				err = fmt.Errorf("scheduler error: %w", err)
+			}
+			failure.Reason = err.Error()
+			result.Unschedulable = append(result.Unschedulable, failure)
+			continue
+		}
+
+		nodeName := selected.Node.Metadata.Name
+		selected.AddPod(pod)
+		simulated[nodeName]++
+		result.Placements = append(result.Placements, PodPlacement{
+			Namespace: pod.Metadata.Namespace,
+			Name:      pod.Metadata.Name,
+			Node:      nodeName,
+		})
+	}
+
+	for _, nodeInfo := range nodeInfos {
+		result.Nodes = append(result.Nodes, nodeUtilization(nodeInfo, simulated[nodeInfo.Node.Metadata.Name]))
+	}
+	return result, nil
+}
+
+// prepareSimulatedPods copies the pods, filling in what the API server would
+// set on creation: the default namespace, a name and a UID unique among the
+// simulated pods
+func prepareSimulatedPods(pods []*types.Pod) []*types.Pod {
+	prepared := make([]*types.Pod, 0, len(pods))
+	for i, pod := range pods {
+		copied := *pod
+		if copied.Metadata.Namespace == "" {
+			copied.Metadata.Namespace = "default"
+		}
+		if copied.Metadata.Name == "" {
+			copied.Metadata.Name = fmt.Sprintf("pod-%d", i+1)
+		}
+		copied.Metadata.UID = fmt.Sprintf("simulated-%d-%s-%s", i, copied.Metadata.Namespace, copied.Metadata.Name)
+		copied.Spec.NodeName = ""
+		copied.Status = types.PodStatus{Phase: "Pending"}
+		prepared = append(prepared, &copied)
+	}
+	return prepared
+}
+
+// nodeUtilization summarizes the requests on a node against its allocatable
+// resources. A node with invalid allocatable resources reports none.
+func nodeUtilization(nodeInfo *NodeInfo, simulatedPods int) NodeUtilization {
+	utilization := NodeUtilization{
+		Name:            nodeInfo.Node.Metadata.Name,
+		Pods:            len(nodeInfo.Pods),
+		SimulatedPods:   simulatedPods,
+		RequestedCPU:    nodeInfo.Requested.MilliCPU,
+		RequestedMemory: nodeInfo.Requested.Memory,
+	}
+	cpu, memory, err := getNodeAllocatableResources(nodeInfo.Node)
+	if err != nil {
+		return utilization
+	}
+	utilization.AllocatableCPU = cpu
+	utilization.AllocatableMemory = memory
+	utilization.CPUPercent = fraction(nodeInfo.Requested.MilliCPU, cpu) * 100
+	utilization.MemoryPercent = fraction(nodeInfo.Requested.Memory, memory) * 100
+	return utilization
+}
+
+// DeploymentPods returns the pods a deployment would create for the given
+// number of replicas, for simulating its rollout
+func DeploymentPods(deployment *types.Deployment, replicas int32) []*types.Pod {
+	pods := make([]*types.Pod, 0, replicas)
+	for i := int32(0); i < replicas; i++ {
+		labels := make(map[string]string)
+		for key, value := range deployment.Spec.Template.Metadata.Labels {
+			labels[key] = value
+		}
+		pods = append(pods, &types.Pod{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Metadata: types.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", deployment.Metadata.Name, i+1),
+				Namespace: deployment.Metadata.Namespace,
+				Labels:    labels,
+			},
+			Spec: deployment.Spec.Template.Spec,
+		})
+	}
+	return pods
+}
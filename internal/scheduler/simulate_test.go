@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+func TestSimulate(t *testing.T) {
+	repo := NewMockRepository()
+	repo.CreateNode(testNode("node-a", "2", "4Gi", nil))
+	repo.CreateNode(testNode("node-b", "2500m", "4Gi", map[string]string{"disk": "ssd"}))
+	existing := boundPod("existing", "1500m", 0, "node-a")
+	repo.pods[existing.Metadata.UID] = existing
+
+	deployment := &types.Deployment{
+		Metadata: types.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: types.DeploymentSpec{
+			Template: types.PodTemplateSpec{
+				Metadata: types.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec:     testPod("template", "1", "1Gi").Spec,
+			},
+		},
+	}
+	pods := DeploymentPods(deployment, 3)
+	if len(pods) != 3 || pods[2].Metadata.Name != "web-3" || pods[2].Metadata.Labels["app"] != "web" {
+		t.Fatalf("Unexpected deployment pods %+v", pods)
+	}
+	ssdPod := testPod("", "100m", "64Mi")
+	ssdPod.Spec.NodeSelector = map[string]string{"disk": "ssd"}
+	pods = append(pods, ssdPod)
+
+	result, err := NewScheduler(repo).Simulate(pods)
+	if err != nil {
+		t.Fatalf("Simulation failed: %v", err)
+	}
+
+	// node-b takes two replicas and the ssd pod; node-a has no room left
+	var placed []string
+	for _, placement := range result.Placements {
+		placed = append(placed, placement.Name+"="+placement.Node)
+	}
+	if got := strings.Join(placed, ","); got != "web-1=node-b,web-2=node-b,pod-4=node-b" {
+		t.Errorf("Unexpected placements %s", got)
+	}
+	if len(result.Unschedulable) != 1 || result.Unschedulable[0].Name != "web-3" {
+		t.Fatalf("Expected web-3 not to fit, got %+v", result.Unschedulable)
+	}
+	expected := "0/2 nodes are available: 2 Insufficient cpu"
+	if reason := result.Unschedulable[0].Reason; reason != expected {
+		t.Errorf("Expected %q, got %q", expected, reason)
+	}
+
+	if len(result.Nodes) != 2 {
+		t.Fatalf("Expected 2 nodes, got %+v", result.Nodes)
+	}
+	nodeB := result.Nodes[1]
+	if nodeB.Name != "node-b" || nodeB.Pods != 3 || nodeB.SimulatedPods != 3 || nodeB.RequestedCPU != 2100 || nodeB.AllocatableCPU != 2500 {
+		t.Errorf("Unexpected node-b utilization %+v", nodeB)
+	}
+	if nodeA := result.Nodes[0]; nodeA.Pods != 1 || nodeA.SimulatedPods != 0 || nodeA.CPUPercent != 75 {
+		t.Errorf("Unexpected node-a utilization %+v", nodeA)
+	}
+
+	// Nothing was written
+	if len(repo.podAssignments) != 0 {
+		t.Errorf("Expected no pod assignments, got %v", repo.podAssignments)
+	}
+	if len(repo.pods) != 1 {
+		t.Errorf("Expected only the existing pod, got %d pods", len(repo.pods))
+	}
+}
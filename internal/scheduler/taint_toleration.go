@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// TaintTolerationName is the name of the TaintToleration plugin
+const TaintTolerationName = "TaintToleration"
+
+// TaintToleration filters out nodes with NoSchedule or NoExecute taints the
+// pod doesn't tolerate, and prefers nodes with the fewest untolerated
+// PreferNoSchedule taints
+type TaintToleration struct{}
+
+// NewTaintToleration creates the TaintToleration plugin
+func NewTaintToleration(args json.RawMessage, handle Handle) (Plugin, error) {
+	return &TaintToleration{}, nil
+}
+
+// Name returns the name of the plugin
+func (p *TaintToleration) Name() string {
+	return TaintTolerationName
+}
+
+// Filter rejects nodes with a NoSchedule or NoExecute taint the pod doesn't
+// tolerate
+func (p *TaintToleration) Filter(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) *Status {
+	for i := range nodeInfo.Node.Spec.Taints {
+		taint := &nodeInfo.Node.Spec.Taints[i]
+		if taint.Effect == types.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !types.TolerationsTolerateTaint(pod.Spec.Tolerations, taint) {
+			return NewStatus(Unschedulable, fmt.Sprintf("node(s) had untolerated taint {%s: %s}", taint.Key, taint.Value))
+		}
+	}
+	return nil
+}
+
+// Score counts the PreferNoSchedule taints of the node the pod doesn't
+// tolerate; NormalizeScore turns the counts into scores
+func (p *TaintToleration) Score(state *CycleState, pod *types.Pod, nodeInfo *NodeInfo) (int64, *Status) {
+	var count int64
+	for i := range nodeInfo.Node.Spec.Taints {
+		taint := &nodeInfo.Node.Spec.Taints[i]
+		if taint.Effect == types.TaintEffectPreferNoSchedule && !types.TolerationsTolerateTaint(pod.Spec.Tolerations, taint) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// NormalizeScore gives nodes without untolerated taints the highest score and
+// the node with the most of them the lowest
+func (p *TaintToleration) NormalizeScore(state *CycleState, pod *types.Pod, scores []NodeScore) *Status {
+	var maxCount int64
+	for _, score := range scores {
+		if score.Score > maxCount {
+			maxCount = score.Score
+		}
+	}
+
+	for i := range scores {
+		if maxCount == 0 {
+			scores[i].Score = MaxNodeScore
+			continue
+		}
+		scores[i].Score = MaxNodeScore - scores[i].Score*MaxNodeScore/maxCount
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -16,6 +17,10 @@ var schemaFS embed.FS
 // Database wraps the SQL database connection
 type Database struct {
 	db *sql.DB
+
+	// changed is closed and replaced after every committed write
+	mu      sync.Mutex
+	changed chan struct{}
 }
 
 // NewDatabase creates a new database connection
@@ -25,9 +30,10 @@ func NewDatabase(dataDir string) (*Database, error) {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	// Open SQLite database
+	// Open SQLite database. Transactions take the write lock up front so
+	// that concurrent writers wait for each other instead of deadlocking.
 	dbPath := filepath.Join(dataDir, "orchestrator.db")
-	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on&_txlock=immediate")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -37,7 +43,7 @@ func NewDatabase(dataDir string) (*Database, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	database := &Database{db: db}
+	database := &Database{db: db, changed: make(chan struct{})}
 
 	// Run migrations
 	if err := database.migrate(); err != nil {
@@ -47,6 +53,19 @@ func NewDatabase(dataDir string) (*Database, error) {
 	return database, nil
 }
 
+// addedColumns lists columns added to existing tables after they were first
+// created. CREATE TABLE IF NOT EXISTS leaves older databases without them.
+var addedColumns = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"resources", "resource_version", "INTEGER NOT NULL DEFAULT 0"},
+	{"nodes", "metadata", "TEXT NOT NULL DEFAULT '{}'"},
+	{"nodes", "spec", "TEXT NOT NULL DEFAULT '{}'"},
+	{"nodes", "resource_version", "INTEGER NOT NULL DEFAULT 0"},
+}
+
 // migrate runs database migrations
 func (d *Database) migrate() error {
 	schema, err := schemaFS.ReadFile("schema.sql")
@@ -58,6 +77,21 @@ func (d *Database) migrate() error {
 		return fmt.Errorf("failed to execute schema: %w", err)
 	}
 
+	for _, added := range addedColumns {
+		var count int
+		err := d.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, added.table, added.column).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to inspect table %s: %w", added.table, err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		if _, err := d.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", added.table, added.column, added.definition)); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", added.table, added.column, err)
+		}
+	}
+
 	return nil
 }
 
@@ -76,6 +110,21 @@ func (d *Database) BeginTx() (*sql.Tx, error) {
 	return d.db.Begin()
 }
 
+// notifyChanged wakes up everyone waiting for a change
+func (d *Database) notifyChanged() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+// changes returns a channel that is closed after the next committed write
+func (d *Database) changes() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.changed
+}
+
 // Health checks if the database is healthy
 func (d *Database) Health() error {
 	return d.db.Ping()
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// ErrInvalidContinue is returned when a continue token is malformed or was
+// issued for a different list
+var ErrInvalidContinue = errors.New("invalid continue token")
+
+// ListOptions selects a page of a list. Pages are ordered by namespace and
+// name, so a continue token stays valid while objects are added or removed:
+// objects created behind the token are simply not part of the remaining pages.
+type ListOptions struct {
+	// Limit is the maximum number of objects to return, or 0 for all of them
+	Limit int
+	// Continue is the token returned with the previous page, or "" to start
+	Continue string
+}
+
+// ResourcePage is a page of resources
+type ResourcePage struct {
+	Resources []Resource
+	// Continue is the token of the next page, or "" if this is the last one
+	Continue string
+}
+
+// NodePage is a page of nodes
+type NodePage struct {
+	Nodes []*types.Node
+	// Continue is the token of the next page, or "" if this is the last one
+	Continue string
+}
+
+// continueToken is the position after which the next page starts
+type continueToken struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// encodeContinue returns the opaque token of the page after the given object
+func encodeContinue(kind, namespace, name string) string {
+	data, _ := json.Marshal(continueToken{Kind: kind, Namespace: namespace, Name: name})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeContinue returns the position encoded in a continue token for a list
+// of the given kind
+func decodeContinue(kind, token string) (*continueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidContinue
+	}
+	var position continueToken
+	if err := json.Unmarshal(data, &position); err != nil || position.Kind != kind || position.Name == "" {
+		return nil, ErrInvalidContinue
+	}
+	return &position, nil
+}
+
+// ListResourcesPage lists a page of the resources of a kind, in all
+// namespaces if namespace is empty
+func (r *SQLRepository) ListResourcesPage(kind, namespace string, options ListOptions) (*ResourcePage, error) {
+	if options.Limit < 0 {
+		return nil, fmt.Errorf("limit must not be negative: %d", options.Limit)
+	}
+
+	query := `
+		SELECT id, kind, namespace, name, metadata, spec, status, created_at, updated_at, resource_version
+		FROM resources
+		WHERE kind = ?`
+	args := []interface{}{kind}
+
+	if namespace != "" {
+		query += ` AND namespace = ?`
+		args = append(args, namespace)
+	}
+
+	if options.Continue != "" {
+		position, err := decodeContinue(kind, options.Continue)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (namespace, name) > (?, ?)`
+		args = append(args, position.Namespace, position.Name)
+	}
+
+	query += ` ORDER BY namespace, name`
+
+	// Read one extra resource to find out whether there is another page
+	if options.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, options.Limit+1)
+	}
+
+	rows, err := r.db.DB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+	defer rows.Close()
+
+	resources, err := scanResources(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ResourcePage{Resources: resources}
+	if options.Limit > 0 && len(resources) > options.Limit {
+		page.Resources = resources[:options.Limit]
+		last := page.Resources[options.Limit-1]
+		page.Continue = encodeContinue(kind, last.Namespace, last.Name)
+	}
+	return page, nil
+}
+
+// ListNodesPage lists a page of the nodes, ordered by name
+func (r *SQLRepository) ListNodesPage(options ListOptions) (*NodePage, error) {
+	if options.Limit < 0 {
+		return nil, fmt.Errorf("limit must not be negative: %d", options.Limit)
+	}
+
+	query := `
+		SELECT id, name, address, metadata, spec, status, last_heartbeat, created_at, resource_version
+		FROM nodes`
+	var args []interface{}
+
+	if options.Continue != "" {
+		position, err := decodeContinue("Node", options.Continue)
+		if err != nil {
+			return nil, err
+		}
+		query += ` WHERE name > ?`
+		args = append(args, position.Name)
+	}
+
+	query += ` ORDER BY name`
+
+	// Read one extra node to find out whether there is another page
+	if options.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, options.Limit+1)
+	}
+
+	rows, err := r.db.DB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	defer rows.Close()
+
+	nodes, err := scanNodes(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &NodePage{Nodes: nodes}
+	if options.Limit > 0 && len(nodes) > options.Limit {
+		page.Nodes = nodes[:options.Limit]
+		page.Continue = encodeContinue("Node", "", page.Nodes[options.Limit-1].Metadata.Name)
+	}
+	return page, nil
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+func createPageTestPod(t *testing.T, repo Repository, namespace, name string) {
+	t.Helper()
+
+	resource := Resource{ID: namespace + "-" + name, Kind: "Pod", Namespace: namespace, Name: name, Spec: "{}"}
+	if err := repo.CreateResource(resource); err != nil {
+		t.Fatalf("Failed to create pod %s/%s: %v", namespace, name, err)
+	}
+}
+
+func pageNames(page *ResourcePage) []string {
+	var names []string
+	for _, resource := range page.Resources {
+		names = append(names, resource.Namespace+"/"+resource.Name)
+	}
+	return names
+}
+
+func TestListResourcesPage(t *testing.T) {
+	repo := setupTestRepository(t)
+
+	for _, name := range []string{"c", "a", "e", "b"} {
+		createPageTestPod(t, repo, "default", name)
+	}
+	createPageTestPod(t, repo, "other", "a")
+
+	// Without a limit everything is returned in one page
+	page, err := repo.ListResourcesPage("Pod", "", ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+	if len(page.Resources) != 5 || page.Continue != "" {
+		t.Fatalf("Expected 5 pods in a single page, got %v with continue %q", pageNames(page), page.Continue)
+	}
+
+	page, err = repo.ListResourcesPage("Pod", "", ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+	if names := pageNames(page); len(names) != 2 || names[0] != "default/a" || names[1] != "default/b" {
+		t.Fatalf("Unexpected first page %v", names)
+	}
+	if page.Continue == "" {
+		t.Fatal("Expected a continue token for the next page")
+	}
+
+	// Objects added while paging show up only if they sort after the token
+	createPageTestPod(t, repo, "default", "aa")
+	createPageTestPod(t, repo, "default", "d")
+
+	page, err = repo.ListResourcesPage("Pod", "", ListOptions{Limit: 2, Continue: page.Continue})
+	if err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+	if names := pageNames(page); len(names) != 2 || names[0] != "default/c" || names[1] != "default/d" {
+		t.Fatalf("Unexpected second page %v", names)
+	}
+
+	page, err = repo.ListResourcesPage("Pod", "", ListOptions{Limit: 2, Continue: page.Continue})
+	if err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+	if names := pageNames(page); len(names) != 2 || names[0] != "default/e" || names[1] != "other/a" {
+		t.Fatalf("Unexpected last page %v", names)
+	}
+	if page.Continue != "" {
+		t.Errorf("Expected no continue token on the last page, got %q", page.Continue)
+	}
+
+	// Namespaced lists page within the namespace
+	page, err = repo.ListResourcesPage("Pod", "other", ListOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+	if names := pageNames(page); len(names) != 1 || names[0] != "other/a" || page.Continue != "" {
+		t.Errorf("Unexpected namespaced page %v with continue %q", names, page.Continue)
+	}
+}
+
+func TestListResourcesPageInvalidContinue(t *testing.T) {
+	repo := setupTestRepository(t)
+
+	for _, name := range []string{"a", "b"} {
+		createPageTestPod(t, repo, "default", name)
+	}
+
+	page, err := repo.ListResourcesPage("Pod", "", ListOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+
+	// Tokens are only valid for the kind they were issued for
+	if _, err := repo.ListResourcesPage("Service", "", ListOptions{Continue: page.Continue}); !errors.Is(err, ErrInvalidContinue) {
+		t.Errorf("Expected ErrInvalidContinue for another kind, got %v", err)
+	}
+	if _, err := repo.ListResourcesPage("Pod", "", ListOptions{Continue: "not-a-token"}); !errors.Is(err, ErrInvalidContinue) {
+		t.Errorf("Expected ErrInvalidContinue for a malformed token, got %v", err)
+	}
+}
+
+func TestListNodesPage(t *testing.T) {
+	repo := setupTestRepository(t)
+
+	for _, name := range []string{"node-b", "node-a", "node-c"} {
+		node := &types.Node{Metadata: types.ObjectMeta{Name: name, UID: name + "-uid"}}
+		if err := repo.CreateNode(node); err != nil {
+			t.Fatalf("Failed to create node: %v", err)
+		}
+	}
+
+	var names []string
+	options := ListOptions{Limit: 2}
+	for {
+		page, err := repo.ListNodesPage(options)
+		if err != nil {
+			t.Fatalf("Failed to list nodes: %v", err)
+		}
+		for _, node := range page.Nodes {
+			names = append(names, node.Metadata.Name)
+		}
+		if page.Continue == "" {
+			break
+		}
+		options.Continue = page.Continue
+	}
+
+	if len(names) != 3 || names[0] != "node-a" || names[1] != "node-b" || names[2] != "node-c" {
+		t.Errorf("Expected nodes in name order, got %v", names)
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// DeletePodGracefully deletes a pod. A pod on a node is marked as terminating
+// and removed by the node's agent once its containers have shut down; a
+// repeated deletion may only shorten the grace period. A pod that isn't on a
+// node, or is deleted with a grace period of 0, is removed at once along with
+// its node assignment. gracePeriod overrides the grace period of the pod's
+// spec. It returns the terminating pod, or nil if the pod was removed.
+func DeletePodGracefully(repository Repository, namespace, name string, gracePeriod *int64) (*types.Pod, error) {
+	resource, err := repository.GetResource("Pod", namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// A pod that can't be decoded can't be tracked while its containers
+	// shut down
+	pod, err := decodePod(resource)
+	if err != nil || (gracePeriod != nil && *gracePeriod == 0) || !podOnNode(repository, resource.ID, pod) {
+		if err := repository.DeleteResource("Pod", namespace, name); err != nil {
+			return nil, err
+		}
+		// The pod may never have been scheduled, so a missing assignment is fine
+		repository.DeletePodAssignment(resource.ID)
+		return nil, nil
+	}
+
+	// Mark the latest version of the pod as terminating
+	err = RetryOnConflict(func() error {
+		latest, err := repository.GetResource("Pod", namespace, name)
+		if err != nil {
+			return err
+		}
+		pod, err = decodePod(latest)
+		if err != nil {
+			return err
+		}
+		if !types.MarkPodTerminating(pod, gracePeriod) {
+			return nil
+		}
+
+		metadataJSON, err := json.Marshal(pod.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal pod metadata: %w", err)
+		}
+		statusJSON, err := json.Marshal(pod.Status)
+		if err != nil {
+			return fmt.Errorf("failed to marshal pod status: %w", err)
+		}
+		latest.Metadata = string(metadataJSON)
+		latest.Status = string(statusJSON)
+		return repository.UpdateResource(latest)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+// podOnNode reports whether a pod is bound or assigned to a node
+func podOnNode(repository Repository, podID string, pod *types.Pod) bool {
+	if pod.Spec.NodeName != "" {
+		return true
+	}
+	assignment, err := repository.GetPodAssignment(podID)
+	return err == nil && assignment != nil
+}
+
+// decodePod decodes the stored parts of a pod
+func decodePod(resource Resource) (*types.Pod, error) {
+	pod := &types.Pod{APIVersion: "v1", Kind: "Pod"}
+	if resource.Metadata != "" {
+		if err := json.Unmarshal([]byte(resource.Metadata), &pod.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pod metadata: %w", err)
+		}
+	}
+	if err := json.Unmarshal([]byte(resource.Spec), &pod.Spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pod spec: %w", err)
+	}
+	if resource.Status != "" {
+		if err := json.Unmarshal([]byte(resource.Status), &pod.Status); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pod status: %w", err)
+		}
+	}
+	return pod, nil
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+func TestDeletePodGracefully(t *testing.T) {
+	repo := setupTestRepository(t)
+	create := func(name, nodeName string) {
+		t.Helper()
+		spec, _ := json.Marshal(types.PodSpec{NodeName: nodeName})
+		err := repo.CreateResource(Resource{
+			ID:        name + "-uid",
+			Kind:      "Pod",
+			Namespace: "default",
+			Name:      name,
+			Metadata:  `{"name":"` + name + `","namespace":"default"}`,
+			Spec:      string(spec),
+			Status:    `{"phase":"Running"}`,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create pod %s: %v", name, err)
+		}
+	}
+
+	// A pod that isn't on a node is removed at once
+	create("pending", "")
+	if pod, err := DeletePodGracefully(repo, "default", "pending", nil); err != nil || pod != nil {
+		t.Fatalf("Expected the pending pod to be removed, got %v, %v", pod, err)
+	}
+	if _, err := repo.GetResource("Pod", "default", "pending"); err == nil {
+		t.Error("Expected the pending pod to be gone")
+	}
+
+	// A pod on a node is marked as terminating with its spec's grace period
+	create("web", "node-a")
+	pod, err := DeletePodGracefully(repo, "default", "web", nil)
+	if err != nil || pod == nil {
+		t.Fatalf("Expected the pod to be terminating, got %v, %v", pod, err)
+	}
+	resource, err := repo.GetResource("Pod", "default", "web")
+	if err != nil {
+		t.Fatalf("Failed to get pod: %v", err)
+	}
+	stored, err := decodePod(resource)
+	if err != nil {
+		t.Fatalf("Failed to decode pod: %v", err)
+	}
+	if !types.IsPodTerminating(stored) || stored.Status.Phase != types.PodPhaseTerminating {
+		t.Errorf("Expected a terminating pod, got phase %s", stored.Status.Phase)
+	}
+	if *stored.Metadata.DeletionGracePeriodSeconds != types.DefaultTerminationGracePeriodSeconds {
+		t.Errorf("Expected the default grace period, got %d", *stored.Metadata.DeletionGracePeriodSeconds)
+	}
+
+	// A repeated deletion may shorten the grace period
+	shorter := int64(5)
+	if pod, err := DeletePodGracefully(repo, "default", "web", &shorter); err != nil || *pod.Metadata.DeletionGracePeriodSeconds != 5 {
+		t.Fatalf("Expected the grace period to shrink to 5, got %v", err)
+	}
+
+	// A grace period of 0 removes the pod right away
+	zero := int64(0)
+	if pod, err := DeletePodGracefully(repo, "default", "web", &zero); err != nil || pod != nil {
+		t.Fatalf("Expected the pod to be removed, got %v, %v", pod, err)
+	}
+	if _, err := repo.GetResource("Pod", "default", "web"); err == nil {
+		t.Error("Expected the pod to be gone")
+	}
+
+	if _, err := DeletePodGracefully(repo, "default", "web", nil); err == nil {
+		t.Error("Expected deleting a missing pod to fail")
+	}
+}
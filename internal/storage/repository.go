@@ -1,10 +1,12 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"mini-k8s-orchestration/pkg/types"
@@ -13,6 +15,7 @@ import (
 // Common errors
 var (
 	ErrResourceNotFound = errors.New("resource not found")
+	ErrConflict         = errors.New("resource has been modified")
 )
 
 // Repository defines the interface for resource storage operations
@@ -23,6 +26,7 @@ type Repository interface {
 	UpdateResource(resource Resource) error
 	DeleteResource(kind, namespace, name string) error
 	ListResources(kind, namespace string) ([]Resource, error)
+	ListResourcesPage(kind, namespace string, options ListOptions) (*ResourcePage, error)
 
 	// Node-specific operations
 	CreateNode(node *types.Node) error
@@ -30,6 +34,7 @@ type Repository interface {
 	UpdateNode(node *types.Node) error
 	DeleteNode(name string) error
 	ListNodes() ([]*types.Node, error)
+	ListNodesPage(options ListOptions) (*NodePage, error)
 	UpdateNodeHeartbeat(name string) error
 
 	// Pod assignment operations
@@ -38,6 +43,10 @@ type Repository interface {
 	UpdatePodAssignmentStatus(podID, status string) error
 	DeletePodAssignment(podID string) error
 	ListPodAssignmentsByNode(nodeID string) ([]*PodAssignment, error)
+
+	// Watch operations
+	CurrentRevision() (int64, error)
+	Watch(ctx context.Context, kind, namespace string, revision int64) (<-chan WatchEvent, error)
 }
 
 // Resource represents a generic Kubernetes resource
@@ -51,6 +60,11 @@ type Resource struct {
 	Status    string    `json:"status"`    // JSON blob
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// ResourceVersion is the revision of the last write to the resource. An
+	// update with a non-zero ResourceVersion only succeeds if the resource
+	// has not been written since; zero updates unconditionally.
+	ResourceVersion int64 `json:"resourceVersion"`
 }
 
 // PodAssignment represents a pod assignment to a node
@@ -74,40 +88,57 @@ func NewSQLRepository(db *Database) Repository {
 // CreateResource creates a new resource
 func (r *SQLRepository) CreateResource(resource Resource) error {
 	query := `
-		INSERT INTO resources (id, kind, namespace, name, metadata, spec, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO resources (id, kind, namespace, name, metadata, spec, status, created_at, updated_at, resource_version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	
 	now := time.Now()
-	_, err := r.db.DB().Exec(query,
-		resource.ID,
-		resource.Kind,
-		resource.Namespace,
-		resource.Name,
-		resource.Metadata,
-		resource.Spec,
-		resource.Status,
-		now,
-		now,
-	)
-	
-	if err != nil {
-		return fmt.Errorf("failed to create resource: %w", err)
-	}
+	resource.CreatedAt = now
+	resource.UpdatedAt = now
+	
+	err := r.withEvent(func(tx *sql.Tx, revision int64) (string, Resource, error) {
+		resource.Metadata = withResourceVersion(resource.Metadata, revision)
+		_, err := tx.Exec(query,
+			resource.ID,
+			resource.Kind,
+			resource.Namespace,
+			resource.Name,
+			resource.Metadata,
+			resource.Spec,
+			resource.Status,
+			now,
+			now,
+			revision,
+		)
+		if err != nil {
+			return "", Resource{}, fmt.Errorf("failed to create resource: %w", err)
+		}
+		return EventAdded, resource, nil
+	})
 	
-	return nil
+	return err
 }
 
 // GetResource retrieves a resource by kind, namespace, and name
 func (r *SQLRepository) GetResource(kind, namespace, name string) (Resource, error) {
+	return getResource(r.db.DB(), kind, namespace, name)
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// getResource retrieves a resource by kind, namespace, and name
+func getResource(q queryer, kind, namespace, name string) (Resource, error) {
 	query := `
-		SELECT id, kind, namespace, name, metadata, spec, status, created_at, updated_at
+		SELECT id, kind, namespace, name, metadata, spec, status, created_at, updated_at, resource_version
 		FROM resources
 		WHERE kind = ? AND namespace = ? AND name = ?
 	`
 	
 	var resource Resource
-	err := r.db.DB().QueryRow(query, kind, namespace, name).Scan(
+	err := q.QueryRow(query, kind, namespace, name).Scan(
 		&resource.ID,
 		&resource.Kind,
 		&resource.Namespace,
@@ -117,6 +148,7 @@ func (r *SQLRepository) GetResource(kind, namespace, name string) (Resource, err
 		&resource.Status,
 		&resource.CreatedAt,
 		&resource.UpdatedAt,
+		&resource.ResourceVersion,
 	)
 	
 	if err != nil {
@@ -133,55 +165,67 @@ func (r *SQLRepository) GetResource(kind, namespace, name string) (Resource, err
 func (r *SQLRepository) UpdateResource(resource Resource) error {
 	query := `
 		UPDATE resources
-		SET metadata = ?, spec = ?, status = ?, updated_at = ?
-		WHERE kind = ? AND namespace = ? AND name = ?
+		SET metadata = ?, spec = ?, status = ?, updated_at = ?, resource_version = ?
+		WHERE kind = ? AND namespace = ? AND name = ? AND (? = 0 OR resource_version = ?)
 	`
 	
-	result, err := r.db.DB().Exec(query,
-		resource.Metadata,
-		resource.Spec,
-		resource.Status,
-		time.Now(),
-		resource.Kind,
-		resource.Namespace,
-		resource.Name,
-	)
-	
-	if err != nil {
-		return fmt.Errorf("failed to update resource: %w", err)
-	}
-	
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-	
-	if rowsAffected == 0 {
-		return fmt.Errorf("resource not found: %s/%s/%s", resource.Kind, resource.Namespace, resource.Name)
-	}
+	err := r.withEvent(func(tx *sql.Tx, revision int64) (string, Resource, error) {
+		result, err := tx.Exec(query,
+			withResourceVersion(resource.Metadata, revision),
+			resource.Spec,
+			resource.Status,
+			time.Now(),
+			revision,
+			resource.Kind,
+			resource.Namespace,
+			resource.Name,
+			resource.ResourceVersion,
+			resource.ResourceVersion,
+		)
+		if err != nil {
+			return "", Resource{}, fmt.Errorf("failed to update resource: %w", err)
+		}
+		
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return "", Resource{}, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		
+		if rowsAffected == 0 {
+			// The resource either doesn't exist or was written since it was read
+			if _, err := getResource(tx, resource.Kind, resource.Namespace, resource.Name); err != nil {
+				return "", Resource{}, err
+			}
+			return "", Resource{}, fmt.Errorf("%w: %s/%s/%s", ErrConflict, resource.Kind, resource.Namespace, resource.Name)
+		}
+		
+		// Record the resource as stored
+		updated, err := getResource(tx, resource.Kind, resource.Namespace, resource.Name)
+		return EventModified, updated, err
+	})
 	
-	return nil
+	return err
 }
 
 // DeleteResource deletes a resource
 func (r *SQLRepository) DeleteResource(kind, namespace, name string) error {
 	query := `DELETE FROM resources WHERE kind = ? AND namespace = ? AND name = ?`
 	
-	result, err := r.db.DB().Exec(query, kind, namespace, name)
-	if err != nil {
-		return fmt.Errorf("failed to delete resource: %w", err)
-	}
-	
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-	
-	if rowsAffected == 0 {
-		return fmt.Errorf("resource not found: %s/%s/%s", kind, namespace, name)
-	}
+	err := r.withEvent(func(tx *sql.Tx, revision int64) (string, Resource, error) {
+		// Record the last state of the resource
+		deleted, err := getResource(tx, kind, namespace, name)
+		if err != nil {
+			return "", Resource{}, err
+		}
+		
+		if _, err := tx.Exec(query, kind, namespace, name); err != nil {
+			return "", Resource{}, fmt.Errorf("failed to delete resource: %w", err)
+		}
+		
+		return EventDeleted, deleted, nil
+	})
 	
-	return nil
+	return err
 }
 
 // ListResources lists resources by kind and namespace
@@ -191,7 +235,7 @@ func (r *SQLRepository) ListResources(kind, namespace string) ([]Resource, error
 	
 	if namespace == "" {
 		query = `
-			SELECT id, kind, namespace, name, metadata, spec, status, created_at, updated_at
+			SELECT id, kind, namespace, name, metadata, spec, status, created_at, updated_at, resource_version
 			FROM resources
 			WHERE kind = ?
 			ORDER BY created_at DESC
@@ -199,7 +243,7 @@ func (r *SQLRepository) ListResources(kind, namespace string) ([]Resource, error
 		args = []interface{}{kind}
 	} else {
 		query = `
-			SELECT id, kind, namespace, name, metadata, spec, status, created_at, updated_at
+			SELECT id, kind, namespace, name, metadata, spec, status, created_at, updated_at, resource_version
 			FROM resources
 			WHERE kind = ? AND namespace = ?
 			ORDER BY created_at DESC
@@ -213,6 +257,11 @@ func (r *SQLRepository) ListResources(kind, namespace string) ([]Resource, error
 	}
 	defer rows.Close()
 	
+	return scanResources(rows)
+}
+
+// scanResources reads the resources selected by a query
+func scanResources(rows *sql.Rows) ([]Resource, error) {
 	var resources []Resource
 	for rows.Next() {
 		var resource Resource
@@ -226,6 +275,7 @@ func (r *SQLRepository) ListResources(kind, namespace string) ([]Resource, error
 			&resource.Status,
 			&resource.CreatedAt,
 			&resource.UpdatedAt,
+			&resource.ResourceVersion,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan resource: %w", err)
@@ -242,14 +292,19 @@ func (r *SQLRepository) ListResources(kind, namespace string) ([]Resource, error
 
 // CreateNode creates a new node
 func (r *SQLRepository) CreateNode(node *types.Node) error {
+	specJSON, err := json.Marshal(node.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal node spec: %w", err)
+	}
+	
 	statusJSON, err := json.Marshal(node.Status)
 	if err != nil {
 		return fmt.Errorf("failed to marshal node status: %w", err)
 	}
 	
 	query := `
-		INSERT INTO nodes (id, name, address, status, last_heartbeat, created_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO nodes (id, name, address, metadata, spec, status, last_heartbeat, created_at, resource_version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	
 	now := time.Now()
@@ -258,42 +313,47 @@ func (r *SQLRepository) CreateNode(node *types.Node) error {
 		address = node.Status.Addresses[0].Address
 	}
 	
-	_, err = r.db.DB().Exec(query,
-		node.Metadata.UID,
-		node.Metadata.Name,
-		address,
-		string(statusJSON),
-		now,
-		now,
-	)
-	
-	if err != nil {
-		return fmt.Errorf("failed to create node: %w", err)
-	}
-	
-	return nil
+	return r.withEvent(func(tx *sql.Tx, revision int64) (string, Resource, error) {
+		node.Metadata.ResourceVersion = FormatResourceVersion(revision)
+		metadataJSON, err := json.Marshal(node.Metadata)
+		if err != nil {
+			return "", Resource{}, fmt.Errorf("failed to marshal node metadata: %w", err)
+		}
+		
+		_, err = tx.Exec(query,
+			node.Metadata.UID,
+			node.Metadata.Name,
+			address,
+			string(metadataJSON),
+			string(specJSON),
+			string(statusJSON),
+			now,
+			now,
+			revision,
+		)
+		if err != nil {
+			return "", Resource{}, fmt.Errorf("failed to create node: %w", err)
+		}
+		
+		created, err := getNodeResource(tx, node.Metadata.Name)
+		return EventAdded, created, err
+	})
 }
 
 // GetNode retrieves a node by name
 func (r *SQLRepository) GetNode(name string) (*types.Node, error) {
+	return getNode(r.db.DB(), name)
+}
+
+// getNode retrieves a node by name
+func getNode(q queryer, name string) (*types.Node, error) {
 	query := `
-		SELECT id, name, address, status, last_heartbeat, created_at
+		SELECT id, name, address, metadata, spec, status, last_heartbeat, created_at, resource_version
 		FROM nodes
 		WHERE name = ?
 	`
 	
-	var id, nodeName, address, statusJSON string
-	var lastHeartbeat, createdAt time.Time
-	
-	err := r.db.DB().QueryRow(query, name).Scan(
-		&id,
-		&nodeName,
-		&address,
-		&statusJSON,
-		&lastHeartbeat,
-		&createdAt,
-	)
-	
+	node, err := scanNode(q.QueryRow(query, name))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("node not found: %s", name)
@@ -301,27 +361,22 @@ func (r *SQLRepository) GetNode(name string) (*types.Node, error) {
 		return nil, fmt.Errorf("failed to get node: %w", err)
 	}
 	
-	var status types.NodeStatus
-	if err := json.Unmarshal([]byte(statusJSON), &status); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal node status: %w", err)
-	}
-	
-	node := &types.Node{
-		APIVersion: "v1",
-		Kind:       "Node",
-		Metadata: types.ObjectMeta{
-			Name:      nodeName,
-			UID:       id,
-			CreatedAt: createdAt,
-		},
-		Status: status,
-	}
-	
 	return node, nil
 }
 
-// UpdateNode updates an existing node
+// UpdateNode updates an existing node. If the node carries a resourceVersion
+// the update only applies to that version, otherwise it is unconditional.
 func (r *SQLRepository) UpdateNode(node *types.Node) error {
+	resourceVersion, err := ParseResourceVersion(node.Metadata.ResourceVersion)
+	if err != nil {
+		return err
+	}
+	
+	specJSON, err := json.Marshal(node.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal node spec: %w", err)
+	}
+	
 	statusJSON, err := json.Marshal(node.Status)
 	if err != nil {
 		return fmt.Errorf("failed to marshal node status: %w", err)
@@ -334,58 +389,75 @@ func (r *SQLRepository) UpdateNode(node *types.Node) error {
 	
 	query := `
 		UPDATE nodes
-		SET address = ?, status = ?, last_heartbeat = ?
-		WHERE name = ?
+		SET address = ?, metadata = ?, spec = ?, status = ?, last_heartbeat = ?, resource_version = ?
+		WHERE name = ? AND (? = 0 OR resource_version = ?)
 	`
 	
-	result, err := r.db.DB().Exec(query,
-		address,
-		string(statusJSON),
-		time.Now(),
-		node.Metadata.Name,
-	)
-	
-	if err != nil {
-		return fmt.Errorf("failed to update node: %w", err)
-	}
-	
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-	
-	if rowsAffected == 0 {
-		return fmt.Errorf("node not found: %s", node.Metadata.Name)
-	}
-	
-	return nil
+	return r.withEvent(func(tx *sql.Tx, revision int64) (string, Resource, error) {
+		metadata := node.Metadata
+		metadata.ResourceVersion = FormatResourceVersion(revision)
+		metadataJSON, err := json.Marshal(metadata)
+		if err != nil {
+			return "", Resource{}, fmt.Errorf("failed to marshal node metadata: %w", err)
+		}
+		
+		result, err := tx.Exec(query,
+			address,
+			string(metadataJSON),
+			string(specJSON),
+			string(statusJSON),
+			time.Now(),
+			revision,
+			node.Metadata.Name,
+			resourceVersion,
+			resourceVersion,
+		)
+		
+		if err != nil {
+			return "", Resource{}, fmt.Errorf("failed to update node: %w", err)
+		}
+		
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return "", Resource{}, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		
+		if rowsAffected == 0 {
+			// The node either doesn't exist or was written since it was read
+			if _, err := getNode(tx, node.Metadata.Name); err != nil {
+				return "", Resource{}, err
+			}
+			return "", Resource{}, fmt.Errorf("%w: Node/%s", ErrConflict, node.Metadata.Name)
+		}
+		
+		updated, err := getNodeResource(tx, node.Metadata.Name)
+		return EventModified, updated, err
+	})
 }
 
 // DeleteNode deletes a node
 func (r *SQLRepository) DeleteNode(name string) error {
 	query := `DELETE FROM nodes WHERE name = ?`
 	
-	result, err := r.db.DB().Exec(query, name)
-	if err != nil {
-		return fmt.Errorf("failed to delete node: %w", err)
-	}
-	
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-	
-	if rowsAffected == 0 {
-		return fmt.Errorf("node not found: %s", name)
-	}
-	
-	return nil
+	return r.withEvent(func(tx *sql.Tx, revision int64) (string, Resource, error) {
+		// Record the last state of the node
+		deleted, err := getNodeResource(tx, name)
+		if err != nil {
+			return "", Resource{}, err
+		}
+		
+		if _, err := tx.Exec(query, name); err != nil {
+			return "", Resource{}, fmt.Errorf("failed to delete node: %w", err)
+		}
+		
+		return EventDeleted, deleted, nil
+	})
 }
 
 // ListNodes lists all nodes
 func (r *SQLRepository) ListNodes() ([]*types.Node, error) {
 	query := `
-		SELECT id, name, address, status, last_heartbeat, created_at
+		SELECT id, name, address, metadata, spec, status, last_heartbeat, created_at, resource_version
 		FROM nodes
 		ORDER BY created_at DESC
 	`
@@ -396,39 +468,18 @@ func (r *SQLRepository) ListNodes() ([]*types.Node, error) {
 	}
 	defer rows.Close()
 	
+	return scanNodes(rows)
+}
+
+// scanNodes reads the nodes selected by a query
+func scanNodes(rows *sql.Rows) ([]*types.Node, error) {
 	var nodes []*types.Node
 	for rows.Next() {
-		var id, nodeName, address, statusJSON string
-		var lastHeartbeat, createdAt time.Time
-		
-		err := rows.Scan(
-			&id,
-			&nodeName,
-			&address,
-			&statusJSON,
-			&lastHeartbeat,
-			&createdAt,
-		)
+		node, err := scanNode(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan node: %w", err)
 		}
 		
-		var status types.NodeStatus
-		if err := json.Unmarshal([]byte(statusJSON), &status); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal node status: %w", err)
-		}
-		
-		node := &types.Node{
-			APIVersion: "v1",
-			Kind:       "Node",
-			Metadata: types.ObjectMeta{
-				Name:      nodeName,
-				UID:       id,
-				CreatedAt: createdAt,
-			},
-			Status: status,
-		}
-		
 		nodes = append(nodes, node)
 	}
 	
@@ -439,6 +490,66 @@ func (r *SQLRepository) ListNodes() ([]*types.Node, error) {
 	return nodes, nil
 }
 
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanNode reads a node row selected with the columns id, name, address,
+// metadata, spec, status, last_heartbeat, created_at and resource_version
+func scanNode(row rowScanner) (*types.Node, error) {
+	var id, nodeName, address, metadataJSON, specJSON, statusJSON string
+	var lastHeartbeat, createdAt time.Time
+	var resourceVersion int64
+	
+	err := row.Scan(
+		&id,
+		&nodeName,
+		&address,
+		&metadataJSON,
+		&specJSON,
+		&statusJSON,
+		&lastHeartbeat,
+		&createdAt,
+		&resourceVersion,
+	)
+	if err != nil {
+		return nil, err
+	}
+	
+	var metadata types.ObjectMeta
+	if err := json.Unmarshal([]byte(metadataJSON), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal node metadata: %w", err)
+	}
+	
+	var spec types.NodeSpec
+	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal node spec: %w", err)
+	}
+	
+	var status types.NodeStatus
+	if err := json.Unmarshal([]byte(statusJSON), &status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal node status: %w", err)
+	}
+	
+	// Identity always comes from the stored row
+	metadata.Name = nodeName
+	metadata.Namespace = ""
+	metadata.UID = id
+	metadata.CreatedAt = createdAt
+	metadata.ResourceVersion = FormatResourceVersion(resourceVersion)
+	
+	node := &types.Node{
+		APIVersion: "v1",
+		Kind:       "Node",
+		Metadata:   metadata,
+		Spec:       spec,
+		Status:     status,
+	}
+	
+	return node, nil
+}
+
 // UpdateNodeHeartbeat updates the last heartbeat time for a node
 func (r *SQLRepository) UpdateNodeHeartbeat(name string) error {
 	query := `UPDATE nodes SET last_heartbeat = ? WHERE name = ?`
@@ -578,4 +689,58 @@ func (r *SQLRepository) ListPodAssignmentsByNode(nodeID string) ([]*PodAssignmen
 	}
 	
 	return assignments, nil
-}
\ No newline at end of file
+}
+
+// maxConflictRetries bounds how often RetryOnConflict repeats an update
+const maxConflictRetries = 5
+
+// RetryOnConflict runs update until it succeeds or fails with an error other
+// than ErrConflict. update must re-read the resource it changes on each attempt.
+func RetryOnConflict(update func() error) error {
+	var err error
+	for i := 0; i < maxConflictRetries; i++ {
+		if err = update(); !errors.Is(err, ErrConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// FormatResourceVersion formats a resource version for ObjectMeta
+func FormatResourceVersion(version int64) string {
+	if version == 0 {
+		return ""
+	}
+	return strconv.FormatInt(version, 10)
+}
+
+// withResourceVersion sets the resourceVersion in a metadata JSON blob, so that
+// objects decoded from it carry the version they were stored with
+func withResourceVersion(metadata string, version int64) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(metadata), &fields); err != nil || fields == nil {
+		return metadata
+	}
+
+	value, _ := json.Marshal(FormatResourceVersion(version))
+	fields["resourceVersion"] = value
+
+	updated, err := json.Marshal(fields)
+	if err != nil {
+		return metadata
+	}
+	return string(updated)
+}
+
+// ParseResourceVersion parses the resource version of ObjectMeta. An empty
+// version parses as zero, which makes updates unconditional.
+func ParseResourceVersion(version string) (int64, error) {
+	if version == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseInt(version, 10, 64)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid resource version: %q", version)
+	}
+	return parsed, nil
+}
@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"mini-k8s-orchestration/pkg/types"
@@ -303,4 +305,145 @@ func TestResourceNotFound(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error when deleting non-existent resource")
 	}
-}
\ No newline at end of file
+}
+
+func TestUpdateResourceConflict(t *testing.T) {
+	repo := setupTestRepository(t)
+	
+	resource := Resource{
+		ID:        "test-pod-123",
+		Kind:      "Pod",
+		Namespace: "default",
+		Name:      "test-pod",
+		Metadata:  `{"name":"test-pod","namespace":"default"}`,
+		Spec:      "{}",
+		Status:    `{"phase":"Pending"}`,
+	}
+	if err := repo.CreateResource(resource); err != nil {
+		t.Fatalf("Failed to create resource: %v", err)
+	}
+	
+	read, err := repo.GetResource("Pod", "default", "test-pod")
+	if err != nil {
+		t.Fatalf("Failed to get resource: %v", err)
+	}
+	if read.ResourceVersion == 0 {
+		t.Fatal("Expected a resource version to be assigned")
+	}
+	
+	// The version is also visible in the stored metadata
+	var metadata types.ObjectMeta
+	if err := json.Unmarshal([]byte(read.Metadata), &metadata); err != nil {
+		t.Fatalf("Failed to unmarshal metadata: %v", err)
+	}
+	if metadata.ResourceVersion != FormatResourceVersion(read.ResourceVersion) {
+		t.Errorf("Expected metadata resourceVersion %d, got %q", read.ResourceVersion, metadata.ResourceVersion)
+	}
+	
+	// An update based on the version that was read succeeds
+	first := read
+	first.Status = `{"phase":"Running"}`
+	if err := repo.UpdateResource(first); err != nil {
+		t.Fatalf("Failed to update resource: %v", err)
+	}
+	
+	updated, err := repo.GetResource("Pod", "default", "test-pod")
+	if err != nil {
+		t.Fatalf("Failed to get resource: %v", err)
+	}
+	if updated.ResourceVersion <= read.ResourceVersion {
+		t.Errorf("Expected resource version to increase from %d, got %d", read.ResourceVersion, updated.ResourceVersion)
+	}
+	
+	// A second update based on the same, now stale, version conflicts
+	second := read
+	second.Status = `{"phase":"Failed"}`
+	if err := repo.UpdateResource(second); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+	
+	current, _ := repo.GetResource("Pod", "default", "test-pod")
+	if current.Status != `{"phase":"Running"}` {
+		t.Errorf("Expected conflicting update to be rejected, got status %s", current.Status)
+	}
+	
+	// Without a version the update is unconditional
+	second.ResourceVersion = 0
+	if err := repo.UpdateResource(second); err != nil {
+		t.Errorf("Expected unconditional update to succeed, got %v", err)
+	}
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	attempts := 0
+	err := RetryOnConflict(func() error {
+		attempts++
+		if attempts < 3 {
+			return ErrConflict
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("Expected success after 3 attempts, got %v after %d", err, attempts)
+	}
+	
+	attempts = 0
+	err = RetryOnConflict(func() error {
+		attempts++
+		return ErrConflict
+	})
+	if !errors.Is(err, ErrConflict) || attempts != maxConflictRetries {
+		t.Errorf("Expected ErrConflict after %d attempts, got %v after %d", maxConflictRetries, err, attempts)
+	}
+}
+
+func TestNodeMetadataAndConflicts(t *testing.T) {
+	repo := setupTestRepository(t)
+	
+	node := &types.Node{
+		Metadata: types.ObjectMeta{
+			Name:   "test-node",
+			UID:    "node-123",
+			Labels: map[string]string{"zone": "a"},
+		},
+		Spec: types.NodeSpec{Unschedulable: true},
+	}
+	if err := repo.CreateNode(node); err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	
+	// Labels and spec are stored with the node
+	retrieved, err := repo.GetNode("test-node")
+	if err != nil {
+		t.Fatalf("Failed to get node: %v", err)
+	}
+	if retrieved.Metadata.Labels["zone"] != "a" || !retrieved.Spec.Unschedulable {
+		t.Errorf("Expected labels and spec to be stored, got %+v %+v", retrieved.Metadata, retrieved.Spec)
+	}
+	if retrieved.Metadata.ResourceVersion == "" {
+		t.Error("Expected the node to have a resourceVersion")
+	}
+	
+	// An update based on the version that was read succeeds
+	retrieved.Metadata.Labels["zone"] = "b"
+	if err := repo.UpdateNode(retrieved); err != nil {
+		t.Fatalf("Failed to update node: %v", err)
+	}
+	
+	// Another update based on the same version is rejected
+	stale := *retrieved
+	stale.Spec.Unschedulable = false
+	if err := repo.UpdateNode(&stale); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+	
+	// Updates without a resourceVersion are applied unconditionally
+	stale.Metadata.ResourceVersion = ""
+	if err := repo.UpdateNode(&stale); err != nil {
+		t.Errorf("Failed to update node: %v", err)
+	}
+	
+	if err := repo.UpdateNode(&types.Node{Metadata: types.ObjectMeta{Name: "missing", ResourceVersion: "1"}}); err == nil || errors.Is(err, ErrConflict) {
+		t.Errorf("Expected a not found error for a missing node, got %v", err)
+	}
+}
@@ -9,6 +9,7 @@ CREATE TABLE IF NOT EXISTS resources (
     status TEXT,                -- JSON blob
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    resource_version INTEGER NOT NULL DEFAULT 0, -- revision of the last write
     UNIQUE(kind, namespace, name)
 );
 
@@ -17,9 +18,12 @@ CREATE TABLE IF NOT EXISTS nodes (
     id TEXT PRIMARY KEY,
     name TEXT UNIQUE NOT NULL,
     address TEXT NOT NULL,
+    metadata TEXT NOT NULL DEFAULT '{}', -- JSON blob containing full metadata
+    spec TEXT NOT NULL DEFAULT '{}',     -- JSON blob
     status TEXT NOT NULL,  -- JSON blob
     last_heartbeat DATETIME,
-    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    resource_version INTEGER NOT NULL DEFAULT 0 -- revision of the last write
 );
 
 -- Pod assignments for tracking which pods run on which nodes
@@ -31,9 +35,22 @@ CREATE TABLE IF NOT EXISTS pod_assignments (
     FOREIGN KEY (node_id) REFERENCES nodes(id)
 );
 
+-- Change history backing the watch API; every write to resources and nodes
+-- appends an event, and its revision orders all changes in the cluster
+CREATE TABLE IF NOT EXISTS watch_events (
+    revision INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,          -- ADDED, MODIFIED or DELETED
+    kind TEXT NOT NULL,
+    namespace TEXT DEFAULT '',
+    name TEXT NOT NULL,
+    object TEXT NOT NULL,        -- JSON blob of the resource after the change
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Create indexes for better query performance
 CREATE INDEX IF NOT EXISTS idx_resources_kind ON resources(kind);
 CREATE INDEX IF NOT EXISTS idx_resources_namespace ON resources(namespace);
 CREATE INDEX IF NOT EXISTS idx_resources_name ON resources(name);
 CREATE INDEX IF NOT EXISTS idx_nodes_name ON nodes(name);
-CREATE INDEX IF NOT EXISTS idx_pod_assignments_node ON pod_assignments(node_id);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_pod_assignments_node ON pod_assignments(node_id);
+CREATE INDEX IF NOT EXISTS idx_watch_events_kind ON watch_events(kind, revision);
\ No newline at end of file
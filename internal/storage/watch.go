@@ -0,0 +1,342 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"mini-k8s-orchestration/pkg/types"
+)
+
+// Watch event types
+const (
+	EventAdded    = types.WatchEventAdded
+	EventModified = types.WatchEventModified
+	EventDeleted  = types.WatchEventDeleted
+)
+
+const (
+	// eventHistoryLimit is the number of most recent events kept for watchers to resume from
+	eventHistoryLimit = 10000
+	// compactionInterval is how many revisions pass between removals of old events
+	compactionInterval = 1000
+	// watchPollInterval bounds how long a watcher takes to notice changes made by other processes
+	watchPollInterval = 250 * time.Millisecond
+	// watchBatchSize is the number of events read from the database at a time
+	watchBatchSize = 100
+)
+
+// ErrRevisionCompacted is returned when a watch starts from a revision whose events are no longer kept
+var ErrRevisionCompacted = errors.New("requested revision has been compacted")
+
+// WatchEvent is a single change to a resource. Revision is the position of the
+// change in the repository's history and can be used to resume a watch.
+type WatchEvent struct {
+	Type     string   `json:"type"`
+	Revision int64    `json:"revision"`
+	Resource Resource `json:"resource"`
+}
+
+// withEvent runs a write in a transaction and appends the event it returns to
+// the watch history, so that every committed change has exactly one revision.
+// The write is given the revision it will be recorded at.
+func (r *SQLRepository) withEvent(write func(tx *sql.Tx, revision int64) (string, Resource, error)) error {
+	tx, err := r.db.BeginTx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Allocate the revision first; the event is filled in once the write is done
+	result, err := tx.Exec(`
+		INSERT INTO watch_events (type, kind, namespace, name, object)
+		VALUES ('', '', '', '', '')
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to record watch event: %w", err)
+	}
+
+	revision, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	eventType, resource, err := write(tx, revision)
+	if err != nil {
+		return err
+	}
+	resource.ResourceVersion = revision
+
+	object, err := json.Marshal(resource)
+	if err != nil {
+		return fmt.Errorf("failed to marshal watch event: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE watch_events
+		SET type = ?, kind = ?, namespace = ?, name = ?, object = ?
+		WHERE revision = ?
+	`, eventType, resource.Kind, resource.Namespace, resource.Name, string(object), revision)
+	if err != nil {
+		return fmt.Errorf("failed to record watch event: %w", err)
+	}
+
+	// Drop events that have fallen out of the watch window
+	if revision%compactionInterval == 0 {
+		if _, err := tx.Exec(`DELETE FROM watch_events WHERE revision <= ?`, revision-eventHistoryLimit); err != nil {
+			return fmt.Errorf("failed to compact watch events: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.db.notifyChanged()
+	return nil
+}
+
+// CurrentRevision returns the revision of the most recent change
+func (r *SQLRepository) CurrentRevision() (int64, error) {
+	var revision int64
+	if err := r.db.DB().QueryRow(`SELECT COALESCE(MAX(revision), 0) FROM watch_events`).Scan(&revision); err != nil {
+		return 0, fmt.Errorf("failed to get current revision: %w", err)
+	}
+	return revision, nil
+}
+
+// Watch streams the changes to resources of the given kind made after revision.
+// An empty namespace watches all namespaces. The channel is closed when ctx is
+// done or the watch fails; callers resume by watching from the last revision they saw.
+func (r *SQLRepository) Watch(ctx context.Context, kind, namespace string, revision int64) (<-chan WatchEvent, error) {
+	if err := r.checkRevision(revision); err != nil {
+		return nil, err
+	}
+
+	events := make(chan WatchEvent)
+	go func() {
+		defer close(events)
+
+		// Changes made by this process wake the watcher immediately; the
+		// ticker picks up changes made by other processes sharing the database
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+
+		for {
+			changed := r.db.changes()
+
+			if err := r.checkRevision(revision); err != nil {
+				log.Printf("Watch on %s ended: %v", kind, err)
+				return
+			}
+
+			// Read the current revision first: changes commit in revision
+			// order, so every event up to it is already visible
+			current, err := r.CurrentRevision()
+			if err != nil {
+				log.Printf("Watch on %s failed: %v", kind, err)
+				return
+			}
+			batch, err := r.eventsSince(kind, namespace, revision, current)
+			if err != nil {
+				log.Printf("Watch on %s failed: %v", kind, err)
+				return
+			}
+
+			for _, event := range batch {
+				select {
+				case events <- event:
+					revision = event.Revision
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if len(batch) == watchBatchSize {
+				continue
+			}
+
+			// Nothing else up to the current revision concerns this watch, so
+			// move past it; otherwise an idle watch falls behind the history
+			// and is cut off once its revision is compacted
+			if current > revision {
+				revision = current
+			}
+
+			select {
+			case <-changed:
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// checkRevision returns ErrRevisionCompacted if events after revision have been removed
+func (r *SQLRepository) checkRevision(revision int64) error {
+	var oldest int64
+	if err := r.db.DB().QueryRow(`SELECT COALESCE(MIN(revision), 0) FROM watch_events`).Scan(&oldest); err != nil {
+		return fmt.Errorf("failed to get oldest revision: %w", err)
+	}
+	if oldest > 0 && revision < oldest-1 {
+		return ErrRevisionCompacted
+	}
+	return nil
+}
+
+// eventsSince reads the next batch of events after revision, up to and
+// including until
+func (r *SQLRepository) eventsSince(kind, namespace string, revision, until int64) ([]WatchEvent, error) {
+	query := `
+		SELECT revision, type, object
+		FROM watch_events
+		WHERE revision > ? AND revision <= ? AND kind = ?
+		ORDER BY revision
+		LIMIT ?
+	`
+	args := []interface{}{revision, until, kind, watchBatchSize}
+	if namespace != "" {
+		query = `
+			SELECT revision, type, object
+			FROM watch_events
+			WHERE revision > ? AND revision <= ? AND kind = ? AND namespace = ?
+			ORDER BY revision
+			LIMIT ?
+		`
+		args = []interface{}{revision, until, kind, namespace, watchBatchSize}
+	}
+
+	rows, err := r.db.DB().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watch events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []WatchEvent
+	for rows.Next() {
+		var event WatchEvent
+		var object string
+		if err := rows.Scan(&event.Revision, &event.Type, &object); err != nil {
+			return nil, fmt.Errorf("failed to scan watch event: %w", err)
+		}
+		if err := json.Unmarshal([]byte(object), &event.Resource); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal watch event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// nodeResource represents a node as a generic resource for watch events
+func nodeResource(node *types.Node) (Resource, error) {
+	metadataJSON, err := json.Marshal(node.Metadata)
+	if err != nil {
+		return Resource{}, fmt.Errorf("failed to marshal node metadata: %w", err)
+	}
+	specJSON, err := json.Marshal(node.Spec)
+	if err != nil {
+		return Resource{}, fmt.Errorf("failed to marshal node spec: %w", err)
+	}
+	statusJSON, err := json.Marshal(node.Status)
+	if err != nil {
+		return Resource{}, fmt.Errorf("failed to marshal node status: %w", err)
+	}
+
+	return Resource{
+		ID:        node.Metadata.UID,
+		Kind:      "Node",
+		Name:      node.Metadata.Name,
+		Metadata:  string(metadataJSON),
+		Spec:      string(specJSON),
+		Status:    string(statusJSON),
+		CreatedAt: node.Metadata.CreatedAt,
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// getNodeResource reads a node row as a generic resource for watch events
+func getNodeResource(q queryer, name string) (Resource, error) {
+	node, err := getNode(q, name)
+	if err != nil {
+		return Resource{}, err
+	}
+	return nodeResource(node)
+}
+
+// NotifyChanges watches the given kinds and signals on the returned channel
+// whenever one of them changes, until ctx is done. Signals are coalesced, so
+// receivers should re-read whatever state they need. Watches are resumed
+// after errors, and a compacted history is reported as a change.
+func NotifyChanges(ctx context.Context, repo Repository, kinds ...string) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	signal := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+
+	wait := func() bool {
+		select {
+		case <-time.After(time.Second):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for _, kind := range kinds {
+		go func(kind string) {
+			revision, err := repo.CurrentRevision()
+			for err != nil {
+				log.Printf("Failed to get current revision: %v", err)
+				if !wait() {
+					return
+				}
+				revision, err = repo.CurrentRevision()
+			}
+
+			for ctx.Err() == nil {
+				events, err := repo.Watch(ctx, kind, "", revision)
+				if errors.Is(err, ErrRevisionCompacted) {
+					// Missed events can't be replayed, so start over from now
+					if current, err := repo.CurrentRevision(); err == nil {
+						revision = current
+						signal()
+						continue
+					}
+				}
+				if err != nil {
+					log.Printf("Failed to watch %s: %v", kind, err)
+					if !wait() {
+						return
+					}
+					continue
+				}
+
+				for event := range events {
+					revision = event.Revision
+					signal()
+				}
+
+				if !wait() {
+					return
+				}
+			}
+		}(kind)
+	}
+
+	return changes
+}
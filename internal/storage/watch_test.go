@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func receiveEvent(t *testing.T, events <-chan WatchEvent) WatchEvent {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("Watch channel closed unexpectedly")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for watch event")
+	}
+	return WatchEvent{}
+}
+
+func TestWatch(t *testing.T) {
+	repo := setupTestRepository(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start, err := repo.CurrentRevision()
+	if err != nil {
+		t.Fatalf("Failed to get current revision: %v", err)
+	}
+
+	events, err := repo.Watch(ctx, "Pod", "default", start)
+	if err != nil {
+		t.Fatalf("Failed to watch pods: %v", err)
+	}
+
+	pod := Resource{ID: "pod-1", Kind: "Pod", Namespace: "default", Name: "web", Spec: "{}", Status: `{"phase":"Pending"}`}
+	if err := repo.CreateResource(pod); err != nil {
+		t.Fatalf("Failed to create pod: %v", err)
+	}
+
+	// Changes to other kinds and namespaces are filtered out
+	service := Resource{ID: "svc-1", Kind: "Service", Namespace: "default", Name: "web", Spec: "{}"}
+	if err := repo.CreateResource(service); err != nil {
+		t.Fatalf("Failed to create service: %v", err)
+	}
+	other := Resource{ID: "pod-2", Kind: "Pod", Namespace: "other", Name: "web", Spec: "{}"}
+	if err := repo.CreateResource(other); err != nil {
+		t.Fatalf("Failed to create pod: %v", err)
+	}
+
+	pod.Status = `{"phase":"Running"}`
+	if err := repo.UpdateResource(pod); err != nil {
+		t.Fatalf("Failed to update pod: %v", err)
+	}
+	if err := repo.DeleteResource("Pod", "default", "web"); err != nil {
+		t.Fatalf("Failed to delete pod: %v", err)
+	}
+
+	expected := []struct {
+		eventType string
+		status    string
+	}{
+		{EventAdded, `{"phase":"Pending"}`},
+		{EventModified, `{"phase":"Running"}`},
+		{EventDeleted, `{"phase":"Running"}`},
+	}
+
+	var revisions []int64
+	for _, want := range expected {
+		event := receiveEvent(t, events)
+		if event.Type != want.eventType {
+			t.Errorf("Expected %s event, got %s", want.eventType, event.Type)
+		}
+		if event.Resource.Namespace != "default" || event.Resource.Name != "web" {
+			t.Errorf("Unexpected resource %s/%s", event.Resource.Namespace, event.Resource.Name)
+		}
+		if event.Resource.Status != want.status {
+			t.Errorf("Expected status %s, got %s", want.status, event.Resource.Status)
+		}
+		revisions = append(revisions, event.Revision)
+	}
+
+	// Revisions count every change, including the filtered ones
+	if revisions[0] != start+1 || revisions[1] != start+4 || revisions[2] != start+5 {
+		t.Errorf("Unexpected revisions %v starting from %d", revisions, start)
+	}
+
+	current, err := repo.CurrentRevision()
+	if err != nil {
+		t.Fatalf("Failed to get current revision: %v", err)
+	}
+	if current != start+5 {
+		t.Errorf("Expected current revision %d, got %d", start+5, current)
+	}
+
+	// The watch closes when its context is done
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected no more events")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Watch channel was not closed")
+	}
+}
+
+func TestWatchResume(t *testing.T) {
+	repo := setupTestRepository(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, name := range []string{"a", "b", "c"} {
+		resource := Resource{ID: "id-" + name, Kind: "Pod", Namespace: "default", Name: name, Spec: "{}"}
+		if err := repo.CreateResource(resource); err != nil {
+			t.Fatalf("Failed to create pod: %v", err)
+		}
+	}
+
+	// Watch all pods from the beginning to find the revision of the first one
+	events, err := repo.Watch(ctx, "Pod", "", 0)
+	if err != nil {
+		t.Fatalf("Failed to watch pods: %v", err)
+	}
+	first := receiveEvent(t, events)
+	if first.Resource.Name != "a" {
+		t.Fatalf("Expected first event for pod a, got %s", first.Resource.Name)
+	}
+
+	// Resuming after it replays only the later changes
+	resumed, err := repo.Watch(ctx, "Pod", "", first.Revision)
+	if err != nil {
+		t.Fatalf("Failed to resume watch: %v", err)
+	}
+	for _, name := range []string{"b", "c"} {
+		event := receiveEvent(t, resumed)
+		if event.Type != EventAdded || event.Resource.Name != name {
+			t.Errorf("Expected ADDED event for pod %s, got %s for %s", name, event.Type, event.Resource.Name)
+		}
+	}
+}
+
+func TestWatchCompacted(t *testing.T) {
+	repo := setupTestRepository(t)
+
+	for _, name := range []string{"a", "b", "c"} {
+		resource := Resource{ID: "id-" + name, Kind: "Pod", Namespace: "default", Name: name, Spec: "{}"}
+		if err := repo.CreateResource(resource); err != nil {
+			t.Fatalf("Failed to create pod: %v", err)
+		}
+	}
+
+	current, err := repo.CurrentRevision()
+	if err != nil {
+		t.Fatalf("Failed to get current revision: %v", err)
+	}
+
+	// Drop all but the latest event, as compaction would
+	db := repo.(*SQLRepository).db
+	if _, err := db.DB().Exec(`DELETE FROM watch_events WHERE revision < ?`, current); err != nil {
+		t.Fatalf("Failed to compact events: %v", err)
+	}
+
+	if _, err := repo.Watch(context.Background(), "Pod", "", current-2); !errors.Is(err, ErrRevisionCompacted) {
+		t.Errorf("Expected ErrRevisionCompacted, got %v", err)
+	}
+
+	// Watching from just before the oldest kept event still works
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := repo.Watch(ctx, "Pod", "", current-1)
+	if err != nil {
+		t.Fatalf("Failed to watch pods: %v", err)
+	}
+	if event := receiveEvent(t, events); event.Resource.Name != "c" {
+		t.Errorf("Expected event for pod c, got %s", event.Resource.Name)
+	}
+}
+
+func TestWatchIdleKeepsUp(t *testing.T) {
+	repo := setupTestRepository(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start, err := repo.CurrentRevision()
+	if err != nil {
+		t.Fatalf("Failed to get current revision: %v", err)
+	}
+	events, err := repo.Watch(ctx, "Pod", "", start)
+	if err != nil {
+		t.Fatalf("Failed to watch pods: %v", err)
+	}
+
+	// Only other kinds change while the pod watch is idle
+	for _, name := range []string{"a", "b", "c"} {
+		resource := Resource{ID: "svc-" + name, Kind: "Service", Namespace: "default", Name: name, Spec: "{}"}
+		if err := repo.CreateResource(resource); err != nil {
+			t.Fatalf("Failed to create service: %v", err)
+		}
+	}
+	time.Sleep(2 * watchPollInterval)
+
+	// Compacting those changes doesn't cut the idle watch off
+	current, err := repo.CurrentRevision()
+	if err != nil {
+		t.Fatalf("Failed to get current revision: %v", err)
+	}
+	db := repo.(*SQLRepository).db
+	if _, err := db.DB().Exec(`DELETE FROM watch_events WHERE revision < ?`, current); err != nil {
+		t.Fatalf("Failed to compact events: %v", err)
+	}
+
+	pod := Resource{ID: "pod-1", Kind: "Pod", Namespace: "default", Name: "web", Spec: "{}"}
+	if err := repo.CreateResource(pod); err != nil {
+		t.Fatalf("Failed to create pod: %v", err)
+	}
+	if event := receiveEvent(t, events); event.Type != EventAdded || event.Resource.Name != "web" {
+		t.Errorf("Expected ADDED event for pod web, got %s for %s", event.Type, event.Resource.Name)
+	}
+}
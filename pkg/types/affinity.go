@@ -0,0 +1,51 @@
+package types
+
+// Topology spread actions for constraints that can't be satisfied
+const (
+	DoNotSchedule  = "DoNotSchedule"
+	ScheduleAnyway = "ScheduleAnyway"
+)
+
+// Matches reports whether a node with the given labels satisfies any of the
+// selector's terms
+func (s *NodeSelector) Matches(labels map[string]string) bool {
+	for i := range s.NodeSelectorTerms {
+		if s.NodeSelectorTerms[i].Matches(labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether a node with the given labels satisfies every
+// requirement of the term. A term without requirements matches no node.
+func (t *NodeSelectorTerm) Matches(labels map[string]string) bool {
+	if len(t.MatchExpressions) == 0 {
+		return false
+	}
+	for i := range t.MatchExpressions {
+		if !t.MatchExpressions[i].Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether a pod is selected by the term. namespace is the
+// namespace of the pod that owns the term, used when the term names no
+// namespaces. A term without a label selector selects no pods.
+func (t *PodAffinityTerm) Matches(pod *Pod, namespace string) bool {
+	if t.LabelSelector == nil {
+		return false
+	}
+
+	if len(t.Namespaces) == 0 {
+		if pod.Metadata.Namespace != namespace {
+			return false
+		}
+	} else if !contains(t.Namespaces, pod.Metadata.Namespace) {
+		return false
+	}
+
+	return t.LabelSelector.Matches(pod.Metadata.Labels)
+}
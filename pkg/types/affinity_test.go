@@ -0,0 +1,196 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNodeSelectorMatches(t *testing.T) {
+	selector := NodeSelector{NodeSelectorTerms: []NodeSelectorTerm{
+		{MatchExpressions: []LabelSelectorRequirement{
+			{Key: "zone", Operator: LabelSelectorOpIn, Values: []string{"a", "b"}},
+			{Key: "gpu", Operator: LabelSelectorOpDoesNotExist},
+		}},
+		{MatchExpressions: []LabelSelectorRequirement{
+			{Key: "dedicated", Operator: LabelSelectorOpExists},
+		}},
+	}}
+
+	tests := []struct {
+		labels map[string]string
+		want   bool
+	}{
+		{map[string]string{"zone": "a"}, true},
+		{map[string]string{"zone": "a", "gpu": "true"}, false},
+		{map[string]string{"zone": "c"}, false},
+		{map[string]string{"zone": "c", "dedicated": "ml"}, true},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := selector.Matches(tt.labels); got != tt.want {
+			t.Errorf("Matches(%v) = %v, want %v", tt.labels, got, tt.want)
+		}
+	}
+
+	// Terms without requirements match nothing
+	empty := NodeSelectorTerm{}
+	if empty.Matches(map[string]string{"zone": "a"}) {
+		t.Error("Expected an empty term to match no node")
+	}
+}
+
+func TestPodAffinityTermMatches(t *testing.T) {
+	pod := &Pod{Metadata: ObjectMeta{Name: "cache", Namespace: "team-a", Labels: map[string]string{"app": "cache"}}}
+	selector := &LabelSelector{MatchLabels: map[string]string{"app": "cache"}}
+
+	tests := []struct {
+		name      string
+		term      PodAffinityTerm
+		namespace string
+		want      bool
+	}{
+		{"own namespace", PodAffinityTerm{LabelSelector: selector, TopologyKey: "zone"}, "team-a", true},
+		{"other namespace", PodAffinityTerm{LabelSelector: selector, TopologyKey: "zone"}, "team-b", false},
+		{"listed namespace", PodAffinityTerm{LabelSelector: selector, Namespaces: []string{"team-a"}, TopologyKey: "zone"}, "team-b", true},
+		{"labels differ", PodAffinityTerm{LabelSelector: &LabelSelector{MatchLabels: map[string]string{"app": "web"}}, TopologyKey: "zone"}, "team-a", false},
+		{"no selector", PodAffinityTerm{TopologyKey: "zone"}, "team-a", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.term.Matches(pod, tt.namespace); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAffinity(t *testing.T) {
+	selector := &LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+
+	tests := []struct {
+		name     string
+		affinity Affinity
+		errMsg   string
+	}{
+		{
+			name: "valid affinity",
+			affinity: Affinity{
+				NodeAffinity: &NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &NodeSelector{NodeSelectorTerms: []NodeSelectorTerm{
+						{MatchExpressions: []LabelSelectorRequirement{{Key: "zone", Operator: LabelSelectorOpIn, Values: []string{"a"}}}},
+					}},
+					PreferredDuringSchedulingIgnoredDuringExecution: []PreferredSchedulingTerm{
+						{Weight: 10, Preference: NodeSelectorTerm{MatchExpressions: []LabelSelectorRequirement{{Key: "ssd", Operator: LabelSelectorOpExists}}}},
+					},
+				},
+				PodAntiAffinity: &PodAntiAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []PodAffinityTerm{{LabelSelector: selector, TopologyKey: "hostname"}},
+				},
+			},
+		},
+		{
+			name: "empty required node affinity",
+			affinity: Affinity{NodeAffinity: &NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &NodeSelector{},
+			}},
+			errMsg: "at least one node selector term is required",
+		},
+		{
+			name: "invalid operator",
+			affinity: Affinity{NodeAffinity: &NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &NodeSelector{NodeSelectorTerms: []NodeSelectorTerm{
+					{MatchExpressions: []LabelSelectorRequirement{{Key: "zone", Operator: "Gt", Values: []string{"1"}}}},
+				}},
+			}},
+			errMsg: "must be one of: In, NotIn, Exists, DoesNotExist",
+		},
+		{
+			name: "weight out of range",
+			affinity: Affinity{PodAffinity: &PodAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []WeightedPodAffinityTerm{
+					{Weight: 101, PodAffinityTerm: PodAffinityTerm{LabelSelector: selector, TopologyKey: "zone"}},
+				},
+			}},
+			errMsg: "must be between 1 and 100",
+		},
+		{
+			name: "missing topology key",
+			affinity: Affinity{PodAffinity: &PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []PodAffinityTerm{{LabelSelector: selector}},
+			}},
+			errMsg: "topologyKey is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &Pod{
+				Metadata: ObjectMeta{Name: "web"},
+				Spec: PodSpec{
+					Containers: []Container{{Name: "nginx", Image: "nginx:latest"}},
+					Affinity:   &tt.affinity,
+				},
+			}
+			err := ValidatePod(pod)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("ValidatePod() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("ValidatePod() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidateTopologySpreadConstraints(t *testing.T) {
+	selector := &LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+
+	tests := []struct {
+		name        string
+		constraints []TopologySpreadConstraint
+		errMsg      string
+	}{
+		{"valid constraints", []TopologySpreadConstraint{
+			{MaxSkew: 1, TopologyKey: "zone", WhenUnsatisfiable: DoNotSchedule, LabelSelector: selector},
+			{MaxSkew: 2, TopologyKey: "hostname", WhenUnsatisfiable: ScheduleAnyway, LabelSelector: selector},
+		}, ""},
+		{"zero max skew", []TopologySpreadConstraint{
+			{TopologyKey: "zone", WhenUnsatisfiable: DoNotSchedule, LabelSelector: selector},
+		}, "must be at least 1"},
+		{"unknown action", []TopologySpreadConstraint{
+			{MaxSkew: 1, TopologyKey: "zone", WhenUnsatisfiable: "Never", LabelSelector: selector},
+		}, "must be one of: DoNotSchedule, ScheduleAnyway"},
+		{"missing selector", []TopologySpreadConstraint{
+			{MaxSkew: 1, TopologyKey: "zone", WhenUnsatisfiable: DoNotSchedule},
+		}, "labelSelector is required"},
+		{"duplicate constraint", []TopologySpreadConstraint{
+			{MaxSkew: 1, TopologyKey: "zone", WhenUnsatisfiable: DoNotSchedule, LabelSelector: selector},
+			{MaxSkew: 2, TopologyKey: "zone", WhenUnsatisfiable: DoNotSchedule, LabelSelector: selector},
+		}, "duplicates the constraint on zone with DoNotSchedule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &Pod{
+				Metadata: ObjectMeta{Name: "web"},
+				Spec: PodSpec{
+					Containers:                []Container{{Name: "nginx", Image: "nginx:latest"}},
+					TopologySpreadConstraints: tt.constraints,
+				},
+			}
+			err := ValidatePod(pod)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("ValidatePod() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("ValidatePod() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
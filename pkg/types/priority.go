@@ -0,0 +1,28 @@
+package types
+
+// HighestUserDefinablePriority is the highest value a PriorityClass may have
+const HighestUserDefinablePriority = 1000000000
+
+// FindPriorityClass returns the class a pod naming priorityClassName gets its
+// priority from: the class of that name, or the global default class when
+// the name is empty. It returns nil when no class applies.
+func FindPriorityClass(classes []*PriorityClass, priorityClassName string) *PriorityClass {
+	for _, class := range classes {
+		if priorityClassName == "" && class.GlobalDefault {
+			return class
+		}
+		if priorityClassName != "" && class.Metadata.Name == priorityClassName {
+			return class
+		}
+	}
+	return nil
+}
+
+// PodPriority returns the priority of a pod. Pods whose priority hasn't been
+// resolved have priority 0.
+func PodPriority(pod *Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindPriorityClass(t *testing.T) {
+	classes := []*PriorityClass{
+		{Metadata: ObjectMeta{Name: "high"}, Value: 1000},
+		{Metadata: ObjectMeta{Name: "standard"}, Value: 100, GlobalDefault: true},
+	}
+
+	if class := FindPriorityClass(classes, "high"); class == nil || class.Value != 1000 {
+		t.Errorf("Expected the high class, got %v", class)
+	}
+	if class := FindPriorityClass(classes, ""); class == nil || class.Metadata.Name != "standard" {
+		t.Errorf("Expected the global default class, got %v", class)
+	}
+	if class := FindPriorityClass(classes, "missing"); class != nil {
+		t.Errorf("Expected no class, got %v", class)
+	}
+	if class := FindPriorityClass(classes[:1], ""); class != nil {
+		t.Errorf("Expected no class without a global default, got %v", class)
+	}
+}
+
+func TestValidatePriorityClass(t *testing.T) {
+	tests := []struct {
+		name   string
+		class  PriorityClass
+		errMsg string
+	}{
+		{"valid class", PriorityClass{Metadata: ObjectMeta{Name: "high"}, Value: 1000}, ""},
+		{"negative value", PriorityClass{Metadata: ObjectMeta{Name: "low"}, Value: -10}, ""},
+		{"value too high", PriorityClass{Metadata: ObjectMeta{Name: "system"}, Value: HighestUserDefinablePriority + 1}, "must be at most 1000000000"},
+		{"namespaced", PriorityClass{Metadata: ObjectMeta{Name: "high", Namespace: "default"}}, "priority classes are not namespaced"},
+		{"missing name", PriorityClass{Value: 1}, "name is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePriorityClass(&tt.class)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("ValidatePriorityClass() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("ValidatePriorityClass() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
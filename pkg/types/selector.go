@@ -0,0 +1,290 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Label selector operators
+const (
+	LabelSelectorOpIn           = "In"
+	LabelSelectorOpNotIn        = "NotIn"
+	LabelSelectorOpExists       = "Exists"
+	LabelSelectorOpDoesNotExist = "DoesNotExist"
+)
+
+// Field selector operators
+const (
+	FieldSelectorOpEquals    = "="
+	FieldSelectorOpNotEquals = "!="
+)
+
+// Empty reports whether the selector has no requirements
+func (s *LabelSelector) Empty() bool {
+	return len(s.MatchLabels) == 0 && len(s.MatchExpressions) == 0
+}
+
+// Matches reports whether a set of labels satisfies every requirement of the
+// selector. An empty selector matches everything.
+func (s *LabelSelector) Matches(labels map[string]string) bool {
+	for key, value := range s.MatchLabels {
+		if actual, ok := labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	for _, requirement := range s.MatchExpressions {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches reports whether a set of labels satisfies the requirement
+func (r *LabelSelectorRequirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case LabelSelectorOpIn:
+		return ok && contains(r.Values, value)
+	case LabelSelectorOpNotIn:
+		return !ok || !contains(r.Values, value)
+	case LabelSelectorOpExists:
+		return ok
+	case LabelSelectorOpDoesNotExist:
+		return !ok
+	default:
+		return false
+	}
+}
+
+// setExpression matches `key in (a, b)` and `key notin (a, b)`
+var setExpression = regexp.MustCompile(`^([^\s!=(),]+)\s+(in|notin)\s+\(([^()]*)\)$`)
+
+// ParseLabelSelector parses a label selector query such as
+// "app=web,tier!=cache,env in (prod, staging),!canary". Terms are separated
+// by commas and all of them must match:
+//
+//	key=value, key==value  the label is set to value
+//	key!=value             the label is unset or set to another value
+//	key in (a, b)          the label is set to one of the values
+//	key notin (a, b)       the label is unset or set to none of the values
+//	key                    the label is set
+//	!key                   the label is unset
+func ParseLabelSelector(query string) (LabelSelector, error) {
+	var selector LabelSelector
+
+	for _, term := range splitSelector(query) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return LabelSelector{}, fmt.Errorf("empty term in label selector %q", query)
+		}
+
+		if match := setExpression.FindStringSubmatch(term); match != nil {
+			operator := LabelSelectorOpIn
+			if match[2] == "notin" {
+				operator = LabelSelectorOpNotIn
+			}
+			var values []string
+			for _, value := range strings.Split(match[3], ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					values = append(values, value)
+				}
+			}
+			if len(values) == 0 {
+				return LabelSelector{}, fmt.Errorf("no values in %q", term)
+			}
+			selector.MatchExpressions = append(selector.MatchExpressions, LabelSelectorRequirement{
+				Key:      match[1],
+				Operator: operator,
+				Values:   values,
+			})
+			continue
+		}
+
+		if key, ok := strings.CutPrefix(term, "!"); ok {
+			if !isSelectorKey(key) {
+				return LabelSelector{}, fmt.Errorf("invalid label key in %q", term)
+			}
+			selector.MatchExpressions = append(selector.MatchExpressions, LabelSelectorRequirement{
+				Key:      key,
+				Operator: LabelSelectorOpDoesNotExist,
+			})
+			continue
+		}
+
+		key, operator, value, ok := cutSelectorTerm(term)
+		if !ok {
+			if !isSelectorKey(term) {
+				return LabelSelector{}, fmt.Errorf("invalid label selector term %q", term)
+			}
+			selector.MatchExpressions = append(selector.MatchExpressions, LabelSelectorRequirement{
+				Key:      term,
+				Operator: LabelSelectorOpExists,
+			})
+			continue
+		}
+		if !isSelectorKey(key) {
+			return LabelSelector{}, fmt.Errorf("invalid label key in %q", term)
+		}
+
+		_, duplicate := selector.MatchLabels[key]
+		switch {
+		case operator == FieldSelectorOpNotEquals:
+			selector.MatchExpressions = append(selector.MatchExpressions, LabelSelectorRequirement{
+				Key:      key,
+				Operator: LabelSelectorOpNotIn,
+				Values:   []string{value},
+			})
+		case duplicate:
+			// A second equality on the same key still has to hold
+			selector.MatchExpressions = append(selector.MatchExpressions, LabelSelectorRequirement{
+				Key:      key,
+				Operator: LabelSelectorOpIn,
+				Values:   []string{value},
+			})
+		default:
+			if selector.MatchLabels == nil {
+				selector.MatchLabels = make(map[string]string)
+			}
+			selector.MatchLabels[key] = value
+		}
+	}
+
+	return selector, nil
+}
+
+// FieldSelector restricts a list to the objects whose fields have, or don't
+// have, given values
+type FieldSelector struct {
+	Requirements []FieldSelectorRequirement
+}
+
+// FieldSelectorRequirement compares the value of a single field
+type FieldSelectorRequirement struct {
+	// Field is the dotted JSON path of the field, e.g. spec.nodeName
+	Field    string
+	Operator string
+	Value    string
+}
+
+// ParseFieldSelector parses a field selector query such as
+// "spec.nodeName=node-1,status.phase!=Running". Terms are separated by commas
+// and all of them must match. Fields that are not set compare as "".
+func ParseFieldSelector(query string) (FieldSelector, error) {
+	var selector FieldSelector
+
+	for _, term := range splitSelector(query) {
+		term = strings.TrimSpace(term)
+		field, operator, value, ok := cutSelectorTerm(term)
+		if !ok || !isSelectorKey(field) {
+			return FieldSelector{}, fmt.Errorf("invalid field selector term %q", term)
+		}
+		selector.Requirements = append(selector.Requirements, FieldSelectorRequirement{
+			Field:    field,
+			Operator: operator,
+			Value:    value,
+		})
+	}
+
+	return selector, nil
+}
+
+// Empty reports whether the selector has no requirements
+func (s *FieldSelector) Empty() bool {
+	return len(s.Requirements) == 0
+}
+
+// Matches reports whether the fields of an object satisfy every requirement
+// of the selector. An empty selector matches everything.
+func (s *FieldSelector) Matches(object interface{}) bool {
+	if s.Empty() {
+		return true
+	}
+
+	data, err := json.Marshal(object)
+	if err != nil {
+		return false
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false
+	}
+
+	for _, requirement := range s.Requirements {
+		equal := fieldValue(fields, requirement.Field) == requirement.Value
+		if equal != (requirement.Operator == FieldSelectorOpEquals) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldValue returns the value at a dotted path in a decoded JSON object as a
+// string, or "" when the path doesn't lead to a scalar
+func fieldValue(fields map[string]interface{}, path string) string {
+	var value interface{} = fields
+	for _, part := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		value = object[part]
+	}
+
+	switch value := value.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return ""
+	case string:
+		return value
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// splitSelector splits a selector query on the commas that are not inside
+// parentheses. An empty query has no terms.
+func splitSelector(query string) []string {
+	if strings.TrimSpace(query) == "" {
+		return nil
+	}
+
+	var terms []string
+	depth, start := 0, 0
+	for i, r := range query {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, query[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, query[start:])
+}
+
+// cutSelectorTerm splits an equality term into its key, operator and value.
+// "==" is accepted as a synonym of "=".
+func cutSelectorTerm(term string) (string, string, string, bool) {
+	if key, value, ok := strings.Cut(term, "!="); ok {
+		return strings.TrimSpace(key), FieldSelectorOpNotEquals, strings.TrimSpace(value), true
+	}
+	if key, value, ok := strings.Cut(term, "=="); ok {
+		return strings.TrimSpace(key), FieldSelectorOpEquals, strings.TrimSpace(value), true
+	}
+	if key, value, ok := strings.Cut(term, "="); ok {
+		return strings.TrimSpace(key), FieldSelectorOpEquals, strings.TrimSpace(value), true
+	}
+	return "", "", "", false
+}
+
+// isSelectorKey checks that a label key or field path is non-empty and free of
+// whitespace and selector syntax
+func isSelectorKey(key string) bool {
+	return key != "" && !strings.ContainsAny(key, " \t!=(),")
+}
@@ -0,0 +1,89 @@
+package types
+
+import "testing"
+
+func TestParseLabelSelector(t *testing.T) {
+	labels := map[string]string{"app": "web", "env": "prod", "tier": "frontend"}
+
+	tests := []struct {
+		query   string
+		matches bool
+	}{
+		{"", true},
+		{"app=web", true},
+		{"app==web", true},
+		{"app=api", false},
+		{"app!=api", true},
+		{"app!=web", false},
+		{"missing!=web", true},
+		{"env in (prod, staging)", true},
+		{"env in (dev)", false},
+		{"env notin (dev,staging)", true},
+		{"env notin (prod)", false},
+		{"missing notin (prod)", true},
+		{"tier", true},
+		{"missing", false},
+		{"!missing", true},
+		{"!tier", false},
+		{"app=web, env in (prod, staging), !canary", true},
+		{"app=web,app=api", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			selector, err := ParseLabelSelector(tt.query)
+			if err != nil {
+				t.Fatalf("ParseLabelSelector() error = %v", err)
+			}
+			if got := selector.Matches(labels); got != tt.matches {
+				t.Errorf("Matches() = %v, want %v", got, tt.matches)
+			}
+		})
+	}
+}
+
+func TestParseLabelSelectorInvalid(t *testing.T) {
+	for _, query := range []string{"app=web,", "env in ()", "!", "=web", "app in prod", "a b"} {
+		if _, err := ParseLabelSelector(query); err == nil {
+			t.Errorf("ParseLabelSelector(%q) expected an error", query)
+		}
+	}
+}
+
+func TestFieldSelector(t *testing.T) {
+	pod := &Pod{
+		Metadata: ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:     PodSpec{NodeName: "node-1"},
+		Status:   PodStatus{Phase: "Running"},
+	}
+
+	tests := []struct {
+		query   string
+		matches bool
+	}{
+		{"", true},
+		{"spec.nodeName=node-1", true},
+		{"spec.nodeName==node-2", false},
+		{"status.phase!=Pending", true},
+		{"metadata.name=web,status.phase=Running", true},
+		{"metadata.name=web,status.phase=Failed", false},
+		{"status.podIP=", true},
+		{"spec.missing.field!=", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			selector, err := ParseFieldSelector(tt.query)
+			if err != nil {
+				t.Fatalf("ParseFieldSelector() error = %v", err)
+			}
+			if got := selector.Matches(pod); got != tt.matches {
+				t.Errorf("Matches() = %v, want %v", got, tt.matches)
+			}
+		})
+	}
+
+	if _, err := ParseFieldSelector("spec.nodeName"); err == nil {
+		t.Error("Expected an error for a field selector term without a value")
+	}
+}
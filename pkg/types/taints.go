@@ -0,0 +1,71 @@
+package types
+
+import "fmt"
+
+// Taint effects
+const (
+	// TaintEffectNoSchedule keeps new pods that don't tolerate the taint off
+	// the node
+	TaintEffectNoSchedule = "NoSchedule"
+	// TaintEffectPreferNoSchedule makes the scheduler avoid the node for pods
+	// that don't tolerate the taint, without ruling it out
+	TaintEffectPreferNoSchedule = "PreferNoSchedule"
+	// TaintEffectNoExecute also evicts running pods that don't tolerate the
+	// taint, once their tolerationSeconds have passed
+	TaintEffectNoExecute = "NoExecute"
+)
+
+// Toleration operators
+const (
+	TolerationOpEqual  = "Equal"
+	TolerationOpExists = "Exists"
+)
+
+// TaintNodeNotReady is the NoExecute taint the node monitor puts on nodes
+// that stop sending heartbeats
+const TaintNodeNotReady = "node.kubernetes.io/not-ready"
+
+// String formats the taint as key=value:effect
+func (t *Taint) String() string {
+	if t.Value == "" {
+		return fmt.Sprintf("%s:%s", t.Key, t.Effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)
+}
+
+// MatchTaint reports whether two taints have the same key and effect, which
+// identify a taint on a node
+func (t *Taint) MatchTaint(other *Taint) bool {
+	return t.Key == other.Key && t.Effect == other.Effect
+}
+
+// ToleratesTaint reports whether the toleration matches the taint. An empty
+// operator means Equal.
+func (t *Toleration) ToleratesTaint(taint *Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Key != "" && t.Key != taint.Key {
+		return false
+	}
+
+	switch t.Operator {
+	case TolerationOpExists:
+		return true
+	case TolerationOpEqual, "":
+		return t.Value == taint.Value
+	default:
+		return false
+	}
+}
+
+// TolerationsTolerateTaint reports whether any of the tolerations matches the
+// taint
+func TolerationsTolerateTaint(tolerations []Toleration, taint *Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
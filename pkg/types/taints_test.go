@@ -0,0 +1,66 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+func TestToleratesTaint(t *testing.T) {
+	taint := Taint{Key: "dedicated", Value: "gpu", Effect: TaintEffectNoSchedule}
+
+	tests := []struct {
+		name       string
+		toleration Toleration
+		want       bool
+	}{
+		{"equal key and value", Toleration{Key: "dedicated", Value: "gpu", Effect: TaintEffectNoSchedule}, true},
+		{"explicit Equal operator", Toleration{Key: "dedicated", Operator: TolerationOpEqual, Value: "gpu"}, true},
+		{"different value", Toleration{Key: "dedicated", Value: "cpu"}, false},
+		{"different key", Toleration{Key: "team", Value: "gpu"}, false},
+		{"different effect", Toleration{Key: "dedicated", Value: "gpu", Effect: TaintEffectNoExecute}, false},
+		{"exists on key", Toleration{Key: "dedicated", Operator: TolerationOpExists}, true},
+		{"exists on everything", Toleration{Operator: TolerationOpExists}, true},
+		{"unknown operator", Toleration{Key: "dedicated", Operator: "In", Value: "gpu"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.toleration.ToleratesTaint(&taint); got != tt.want {
+				t.Errorf("ToleratesTaint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNodeTaints(t *testing.T) {
+	tests := []struct {
+		name   string
+		taints []Taint
+		errMsg string
+	}{
+		{"valid taints", []Taint{{Key: "dedicated", Value: "gpu", Effect: TaintEffectNoSchedule}, {Key: "dedicated", Effect: TaintEffectNoExecute}}, ""},
+		{"missing key", []Taint{{Effect: TaintEffectNoSchedule}}, "key is required"},
+		{"unknown effect", []Taint{{Key: "dedicated", Effect: "NoRun"}}, "must be one of: NoSchedule, PreferNoSchedule, NoExecute"},
+		{"duplicate key and effect", []Taint{{Key: "dedicated", Value: "a", Effect: TaintEffectNoSchedule}, {Key: "dedicated", Value: "b", Effect: TaintEffectNoSchedule}}, "duplicates taint dedicated=a:NoSchedule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &Node{Metadata: ObjectMeta{Name: "node-a"}, Spec: NodeSpec{Taints: tt.taints}}
+			err := ValidateNode(node)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("ValidateNode() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("ValidateNode() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package types
+
+import "time"
+
+// DefaultTerminationGracePeriodSeconds is how long a pod's containers have
+// to shut down when its spec doesn't say
+const DefaultTerminationGracePeriodSeconds int64 = 30
+
+// PodPhaseTerminating is the phase of a pod whose deletion has been
+// requested while its containers shut down
+const PodPhaseTerminating = "Terminating"
+
+// IsPodTerminating reports whether a graceful deletion of the pod has been
+// requested
+func IsPodTerminating(pod *Pod) bool {
+	return pod.Metadata.DeletionTimestamp != nil
+}
+
+// PodTerminationGracePeriod returns how long the pod's containers have to
+// shut down: the grace period of its deletion if one was requested,
+// otherwise that of its spec
+func PodTerminationGracePeriod(pod *Pod) time.Duration {
+	seconds := DefaultTerminationGracePeriodSeconds
+	if pod.Metadata.DeletionGracePeriodSeconds != nil {
+		seconds = *pod.Metadata.DeletionGracePeriodSeconds
+	} else if pod.Spec.TerminationGracePeriodSeconds != nil {
+		seconds = *pod.Spec.TerminationGracePeriodSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// MarkPodTerminating requests a graceful deletion of the pod: it sets the
+// deletion timestamp and grace period and moves the pod to the Terminating
+// phase. gracePeriod overrides the grace period of the pod's spec. It reports
+// whether the pod changed: a pod that is already terminating only changes if
+// the grace period gets shorter.
+func MarkPodTerminating(pod *Pod, gracePeriod *int64) bool {
+	seconds := int64(PodTerminationGracePeriod(&Pod{Spec: pod.Spec}) / time.Second)
+	if gracePeriod != nil {
+		seconds = *gracePeriod
+	}
+
+	if pod.Metadata.DeletionTimestamp != nil {
+		if pod.Metadata.DeletionGracePeriodSeconds != nil && *pod.Metadata.DeletionGracePeriodSeconds <= seconds {
+			return false
+		}
+	} else {
+		now := time.Now()
+		pod.Metadata.DeletionTimestamp = &now
+	}
+	pod.Metadata.DeletionGracePeriodSeconds = &seconds
+	pod.Status.Phase = PodPhaseTerminating
+	return true
+}
@@ -0,0 +1,32 @@
+package types
+
+import "testing"
+
+func TestMarkPodTerminating(t *testing.T) {
+	grace := int64(10)
+	pod := &Pod{Spec: PodSpec{TerminationGracePeriodSeconds: &grace}, Status: PodStatus{Phase: "Running"}}
+
+	if !MarkPodTerminating(pod, nil) {
+		t.Fatal("Expected a running pod to change")
+	}
+	if !IsPodTerminating(pod) || pod.Status.Phase != PodPhaseTerminating {
+		t.Fatalf("Expected a terminating pod, got phase %s", pod.Status.Phase)
+	}
+	if *pod.Metadata.DeletionGracePeriodSeconds != 10 {
+		t.Errorf("Expected the spec's grace period, got %d", *pod.Metadata.DeletionGracePeriodSeconds)
+	}
+	requested := pod.Metadata.DeletionTimestamp
+
+	// A repeated deletion may only shorten the grace period
+	longer := int64(60)
+	if MarkPodTerminating(pod, &longer) {
+		t.Error("Expected a longer grace period to be ignored")
+	}
+	shorter := int64(0)
+	if !MarkPodTerminating(pod, &shorter) || *pod.Metadata.DeletionGracePeriodSeconds != 0 {
+		t.Errorf("Expected the grace period to shrink to 0, got %d", *pod.Metadata.DeletionGracePeriodSeconds)
+	}
+	if pod.Metadata.DeletionTimestamp != requested {
+		t.Error("Expected the deletion timestamp to be kept")
+	}
+}
@@ -1,22 +1,50 @@
 package types
 
 import (
+	"encoding/json"
 	"time"
 )
 
 // ObjectMeta contains metadata that all persisted resources must have
 type ObjectMeta struct {
-	Name      string            `json:"name"`
-	Namespace string            `json:"namespace,omitempty"`
-	Labels    map[string]string `json:"labels,omitempty"`
-	UID       string            `json:"uid,omitempty"`
-	CreatedAt time.Time         `json:"createdAt,omitempty"`
-	UpdatedAt time.Time         `json:"updatedAt,omitempty"`
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+	UID             string            `json:"uid,omitempty"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+	OwnerReferences []OwnerReference  `json:"ownerReferences,omitempty"`
+	CreatedAt       time.Time         `json:"createdAt,omitempty"`
+	UpdatedAt       time.Time         `json:"updatedAt,omitempty"`
+	// DeletionTimestamp is set when a graceful deletion is requested; the
+	// object is removed once DeletionGracePeriodSeconds have passed or its
+	// owner is done with it
+	DeletionTimestamp          *time.Time `json:"deletionTimestamp,omitempty"`
+	DeletionGracePeriodSeconds *int64     `json:"deletionGracePeriodSeconds,omitempty"`
+}
+
+// OwnerReference identifies the object that owns and manages another object
+type OwnerReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	Controller bool   `json:"controller,omitempty"`
+}
+
+// LabelSelector represents a label query over a set of resources. The
+// requirements of MatchLabels and MatchExpressions must all be satisfied.
+type LabelSelector struct {
+	MatchLabels      map[string]string          `json:"matchLabels,omitempty"`
+	MatchExpressions []LabelSelectorRequirement `json:"matchExpressions,omitempty"`
 }
 
-// LabelSelector represents a label query over a set of resources
-type LabelSelector struct {
-	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+// LabelSelectorRequirement is a set-based requirement on the value of a label.
+// Values must be non-empty for In and NotIn and empty for Exists and DoesNotExist.
+type LabelSelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
 }
 
 // ResourceRequirements describes the compute resource requirements
@@ -44,6 +72,7 @@ type Probe struct {
 	PeriodSeconds       int32          `json:"periodSeconds,omitempty"`
 	TimeoutSeconds      int32          `json:"timeoutSeconds,omitempty"`
 	FailureThreshold    int32          `json:"failureThreshold,omitempty"`
+	SuccessThreshold    int32          `json:"successThreshold,omitempty"`
 }
 
 // HTTPGetAction describes an action based on HTTP Get requests
@@ -63,6 +92,23 @@ type ExecAction struct {
 	Command []string `json:"command,omitempty"`
 }
 
+// Lifecycle describes actions to take in response to container lifecycle
+// events
+type Lifecycle struct {
+	// PostStart runs right after the container starts; if it fails, the
+	// container is killed and restarted by its restart policy
+	PostStart *LifecycleHandler `json:"postStart,omitempty"`
+	// PreStop runs before the container is stopped
+	PreStop *LifecycleHandler `json:"preStop,omitempty"`
+}
+
+// LifecycleHandler is the action of a lifecycle hook: exactly one of Exec
+// and HTTPGet
+type LifecycleHandler struct {
+	Exec    *ExecAction    `json:"exec,omitempty"`
+	HTTPGet *HTTPGetAction `json:"httpGet,omitempty"`
+}
+
 // Pod represents a collection of containers that can run on a host
 type Pod struct {
 	APIVersion string    `json:"apiVersion"`
@@ -78,16 +124,130 @@ type PodSpec struct {
 	RestartPolicy string            `json:"restartPolicy,omitempty"`
 	NodeSelector  map[string]string `json:"nodeSelector,omitempty"`
 	NodeName      string            `json:"nodeName,omitempty"`
+	Tolerations   []Toleration      `json:"tolerations,omitempty"`
+	Affinity      *Affinity         `json:"affinity,omitempty"`
+	// TopologySpreadConstraints spread matching pods evenly over the values
+	// of node labels such as zone or hostname
+	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// PriorityClassName names the PriorityClass giving the pod its priority.
+	// When empty, the global default class applies, if there is one.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Priority is resolved from the priority class when the pod is created.
+	// Higher priority pods are scheduled first and may preempt lower ones.
+	Priority *int32 `json:"priority,omitempty"`
+	// SchedulerName names the scheduler profile that places the pod. When
+	// empty, the default scheduler does.
+	SchedulerName string `json:"schedulerName,omitempty"`
+	// TerminationGracePeriodSeconds is how long the pod's containers have to
+	// shut down after a deletion is requested, preStop hooks included, before
+	// they are killed. When nil, DefaultTerminationGracePeriodSeconds applies.
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+}
+
+// TopologySpreadConstraint limits how unevenly the pods matched by
+// LabelSelector may be spread over the topology domains of TopologyKey
+type TopologySpreadConstraint struct {
+	// MaxSkew is the largest difference allowed between the number of
+	// matching pods in any domain and in the domain with the fewest
+	MaxSkew     int32  `json:"maxSkew"`
+	TopologyKey string `json:"topologyKey"`
+	// WhenUnsatisfiable is DoNotSchedule to filter out nodes that would
+	// exceed MaxSkew, or ScheduleAnyway to only prefer the nodes that reduce
+	// the skew
+	WhenUnsatisfiable string         `json:"whenUnsatisfiable"`
+	LabelSelector     *LabelSelector `json:"labelSelector,omitempty"`
+}
+
+// Affinity groups the pod's scheduling constraints on node labels and on the
+// pods already running in the same topology domain
+type Affinity struct {
+	NodeAffinity    *NodeAffinity    `json:"nodeAffinity,omitempty"`
+	PodAffinity     *PodAffinity     `json:"podAffinity,omitempty"`
+	PodAntiAffinity *PodAntiAffinity `json:"podAntiAffinity,omitempty"`
+}
+
+// NodeAffinity constrains the nodes a pod can be scheduled on by their labels.
+// Like the node selector it is only checked at scheduling time.
+type NodeAffinity struct {
+	// The node must match at least one of the required terms
+	RequiredDuringSchedulingIgnoredDuringExecution *NodeSelector `json:"requiredDuringSchedulingIgnoredDuringExecution,omitempty"`
+	// Nodes matching preferred terms score the terms' weights
+	PreferredDuringSchedulingIgnoredDuringExecution []PreferredSchedulingTerm `json:"preferredDuringSchedulingIgnoredDuringExecution,omitempty"`
+}
+
+// NodeSelector matches the nodes that satisfy any of its terms
+type NodeSelector struct {
+	NodeSelectorTerms []NodeSelectorTerm `json:"nodeSelectorTerms"`
+}
+
+// NodeSelectorTerm matches the nodes whose labels satisfy all of its
+// set-based requirements
+type NodeSelectorTerm struct {
+	MatchExpressions []LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+// PreferredSchedulingTerm is a node selector term with a weight from 1 to 100
+type PreferredSchedulingTerm struct {
+	Weight     int32            `json:"weight"`
+	Preference NodeSelectorTerm `json:"preference"`
+}
+
+// PodAffinity attracts a pod to topology domains, e.g. a zone or a node,
+// already running pods matching its terms
+type PodAffinity struct {
+	RequiredDuringSchedulingIgnoredDuringExecution  []PodAffinityTerm         `json:"requiredDuringSchedulingIgnoredDuringExecution,omitempty"`
+	PreferredDuringSchedulingIgnoredDuringExecution []WeightedPodAffinityTerm `json:"preferredDuringSchedulingIgnoredDuringExecution,omitempty"`
+}
+
+// PodAntiAffinity keeps a pod out of topology domains already running pods
+// matching its terms
+type PodAntiAffinity struct {
+	RequiredDuringSchedulingIgnoredDuringExecution  []PodAffinityTerm         `json:"requiredDuringSchedulingIgnoredDuringExecution,omitempty"`
+	PreferredDuringSchedulingIgnoredDuringExecution []WeightedPodAffinityTerm `json:"preferredDuringSchedulingIgnoredDuringExecution,omitempty"`
+}
+
+// PodAffinityTerm selects pods by label in some namespaces. Nodes with the
+// same value of the TopologyKey label, e.g. "zone" or "hostname", form a
+// topology domain.
+type PodAffinityTerm struct {
+	LabelSelector *LabelSelector `json:"labelSelector,omitempty"`
+	// Namespaces to select pods from; empty means the pod's own namespace
+	Namespaces  []string `json:"namespaces,omitempty"`
+	TopologyKey string   `json:"topologyKey"`
+}
+
+// WeightedPodAffinityTerm is a pod affinity term with a weight from 1 to 100
+type WeightedPodAffinityTerm struct {
+	Weight          int32           `json:"weight"`
+	PodAffinityTerm PodAffinityTerm `json:"podAffinityTerm"`
+}
+
+// Toleration lets a pod be scheduled on, or keep running on, a node with a
+// matching taint. An empty key with operator Exists matches every taint, and
+// an empty effect matches every effect.
+type Toleration struct {
+	Key      string `json:"key,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Effect   string `json:"effect,omitempty"`
+	// TolerationSeconds bounds how long a pod keeps running on a node after
+	// a matching NoExecute taint is added. Nil tolerates the taint forever.
+	TolerationSeconds *int64 `json:"tolerationSeconds,omitempty"`
 }
 
 // Container represents a single container that is run within a pod
 type Container struct {
 	Name           string               `json:"name"`
 	Image          string               `json:"image"`
+	Command        []string             `json:"command,omitempty"`
+	Args           []string             `json:"args,omitempty"`
+	WorkingDir     string               `json:"workingDir,omitempty"`
 	Ports          []ContainerPort      `json:"ports,omitempty"`
 	Resources      ResourceRequirements `json:"resources,omitempty"`
 	LivenessProbe  *Probe               `json:"livenessProbe,omitempty"`
 	ReadinessProbe *Probe               `json:"readinessProbe,omitempty"`
+	StartupProbe   *Probe               `json:"startupProbe,omitempty"`
+	Lifecycle      *Lifecycle           `json:"lifecycle,omitempty"`
 	Env            []EnvVar             `json:"env,omitempty"`
 }
 
@@ -104,6 +264,9 @@ type PodStatus struct {
 	ContainerStatuses []ContainerStatus  `json:"containerStatuses,omitempty"`
 	PodIP             string             `json:"podIP,omitempty"`
 	StartTime         *time.Time         `json:"startTime,omitempty"`
+	// NominatedNodeName is the node the scheduler preempted pods on to make
+	// room for this pod, until the pod is bound
+	NominatedNodeName string `json:"nominatedNodeName,omitempty"`
 }
 
 // PodCondition contains details for the current condition of this pod
@@ -124,6 +287,9 @@ type ContainerStatus struct {
 	ImageID      string `json:"imageID"`
 	ContainerID  string `json:"containerID,omitempty"`
 	State        ContainerState `json:"state,omitempty"`
+	// LastTerminationState is how the container last exited before it was
+	// restarted
+	LastTerminationState ContainerState `json:"lastState,omitempty"`
 }
 
 // ContainerState holds a possible state of container
@@ -166,8 +332,16 @@ type Service struct {
 // ServiceSpec describes the attributes that a user creates on a service
 type ServiceSpec struct {
 	Selector map[string]string `json:"selector"`
-	Ports    []ServicePort     `json:"ports"`
-	Type     string            `json:"type,omitempty"`
+	// SelectorExpressions are set-based requirements the selected pods must
+	// satisfy in addition to Selector
+	SelectorExpressions []LabelSelectorRequirement `json:"selectorExpressions,omitempty"`
+	Ports               []ServicePort              `json:"ports"`
+	Type                string                     `json:"type,omitempty"`
+}
+
+// LabelSelector returns the selector of the pods that back the service
+func (spec *ServiceSpec) LabelSelector() LabelSelector {
+	return LabelSelector{MatchLabels: spec.Selector, MatchExpressions: spec.SelectorExpressions}
 }
 
 // ServicePort contains information on service's port
@@ -218,6 +392,12 @@ type DeploymentSpec struct {
 	Selector LabelSelector      `json:"selector"`
 	Template PodTemplateSpec    `json:"template"`
 	Strategy DeploymentStrategy `json:"strategy,omitempty"`
+	// ProgressDeadlineSeconds is how long a rollout may go without progress
+	// before it is reported as failed. Defaults to 600 seconds.
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+	// RevisionHistoryLimit is the number of old ReplicaSets kept to allow
+	// rollback. Defaults to 10.
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 }
 
 // PodTemplateSpec describes the data a pod should have when created from a template
@@ -232,7 +412,17 @@ type DeploymentStrategy struct {
 	RollingUpdate *RollingUpdateStrategy `json:"rollingUpdate,omitempty"`
 }
 
-// RollingUpdateStrategy specifies the strategy used to replace old Pods by new ones
+// PodTemplateHashLabel is added to pods created by a Deployment to identify their template
+const PodTemplateHashLabel = "pod-template-hash"
+
+// Deployment strategy types
+const (
+	RecreateDeploymentStrategyType      = "Recreate"
+	RollingUpdateDeploymentStrategyType = "RollingUpdate"
+)
+
+// RollingUpdateStrategy specifies the strategy used to replace old Pods by new ones.
+// When omitted, up to 25% of the replicas may surge or be unavailable.
 type RollingUpdateStrategy struct {
 	MaxUnavailable int32 `json:"maxUnavailable,omitempty"`
 	MaxSurge       int32 `json:"maxSurge,omitempty"`
@@ -259,6 +449,55 @@ type DeploymentCondition struct {
 	Message            string    `json:"message,omitempty"`
 }
 
+// RevisionAnnotation records the rollout revision of a ReplicaSet owned by a Deployment
+const RevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// DeploymentRevision describes one entry in a Deployment's rollout history
+type DeploymentRevision struct {
+	Revision   int64           `json:"revision"`
+	ReplicaSet string          `json:"replicaSet"`
+	Replicas   int32           `json:"replicas"`
+	Template   PodTemplateSpec `json:"template"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// ReplicaSet ensures that a specified number of pod replicas are running
+type ReplicaSet struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Metadata   ObjectMeta       `json:"metadata"`
+	Spec       ReplicaSetSpec   `json:"spec"`
+	Status     ReplicaSetStatus `json:"status,omitempty"`
+}
+
+// ReplicaSetSpec is the specification of a ReplicaSet
+type ReplicaSetSpec struct {
+	Replicas int32           `json:"replicas"`
+	Selector LabelSelector   `json:"selector"`
+	Template PodTemplateSpec `json:"template"`
+}
+
+// ReplicaSetStatus represents the current status of a ReplicaSet
+type ReplicaSetStatus struct {
+	Replicas           int32 `json:"replicas"`
+	ReadyReplicas      int32 `json:"readyReplicas,omitempty"`
+	AvailableReplicas  int32 `json:"availableReplicas,omitempty"`
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// PriorityClass maps a priority class name to the priority of the pods that
+// name it. PriorityClasses are not namespaced.
+type PriorityClass struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   ObjectMeta `json:"metadata"`
+	Value      int32      `json:"value"`
+	// GlobalDefault makes the class apply to pods without a
+	// priorityClassName. At most one class may be the global default.
+	GlobalDefault bool   `json:"globalDefault,omitempty"`
+	Description   string `json:"description,omitempty"`
+}
+
 // Node represents a worker node in the cluster
 type Node struct {
 	APIVersion string     `json:"apiVersion"`
@@ -270,8 +509,19 @@ type Node struct {
 
 // NodeSpec describes the attributes that a node is created with
 type NodeSpec struct {
-	Unschedulable bool   `json:"unschedulable,omitempty"`
-	ExternalID    string `json:"externalID,omitempty"`
+	Unschedulable bool    `json:"unschedulable,omitempty"`
+	ExternalID    string  `json:"externalID,omitempty"`
+	Taints        []Taint `json:"taints,omitempty"`
+}
+
+// Taint repels pods that don't tolerate it from a node
+type Taint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Effect string `json:"effect"`
+	// TimeAdded is when a NoExecute taint was added, from which the
+	// tolerationSeconds of tolerating pods are counted
+	TimeAdded *time.Time `json:"timeAdded,omitempty"`
 }
 
 // NodeStatus is information about the current status of a node
@@ -310,4 +560,18 @@ type NodeSystemInfo struct {
 	ContainerRuntimeVersion string `json:"containerRuntimeVersion"`
 	Architecture            string `json:"architecture"`
 	OperatingSystem         string `json:"operatingSystem"`
-}
\ No newline at end of file
+}
+
+// Watch event types
+const (
+	WatchEventAdded    = "ADDED"
+	WatchEventModified = "MODIFIED"
+	WatchEventDeleted  = "DELETED"
+)
+
+// WatchEvent is a single change streamed by a watch request
+type WatchEvent struct {
+	Type            string          `json:"type"`
+	ResourceVersion string          `json:"resourceVersion"`
+	Object          json.RawMessage `json:"object"`
+}
@@ -324,6 +324,209 @@ func TestValidatePod(t *testing.T) {
 			wantErr: true,
 			errMsg:  "must be one of: Always, OnFailure, Never",
 		},
+		{
+			name: "valid tolerations",
+			pod: &Pod{
+				Metadata: ObjectMeta{
+					Name: "tolerant",
+				},
+				Spec: PodSpec{
+					Containers: []Container{
+						{
+							Name:  "nginx",
+							Image: "nginx:latest",
+						},
+					},
+					Tolerations: []Toleration{
+						{Key: "dedicated", Value: "gpu", Effect: TaintEffectNoSchedule},
+						{Operator: TolerationOpExists},
+						{Key: TaintNodeNotReady, Operator: TolerationOpExists, Effect: TaintEffectNoExecute, TolerationSeconds: int64Ptr(300)},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "toleration seconds without NoExecute",
+			pod: &Pod{
+				Metadata: ObjectMeta{
+					Name: "bad-toleration",
+				},
+				Spec: PodSpec{
+					Containers: []Container{
+						{
+							Name:  "nginx",
+							Image: "nginx:latest",
+						},
+					},
+					Tolerations: []Toleration{
+						{Key: "dedicated", Effect: TaintEffectNoSchedule, TolerationSeconds: int64Ptr(60)},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "may only be set when the effect is NoExecute",
+		},
+		{
+			name: "toleration with Exists and a value",
+			pod: &Pod{
+				Metadata: ObjectMeta{
+					Name: "bad-toleration",
+				},
+				Spec: PodSpec{
+					Containers: []Container{
+						{
+							Name:  "nginx",
+							Image: "nginx:latest",
+						},
+					},
+					Tolerations: []Toleration{
+						{Key: "dedicated", Operator: TolerationOpExists, Value: "gpu"},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "must be empty for operator Exists",
+		},
+		{
+			name: "valid probes",
+			pod: &Pod{
+				Metadata: ObjectMeta{
+					Name: "probed",
+				},
+				Spec: PodSpec{
+					Containers: []Container{
+						{
+							Name:           "app",
+							Image:          "app:latest",
+							StartupProbe:   &Probe{HTTPGet: &HTTPGetAction{Path: "/healthz", Port: 8080}, FailureThreshold: 30, SuccessThreshold: 1},
+							LivenessProbe:  &Probe{TCPSocket: &TCPSocketAction{Port: 8080}},
+							ReadinessProbe: &Probe{Exec: &ExecAction{Command: []string{"cat", "/tmp/ready"}}, SuccessThreshold: 3},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "startup probe with success threshold",
+			pod: &Pod{
+				Metadata: ObjectMeta{
+					Name: "probed",
+				},
+				Spec: PodSpec{
+					Containers: []Container{
+						{
+							Name:         "app",
+							Image:        "app:latest",
+							StartupProbe: &Probe{TCPSocket: &TCPSocketAction{Port: 8080}, SuccessThreshold: 2},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "startupProbe.successThreshold': must be 1",
+		},
+		{
+			name: "probe without action",
+			pod: &Pod{
+				Metadata: ObjectMeta{
+					Name: "probed",
+				},
+				Spec: PodSpec{
+					Containers: []Container{
+						{
+							Name:         "app",
+							Image:        "app:latest",
+							StartupProbe: &Probe{PeriodSeconds: 10},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "must have exactly one of httpGet, tcpSocket or exec",
+		},
+		{
+			name: "command, working dir and lifecycle hooks",
+			pod: &Pod{
+				Metadata: ObjectMeta{
+					Name: "hooked",
+				},
+				Spec: PodSpec{
+					Containers: []Container{
+						{
+							Name:       "app",
+							Image:      "app:latest",
+							Command:    []string{"/bin/app"},
+							Args:       []string{"--port", "8080"},
+							WorkingDir: "/srv",
+							Lifecycle: &Lifecycle{
+								PostStart: &LifecycleHandler{Exec: &ExecAction{Command: []string{"/bin/warm-cache"}}},
+								PreStop:   &LifecycleHandler{HTTPGet: &HTTPGetAction{Path: "/drain", Port: 8080}},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative termination grace period",
+			pod: &Pod{
+				Metadata: ObjectMeta{
+					Name: "graceful",
+				},
+				Spec: PodSpec{
+					Containers: []Container{
+						{
+							Name:  "app",
+							Image: "app:latest",
+						},
+					},
+					TerminationGracePeriodSeconds: func() *int64 { seconds := int64(-1); return &seconds }(),
+				},
+			},
+			wantErr: true,
+			errMsg:  "must be non-negative",
+		},
+		{
+			name: "relative working dir",
+			pod: &Pod{
+				Metadata: ObjectMeta{
+					Name: "hooked",
+				},
+				Spec: PodSpec{
+					Containers: []Container{
+						{
+							Name:       "app",
+							Image:      "app:latest",
+							WorkingDir: "srv",
+						},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "must be an absolute path",
+		},
+		{
+			name: "lifecycle hook without action",
+			pod: &Pod{
+				Metadata: ObjectMeta{
+					Name: "hooked",
+				},
+				Spec: PodSpec{
+					Containers: []Container{
+						{
+							Name:      "app",
+							Image:     "app:latest",
+							Lifecycle: &Lifecycle{PreStop: &LifecycleHandler{}},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "must have exactly one of httpGet or exec",
+		},
 	}
 
 	for _, tt := range tests {
@@ -461,6 +664,113 @@ func TestValidateDeployment(t *testing.T) {
 			wantErr: true,
 			errMsg:  "must be non-negative",
 		},
+		{
+			name: "deployment with selector not matching template",
+			deployment: &Deployment{
+				Metadata: ObjectMeta{
+					Name: "mismatched-selector",
+				},
+				Spec: DeploymentSpec{
+					Replicas: 1,
+					Selector: LabelSelector{
+						MatchLabels: map[string]string{"app": "web"},
+					},
+					Template: PodTemplateSpec{
+						Metadata: ObjectMeta{
+							Labels: map[string]string{"app": "api"},
+						},
+						Spec: PodSpec{
+							Containers: []Container{
+								{
+									Name:  "nginx",
+									Image: "nginx:latest",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "selector does not match template labels",
+		},
+		{
+			name: "deployment with unknown strategy",
+			deployment: &Deployment{
+				Metadata: ObjectMeta{
+					Name: "bad-strategy",
+				},
+				Spec: DeploymentSpec{
+					Replicas: 1,
+					Strategy: DeploymentStrategy{Type: "BlueGreen"},
+					Template: PodTemplateSpec{
+						Spec: PodSpec{
+							Containers: []Container{
+								{
+									Name:  "nginx",
+									Image: "nginx:latest",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "must be one of: RollingUpdate, Recreate",
+		},
+		{
+			name: "deployment with rolling update limits both zero",
+			deployment: &Deployment{
+				Metadata: ObjectMeta{
+					Name: "zero-limits",
+				},
+				Spec: DeploymentSpec{
+					Replicas: 1,
+					Strategy: DeploymentStrategy{
+						Type:          RollingUpdateDeploymentStrategyType,
+						RollingUpdate: &RollingUpdateStrategy{},
+					},
+					Template: PodTemplateSpec{
+						Spec: PodSpec{
+							Containers: []Container{
+								{
+									Name:  "nginx",
+									Image: "nginx:latest",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "maxSurge and maxUnavailable may not both be zero",
+		},
+		{
+			name: "deployment with rolling update params on recreate",
+			deployment: &Deployment{
+				Metadata: ObjectMeta{
+					Name: "recreate-params",
+				},
+				Spec: DeploymentSpec{
+					Replicas: 1,
+					Strategy: DeploymentStrategy{
+						Type:          RecreateDeploymentStrategyType,
+						RollingUpdate: &RollingUpdateStrategy{MaxSurge: 1},
+					},
+					Template: PodTemplateSpec{
+						Spec: PodSpec{
+							Containers: []Container{
+								{
+									Name:  "nginx",
+									Image: "nginx:latest",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+			errMsg:  "may not be specified when strategy type is Recreate",
+		},
 	}
 
 	for _, tt := range tests {
@@ -477,6 +787,149 @@ func TestValidateDeployment(t *testing.T) {
 	}
 }
 
+func TestValidateReplicaSet(t *testing.T) {
+	template := PodTemplateSpec{
+		Metadata: ObjectMeta{
+			Labels: map[string]string{"app": "web"},
+		},
+		Spec: PodSpec{
+			Containers: []Container{
+				{
+					Name:  "nginx",
+					Image: "nginx:latest",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		replicaSet *ReplicaSet
+		wantErr    bool
+		errMsg     string
+	}{
+		{
+			name: "valid replica set",
+			replicaSet: &ReplicaSet{
+				Metadata: ObjectMeta{Name: "web"},
+				Spec: ReplicaSetSpec{
+					Replicas: 2,
+					Selector: LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					Template: template,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "replica set without selector",
+			replicaSet: &ReplicaSet{
+				Metadata: ObjectMeta{Name: "web"},
+				Spec: ReplicaSetSpec{
+					Replicas: 2,
+					Template: template,
+				},
+			},
+			wantErr: true,
+			errMsg:  "selector is required",
+		},
+		{
+			name: "replica set with negative replicas",
+			replicaSet: &ReplicaSet{
+				Metadata: ObjectMeta{Name: "web"},
+				Spec: ReplicaSetSpec{
+					Replicas: -1,
+					Selector: LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					Template: template,
+				},
+			},
+			wantErr: true,
+			errMsg:  "must be non-negative",
+		},
+		{
+			name: "replica set with set-based selector",
+			replicaSet: &ReplicaSet{
+				Metadata: ObjectMeta{Name: "web"},
+				Spec: ReplicaSetSpec{
+					Replicas: 2,
+					Selector: LabelSelector{MatchExpressions: []LabelSelectorRequirement{
+						{Key: "app", Operator: LabelSelectorOpIn, Values: []string{"web", "api"}},
+					}},
+					Template: template,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "replica set with set-based selector not matching template",
+			replicaSet: &ReplicaSet{
+				Metadata: ObjectMeta{Name: "web"},
+				Spec: ReplicaSetSpec{
+					Replicas: 2,
+					Selector: LabelSelector{MatchExpressions: []LabelSelectorRequirement{
+						{Key: "app", Operator: LabelSelectorOpNotIn, Values: []string{"web"}},
+					}},
+					Template: template,
+				},
+			},
+			wantErr: true,
+			errMsg:  "selector does not match template labels",
+		},
+		{
+			name: "replica set with invalid selector operator",
+			replicaSet: &ReplicaSet{
+				Metadata: ObjectMeta{Name: "web"},
+				Spec: ReplicaSetSpec{
+					Replicas: 2,
+					Selector: LabelSelector{MatchExpressions: []LabelSelectorRequirement{
+						{Key: "app", Operator: "Like", Values: []string{"web"}},
+					}},
+					Template: template,
+				},
+			},
+			wantErr: true,
+			errMsg:  "must be one of: In, NotIn, Exists, DoesNotExist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReplicaSet(tt.replicaSet)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateReplicaSet() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && err != nil && !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("ValidateReplicaSet() error = %v, want error containing %v", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestPodTerminationGracePeriod(t *testing.T) {
+	specSeconds := int64(60)
+	deletionSeconds := int64(5)
+	pod := &Pod{}
+	if got := PodTerminationGracePeriod(pod); got != 30*time.Second {
+		t.Errorf("Expected the default grace period, got %s", got)
+	}
+
+	pod.Spec.TerminationGracePeriodSeconds = &specSeconds
+	if got := PodTerminationGracePeriod(pod); got != time.Minute {
+		t.Errorf("Expected the spec's grace period, got %s", got)
+	}
+
+	// A deletion's grace period wins over the spec's
+	now := time.Now()
+	pod.Metadata.DeletionTimestamp = &now
+	pod.Metadata.DeletionGracePeriodSeconds = &deletionSeconds
+	if got := PodTerminationGracePeriod(pod); got != 5*time.Second {
+		t.Errorf("Expected the deletion's grace period, got %s", got)
+	}
+	if !IsPodTerminating(pod) {
+		t.Error("Expected the pod to be terminating")
+	}
+}
+
 func TestIsValidName(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -87,6 +87,26 @@ func ValidateDeployment(deployment *Deployment) error {
 	return nil
 }
 
+// ValidateReplicaSet validates a ReplicaSet resource
+func ValidateReplicaSet(replicaSet *ReplicaSet) error {
+	var errors ValidationErrors
+
+	// Validate metadata
+	if errs := validateObjectMeta(&replicaSet.Metadata); errs != nil {
+		errors = append(errors, errs...)
+	}
+
+	// Validate spec
+	if errs := validateReplicaSetSpec(&replicaSet.Spec); errs != nil {
+		errors = append(errors, errs...)
+	}
+
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
 // ValidateNode validates a Node resource
 func ValidateNode(node *Node) error {
 	var errors ValidationErrors
@@ -96,12 +116,88 @@ func ValidateNode(node *Node) error {
 		errors = append(errors, errs...)
 	}
 
+	// Validate spec
+	if errs := validateNodeSpec(&node.Spec); errs != nil {
+		errors = append(errors, errs...)
+	}
+
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
+// ValidatePriorityClass validates a PriorityClass resource
+func ValidatePriorityClass(class *PriorityClass) error {
+	var errors ValidationErrors
+
+	// Validate metadata
+	if errs := validateObjectMeta(&class.Metadata); errs != nil {
+		errors = append(errors, errs...)
+	}
+
+	// Priority classes are cluster-wide
+	if class.Metadata.Namespace != "" {
+		errors = append(errors, ValidationError{
+			Field:   "metadata.namespace",
+			Message: "priority classes are not namespaced",
+		})
+	}
+
+	if class.Value > HighestUserDefinablePriority {
+		errors = append(errors, ValidationError{
+			Field:   "value",
+			Message: fmt.Sprintf("must be at most %d", HighestUserDefinablePriority),
+		})
+	}
+
 	if len(errors) > 0 {
 		return errors
 	}
 	return nil
 }
 
+// validateNodeSpec validates a NodeSpec
+func validateNodeSpec(spec *NodeSpec) ValidationErrors {
+	var errors ValidationErrors
+
+	for i, taint := range spec.Taints {
+		fieldPath := fmt.Sprintf("spec.taints[%d]", i)
+
+		if taint.Key == "" {
+			errors = append(errors, ValidationError{
+				Field:   fieldPath + ".key",
+				Message: "key is required",
+			})
+		}
+
+		if !isValidTaintEffect(taint.Effect) {
+			errors = append(errors, ValidationError{
+				Field:   fieldPath + ".effect",
+				Message: "must be one of: NoSchedule, PreferNoSchedule, NoExecute",
+			})
+		}
+
+		// A node has at most one taint per key and effect
+		for j := 0; j < i; j++ {
+			if spec.Taints[j].MatchTaint(&taint) {
+				errors = append(errors, ValidationError{
+					Field:   fieldPath,
+					Message: fmt.Sprintf("duplicates taint %s", spec.Taints[j].String()),
+				})
+				break
+			}
+		}
+	}
+
+	return errors
+}
+
+// isValidTaintEffect checks if an effect is one of the known taint effects
+func isValidTaintEffect(effect string) bool {
+	return contains([]string{TaintEffectNoSchedule, TaintEffectPreferNoSchedule, TaintEffectNoExecute}, effect)
+}
+
 // validateObjectMeta validates common metadata fields
 func validateObjectMeta(meta *ObjectMeta) ValidationErrors {
 	var errors ValidationErrors
@@ -160,6 +256,261 @@ func validatePodSpec(spec *PodSpec) ValidationErrors {
 		}
 	}
 
+	// Validate priority class name
+	if spec.PriorityClassName != "" && !isValidName(spec.PriorityClassName) {
+		errors = append(errors, ValidationError{
+			Field:   "spec.priorityClassName",
+			Message: "priorityClassName must be a valid DNS subdomain",
+		})
+	}
+
+	// Validate scheduler name
+	if spec.SchedulerName != "" && !isValidName(spec.SchedulerName) {
+		errors = append(errors, ValidationError{
+			Field:   "spec.schedulerName",
+			Message: "schedulerName must be a valid DNS subdomain",
+		})
+	}
+
+	// Validate termination grace period
+	if spec.TerminationGracePeriodSeconds != nil && *spec.TerminationGracePeriodSeconds < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "spec.terminationGracePeriodSeconds",
+			Message: "must be non-negative",
+		})
+	}
+
+	// Validate tolerations
+	for i, toleration := range spec.Tolerations {
+		errors = append(errors, validateToleration(&toleration, fmt.Sprintf("spec.tolerations[%d]", i))...)
+	}
+
+	// Validate affinity
+	if spec.Affinity != nil {
+		errors = append(errors, validateAffinity(spec.Affinity, "spec.affinity")...)
+	}
+
+	// Validate topology spread constraints
+	for i, constraint := range spec.TopologySpreadConstraints {
+		fieldPath := fmt.Sprintf("spec.topologySpreadConstraints[%d]", i)
+		errors = append(errors, validateTopologySpreadConstraint(&constraint, fieldPath)...)
+
+		// Constraints are identified by their topology key and action
+		for j := 0; j < i; j++ {
+			previous := spec.TopologySpreadConstraints[j]
+			if previous.TopologyKey == constraint.TopologyKey && previous.WhenUnsatisfiable == constraint.WhenUnsatisfiable {
+				errors = append(errors, ValidationError{
+					Field:   fieldPath,
+					Message: fmt.Sprintf("duplicates the constraint on %s with %s", constraint.TopologyKey, constraint.WhenUnsatisfiable),
+				})
+				break
+			}
+		}
+	}
+
+	return errors
+}
+
+// validateTopologySpreadConstraint validates a single topology spread constraint
+func validateTopologySpreadConstraint(constraint *TopologySpreadConstraint, fieldPath string) ValidationErrors {
+	var errors ValidationErrors
+
+	if constraint.MaxSkew < 1 {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".maxSkew",
+			Message: "must be at least 1",
+		})
+	}
+
+	if constraint.TopologyKey == "" {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".topologyKey",
+			Message: "topologyKey is required",
+		})
+	}
+
+	if !contains([]string{DoNotSchedule, ScheduleAnyway}, constraint.WhenUnsatisfiable) {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".whenUnsatisfiable",
+			Message: "must be one of: DoNotSchedule, ScheduleAnyway",
+		})
+	}
+
+	if constraint.LabelSelector == nil {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".labelSelector",
+			Message: "labelSelector is required",
+		})
+	} else {
+		errors = append(errors, validateLabelSelector(constraint.LabelSelector, fieldPath+".labelSelector")...)
+	}
+
+	return errors
+}
+
+// validateAffinity validates the node, pod and pod anti-affinity of a pod
+func validateAffinity(affinity *Affinity, fieldPath string) ValidationErrors {
+	var errors ValidationErrors
+
+	if nodeAffinity := affinity.NodeAffinity; nodeAffinity != nil {
+		path := fieldPath + ".nodeAffinity"
+
+		if required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			requiredPath := path + ".requiredDuringSchedulingIgnoredDuringExecution"
+			if len(required.NodeSelectorTerms) == 0 {
+				errors = append(errors, ValidationError{
+					Field:   requiredPath + ".nodeSelectorTerms",
+					Message: "at least one node selector term is required",
+				})
+			}
+			for i, term := range required.NodeSelectorTerms {
+				errors = append(errors, validateNodeSelectorTerm(&term, fmt.Sprintf("%s.nodeSelectorTerms[%d]", requiredPath, i))...)
+			}
+		}
+
+		for i, preferred := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			preferredPath := fmt.Sprintf("%s.preferredDuringSchedulingIgnoredDuringExecution[%d]", path, i)
+			errors = append(errors, validateSchedulingWeight(preferred.Weight, preferredPath)...)
+			errors = append(errors, validateNodeSelectorTerm(&preferred.Preference, preferredPath+".preference")...)
+		}
+	}
+
+	if podAffinity := affinity.PodAffinity; podAffinity != nil {
+		errors = append(errors, validatePodAffinityTerms(podAffinity.RequiredDuringSchedulingIgnoredDuringExecution, podAffinity.PreferredDuringSchedulingIgnoredDuringExecution, fieldPath+".podAffinity")...)
+	}
+	if podAntiAffinity := affinity.PodAntiAffinity; podAntiAffinity != nil {
+		errors = append(errors, validatePodAffinityTerms(podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, fieldPath+".podAntiAffinity")...)
+	}
+
+	return errors
+}
+
+// validateNodeSelectorTerm validates the match expressions of a node selector term
+func validateNodeSelectorTerm(term *NodeSelectorTerm, fieldPath string) ValidationErrors {
+	var errors ValidationErrors
+
+	if len(term.MatchExpressions) == 0 {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".matchExpressions",
+			Message: "at least one match expression is required",
+		})
+	}
+	for i, requirement := range term.MatchExpressions {
+		errors = append(errors, validateLabelSelectorRequirement(&requirement, fmt.Sprintf("%s.matchExpressions[%d]", fieldPath, i))...)
+	}
+
+	return errors
+}
+
+// validatePodAffinityTerms validates the required and preferred terms of a
+// pod affinity or anti-affinity
+func validatePodAffinityTerms(required []PodAffinityTerm, preferred []WeightedPodAffinityTerm, fieldPath string) ValidationErrors {
+	var errors ValidationErrors
+
+	for i, term := range required {
+		errors = append(errors, validatePodAffinityTerm(&term, fmt.Sprintf("%s.requiredDuringSchedulingIgnoredDuringExecution[%d]", fieldPath, i))...)
+	}
+	for i, weighted := range preferred {
+		preferredPath := fmt.Sprintf("%s.preferredDuringSchedulingIgnoredDuringExecution[%d]", fieldPath, i)
+		errors = append(errors, validateSchedulingWeight(weighted.Weight, preferredPath)...)
+		errors = append(errors, validatePodAffinityTerm(&weighted.PodAffinityTerm, preferredPath+".podAffinityTerm")...)
+	}
+
+	return errors
+}
+
+// validatePodAffinityTerm validates a single pod affinity term
+func validatePodAffinityTerm(term *PodAffinityTerm, fieldPath string) ValidationErrors {
+	var errors ValidationErrors
+
+	if term.TopologyKey == "" {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".topologyKey",
+			Message: "topologyKey is required",
+		})
+	}
+
+	if term.LabelSelector == nil {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".labelSelector",
+			Message: "labelSelector is required",
+		})
+	} else {
+		errors = append(errors, validateLabelSelector(term.LabelSelector, fieldPath+".labelSelector")...)
+	}
+
+	for i, namespace := range term.Namespaces {
+		if !isValidName(namespace) {
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("%s.namespaces[%d]", fieldPath, i),
+				Message: "namespace must be a valid DNS subdomain",
+			})
+		}
+	}
+
+	return errors
+}
+
+// validateSchedulingWeight validates the weight of a preferred scheduling term
+func validateSchedulingWeight(weight int32, fieldPath string) ValidationErrors {
+	if weight < 1 || weight > 100 {
+		return ValidationErrors{{
+			Field:   fieldPath + ".weight",
+			Message: "must be between 1 and 100",
+		}}
+	}
+	return nil
+}
+
+// validateToleration validates a pod Toleration
+func validateToleration(toleration *Toleration, fieldPath string) ValidationErrors {
+	var errors ValidationErrors
+
+	switch toleration.Operator {
+	case TolerationOpEqual, "":
+		// An empty key only makes sense with Exists, matching every taint
+		if toleration.Key == "" {
+			errors = append(errors, ValidationError{
+				Field:   fieldPath + ".key",
+				Message: "key is required unless the operator is Exists",
+			})
+		}
+	case TolerationOpExists:
+		if toleration.Value != "" {
+			errors = append(errors, ValidationError{
+				Field:   fieldPath + ".value",
+				Message: "must be empty for operator Exists",
+			})
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".operator",
+			Message: "must be one of: Equal, Exists",
+		})
+	}
+
+	if toleration.Effect != "" && !isValidTaintEffect(toleration.Effect) {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".effect",
+			Message: "must be one of: NoSchedule, PreferNoSchedule, NoExecute",
+		})
+	}
+
+	// Only NoExecute taints evict running pods
+	if toleration.TolerationSeconds != nil {
+		if toleration.Effect != TaintEffectNoExecute {
+			errors = append(errors, ValidationError{
+				Field:   fieldPath + ".tolerationSeconds",
+				Message: "may only be set when the effect is NoExecute",
+			})
+		} else if *toleration.TolerationSeconds < 0 {
+			errors = append(errors, ValidationError{
+				Field:   fieldPath + ".tolerationSeconds",
+				Message: "must not be negative",
+			})
+		}
+	}
+
 	return errors
 }
 
@@ -198,9 +549,150 @@ func validateContainer(container *Container, fieldPath string) ValidationErrors
 		}
 	}
 
+	// The working directory is inside the container's filesystem
+	if container.WorkingDir != "" && !strings.HasPrefix(container.WorkingDir, "/") {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".workingDir",
+			Message: "must be an absolute path",
+		})
+	}
+
+	// Validate lifecycle hooks
+	if container.Lifecycle != nil {
+		if container.Lifecycle.PostStart != nil {
+			errors = append(errors, validateLifecycleHandler(container.Lifecycle.PostStart, fieldPath+".lifecycle.postStart")...)
+		}
+		if container.Lifecycle.PreStop != nil {
+			errors = append(errors, validateLifecycleHandler(container.Lifecycle.PreStop, fieldPath+".lifecycle.preStop")...)
+		}
+	}
+
+	// Validate probes; only readiness probes may need more than one success
+	if container.LivenessProbe != nil {
+		errors = append(errors, validateProbe(container.LivenessProbe, fieldPath+".livenessProbe", false)...)
+	}
+	if container.ReadinessProbe != nil {
+		errors = append(errors, validateProbe(container.ReadinessProbe, fieldPath+".readinessProbe", true)...)
+	}
+	if container.StartupProbe != nil {
+		errors = append(errors, validateProbe(container.StartupProbe, fieldPath+".startupProbe", false)...)
+	}
+
+	return errors
+}
+
+// validateProbe validates a container probe: it must have exactly one action,
+// and its timings and thresholds can't be negative
+func validateProbe(probe *Probe, fieldPath string, allowSuccessThreshold bool) ValidationErrors {
+	var errors ValidationErrors
+
+	actions := 0
+	if probe.HTTPGet != nil {
+		actions++
+		errors = append(errors, validateHTTPGetAction(probe.HTTPGet, fieldPath+".httpGet")...)
+	}
+	if probe.TCPSocket != nil {
+		actions++
+		if probe.TCPSocket.Port <= 0 || probe.TCPSocket.Port > 65535 {
+			errors = append(errors, ValidationError{
+				Field:   fieldPath + ".tcpSocket.port",
+				Message: "must be between 1 and 65535",
+			})
+		}
+	}
+	if probe.Exec != nil {
+		actions++
+		errors = append(errors, validateExecAction(probe.Exec, fieldPath+".exec")...)
+	}
+	if actions != 1 {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath,
+			Message: "must have exactly one of httpGet, tcpSocket or exec",
+		})
+	}
+
+	fields := []struct {
+		name  string
+		value int32
+	}{
+		{"initialDelaySeconds", probe.InitialDelaySeconds},
+		{"periodSeconds", probe.PeriodSeconds},
+		{"timeoutSeconds", probe.TimeoutSeconds},
+		{"failureThreshold", probe.FailureThreshold},
+		{"successThreshold", probe.SuccessThreshold},
+	}
+	for _, field := range fields {
+		if field.value < 0 {
+			errors = append(errors, ValidationError{
+				Field:   fieldPath + "." + field.name,
+				Message: "must be non-negative",
+			})
+		}
+	}
+
+	if !allowSuccessThreshold && probe.SuccessThreshold > 1 {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".successThreshold",
+			Message: "must be 1",
+		})
+	}
+
+	return errors
+}
+
+// validateLifecycleHandler validates a postStart or preStop hook: it must
+// have exactly one action
+func validateLifecycleHandler(handler *LifecycleHandler, fieldPath string) ValidationErrors {
+	var errors ValidationErrors
+
+	actions := 0
+	if handler.HTTPGet != nil {
+		actions++
+		errors = append(errors, validateHTTPGetAction(handler.HTTPGet, fieldPath+".httpGet")...)
+	}
+	if handler.Exec != nil {
+		actions++
+		errors = append(errors, validateExecAction(handler.Exec, fieldPath+".exec")...)
+	}
+	if actions != 1 {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath,
+			Message: "must have exactly one of httpGet or exec",
+		})
+	}
+
+	return errors
+}
+
+// validateHTTPGetAction validates the port and scheme of an HTTP GET action
+func validateHTTPGetAction(action *HTTPGetAction, fieldPath string) ValidationErrors {
+	var errors ValidationErrors
+	if action.Port <= 0 || action.Port > 65535 {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".port",
+			Message: "must be between 1 and 65535",
+		})
+	}
+	if action.Scheme != "" && action.Scheme != "HTTP" && action.Scheme != "HTTPS" {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".scheme",
+			Message: "must be one of: HTTP, HTTPS",
+		})
+	}
 	return errors
 }
 
+// validateExecAction validates that an exec action has a command
+func validateExecAction(action *ExecAction, fieldPath string) ValidationErrors {
+	if len(action.Command) == 0 {
+		return ValidationErrors{{
+			Field:   fieldPath + ".command",
+			Message: "command is required",
+		}}
+	}
+	return nil
+}
+
 // validateServiceSpec validates a ServiceSpec
 func validateServiceSpec(spec *ServiceSpec) ValidationErrors {
 	var errors ValidationErrors
@@ -229,6 +721,11 @@ func validateServiceSpec(spec *ServiceSpec) ValidationErrors {
 		}
 	}
 
+	// Validate set-based selector requirements
+	for i, requirement := range spec.SelectorExpressions {
+		errors = append(errors, validateLabelSelectorRequirement(&requirement, fmt.Sprintf("spec.selectorExpressions[%d]", i))...)
+	}
+
 	// Validate service type
 	if spec.Type != "" {
 		validTypes := []string{"ClusterIP", "NodePort", "LoadBalancer"}
@@ -255,6 +752,68 @@ func validateDeploymentSpec(spec *DeploymentSpec) ValidationErrors {
 		})
 	}
 
+	// Validate strategy
+	switch spec.Strategy.Type {
+	case "", RollingUpdateDeploymentStrategyType:
+		if ru := spec.Strategy.RollingUpdate; ru != nil {
+			if ru.MaxSurge < 0 {
+				errors = append(errors, ValidationError{
+					Field:   "spec.strategy.rollingUpdate.maxSurge",
+					Message: "must be non-negative",
+				})
+			}
+			if ru.MaxUnavailable < 0 {
+				errors = append(errors, ValidationError{
+					Field:   "spec.strategy.rollingUpdate.maxUnavailable",
+					Message: "must be non-negative",
+				})
+			}
+			if ru.MaxSurge == 0 && ru.MaxUnavailable == 0 {
+				errors = append(errors, ValidationError{
+					Field:   "spec.strategy.rollingUpdate",
+					Message: "maxSurge and maxUnavailable may not both be zero",
+				})
+			}
+		}
+	case RecreateDeploymentStrategyType:
+		if spec.Strategy.RollingUpdate != nil {
+			errors = append(errors, ValidationError{
+				Field:   "spec.strategy.rollingUpdate",
+				Message: "may not be specified when strategy type is Recreate",
+			})
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "spec.strategy.type",
+			Message: "must be one of: RollingUpdate, Recreate",
+		})
+	}
+
+	// Revision history limit must be non-negative if specified
+	if spec.RevisionHistoryLimit != nil && *spec.RevisionHistoryLimit < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "spec.revisionHistoryLimit",
+			Message: "must be non-negative",
+		})
+	}
+
+	// Progress deadline must be positive if specified
+	if spec.ProgressDeadlineSeconds != nil && *spec.ProgressDeadlineSeconds <= 0 {
+		errors = append(errors, ValidationError{
+			Field:   "spec.progressDeadlineSeconds",
+			Message: "must be greater than zero",
+		})
+	}
+
+	// Selector must select the pods created from the template
+	errors = append(errors, validateLabelSelector(&spec.Selector, "spec.selector")...)
+	if !spec.Selector.Matches(spec.Template.Metadata.Labels) {
+		errors = append(errors, ValidationError{
+			Field:   "spec.selector",
+			Message: "selector does not match template labels",
+		})
+	}
+
 	// Validate pod template
 	if errs := validatePodSpec(&spec.Template.Spec); errs != nil {
 		for _, err := range errs {
@@ -266,6 +825,91 @@ func validateDeploymentSpec(spec *DeploymentSpec) ValidationErrors {
 	return errors
 }
 
+// validateReplicaSetSpec validates a ReplicaSetSpec
+func validateReplicaSetSpec(spec *ReplicaSetSpec) ValidationErrors {
+	var errors ValidationErrors
+
+	// Replicas must be non-negative
+	if spec.Replicas < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "spec.replicas",
+			Message: "must be non-negative",
+		})
+	}
+
+	// A ReplicaSet without a selector would adopt every pod in its namespace
+	if spec.Selector.Empty() {
+		errors = append(errors, ValidationError{
+			Field:   "spec.selector",
+			Message: "selector is required",
+		})
+	}
+
+	// Selector must select the pods created from the template
+	errors = append(errors, validateLabelSelector(&spec.Selector, "spec.selector")...)
+	if !spec.Selector.Matches(spec.Template.Metadata.Labels) {
+		errors = append(errors, ValidationError{
+			Field:   "spec.selector",
+			Message: "selector does not match template labels",
+		})
+	}
+
+	// Validate pod template
+	if errs := validatePodSpec(&spec.Template.Spec); errs != nil {
+		for _, err := range errs {
+			err.Field = "spec.template." + err.Field
+			errors = append(errors, err)
+		}
+	}
+
+	return errors
+}
+
+// validateLabelSelector validates the requirements of a LabelSelector
+func validateLabelSelector(selector *LabelSelector, fieldPath string) ValidationErrors {
+	var errors ValidationErrors
+	for i, requirement := range selector.MatchExpressions {
+		errors = append(errors, validateLabelSelectorRequirement(&requirement, fmt.Sprintf("%s.matchExpressions[%d]", fieldPath, i))...)
+	}
+	return errors
+}
+
+// validateLabelSelectorRequirement validates a single set-based label requirement
+func validateLabelSelectorRequirement(requirement *LabelSelectorRequirement, fieldPath string) ValidationErrors {
+	var errors ValidationErrors
+
+	if requirement.Key == "" {
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".key",
+			Message: "key is required",
+		})
+	}
+
+	switch requirement.Operator {
+	case LabelSelectorOpIn, LabelSelectorOpNotIn:
+		if len(requirement.Values) == 0 {
+			errors = append(errors, ValidationError{
+				Field:   fieldPath + ".values",
+				Message: "must be non-empty for operators In and NotIn",
+			})
+		}
+	case LabelSelectorOpExists, LabelSelectorOpDoesNotExist:
+		if len(requirement.Values) > 0 {
+			errors = append(errors, ValidationError{
+				Field:   fieldPath + ".values",
+				Message: "must be empty for operators Exists and DoesNotExist",
+			})
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Field:   fieldPath + ".operator",
+			Message: "must be one of: In, NotIn, Exists, DoesNotExist",
+		})
+	}
+
+	return errors
+}
+
 // isValidName checks if a name is a valid DNS subdomain
 func isValidName(name string) bool {
 	// DNS subdomain: lowercase alphanumeric characters, '-' or '.', 